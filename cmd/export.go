@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"parcel-service/internal/app/export"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/pkg/postgres"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export parcels as CSV or NDJSON",
+	Long:  `It will stream the parcels matching the filters as CSV with a header row or as one JSON object per line to a file or to stdout`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		filter := model.ExportFilter{}
+		filter.Status, _ = cmd.Flags().GetInt("status")
+		filter.UserID, _ = cmd.Flags().GetInt("user")
+		filter.CarrierID, _ = cmd.Flags().GetInt("carrier")
+		filter.Type, _ = cmd.Flags().GetString("type")
+		filter.Date, _ = cmd.Flags().GetString("date")
+
+		from, _ := cmd.Flags().GetString("from")
+		if from != "" {
+			t, err := model.ParseExportTime(from)
+			if err != nil {
+				return err
+			}
+			filter.From = t
+		}
+		to, _ := cmd.Flags().GetString("to")
+		if to != "" {
+			t, err := model.ParseExportTime(to)
+			if err != nil {
+				return err
+			}
+			filter.To = t
+		}
+
+		if err := filter.ValidateExportFilter(format); err != nil {
+			return err
+		}
+
+		db, err := postgres.New(&postgres.Config{
+			Host:     os.Getenv("DB_HOST"),
+			Port:     os.Getenv("DB_PORT"),
+			User:     os.Getenv("DB_USER"),
+			Password: os.Getenv("DB_PASSWORD"),
+			Name:     os.Getenv("DB_NAME"),
+		})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+
+		count, err := export.NewService(export.NewRepository(db)).ExportParcels(context.Background(), w, format, filter)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "exported %d parcels\n", count)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("format", model.ExportFormatCSV, "format of the export: csv or ndjson")
+	exportCmd.Flags().String("output", "", "path of the file to write, stdout by default")
+	exportCmd.Flags().Int("status", 0, "only export parcels with this status")
+	exportCmd.Flags().Int("user", 0, "only export parcels of this user")
+	exportCmd.Flags().Int("carrier", 0, "only export parcels of this carrier")
+	exportCmd.Flags().String("type", "", "only export parcels of this type")
+	exportCmd.Flags().String("date", model.ExportDateCreated, "date the range applies to: created or delivered")
+	exportCmd.Flags().String("from", "", "first date of the range, like 2021-03-01 or an RFC 3339 time")
+	exportCmd.Flags().String("to", "", "date the range ends before, like 2021-04-01 or an RFC 3339 time")
+	rootCmd.AddCommand(exportCmd)
+}
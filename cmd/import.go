@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/notification"
+	"parcel-service/internal/app/parcel"
+	"parcel-service/internal/app/promotion"
+	"parcel-service/internal/app/tax"
+	"parcel-service/internal/pkg/postgres"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import parcels from a CSV or JSON file",
+	Long:  `It will create the parcels of a CSV file with a header row or of a JSON array and print the created parcel or the error of every row`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		format, _ := cmd.Flags().GetString("format")
+		userID, _ := cmd.Flags().GetInt("user")
+		if path == "" {
+			return errors.New("--file is required")
+		}
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+		if format != "csv" && format != "json" {
+			return errors.New("format must be csv or json")
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		rows, err := readBulkRows(file, format)
+		if err != nil {
+			return err
+		}
+
+		db, err := postgres.New(&postgres.Config{
+			Host:     os.Getenv("DB_HOST"),
+			Port:     os.Getenv("DB_PORT"),
+			User:     os.Getenv("DB_USER"),
+			Password: os.Getenv("DB_PASSWORD"),
+			Name:     os.Getenv("DB_NAME"),
+		})
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		// the server is not running here, so nothing is listening for the parcel created events
+		parcelSvc := parcel.NewService(parcel.NewRepository(db),
+			promotion.NewService(promotion.NewRepository(db)),
+			tax.NewService(tax.NewRepository(db)),
+			notification.NewFanout(),
+		)
+		report, err := parcelSvc.ImportParcels(context.Background(), userID, rows)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+		if len(report.Errors) > 0 {
+			return fmt.Errorf("%d of %d parcels were not imported", len(report.Errors), report.Total)
+		}
+		return nil
+	},
+}
+
+func readBulkRows(r io.Reader, format string) ([]model.BulkRow, error) {
+	if format == "csv" {
+		return model.ParseParcelCSV(r)
+	}
+
+	var parcels []model.Parcel
+	if err := json.NewDecoder(r).Decode(&parcels); err != nil {
+		return nil, err
+	}
+	return model.BulkRows(parcels), nil
+}
+
+func init() {
+	importCmd.Flags().String("file", "", "path of the CSV or JSON file to import")
+	importCmd.Flags().String("format", "", "format of the file: csv or json, taken from the file extension by default")
+	importCmd.Flags().Int("user", 0, "ID of the user the parcels are imported for, rows of other users are refused")
+	rootCmd.AddCommand(importCmd)
+}
@@ -1,13 +1,43 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/broadcast"
 	"parcel-service/internal/app/carrier"
+	"parcel-service/internal/app/claim"
+	"parcel-service/internal/app/console"
+	"parcel-service/internal/app/dispatch"
+	"parcel-service/internal/app/export"
+	"parcel-service/internal/app/invoice"
+	"parcel-service/internal/app/jobs"
+	"parcel-service/internal/app/label"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/notification"
+	"parcel-service/internal/app/outbox"
 	"parcel-service/internal/app/parcel"
+	"parcel-service/internal/app/promotion"
+	"parcel-service/internal/app/relay"
+	"parcel-service/internal/app/report"
+	"parcel-service/internal/app/review"
+	"parcel-service/internal/app/route"
 	"parcel-service/internal/app/server"
+	svc "parcel-service/internal/app/service"
+	"parcel-service/internal/app/shipment"
+	"parcel-service/internal/app/sla"
+	"parcel-service/internal/app/tax"
+	"parcel-service/internal/app/webhook"
 	"parcel-service/internal/pkg/postgres"
+	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -18,6 +48,11 @@ var serverCmd = &cobra.Command{
 	Short: "Start server",
 	Long:  `Start server`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		secret := os.Getenv("AUTH_SECRET")
+		if secret == "" {
+			return errors.New("AUTH_SECRET is required")
+		}
+
 		db, err := postgres.New(&postgres.Config{
 			Host:     os.Getenv("DB_HOST"),
 			Port:     os.Getenv("DB_PORT"),
@@ -29,11 +64,70 @@ var serverCmd = &cobra.Command{
 		if err != nil {
 			panic(err)
 		}
+		notifiers, err := newNotifiers(os.Getenv("NOTIFICATION_LOG"))
+		if err != nil {
+			panic(err)
+		}
+
+		publisher, err := newPublisher(os.Getenv("OUTBOX_NATS_URL"))
+		if err != nil {
+			panic(err)
+		}
+
+		parcelOpts, err := newParcelOptions(os.Getenv("MAX_DELIVERY_ATTEMPTS"))
+		if err != nil {
+			return err
+		}
+
+		parcelRepo := parcel.NewRepository(db)
+		notificationSvc := notification.NewService(parcelRepo, notifiers...)
+		webhookSvc := webhook.NewService(webhook.NewRepository(db), parcelRepo, &http.Client{Timeout: 10 * time.Second})
+		broadcaster := broadcast.NewBroadcaster()
+		jobFeed := jobs.NewFeed(parcelRepo)
+		events := notification.NewFanout(notificationSvc, webhookSvc, broadcaster, jobFeed)
+		promotionSvc := promotion.NewService(promotion.NewRepository(db))
+		taxSvc := tax.NewService(tax.NewRepository(db))
+		carrierRepo := carrier.NewRepository(db)
+		carrierSvc := carrier.NewService(carrierRepo, events)
+		matcher := dispatch.NewMatcher(parcelRepo, carrierRepo, carrierSvc, jobFeed)
+		slaSvc := sla.NewService(sla.NewRepository(db), events)
+		parcelSvc := parcel.NewService(parcelRepo, promotionSvc, taxSvc, notification.NewFanout(events, matcher), parcelOpts...)
+		relaySvc := relay.NewService(relay.NewRepository(db), parcelRepo, events)
 		s := server.NewServer(os.Getenv("APP_PORT"),
-			parcel.NewService(parcel.NewRepository(db)),
-			carrier.NewService(carrier.NewRepository(db)),
+			parcelSvc,
+			carrierSvc,
+			server.WithPromotionService(promotionSvc),
+			server.WithTaxService(taxSvc),
+			server.WithInvoiceService(invoice.NewService(invoice.NewRepository(db))),
+			server.WithWebhookService(webhookSvc),
+			server.WithBroadcaster(broadcaster),
+			server.WithAuthenticator(auth.NewSigner([]byte(secret))),
+			server.WithJobFeed(jobFeed),
+			server.WithDispatcher(matcher),
+			server.WithSLAService(slaSvc),
+			server.WithReviewService(review.NewService(review.NewRepository(db), parcelRepo)),
+			server.WithClaimService(claim.NewService(claim.NewRepository(db), parcelRepo)),
+			server.WithShipmentService(shipment.NewService(shipment.NewRepository(db), parcelSvc, events)),
+			server.WithRouteService(route.NewService(route.NewRepository(db))),
+			server.WithRelayService(relaySvc),
+			server.WithLabelService(label.NewService(label.NewRepository(db), relaySvc, events)),
+			server.WithExportService(export.NewService(export.NewRepository(db))),
+			server.WithReportService(report.NewService(report.NewRepository(db))),
+			server.WithConsoleService(console.NewService(console.NewRepository(db), events)),
+			server.WithAuditService(audit.NewService(audit.NewRepository(db))),
 		)
 
+		ctx, cancel := context.WithCancel(context.Background())
+		var workers sync.WaitGroup
+		dispatcher := outbox.NewDispatcher(outbox.NewRepository(db), publisher)
+		for _, run := range []func(context.Context){notificationSvc.Run, webhookSvc.Run, dispatcher.Run, jobFeed.Run, matcher.Run, carrier.NewSweeper(carrierRepo).Run, slaSvc.Run} {
+			workers.Add(1)
+			go func(run func(context.Context)) {
+				defer workers.Done()
+				run(ctx)
+			}(run)
+		}
+
 		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
@@ -44,10 +138,57 @@ var serverCmd = &cobra.Command{
 			}
 		}()
 
-		return s.Run()
+		err = s.Run()
+		cancel()
+		workers.Wait()
+		return err
 	},
 }
 
+// newParcelOptions returns the parcel service settings taken from the environment, unset values keep their defaults
+func newParcelOptions(maxAttempts string) ([]parcel.Option, error) {
+	if maxAttempts == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(maxAttempts)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("MAX_DELIVERY_ATTEMPTS must be a positive number, got %q", maxAttempts)
+	}
+	return []parcel.Option{parcel.WithMaxDeliveryAttempts(n)}, nil
+}
+
+// newPublisher returns the publisher of outbox messages, a NATS server when its URL is given,
+// otherwise messages are kept in memory
+func newPublisher(natsURL string) (svc.Publisher, error) {
+	if natsURL == "" {
+		return outbox.NewMemoryPublisher(), nil
+	}
+	return outbox.NewNATSPublisher(natsURL, os.Getenv("OUTBOX_TOPIC_PREFIX"))
+}
+
+// newNotifiers returns the local sms, email and push channels. Notifications are appended to the
+// file when a path is given, otherwise they are written to the application log.
+func newNotifiers(path string) ([]svc.Notifier, error) {
+	channels := []string{model.ChannelSMS, model.ChannelEmail, model.ChannelPush}
+	notifiers := make([]svc.Notifier, 0, len(channels))
+
+	if path == "" {
+		for _, channel := range channels {
+			notifiers = append(notifiers, notification.NewLogNotifier(channel))
+		}
+		return notifiers, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		notifiers = append(notifiers, notification.NewFileNotifier(channel, f))
+	}
+	return notifiers, nil
+}
+
 func init() {
 	rootCmd.AddCommand(serverCmd)
 }
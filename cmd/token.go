@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Issue an access token",
+	Long:  `It will issue an access token for a user, carrier, hub or admin signed with AUTH_SECRET`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret := os.Getenv("AUTH_SECRET")
+		if secret == "" {
+			return errors.New("AUTH_SECRET is required")
+		}
+
+		role, _ := cmd.Flags().GetString("role")
+		id, _ := cmd.Flags().GetInt("id")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+
+		token, err := auth.NewSigner([]byte(secret)).Issue(model.Claims{
+			Role:      role,
+			ID:        id,
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func init() {
+	tokenCmd.Flags().String("role", model.RoleCarrier, "role of the token holder: user, carrier, hub or admin")
+	tokenCmd.Flags().Int("id", 0, "ID of the user or carrier")
+	tokenCmd.Flags().Duration("ttl", 24*time.Hour, "time until the token expires")
+	rootCmd.AddCommand(tokenCmd)
+}
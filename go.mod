@@ -7,7 +7,9 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.14.1
 	github.com/golang/mock v1.6.0
 	github.com/gorilla/mux v1.7.4
+	github.com/gorilla/websocket v1.4.2
 	github.com/jmoiron/sqlx v1.2.0
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/lib/pq v1.10.3
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rs/zerolog v1.20.0
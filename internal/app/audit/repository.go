@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	insertEntryQuery  = `INSERT INTO audit_log (actor_id, role, action, entity, entity_id, changes, request_id, ip) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	fetchEntriesQuery = `SELECT id, actor_id, role, action, entity, entity_id, changes, request_id, ip, created_at FROM audit_log WHERE ($1 = '' OR entity = $1) AND ($2 = 0 OR entity_id = $2) AND ($3 = 0 OR actor_id = $3) AND ($4 = '' OR role = $4) ORDER BY id DESC LIMIT $5 OFFSET $6`
+)
+
+// Execer is satisfied by the database transactions the audit entry is written in
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write records the change of an entity by the actor of the context within the transaction of the change,
+// so a change is never committed without its entry. before is nil for a created entity.
+func Write(ctx context.Context, tx Execer, action string, entity string, entityID int, before interface{}, after interface{}) error {
+	changes, err := model.AuditChanges(before, after)
+	if err != nil {
+		log.Error().Err(err).Msgf("[Write] failed to compare %s %d Error: %v", entity, entityID, err)
+		return err
+	}
+
+	actor := model.AuditActorFrom(ctx)
+	if _, err := tx.ExecContext(ctx, insertEntryQuery, actor.ID, actor.Role, action, entity, entityID, string(changes), actor.RequestID, actor.IP); err != nil {
+		log.Error().Err(err).Msgf("[Write] failed to write audit entry of %s %d Error: %v", entity, entityID, err)
+		return err
+	}
+	return nil
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates audit repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// FetchEntries returns the latest audit entries matching the filter first
+func (r *repository) FetchEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := r.db.SelectContext(ctx, &entries, fetchEntriesQuery, filter.Entity, filter.EntityID, filter.ActorID, filter.Role, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchEntries] failed to fetch audit entries Error: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	t.Run("should record the actor and the changed fields", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		m.ExpectExec(regexp.QuoteMeta(insertEntryQuery)).
+			WithArgs(2, model.RoleAdmin, model.AuditUpdate, model.AuditParcel, 1, `{"status":{"before":3,"after":4}}`, "req-1", "10.0.0.1").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		ctx := model.WithAuditActor(context.Background(), model.AuditActor{ID: 2, Role: model.RoleAdmin, RequestID: "req-1", IP: "10.0.0.1"})
+		err := Write(ctx, db, model.AuditUpdate, model.AuditParcel, 1,
+			map[string]interface{}{"status": 3, "carrier_id": 7},
+			map[string]interface{}{"status": 4, "carrier_id": 7},
+		)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should record a created entity as the system without an actor", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		m.ExpectExec(regexp.QuoteMeta(insertEntryQuery)).
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditClaim, 5, `{"parcel_id":{"before":null,"after":1}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := Write(context.Background(), db, model.AuditCreate, model.AuditClaim, 5, nil, map[string]interface{}{"parcel_id": 1})
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse a value that is not an object", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		err := Write(context.Background(), db, model.AuditCreate, model.AuditClaim, 5, nil, 42)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		m.ExpectExec(regexp.QuoteMeta(insertEntryQuery)).WillReturnError(errors.New("sql-error"))
+
+		err := Write(context.Background(), db, model.AuditCreate, model.AuditClaim, 5, nil, map[string]interface{}{"parcel_id": 1})
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchEntries(t *testing.T) {
+	columns := []string{"id", "actor_id", "role", "action", "entity", "entity_id", "changes", "request_id", "ip", "created_at"}
+	createdAt := time.Date(2021, time.March, 2, 15, 0, 0, 0, time.UTC)
+	filter := model.AuditFilter{Entity: model.AuditParcel, EntityID: 1, Limit: 50}
+
+	t.Run("should return matching entries", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchEntriesQuery)).
+			WithArgs(model.AuditParcel, 1, 0, "", 50, 0).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, 2, model.RoleAdmin, model.AuditUpdate, model.AuditParcel, 1, []byte(`{"status":{"before":3,"after":4}}`), "req-1", "10.0.0.1", createdAt))
+
+		entries, err := NewRepository(sqlxDB).FetchEntries(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.AuditEntry{{
+			ID:        2,
+			ActorID:   2,
+			Role:      model.RoleAdmin,
+			Action:    model.AuditUpdate,
+			Entity:    model.AuditParcel,
+			EntityID:  1,
+			Changes:   []byte(`{"status":{"before":3,"after":4}}`),
+			RequestID: "req-1",
+			IP:        "10.0.0.1",
+			CreatedAt: createdAt,
+		}}, entries)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchEntriesQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchEntries(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+type service struct {
+	repo svc.AuditRepository
+}
+
+func NewService(repo svc.AuditRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) GetEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	return s.repo.FetchEntries(ctx, filter)
+}
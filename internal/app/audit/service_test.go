@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_GetEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	filter := model.AuditFilter{ActorID: 2, Limit: 50}
+
+	t.Run("should return the entries", func(t *testing.T) {
+		repo := mocks.NewMockAuditRepository(ctrl)
+		repo.EXPECT().FetchEntries(gomock.Any(), filter).Return([]model.AuditEntry{{ID: 1, ActorID: 2}}, nil)
+
+		entries, err := NewService(repo).GetEntries(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.AuditEntry{{ID: 1, ActorID: 2}}, entries)
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		repo := mocks.NewMockAuditRepository(ctrl)
+		repo.EXPECT().FetchEntries(gomock.Any(), filter).Return(nil, errors.New("db-error"))
+
+		_, err := NewService(repo).GetEntries(context.Background(), filter)
+		assert.EqualError(t, err, "db-error")
+	})
+}
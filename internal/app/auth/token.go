@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"parcel-service/internal/app/model"
+	"strings"
+	"time"
+)
+
+var encoding = base64.RawURLEncoding
+
+type signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner initiates the issuer and verifier of access tokens signed with HMAC-SHA256 of the secret
+func NewSigner(secret []byte) *signer {
+	return &signer{
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+// Issue returns a token of the claims in the form <base64 claims>.<base64 signature>
+func (s *signer) Issue(claims model.Claims) (string, error) {
+	if err := claims.ValidateClaims(); err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %v :%w", err, model.IntServerErr)
+	}
+	encoded := encoding.EncodeToString(payload)
+	return encoded + "." + encoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Verify checks the signature and expiry of the token and returns its claims
+func (s *signer) Verify(token string) (model.Claims, error) {
+	var claims model.Claims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return claims, fmt.Errorf("malformed token :%w", model.ErrUnauthorized)
+	}
+
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, s.sign(parts[0])) {
+		return claims, fmt.Errorf("invalid token signature :%w", model.ErrUnauthorized)
+	}
+
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil {
+		return claims, fmt.Errorf("malformed token :%w", model.ErrUnauthorized)
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, fmt.Errorf("malformed token :%w", model.ErrUnauthorized)
+	}
+
+	if claims.Expired(s.now()) {
+		return claims, fmt.Errorf("token has expired :%w", model.ErrUnauthorized)
+	}
+	return claims, nil
+}
+
+func (s *signer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
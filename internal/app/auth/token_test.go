@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"parcel-service/internal/app/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_IssueAndVerify(t *testing.T) {
+	now := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	s := NewSigner([]byte("secret"))
+	s.now = func() time.Time { return now }
+
+	claims := model.Claims{Role: model.RoleCarrier, ID: 7, ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := s.Issue(claims)
+	assert.Nil(t, err)
+
+	t.Run("should return claims", func(t *testing.T) {
+		verified, err := s.Verify(token)
+		assert.Nil(t, err)
+		assert.Equal(t, claims, verified)
+	})
+
+	t.Run("should reject token signed with another secret", func(t *testing.T) {
+		other := NewSigner([]byte("other"))
+		other.now = s.now
+		_, err := other.Verify(token)
+		assert.True(t, errors.Is(err, model.ErrUnauthorized))
+	})
+
+	t.Run("should reject tampered claims", func(t *testing.T) {
+		forged, _ := NewSigner([]byte("other")).Issue(model.Claims{Role: model.RoleAdmin, ExpiresAt: claims.ExpiresAt})
+		tampered := strings.Split(forged, ".")[0] + "." + strings.Split(token, ".")[1]
+		_, err := s.Verify(tampered)
+		assert.True(t, errors.Is(err, model.ErrUnauthorized))
+	})
+
+	t.Run("should reject malformed token", func(t *testing.T) {
+		_, err := s.Verify("not-a-token")
+		assert.True(t, errors.Is(err, model.ErrUnauthorized))
+	})
+
+	t.Run("should reject expired token", func(t *testing.T) {
+		s.now = func() time.Time { return now.Add(2 * time.Hour) }
+		defer func() { s.now = func() time.Time { return now } }()
+
+		_, err := s.Verify(token)
+		assert.EqualError(t, err, "token has expired :unauthorized")
+	})
+}
+
+func TestSigner_IssueInvalidClaims(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+
+	_, err := s.Issue(model.Claims{Role: "driver", ID: 7, ExpiresAt: 1})
+	assert.True(t, errors.Is(err, model.ErrInvalid))
+
+	_, err = s.Issue(model.Claims{Role: model.RoleCarrier, ExpiresAt: 1})
+	assert.True(t, errors.Is(err, model.ErrEmpty))
+}
@@ -0,0 +1,153 @@
+package broadcast
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	"sync"
+	"time"
+)
+
+const (
+	// HISTORY_SIZE is the number of recent events kept to resume streams from their Last-Event-ID
+	HISTORY_SIZE = 1000
+	// SUBSCRIBER_BUFFER is the number of events a subscriber can fall behind before it is dropped
+	SUBSCRIBER_BUFFER = 64
+)
+
+type subscriber struct {
+	topic  string
+	events chan model.StreamEvent
+}
+
+type entry struct {
+	topics []string
+	event  model.StreamEvent
+}
+
+type broadcaster struct {
+	mu          sync.Mutex
+	sequence    int64
+	history     []entry
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func NewBroadcaster() *broadcaster {
+	return &broadcaster{
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// topics returns the parcel and carrier topics of the event. A carrier location is broadcast once to the
+// carrier topic and once per parcel the carrier is delivering, so the parcel copies skip the carrier topic.
+func topics(event model.Event) []string {
+	var t []string
+	if event.ParcelID != 0 {
+		t = append(t, model.ParcelTopic(event.ParcelID))
+		if event.Type == model.EventCarrierLocation {
+			return t
+		}
+	}
+	if event.CarrierID != 0 {
+		t = append(t, model.CarrierTopic(event.CarrierID))
+	}
+	return t
+}
+
+func hasTopic(topics []string, topic string) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify numbers the event and sends it to the subscribers of its topics. A subscriber whose buffer is
+// full is dropped, its stream ends and the client resumes from the last event it received.
+func (b *broadcaster) Notify(ctx context.Context, event model.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	eventTopics := topics(event)
+	if len(eventTopics) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.sequence++
+	e := entry{topics: eventTopics, event: model.StreamEvent{ID: b.sequence, Event: event}}
+	b.history = append(b.history, e)
+	if len(b.history) > HISTORY_SIZE {
+		b.history = b.history[len(b.history)-HISTORY_SIZE:]
+	}
+
+	for sub := range b.subscribers {
+		if !hasTopic(eventTopics, sub.topic) {
+			continue
+		}
+		select {
+		case sub.events <- e.event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe returns the events of the topic, starting with the kept events after lastEventID, and a function
+// to stop the subscription. The channel is closed when the subscriber is dropped or the broadcaster is closed.
+func (b *broadcaster) Subscribe(topic string, lastEventID int64) (<-chan model.StreamEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []model.StreamEvent
+	if lastEventID > 0 {
+		for _, e := range b.history {
+			if e.event.ID > lastEventID && hasTopic(e.topics, topic) {
+				missed = append(missed, e.event)
+			}
+		}
+	}
+
+	sub := &subscriber{
+		topic:  topic,
+		events: make(chan model.StreamEvent, len(missed)+SUBSCRIBER_BUFFER),
+	}
+	for _, event := range missed {
+		sub.events <- event
+	}
+
+	if b.closed {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub.events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Close ends every subscription and ignores later events, it is called when the server shuts down
+func (b *broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,102 @@
+package broadcast
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(events <-chan model.StreamEvent) []model.StreamEvent {
+	var received []model.StreamEvent
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestBroadcaster_Notify(t *testing.T) {
+	b := NewBroadcaster()
+
+	parcelEvents, stopParcel := b.Subscribe(model.ParcelTopic(1), 0)
+	defer stopParcel()
+	carrierEvents, stopCarrier := b.Subscribe(model.CarrierTopic(7), 0)
+	defer stopCarrier()
+	otherEvents, stopOther := b.Subscribe(model.ParcelTopic(2), 0)
+	defer stopOther()
+
+	b.Notify(context.Background(), model.Event{Type: model.EventCarrierAssigned, ParcelID: 1, CarrierID: 7})
+	b.Notify(context.Background(), model.Event{Type: model.EventCarrierLocation, CarrierID: 7, Latitude: 23.8})
+	b.Notify(context.Background(), model.Event{Type: model.EventCarrierLocation, ParcelID: 1, CarrierID: 7, Latitude: 23.8})
+
+	parcel := receive(parcelEvents)
+	assert.Len(t, parcel, 2)
+	assert.Equal(t, int64(1), parcel[0].ID)
+	assert.Equal(t, model.EventCarrierAssigned, parcel[0].Event.Type)
+	assert.Equal(t, int64(3), parcel[1].ID)
+	assert.Equal(t, model.EventCarrierLocation, parcel[1].Event.Type)
+
+	carrier := receive(carrierEvents)
+	assert.Len(t, carrier, 2)
+	assert.Equal(t, int64(1), carrier[0].ID)
+	assert.Equal(t, int64(2), carrier[1].ID)
+
+	assert.Empty(t, receive(otherEvents))
+}
+
+func TestBroadcaster_SubscribeResumesAfterLastEventID(t *testing.T) {
+	b := NewBroadcaster()
+	for status := 1; status <= 4; status++ {
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: status})
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 2, Status: status})
+	}
+
+	events, stop := b.Subscribe(model.ParcelTopic(1), 3)
+	defer stop()
+
+	received := receive(events)
+	assert.Len(t, received, 2)
+	assert.Equal(t, int64(5), received[0].ID)
+	assert.Equal(t, 3, received[0].Event.Status)
+	assert.Equal(t, int64(7), received[1].ID)
+	assert.Equal(t, 4, received[1].Event.Status)
+}
+
+func TestBroadcaster_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroadcaster()
+	events, stop := b.Subscribe(model.ParcelTopic(1), 0)
+	defer stop()
+
+	for i := 0; i < SUBSCRIBER_BUFFER+1; i++ {
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, SUBSCRIBER_BUFFER, received)
+}
+
+func TestBroadcaster_Close(t *testing.T) {
+	b := NewBroadcaster()
+	events, stop := b.Subscribe(model.ParcelTopic(1), 0)
+
+	b.Close()
+	_, ok := <-events
+	assert.False(t, ok)
+
+	// stopping after close and subscribing after close are safe
+	stop()
+	late, _ := b.Subscribe(model.ParcelTopic(1), 0)
+	_, ok = <-late
+	assert.False(t, ok)
+}
@@ -2,8 +2,11 @@ package carrier
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"parcel-service/internal/app/audit"
 	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/outbox"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -15,10 +18,17 @@ import (
 // sql query and error
 const (
 	errUniqueViolation = pq.ErrorCode("23505")
-	updateAcceptQuery  = `UPDATE carrier_request SET status = $1 WHERE parcel_id = $2 AND carrier_id = $3`
+	updateAcceptQuery  = `UPDATE carrier_request SET status = $1 WHERE parcel_id = $2 AND carrier_id = $3 AND expires_at > $4 AND status = $5`
 	updateRejectQuery  = `UPDATE carrier_request SET status = $1 WHERE parcel_id = $2 AND carrier_id != $3`
-	updateParcelStatus = `UPDATE parcel SET carrier_id = $1, status = $2, source_time = $3 WHERE id = $4`
-	insertCarrierQuery = `INSERT INTO carrier_request (carrier_id, parcel_id) VALUES ($1, $2)`
+	updateParcelStatus = `UPDATE parcel SET carrier_id = $1, status = $2, assigned_at = $3 WHERE id = $4`
+	lockParcelQuery    = `SELECT status, COALESCE(carrier_id, 0) FROM parcel WHERE id = $1 FOR UPDATE`
+	insertCarrierQuery = `INSERT INTO carrier_request (carrier_id, parcel_id, expires_at) SELECT $1, $2, $3 WHERE NOT EXISTS (SELECT 1 FROM parcel_leg WHERE parcel_id = $2) AND NOT EXISTS (SELECT 1 FROM carrier_profile WHERE carrier_id = $1 AND suspended) ON CONFLICT (parcel_id, carrier_id) DO UPDATE SET status = $4, expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP WHERE carrier_request.status = $5`
+	fetchRequestQuery  = `SELECT parcel_id, carrier_id, status, expires_at FROM carrier_request WHERE parcel_id = $1 AND carrier_id = $2`
+	expireRequestQuery = `UPDATE carrier_request SET status = $1 WHERE status = $2 AND expires_at <= $3`
+	upsertLocation     = `INSERT INTO carrier_location (carrier_id, latitude, longitude, updated_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP) ON CONFLICT (carrier_id) DO UPDATE SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, updated_at = EXCLUDED.updated_at`
+	activeParcelsQuery = `SELECT id FROM parcel WHERE carrier_id = $1 AND status IN ($2, $3) ORDER BY id`
+	fetchRatingsQuery  = `SELECT carrier_id, rating, rating_count FROM carrier_profile WHERE carrier_id = ANY($1) AND rating_count > 0`
+	fetchRequestsQuery = `SELECT carrier_request.parcel_id, carrier_request.carrier_id, carrier_request.status, carrier_request.expires_at, COALESCE(carrier_profile.rating, 0) AS rating, COALESCE(carrier_profile.rating_count, 0) AS rating_count FROM carrier_request LEFT JOIN carrier_profile ON carrier_profile.carrier_id = carrier_request.carrier_id WHERE carrier_request.parcel_id = $1 AND carrier_request.status = $2 AND carrier_request.expires_at > $3 ORDER BY rating DESC, rating_count DESC, carrier_request.expires_at`
 )
 
 type repository struct {
@@ -32,17 +42,54 @@ func NewRepository(db *sqlx.DB) *repository {
 	}
 }
 
+// InsertCarrierRequest stores a pending request of the carrier for the parcel, an expired request of the
+// carrier is renewed while any other existing request is refused. A parcel split into legs is requested by leg
+// and a carrier suspended by an admin cannot request parcels.
 func (r *repository) InsertCarrierRequest(ctx context.Context, request model.CarrierRequest) error {
-	if _, err := r.db.ExecContext(ctx, insertCarrierQuery, request.CarrierID, request.ParcelID); err != nil {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertCarrierRequest] Internal Server Error.")
+		return fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	result, err := tx.ExecContext(ctx, insertCarrierQuery, request.CarrierID, request.ParcelID, request.ExpiresAt, model.CarrierRequestPending, model.CarrierRequestExpired)
+	if err != nil {
+		tx.Rollback()
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
 			return fmt.Errorf("%v :%w", err, model.ErrInvalid)
 		}
 		return err
 	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rows == 0 {
+		tx.Rollback()
+		return fmt.Errorf("carrier %d has already requested parcel %d, it is delivered in legs or the carrier is suspended :%w", request.CarrierID, request.ParcelID, model.ErrInvalid)
+	}
+
+	request.Status = model.CarrierRequestPending
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditCarrierRequest, request.ParcelID, nil, request); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("[InsertCarrierRequest] Failed to commit")
+		return fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
 	return nil
 }
 
-func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus int, rejectStatus int, parcelStatus int, sourceTime time.Time) error {
+// UpdateCarrierRequest accepts the carrier request, rejects the others, assigns the carrier to the parcel
+// and writes the assignment to the outbox and the audit log in one transaction. A request that has expired by the time of
+// assignment is refused even before the sweeper marks it, and so is a request that was already accepted or rejected.
+// The requested source time of the parcel is kept.
+func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus int, rejectStatus int, parcelStatus int, assignedAt time.Time) error {
 	//starting db transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -50,7 +97,7 @@ func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.Carr
 		return fmt.Errorf("%v", err)
 	}
 	//accept status update for carrier request table
-	result, err := tx.ExecContext(ctx, updateAcceptQuery, acceptStatus, parcel.ParcelID, parcel.CarrierID)
+	result, err := tx.ExecContext(ctx, updateAcceptQuery, acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending)
 	if err != nil {
 		tx.Rollback()
 		log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to update carrier_request table to accept: %v", err)
@@ -64,7 +111,19 @@ func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.Carr
 	}
 
 	if rows == 0 {
+		var request model.CarrierRequest
+		err := tx.QueryRowContext(ctx, fetchRequestQuery, parcel.ParcelID, parcel.CarrierID).Scan(&request.ParcelID, &request.CarrierID, &request.Status, &request.ExpiresAt)
 		tx.Rollback()
+		if err == nil && request.Status != model.CarrierRequestPending && request.Status != model.CarrierRequestExpired {
+			return fmt.Errorf("request of carrier %d for parcel %d is no longer pending :%w", parcel.CarrierID, parcel.ParcelID, model.ErrInvalid)
+		}
+		if err == nil {
+			return fmt.Errorf("request of carrier %d for parcel %d expired at %s :%w", parcel.CarrierID, parcel.ParcelID, request.ExpiresAt.Format(time.RFC3339), model.ErrInvalid)
+		}
+		if err != sql.ErrNoRows {
+			log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to fetch carrier request: %v", err)
+			return err
+		}
 		log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to update invalid parcel id table to accept: %v", err)
 		return fmt.Errorf("parcel %d not updated, please provide valid ID. :%w", parcel.ID, model.ErrNotFound)
 	}
@@ -74,11 +133,37 @@ func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.Carr
 		log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to update carrier_request table to reject: %v", err)
 		return err
 	}
-	if _, err := tx.ExecContext(ctx, updateParcelStatus, parcel.CarrierID, parcelStatus, sourceTime, parcel.ParcelID); err != nil {
+	var previousStatus, previousCarrierID int
+	if err := tx.QueryRowContext(ctx, lockParcelQuery, parcel.ParcelID).Scan(&previousStatus, &previousCarrierID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to lock parcel: %v", err)
+		return err
+	}
+	if previousStatus != model.ParcelStatusCreated {
+		tx.Rollback()
+		return fmt.Errorf("parcel %d is %s and can not be assigned :%w", parcel.ParcelID, model.ParcelStatusName(previousStatus), model.ErrConflict)
+	}
+	if _, err := tx.ExecContext(ctx, updateParcelStatus, parcel.CarrierID, parcelStatus, assignedAt, parcel.ParcelID); err != nil {
 		tx.Rollback()
 		log.Error().Err(err).Msgf("[UpdateCarrierStatus] failed to update parcel table to update status: %v", err)
 		return err
 	}
+	if err := outbox.Write(ctx, tx, model.Event{
+		Type:      model.EventCarrierAssigned,
+		ParcelID:  parcel.ParcelID,
+		CarrierID: parcel.CarrierID,
+		Status:    parcelStatus,
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := audit.Write(ctx, tx, model.AuditAccept, model.AuditParcel, parcel.ParcelID,
+		map[string]interface{}{"status": previousStatus, "carrier_id": previousCarrierID},
+		map[string]interface{}{"status": parcelStatus, "carrier_id": parcel.CarrierID, "assigned_at": assignedAt},
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err = tx.Commit(); err != nil {
 		tx.Rollback()
 		log.Error().Err(err).Msg("[UpdateCarrierRequest] Failed to commit")
@@ -86,3 +171,68 @@ func (r *repository) UpdateCarrierRequest(ctx context.Context, parcel model.Carr
 	}
 	return nil
 }
+
+// UpdateCarrierLocation stores the last position of the carrier and returns the parcels the carrier is delivering
+func (r *repository) UpdateCarrierLocation(ctx context.Context, location model.CarrierLocation) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[UpdateCarrierLocation] failed to begin transaction")
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, upsertLocation, location.CarrierID, location.Latitude, location.Longitude); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[UpdateCarrierLocation] failed to store location of carrier %d: %v", location.CarrierID, err)
+		return nil, err
+	}
+
+	position := map[string]float64{"latitude": location.Latitude, "longitude": location.Longitude}
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditCarrierLocation, location.CarrierID, nil, position); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var parcelIDs []int
+	if err := tx.SelectContext(ctx, &parcelIDs, activeParcelsQuery, location.CarrierID, model.ParcelStatusAssigned, model.ParcelStatusPickedUp); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[UpdateCarrierLocation] failed to fetch parcels of carrier %d: %v", location.CarrierID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[UpdateCarrierLocation] failed to commit")
+		return nil, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return parcelIDs, nil
+}
+
+// FetchCarrierRatings returns the ratings of the carriers that have been reviewed
+func (r *repository) FetchCarrierRatings(ctx context.Context, carrierIDs []int) ([]model.CarrierRating, error) {
+	ratings := []model.CarrierRating{}
+	if err := r.db.SelectContext(ctx, &ratings, fetchRatingsQuery, pq.Array(carrierIDs)); err != nil {
+		log.Error().Err(err).Msgf("[FetchCarrierRatings] failed to fetch ratings of carriers %v: %v", carrierIDs, err)
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// ExpireCarrierRequests marks the pending requests that expired by now and returns how many were marked
+func (r *repository) ExpireCarrierRequests(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, expireRequestQuery, model.CarrierRequestExpired, model.CarrierRequestPending, now)
+	if err != nil {
+		log.Error().Err(err).Msgf("[ExpireCarrierRequests] failed to expire carrier requests: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FetchCarrierRequests returns the pending requests for the parcel that have not expired by now with the rating of
+// their carrier, the best rated carriers first
+func (r *repository) FetchCarrierRequests(ctx context.Context, parcelID int, now time.Time) ([]model.CarrierRequest, error) {
+	requests := []model.CarrierRequest{}
+	if err := r.db.SelectContext(ctx, &requests, fetchRequestsQuery, parcelID, model.CarrierRequestPending, now); err != nil {
+		log.Error().Err(err).Msgf("[FetchCarrierRequests] failed to fetch requests for parcel %d: %v", parcelID, err)
+		return nil, err
+	}
+	return requests, nil
+}
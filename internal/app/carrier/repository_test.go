@@ -23,13 +23,19 @@ func TestRepository_InsertCarrierRequest(t *testing.T) {
 		}
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
-		m.ExpectExec("INSERT INTO carrier_request (.+) VALUES (.+)").
-			WithArgs(carrierRequest.CarrierID, carrierRequest.ParcelID).
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_request (.+) SELECT (.+)").
+			WithArgs(carrierRequest.CarrierID, carrierRequest.ParcelID, carrierRequest.ExpiresAt, model.CarrierRequestPending, model.CarrierRequestExpired).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditCarrierRequest, carrierRequest.ParcelID, sqlmock.AnyArg(), "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
 
 		repo := NewRepository(sqlxDB)
 		err := repo.InsertCarrierRequest(context.Background(), carrierRequest)
 		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
 	})
 
 	t.Run("should return unique key violation error", func(t *testing.T) {
@@ -42,15 +48,49 @@ func TestRepository_InsertCarrierRequest(t *testing.T) {
 		}
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
-		m.ExpectExec("INSERT INTO carrier_request (.+) VALUES (.+)").
-			WithArgs(carrierRequest.CarrierID, carrierRequest.ParcelID).
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_request (.+) SELECT (.+)").
+			WithArgs(carrierRequest.CarrierID, carrierRequest.ParcelID, carrierRequest.ExpiresAt, model.CarrierRequestPending, model.CarrierRequestExpired).
 			WillReturnError(&pq.Error{Code: "23505"})
+		m.ExpectRollback()
 
 		repo := NewRepository(sqlxDB)
 		err := repo.InsertCarrierRequest(context.Background(), carrierRequest)
 		assert.True(t, errors.Is(err, model.ErrInvalid))
 	})
 
+	t.Run("should return existing request error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		carrierRequest := model.CarrierRequest{
+			CarrierID: 1,
+			ParcelID:  1,
+		}
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_request (.+) SELECT (.+) WHERE NOT EXISTS (.+) ON CONFLICT (.+) DO UPDATE SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.InsertCarrierRequest(context.Background(), carrierRequest)
+		assert.EqualError(t, err, "carrier 1 has already requested parcel 1, it is delivered in legs or the carrier is suspended :invalid")
+	})
+
+	t.Run("should return begin error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin().WillReturnError(errors.New("begin-error"))
+
+		repo := NewRepository(sqlxDB)
+		err := repo.InsertCarrierRequest(context.Background(), model.CarrierRequest{CarrierID: 1, ParcelID: 1})
+		assert.True(t, errors.Is(err, model.IntServerErr))
+	})
+
 	t.Run("should return sql error", func(t *testing.T) {
 		db, m, _ := sqlmock.New()
 		defer db.Close()
@@ -61,9 +101,11 @@ func TestRepository_InsertCarrierRequest(t *testing.T) {
 		}
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
-		m.ExpectExec("INSERT INTO carrier_request (.+) VALUES (.+)").
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_request (.+) SELECT (.+)").
 			WithArgs().
 			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
 
 		repo := NewRepository(sqlxDB)
 		err := repo.InsertCarrierRequest(context.Background(), carrierRequest)
@@ -72,7 +114,7 @@ func TestRepository_InsertCarrierRequest(t *testing.T) {
 }
 
 func TestRepository_UpdateCarrierRequest(t *testing.T) {
-	sourceTime := time.Now()
+	assignedAt := time.Now()
 	parcel := model.CarrierRequest{
 		ParcelID:  1,
 		CarrierID: 2,
@@ -88,18 +130,27 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
 			WithArgs(rejectStatus, parcel.ParcelID, parcel.CarrierID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCreated, 0))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
-			WithArgs(parcel.CarrierID, parcelStatus, sourceTime, parcel.ParcelID).
+			WithArgs(parcel.CarrierID, parcelStatus, assignedAt, parcel.ParcelID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WithArgs(model.EventCarrierAssigned, parcel.ParcelID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditParcel, parcel.ParcelID, sqlmock.AnyArg(), "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectCommit()
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.Nil(t, err)
 	})
 
@@ -110,12 +161,80 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("SELECT (.+) FROM carrier_request WHERE (.+)").
+			WithArgs(parcel.ParcelID, parcel.CarrierID).
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id", "carrier_id", "status", "expires_at"}))
+		m.ExpectRollback()
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.True(t, errors.Is(err, model.ErrNotFound))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse a request that is no longer pending", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		expiresAt := assignedAt.Add(time.Hour)
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND status = (.+)").
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("SELECT (.+) FROM carrier_request WHERE (.+)").
+			WithArgs(parcel.ParcelID, parcel.CarrierID).
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id", "carrier_id", "status", "expires_at"}).AddRow(1, 2, model.CarrierRequestRejected, expiresAt))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
+		assert.EqualError(t, err, "request of carrier 2 for parcel 1 is no longer pending :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse expired request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		expiresAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("SELECT (.+) FROM carrier_request WHERE (.+)").
+			WithArgs(parcel.ParcelID, parcel.CarrierID).
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id", "carrier_id", "status", "expires_at"}).AddRow(1, 2, model.CarrierRequestExpired, expiresAt))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
+		assert.EqualError(t, err, "request of carrier 2 for parcel 1 expired at 2020-04-11T21:34:01Z :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse a parcel that is no longer open", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCancelled, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
+		assert.EqualError(t, err, "parcel 1 is cancelled and can not be assigned :conflict")
+		assert.Nil(t, m.ExpectationsWereMet())
 	})
 
 	t.Run("should return internal server error", func(t *testing.T) {
@@ -125,7 +244,7 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		m.ExpectBegin().WillReturnError(model.IntServerErr)
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.EqualError(t, err, "internal server error")
 	})
 
@@ -136,11 +255,11 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnError(errors.New("sql-error"))
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.EqualError(t, err, "sql-error")
 	})
 
@@ -152,14 +271,14 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
 			WithArgs(rejectStatus, parcel.ParcelID, parcel.CarrierID).
 			WillReturnError(errors.New("sql-error"))
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.EqualError(t, err, "sql-error")
 	})
 
@@ -170,39 +289,246 @@ func TestRepository_UpdateCarrierRequest(t *testing.T) {
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
 			WithArgs(rejectStatus, parcel.ParcelID, parcel.CarrierID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCreated, 0))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
-			WithArgs(parcel.CarrierID, parcelStatus, sourceTime, parcel.ParcelID).
+			WithArgs(parcel.CarrierID, parcelStatus, assignedAt, parcel.ParcelID).
 			WillReturnError(errors.New("sql-error"))
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.EqualError(t, err, "sql-error")
 	})
 
+	t.Run("should return outbox write error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
+			WithArgs(rejectStatus, parcel.ParcelID, parcel.CarrierID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCreated, 0))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WithArgs(parcel.CarrierID, parcelStatus, assignedAt, parcel.ParcelID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
 	t.Run("should return commit failed", func(t *testing.T) {
 		db, m, _ := sqlmock.New()
 		defer db.Close()
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
 		m.ExpectBegin()
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
-			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID).
+			WithArgs(acceptStatus, parcel.ParcelID, parcel.CarrierID, assignedAt, model.CarrierRequestPending).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+) AND (.+)").
 			WithArgs(rejectStatus, parcel.ParcelID, parcel.CarrierID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCreated, 0))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
-			WithArgs(parcel.CarrierID, parcelStatus, sourceTime, parcel.ParcelID).
+			WithArgs(parcel.CarrierID, parcelStatus, assignedAt, parcel.ParcelID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WithArgs(model.EventCarrierAssigned, parcel.ParcelID, sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditParcel, parcel.ParcelID, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
 		m.ExpectCommit().WillReturnError(model.IntServerErr)
 
 		repo := NewRepository(sqlxDB)
-		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+		err := repo.UpdateCarrierRequest(context.Background(), parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 		assert.True(t, errors.Is(err, model.IntServerErr))
 	})
 }
+
+func TestRepository_UpdateCarrierLocation(t *testing.T) {
+	location := model.CarrierLocation{
+		CarrierID: 2,
+		Latitude:  23.8103,
+		Longitude: 90.4125,
+	}
+
+	t.Run("should return active parcels", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_location (.+) VALUES (.+) ON CONFLICT").
+			WithArgs(location.CarrierID, location.Latitude, location.Longitude).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditCarrierLocation, 2, `{"latitude":{"before":null,"after":23.8103},"longitude":{"before":null,"after":90.4125}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT id FROM parcel WHERE (.+)").
+			WithArgs(location.CarrierID, model.ParcelStatusAssigned, model.ParcelStatusPickedUp).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(4))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		parcelIDs, err := repo.UpdateCarrierLocation(context.Background(), location)
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 4}, parcelIDs)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error on upsert", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_location (.+) VALUES (.+) ON CONFLICT").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.UpdateCarrierLocation(context.Background(), location)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error on parcel fetch", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO carrier_location (.+) VALUES (.+) ON CONFLICT").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT id FROM parcel WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.UpdateCarrierLocation(context.Background(), location)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FetchCarrierRatings(t *testing.T) {
+	t.Run("should return ratings", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT carrier_id, rating, rating_count FROM carrier_profile WHERE (.+)").
+			WithArgs(pq.Array([]int{1, 2})).
+			WillReturnRows(sqlmock.NewRows([]string{"carrier_id", "rating", "rating_count"}).AddRow(1, 4.5, 12))
+
+		repo := NewRepository(sqlxDB)
+		ratings, err := repo.FetchCarrierRatings(context.Background(), []int{1, 2})
+		assert.Nil(t, err)
+		assert.Equal(t, []model.CarrierRating{{CarrierID: 1, Rating: 4.5, Count: 12}}, ratings)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT carrier_id, rating, rating_count FROM carrier_profile WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchCarrierRatings(context.Background(), []int{1, 2})
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_ExpireCarrierRequests(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should return expired count", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE status = (.+) AND expires_at <= (.+)").
+			WithArgs(model.CarrierRequestExpired, model.CarrierRequestPending, now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		repo := NewRepository(sqlxDB)
+		expired, err := repo.ExpireCarrierRequests(context.Background(), now)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), expired)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.ExpireCarrierRequests(context.Background(), now)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchCarrierRequests(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	t.Run("should return requests with carrier ratings", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT (.+) FROM carrier_request LEFT JOIN carrier_profile (.+) ORDER BY rating DESC(.+)").
+			WithArgs(1, model.CarrierRequestPending, now).
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id", "carrier_id", "status", "expires_at", "rating", "rating_count"}).
+				AddRow(1, 7, model.CarrierRequestPending, expiresAt, 4.5, 12).
+				AddRow(1, 8, model.CarrierRequestPending, expiresAt, 0, 0))
+
+		repo := NewRepository(sqlxDB)
+		requests, err := repo.FetchCarrierRequests(context.Background(), 1, now)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.CarrierRequest{
+			{ParcelID: 1, CarrierID: 7, Status: model.CarrierRequestPending, ExpiresAt: expiresAt, Rating: 4.5, RatingCount: 12},
+			{ParcelID: 1, CarrierID: 8, Status: model.CarrierRequestPending, ExpiresAt: expiresAt},
+		}, requests)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT (.+) FROM carrier_request (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchCarrierRequests(context.Background(), 1, now)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -7,22 +7,76 @@ import (
 	"time"
 )
 
+// REQUEST_TTL is the time a carrier request can be accepted before it expires
+const REQUEST_TTL = 2 * time.Hour
+
 type service struct {
-	repo svc.CarrierRepository
+	repo       svc.CarrierRepository
+	notifier   svc.EventNotifier
+	requestTTL time.Duration
 }
 
-const acceptStatus, rejectStatus, parcelStatus int = 2, 3, 2
+const acceptStatus, rejectStatus, parcelStatus int = model.CarrierRequestAccepted, model.CarrierRequestRejected, model.ParcelStatusAssigned
 
-func NewService(repo svc.CarrierRepository) *service {
+func NewService(repo svc.CarrierRepository, notifier svc.EventNotifier) *service {
 	return &service{
-		repo: repo,
+		repo:       repo,
+		notifier:   notifier,
+		requestTTL: REQUEST_TTL,
 	}
 }
 
 func (s *service) NewCarrierRequest(ctx context.Context, carrierReq model.CarrierRequest) error {
-	return s.repo.InsertCarrierRequest(ctx, carrierReq)
+	carrierReq.Status = model.CarrierRequestPending
+	carrierReq.ExpiresAt = time.Now().Add(s.requestTTL)
+	if err := s.repo.InsertCarrierRequest(ctx, carrierReq); err != nil {
+		return err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventCarrierRequested,
+		ParcelID:  carrierReq.ParcelID,
+		CarrierID: carrierReq.CarrierID,
+	})
+	return nil
 }
 
 func (s *service) AssignCarrierToParcel(ctx context.Context, parcel model.CarrierRequest) error {
-	return s.repo.UpdateCarrierRequest(ctx, parcel, acceptStatus, rejectStatus, parcelStatus, time.Now())
+	if err := s.repo.UpdateCarrierRequest(ctx, parcel, acceptStatus, rejectStatus, parcelStatus, time.Now()); err != nil {
+		return err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventCarrierAssigned,
+		ParcelID:  parcel.ParcelID,
+		CarrierID: parcel.CarrierID,
+		Status:    parcelStatus,
+	})
+	return nil
+}
+
+// UpdateLocation stores the position of the carrier and broadcasts it to the carrier and to every parcel it is delivering
+func (s *service) UpdateLocation(ctx context.Context, location model.CarrierLocation) error {
+	parcelIDs, err := s.repo.UpdateCarrierLocation(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	event := model.Event{
+		Type:      model.EventCarrierLocation,
+		CarrierID: location.CarrierID,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+	}
+	s.notifier.Notify(ctx, event)
+	for _, parcelID := range parcelIDs {
+		event.ParcelID = parcelID
+		s.notifier.Notify(ctx, event)
+	}
+	return nil
+}
+
+// GetCarrierRequests returns the requests the sender can still accept for the parcel
+func (s *service) GetCarrierRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	return s.repo.FetchCarrierRequests(ctx, parcelID, time.Now())
 }
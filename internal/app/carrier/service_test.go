@@ -6,6 +6,7 @@ import (
 	"parcel-service/internal/app/model"
 	"parcel-service/internal/app/service/mocks"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -20,10 +21,11 @@ func TestService_NewCarrierRequest(t *testing.T) {
 	defer ctrl.Finish()
 
 	testCases := []struct {
-		desc     string
-		payload  model.CarrierRequest
-		mockRepo func() *mocks.MockCarrierRepository
-		expErr   error
+		desc         string
+		payload      model.CarrierRequest
+		mockRepo     func() *mocks.MockCarrierRepository
+		mockNotifier func() *mocks.MockEventNotifier
+		expErr       error
 	}{
 		{
 			desc:    "should return success",
@@ -33,6 +35,11 @@ func TestService_NewCarrierRequest(t *testing.T) {
 				r.EXPECT().InsertCarrierRequest(gomock.Any(), gomock.Any()).Return(nil)
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				n := mocks.NewMockEventNotifier(ctrl)
+				n.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCarrierRequested, ParcelID: 1, CarrierID: 1})
+				return n
+			},
 			expErr: nil,
 		},
 
@@ -44,13 +51,16 @@ func TestService_NewCarrierRequest(t *testing.T) {
 				r.EXPECT().InsertCarrierRequest(gomock.Any(), gomock.Any()).Return(errors.New("db-error"))
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				return mocks.NewMockEventNotifier(ctrl)
+			},
 			expErr: errors.New("db-error"),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), tc.mockNotifier())
 			err := s.NewCarrierRequest(context.Background(), tc.payload)
 			assert.Equal(t, tc.expErr, err)
 		})
@@ -68,10 +78,11 @@ func TestService_AssignCarrierToParcel(t *testing.T) {
 	}
 
 	testCases := []struct {
-		desc     string
-		payload  model.CarrierRequest
-		mockRepo func() *mocks.MockCarrierRepository
-		expErr   error
+		desc         string
+		payload      model.CarrierRequest
+		mockRepo     func() *mocks.MockCarrierRepository
+		mockNotifier func() *mocks.MockEventNotifier
+		expErr       error
 	}{
 		{
 			desc:    "should return success",
@@ -81,6 +92,11 @@ func TestService_AssignCarrierToParcel(t *testing.T) {
 				r.EXPECT().UpdateCarrierRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				n := mocks.NewMockEventNotifier(ctrl)
+				n.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCarrierAssigned, ParcelID: 1, CarrierID: 2, Status: model.ParcelStatusAssigned})
+				return n
+			},
 			expErr: nil,
 		}, {
 			desc:    "should return db-error",
@@ -90,14 +106,81 @@ func TestService_AssignCarrierToParcel(t *testing.T) {
 				r.EXPECT().UpdateCarrierRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("db-error"))
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				return mocks.NewMockEventNotifier(ctrl)
+			},
 			expErr: errors.New("db-error"),
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), tc.mockNotifier())
 			err := s.AssignCarrierToParcel(context.Background(), tc.payload)
 			assert.Equal(t, tc.expErr, err)
 		})
 	}
 }
+
+func TestService_UpdateLocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	location := model.CarrierLocation{CarrierID: 2, Latitude: 23.8103, Longitude: 90.4125}
+
+	t.Run("should notify carrier and active parcels", func(t *testing.T) {
+		r := mocks.NewMockCarrierRepository(ctrl)
+		r.EXPECT().UpdateCarrierLocation(gomock.Any(), location).Return([]int{1, 4}, nil)
+
+		event := model.Event{Type: model.EventCarrierLocation, CarrierID: 2, Latitude: 23.8103, Longitude: 90.4125}
+		n := mocks.NewMockEventNotifier(ctrl)
+		gomock.InOrder(
+			n.EXPECT().Notify(gomock.Any(), event),
+			n.EXPECT().Notify(gomock.Any(), model.Event{Type: event.Type, ParcelID: 1, CarrierID: 2, Latitude: event.Latitude, Longitude: event.Longitude}),
+			n.EXPECT().Notify(gomock.Any(), model.Event{Type: event.Type, ParcelID: 4, CarrierID: 2, Latitude: event.Latitude, Longitude: event.Longitude}),
+		)
+
+		err := NewService(r, n).UpdateLocation(context.Background(), location)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		r := mocks.NewMockCarrierRepository(ctrl)
+		r.EXPECT().UpdateCarrierLocation(gomock.Any(), location).Return(nil, errors.New("db-error"))
+
+		err := NewService(r, mocks.NewMockEventNotifier(ctrl)).UpdateLocation(context.Background(), location)
+		assert.EqualError(t, err, "db-error")
+	})
+}
+
+func TestService_NewCarrierRequestExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockCarrierRepository(ctrl)
+	r.EXPECT().InsertCarrierRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, request model.CarrierRequest) error {
+		assert.Equal(t, model.CarrierRequestPending, request.Status)
+		assert.WithinDuration(t, time.Now().Add(REQUEST_TTL), request.ExpiresAt, time.Second)
+		return nil
+	})
+	n := mocks.NewMockEventNotifier(ctrl)
+	n.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	err := NewService(r, n).NewCarrierRequest(context.Background(), model.CarrierRequest{CarrierID: 1, ParcelID: 1, ExpiresAt: time.Now().Add(24 * time.Hour)})
+	assert.Nil(t, err)
+}
+
+func TestService_GetCarrierRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requests := []model.CarrierRequest{{ParcelID: 1, CarrierID: 7, Status: model.CarrierRequestPending, Rating: 4.5, RatingCount: 12}}
+	r := mocks.NewMockCarrierRepository(ctrl)
+	r.EXPECT().FetchCarrierRequests(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(ctx context.Context, parcelID int, now time.Time) ([]model.CarrierRequest, error) {
+		assert.WithinDuration(t, time.Now(), now, time.Second)
+		return requests, nil
+	})
+
+	result, err := NewService(r, nil).GetCarrierRequests(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, requests, result)
+}
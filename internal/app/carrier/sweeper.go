@@ -0,0 +1,51 @@
+package carrier
+
+import (
+	"context"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SWEEP_INTERVAL is the wait between two sweeps of expired carrier requests
+const SWEEP_INTERVAL = time.Minute
+
+type sweeper struct {
+	repo     svc.CarrierRepository
+	interval time.Duration
+}
+
+// NewSweeper initiates the background marking of expired carrier requests
+func NewSweeper(repo svc.CarrierRepository) *sweeper {
+	return &sweeper{
+		repo:     repo,
+		interval: SWEEP_INTERVAL,
+	}
+}
+
+// Run marks expired carrier requests every interval until the context is cancelled
+func (s *sweeper) Run(ctx context.Context) {
+	for {
+		s.Sweep(ctx)
+
+		select {
+		case <-time.After(s.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sweep marks the pending carrier requests that have expired so senders no longer see them
+func (s *sweeper) Sweep(ctx context.Context) (int64, error) {
+	expired, err := s.repo.ExpireCarrierRequests(ctx, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msgf("[Sweep] failed to expire carrier requests. Error: %v", err)
+		return 0, err
+	}
+	if expired > 0 {
+		log.Info().Msgf("[Sweep] %d carrier requests expired", expired)
+	}
+	return expired, nil
+}
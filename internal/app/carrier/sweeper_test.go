@@ -0,0 +1,56 @@
+package carrier
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweeper_Sweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should expire requests", func(t *testing.T) {
+		repo := mocks.NewMockCarrierRepository(ctrl)
+		repo.EXPECT().ExpireCarrierRequests(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, now time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now(), now, time.Second)
+			return 2, nil
+		})
+
+		expired, err := NewSweeper(repo).Sweep(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), expired)
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		repo := mocks.NewMockCarrierRepository(ctrl)
+		repo.EXPECT().ExpireCarrierRequests(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db-error"))
+
+		_, err := NewSweeper(repo).Sweep(context.Background())
+		assert.EqualError(t, err, "db-error")
+	})
+}
+
+func TestSweeper_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := mocks.NewMockCarrierRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().ExpireCarrierRequests(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db-error")),
+		repo.EXPECT().ExpireCarrierRequests(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, time.Time) (int64, error) {
+			cancel()
+			return 1, nil
+		}),
+	)
+
+	s := NewSweeper(repo)
+	s.interval = time.Millisecond
+	s.Run(ctx)
+}
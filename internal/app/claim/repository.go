@@ -0,0 +1,135 @@
+package claim
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation      = pq.ErrorCode("23505")
+	insertClaimQuery        = `INSERT INTO claim (parcel_id, user_id, status, description, evidence) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	fetchClaimByIDQuery     = `SELECT id, parcel_id, user_id, status, description, evidence, compensation, note, created_at, decided_at FROM claim WHERE id = $1`
+	fetchClaimsQuery        = `SELECT id, parcel_id, user_id, status, description, evidence, compensation, note, created_at, decided_at FROM claim WHERE ($1 = '' OR status = $1) ORDER BY created_at, id LIMIT $2 OFFSET $3`
+	decideClaimQuery        = `UPDATE claim SET status = $1, compensation = $2, note = $3, decided_at = $4 WHERE id = $5 AND status = $6`
+	insertCompensationQuery = `INSERT INTO claim_compensation (claim_id, parcel_id, user_id, amount) VALUES ($1, $2, $3, $4)`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates claim repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// InsertClaim stores the claim, a parcel can only have one claim that has not been rejected
+func (r *repository) InsertClaim(ctx context.Context, claim model.Claim) (model.Claim, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertClaim] failed to begin transaction")
+		return model.Claim{}, err
+	}
+
+	err = tx.QueryRowContext(ctx, insertClaimQuery, claim.ParcelID, claim.UserID, claim.Status, claim.Description, claim.Evidence).
+		Scan(&claim.ID, &claim.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.Claim{}, fmt.Errorf("parcel %d already has a claim :%w", claim.ParcelID, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertClaim] failed to insert claim Error: %v", err)
+		return model.Claim{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditClaim, claim.ID, nil, claim); err != nil {
+		tx.Rollback()
+		return model.Claim{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertClaim] failed to commit")
+		return model.Claim{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return claim, nil
+}
+
+func (r *repository) FetchClaimByID(ctx context.Context, claimID int) (model.Claim, error) {
+	var claim model.Claim
+	if err := r.db.GetContext(ctx, &claim, fetchClaimByIDQuery, claimID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Claim{}, fmt.Errorf("claim with the ID %d is not found. :%w", claimID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchClaimByID] failed to fetch claim %d Error: %v", claimID, err)
+		return model.Claim{}, err
+	}
+	return claim, nil
+}
+
+// FetchClaims returns the oldest claims first, an empty status returns every status
+func (r *repository) FetchClaims(ctx context.Context, status string, limit int, offset int) ([]model.Claim, error) {
+	claims := []model.Claim{}
+	if err := r.db.SelectContext(ctx, &claims, fetchClaimsQuery, status, limit, offset); err != nil {
+		log.Error().Err(err).Msgf("[FetchClaims] failed to fetch claims Error: %v", err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// DecideClaim moves the claim from the previous status to its new status and posts the compensation of an
+// approved claim in the same transaction. A claim decided by someone else in the meantime is refused.
+func (r *repository) DecideClaim(ctx context.Context, claim model.Claim, previousStatus string) (model.Claim, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[DecideClaim] failed to begin transaction")
+		return model.Claim{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, decideClaimQuery, claim.Status, claim.Compensation, claim.Note, claim.DecidedAt, claim.ID, previousStatus)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[DecideClaim] failed to update claim %d Error: %v", claim.ID, err)
+		return model.Claim{}, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return model.Claim{}, err
+	}
+	if rows == 0 {
+		tx.Rollback()
+		return model.Claim{}, fmt.Errorf("claim %d is no longer %s :%w", claim.ID, previousStatus, model.ErrInvalid)
+	}
+
+	if claim.Status == model.ClaimApproved {
+		if _, err := tx.ExecContext(ctx, insertCompensationQuery, claim.ID, claim.ParcelID, claim.UserID, claim.Compensation); err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[DecideClaim] failed to post compensation of claim %d Error: %v", claim.ID, err)
+			return model.Claim{}, err
+		}
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditClaim, claim.ID,
+		map[string]interface{}{"status": previousStatus},
+		map[string]interface{}{"status": claim.Status, "compensation": claim.Compensation, "note": claim.Note, "decided_at": claim.DecidedAt},
+	); err != nil {
+		tx.Rollback()
+		return model.Claim{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[DecideClaim] failed to commit")
+		return model.Claim{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return claim, nil
+}
@@ -0,0 +1,177 @@
+package claim
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	createdAt = time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	columns   = []string{"id", "parcel_id", "user_id", "status", "description", "evidence", "compensation", "note", "created_at", "decided_at"}
+	openClaim = model.Claim{ParcelID: 1, UserID: 3, Status: model.ClaimOpen, Description: "Screen is broken", Evidence: []string{"https://img.example/1.jpg"}}
+)
+
+func TestRepository_InsertClaim(t *testing.T) {
+	t.Run("should return claim", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertClaimQuery)).
+			WithArgs(1, 3, model.ClaimOpen, "Screen is broken", openClaim.Evidence).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(3, model.RoleUser, model.AuditCreate, model.AuditClaim, 1, sqlmock.AnyArg(), "req-1", "10.0.0.1").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		ctx := model.WithAuditActor(context.Background(), model.AuditActor{ID: 3, Role: model.RoleUser, RequestID: "req-1", IP: "10.0.0.1"})
+		claim, err := NewRepository(sqlxDB).InsertClaim(ctx, openClaim)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, claim.ID)
+		assert.Equal(t, createdAt, claim.CreatedAt)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse a second claim", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertClaimQuery)).WillReturnError(&pq.Error{Code: errUniqueViolation})
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).InsertClaim(context.Background(), openClaim)
+		assert.EqualError(t, err, "parcel 1 already has a claim :invalid")
+	})
+}
+
+func TestRepository_FetchClaimByID(t *testing.T) {
+	t.Run("should return claim", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchClaimByIDQuery)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, 3, model.ClaimOpen, "Screen is broken", "{https://img.example/1.jpg}", 0, "", createdAt, nil))
+
+		claim, err := NewRepository(sqlxDB).FetchClaimByID(context.Background(), 1)
+		assert.Nil(t, err)
+		expected := openClaim
+		expected.ID = 1
+		expected.CreatedAt = createdAt
+		assert.Equal(t, expected, claim)
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchClaimByIDQuery)).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := NewRepository(sqlxDB).FetchClaimByID(context.Background(), 2)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
+
+func TestRepository_FetchClaims(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchClaimsQuery)).WithArgs(model.ClaimOpen, 20, 0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, 3, model.ClaimOpen, "Screen is broken", "{}", 0, "", createdAt, nil))
+
+	claims, err := NewRepository(sqlxDB).FetchClaims(context.Background(), model.ClaimOpen, 20, 0)
+	assert.Nil(t, err)
+	assert.Len(t, claims, 1)
+	assert.Nil(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_DecideClaim(t *testing.T) {
+	decidedAt := createdAt.Add(time.Hour)
+	approved := model.Claim{ID: 1, ParcelID: 1, UserID: 3, Status: model.ClaimApproved, Compensation: 150, Note: "Confirmed by carrier", DecidedAt: &decidedAt}
+
+	t.Run("should post compensation of approved claim", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(decideClaimQuery)).
+			WithArgs(model.ClaimApproved, float32(150), "Confirmed by carrier", &decidedAt, 1, model.ClaimInvestigating).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec(regexp.QuoteMeta(insertCompensationQuery)).
+			WithArgs(1, 1, 3, float32(150)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditClaim, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		claim, err := NewRepository(sqlxDB).DecideClaim(context.Background(), approved, model.ClaimInvestigating)
+		assert.Nil(t, err)
+		assert.Equal(t, approved, claim)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not post compensation of rejected claim", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		rejected := approved
+		rejected.Status = model.ClaimRejected
+		rejected.Compensation = 0
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(decideClaimQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		_, err := NewRepository(sqlxDB).DecideClaim(context.Background(), rejected, model.ClaimOpen)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse claim decided in the meantime", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(decideClaimQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).DecideClaim(context.Background(), approved, model.ClaimOpen)
+		assert.EqualError(t, err, "claim 1 is no longer open :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should rollback when compensation fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(decideClaimQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec(regexp.QuoteMeta(insertCompensationQuery)).WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).DecideClaim(context.Background(), approved, model.ClaimOpen)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,92 @@
+package claim
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+)
+
+type service struct {
+	repo       svc.ClaimRepository
+	parcelRepo svc.ParcelRepository
+}
+
+func NewService(repo svc.ClaimRepository, parcelRepo svc.ParcelRepository) *service {
+	return &service{
+		repo:       repo,
+		parcelRepo: parcelRepo,
+	}
+}
+
+// OpenClaim lets the sender claim a delivered parcel, or a parcel that is stuck with its carrier past the delivery deadline
+func (s *service) OpenClaim(ctx context.Context, claim model.Claim) (model.Claim, error) {
+	parcel, err := s.parcelRepo.FetchParcelByID(ctx, claim.ParcelID)
+	if err != nil {
+		return model.Claim{}, err
+	}
+
+	if claim.UserID != parcel.UserID {
+		return model.Claim{}, fmt.Errorf("user %d did not send parcel %d :%w", claim.UserID, parcel.ID, model.ErrForbidden)
+	}
+
+	if parcel.Status != model.ParcelStatusDelivered && !stuck(parcel, time.Now()) {
+		return model.Claim{}, fmt.Errorf("parcel %d can only be claimed once it is delivered or overdue :%w", parcel.ID, model.ErrInvalid)
+	}
+
+	claim.Status = model.ClaimOpen
+	return s.repo.InsertClaim(ctx, claim)
+}
+
+// stuck reports whether the parcel is with its carrier and past the delivery deadline
+func stuck(parcel model.Parcel, now time.Time) bool {
+	if parcel.Status != model.ParcelStatusAssigned && parcel.Status != model.ParcelStatusPickedUp {
+		return false
+	}
+	return parcel.DeliveryDeadline != nil && now.After(*parcel.DeliveryDeadline)
+}
+
+func (s *service) GetClaim(ctx context.Context, claimID int) (model.Claim, error) {
+	return s.repo.FetchClaimByID(ctx, claimID)
+}
+
+func (s *service) GetClaims(ctx context.Context, status string, limit int, offset int) ([]model.Claim, error) {
+	return s.repo.FetchClaims(ctx, status, limit, offset)
+}
+
+// DecideClaim moves the claim to the status decided by an admin. An approved claim is compensated with the given
+// amount, or the declared value of the parcel when none is given, and never more than the declared value.
+func (s *service) DecideClaim(ctx context.Context, decision model.ClaimDecision) (model.Claim, error) {
+	claim, err := s.repo.FetchClaimByID(ctx, decision.ClaimID)
+	if err != nil {
+		return model.Claim{}, err
+	}
+
+	if !claim.CanMoveTo(decision.Status) {
+		return model.Claim{}, fmt.Errorf("claim %d can not move from %s to %s :%w", claim.ID, claim.Status, decision.Status, model.ErrInvalid)
+	}
+
+	if decision.Status == model.ClaimApproved {
+		parcel, err := s.parcelRepo.FetchParcelByID(ctx, claim.ParcelID)
+		if err != nil {
+			return model.Claim{}, err
+		}
+		if decision.Compensation == 0 {
+			decision.Compensation = parcel.DeclaredValue
+		}
+		if decision.Compensation > parcel.DeclaredValue {
+			return model.Claim{}, fmt.Errorf("compensation %.2f exceeds the declared value %.2f of parcel %d :%w", decision.Compensation, parcel.DeclaredValue, parcel.ID, model.ErrInvalid)
+		}
+	}
+
+	previousStatus := claim.Status
+	claim.Status = decision.Status
+	claim.Compensation = decision.Compensation
+	claim.Note = decision.Note
+	if decision.Status != model.ClaimInvestigating {
+		now := time.Now()
+		claim.DecidedAt = &now
+	}
+	return s.repo.DecideClaim(ctx, claim, previousStatus)
+}
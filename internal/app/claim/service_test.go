@@ -0,0 +1,196 @@
+package claim
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_OpenClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	input := model.Claim{ParcelID: 1, UserID: 3, Description: "Screen is broken"}
+
+	testCases := []struct {
+		desc     string
+		parcel   model.Parcel
+		mockRepo func() *mocks.MockClaimRepository
+		expErr   string
+	}{
+		{
+			desc:   "should open claim on delivered parcel",
+			parcel: model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusDelivered},
+			mockRepo: func() *mocks.MockClaimRepository {
+				r := mocks.NewMockClaimRepository(ctrl)
+				opened := input
+				opened.Status = model.ClaimOpen
+				r.EXPECT().InsertClaim(gomock.Any(), opened).Return(opened, nil)
+				return r
+			},
+		},
+		{
+			desc:   "should open claim on overdue parcel",
+			parcel: model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusPickedUp, DeliveryDeadline: &past},
+			mockRepo: func() *mocks.MockClaimRepository {
+				r := mocks.NewMockClaimRepository(ctrl)
+				r.EXPECT().InsertClaim(gomock.Any(), gomock.Any()).Return(model.Claim{ID: 1}, nil)
+				return r
+			},
+		},
+		{
+			desc:   "should refuse parcel still on time",
+			parcel: model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusPickedUp, DeliveryDeadline: &future},
+			mockRepo: func() *mocks.MockClaimRepository {
+				return mocks.NewMockClaimRepository(ctrl)
+			},
+			expErr: "parcel 1 can only be claimed once it is delivered or overdue :invalid",
+		},
+		{
+			desc:   "should refuse cancelled parcel",
+			parcel: model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusCancelled, DeliveryDeadline: &past},
+			mockRepo: func() *mocks.MockClaimRepository {
+				return mocks.NewMockClaimRepository(ctrl)
+			},
+			expErr: "parcel 1 can only be claimed once it is delivered or overdue :invalid",
+		},
+		{
+			desc:   "should refuse other users",
+			parcel: model.Parcel{ID: 1, UserID: 4, Status: model.ParcelStatusDelivered},
+			mockRepo: func() *mocks.MockClaimRepository {
+				return mocks.NewMockClaimRepository(ctrl)
+			},
+			expErr: "user 3 did not send parcel 1 :forbidden",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parcelRepo := mocks.NewMockParcelRepository(ctrl)
+			parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(tc.parcel, nil)
+
+			_, err := NewService(tc.mockRepo(), parcelRepo).OpenClaim(context.Background(), input)
+			if tc.expErr != "" {
+				assert.EqualError(t, err, tc.expErr)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestService_DecideClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	open := model.Claim{ID: 1, ParcelID: 1, UserID: 3, Status: model.ClaimOpen}
+	parcel := model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusDelivered, DeclaredValue: 500}
+
+	testCases := []struct {
+		desc            string
+		claim           model.Claim
+		decision        model.ClaimDecision
+		mockParcelRepo  func() *mocks.MockParcelRepository
+		expStatus       string
+		expCompensation float32
+		expDecided      bool
+		expErr          string
+	}{
+		{
+			desc:     "should approve with the declared value",
+			claim:    open,
+			decision: model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved},
+			mockParcelRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expStatus:       model.ClaimApproved,
+			expCompensation: 500,
+			expDecided:      true,
+		},
+		{
+			desc:     "should approve with a partial compensation",
+			claim:    open,
+			decision: model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved, Compensation: 120},
+			mockParcelRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expStatus:       model.ClaimApproved,
+			expCompensation: 120,
+			expDecided:      true,
+		},
+		{
+			desc:     "should refuse compensation above the declared value",
+			claim:    open,
+			decision: model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved, Compensation: 600},
+			mockParcelRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expErr: "compensation 600.00 exceeds the declared value 500.00 of parcel 1 :invalid",
+		},
+		{
+			desc:     "should start investigation",
+			claim:    open,
+			decision: model.ClaimDecision{ClaimID: 1, Status: model.ClaimInvestigating},
+			mockParcelRepo: func() *mocks.MockParcelRepository {
+				return mocks.NewMockParcelRepository(ctrl)
+			},
+			expStatus: model.ClaimInvestigating,
+		},
+		{
+			desc:     "should refuse to reopen a decided claim",
+			claim:    model.Claim{ID: 1, ParcelID: 1, Status: model.ClaimRejected},
+			decision: model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved},
+			mockParcelRepo: func() *mocks.MockParcelRepository {
+				return mocks.NewMockParcelRepository(ctrl)
+			},
+			expErr: "claim 1 can not move from rejected to approved :invalid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := mocks.NewMockClaimRepository(ctrl)
+			repo.EXPECT().FetchClaimByID(gomock.Any(), 1).Return(tc.claim, nil)
+			if tc.expErr == "" {
+				repo.EXPECT().DecideClaim(gomock.Any(), gomock.Any(), tc.claim.Status).DoAndReturn(func(ctx context.Context, claim model.Claim, previousStatus string) (model.Claim, error) {
+					return claim, nil
+				})
+			}
+
+			claim, err := NewService(repo, tc.mockParcelRepo()).DecideClaim(context.Background(), tc.decision)
+			if tc.expErr != "" {
+				assert.EqualError(t, err, tc.expErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expStatus, claim.Status)
+			assert.Equal(t, tc.expCompensation, claim.Compensation)
+			assert.Equal(t, tc.expDecided, claim.DecidedAt != nil)
+		})
+	}
+}
+
+func TestService_DecideClaimNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockClaimRepository(ctrl)
+	repo.EXPECT().FetchClaimByID(gomock.Any(), 1).Return(model.Claim{}, model.ErrNotFound)
+
+	_, err := NewService(repo, nil).DecideClaim(context.Background(), model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved})
+	assert.True(t, errors.Is(err, model.ErrNotFound))
+}
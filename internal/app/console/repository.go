@@ -0,0 +1,164 @@
+package console
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/outbox"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	searchParcelsQuery   = `SELECT p.id, p.user_id, COALESCE(p.carrier_id, 0) AS carrier_id, p.status, p.source_address, p.destination_address, p.type, p.weight, p.price, p.carrier_fee, p.company_fee, p.assigned_at, p.picked_up_at, p.delivered_at, p.created_at, p.updated_at FROM parcel p LEFT JOIN parcel_label l ON l.parcel_id = p.id WHERE ($1 = 0 OR p.id = $1) AND ($2 = 0 OR p.user_id = $2) AND ($3 = 0 OR p.carrier_id = $3) AND ($4 = 0 OR p.status = $4) AND ($5 = '' OR p.source_address ILIKE '%' || $5 || '%' OR p.destination_address ILIKE '%' || $5 || '%' OR l.tracking_code = UPPER($5)) ORDER BY p.id DESC LIMIT $6 OFFSET $7`
+	fetchEventsQuery     = `SELECT id, topic, parcel_id, payload, attempts, last_error, created_at, sent_at FROM outbox WHERE parcel_id = $1 ORDER BY id`
+	fetchRequestsQuery   = `SELECT carrier_request.parcel_id, carrier_request.carrier_id, carrier_request.status, carrier_request.expires_at, COALESCE(carrier_profile.rating, 0) AS rating, COALESCE(carrier_profile.rating_count, 0) AS rating_count FROM carrier_request LEFT JOIN carrier_profile ON carrier_profile.carrier_id = carrier_request.carrier_id WHERE carrier_request.parcel_id = $1 ORDER BY carrier_request.created_at, carrier_request.carrier_id`
+	lockParcelQuery      = `SELECT user_id, COALESCE(carrier_id, 0), status FROM parcel WHERE id = $1 FOR UPDATE`
+	forceStatusQuery     = `UPDATE parcel SET status = $1, picked_up_at = CASE WHEN $1 IN ($3, $4) THEN COALESCE(picked_up_at, $5) ELSE picked_up_at END, delivered_at = CASE WHEN $1 = $4 THEN COALESCE(delivered_at, $5) ELSE delivered_at END WHERE id = $2`
+	fetchCarriersQuery   = `SELECT ids.carrier_id, COALESCE(carrier_profile.rating, 0) AS rating, COALESCE(carrier_profile.rating_count, 0) AS rating_count, COALESCE(carrier_profile.suspended, FALSE) AS suspended, COALESCE(carrier_profile.suspended_reason, '') AS suspended_reason, COUNT(parcel.id) FILTER (WHERE parcel.status IN ($1, $2)) AS active_parcels, COUNT(parcel.id) FILTER (WHERE parcel.status = $3) AS delivered FROM (SELECT carrier_id FROM carrier_profile UNION SELECT carrier_id FROM carrier_request) ids LEFT JOIN carrier_profile ON carrier_profile.carrier_id = ids.carrier_id LEFT JOIN parcel ON parcel.carrier_id = ids.carrier_id WHERE ($4 = 0 OR ids.carrier_id = $4) GROUP BY ids.carrier_id, carrier_profile.carrier_id ORDER BY ids.carrier_id LIMIT $5 OFFSET $6`
+	lockSuspendedQuery   = `SELECT suspended, COALESCE(suspended_reason, '') FROM carrier_profile WHERE carrier_id = $1 FOR UPDATE`
+	upsertSuspendedQuery = `INSERT INTO carrier_profile (carrier_id, suspended, suspended_reason) VALUES ($1, $2, $3) ON CONFLICT (carrier_id) DO UPDATE SET suspended = EXCLUDED.suspended, suspended_reason = EXCLUDED.suspended_reason`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates admin console repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// SearchParcels returns the parcels matching the search, the newest first
+func (r *repository) SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error) {
+	parcels := []model.Parcel{}
+	err := r.db.SelectContext(ctx, &parcels, searchParcelsQuery, search.ParcelID, search.UserID, search.CarrierID, search.Status, search.Query, search.Limit, search.Offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[SearchParcels] failed to search parcels Error: %v", err)
+		return nil, err
+	}
+	return parcels, nil
+}
+
+// FetchParcelEvents returns the outbox messages of the parcel in the order they were written, sent or not
+func (r *repository) FetchParcelEvents(ctx context.Context, parcelID int) ([]model.OutboxMessage, error) {
+	messages := []model.OutboxMessage{}
+	if err := r.db.SelectContext(ctx, &messages, fetchEventsQuery, parcelID); err != nil {
+		log.Error().Err(err).Msgf("[FetchParcelEvents] failed to fetch events of parcel %d Error: %v", parcelID, err)
+		return nil, err
+	}
+	return messages, nil
+}
+
+// FetchParcelRequests returns every carrier request of the parcel whatever its status, the oldest first
+func (r *repository) FetchParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	requests := []model.CarrierRequest{}
+	if err := r.db.SelectContext(ctx, &requests, fetchRequestsQuery, parcelID); err != nil {
+		log.Error().Err(err).Msgf("[FetchParcelRequests] failed to fetch requests of parcel %d Error: %v", parcelID, err)
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ForceParcelStatus sets the status of the parcel and writes the change with its reason to the outbox in the same
+// transaction. Picked up and delivered times are filled in when the parcel skips ahead and kept when it goes back.
+func (r *repository) ForceParcelStatus(ctx context.Context, override model.StatusOverride, now time.Time) (model.StatusOverride, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[ForceParcelStatus] failed to begin transaction")
+		return model.StatusOverride{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, lockParcelQuery, override.ParcelID).Scan(&override.UserID, &override.CarrierID, &override.PreviousStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.StatusOverride{}, fmt.Errorf("parcel with the ID %d is not found. :%w", override.ParcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[ForceParcelStatus] failed to lock parcel %d Error: %v", override.ParcelID, err)
+		return model.StatusOverride{}, err
+	}
+	if override.PreviousStatus == override.Status {
+		return model.StatusOverride{}, fmt.Errorf("parcel %d is already %s :%w", override.ParcelID, model.ParcelStatusName(override.Status), model.ErrInvalid)
+	}
+
+	if _, err := tx.ExecContext(ctx, forceStatusQuery, override.Status, override.ParcelID, model.ParcelStatusPickedUp, model.ParcelStatusDelivered, now); err != nil {
+		log.Error().Err(err).Msgf("[ForceParcelStatus] failed to update parcel %d Error: %v", override.ParcelID, err)
+		return model.StatusOverride{}, err
+	}
+
+	if err := outbox.Write(ctx, tx, model.Event{
+		Type:       model.EventParcelStatusChanged,
+		ParcelID:   override.ParcelID,
+		UserID:     override.UserID,
+		CarrierID:  override.CarrierID,
+		Status:     override.Status,
+		Reason:     override.Reason,
+		OccurredAt: now,
+	}); err != nil {
+		return model.StatusOverride{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditParcel, override.ParcelID,
+		map[string]interface{}{"status": override.PreviousStatus},
+		map[string]interface{}{"status": override.Status, "reason": override.Reason},
+	); err != nil {
+		return model.StatusOverride{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msgf("[ForceParcelStatus] failed to commit Error: %v", err)
+		return model.StatusOverride{}, err
+	}
+	return override, nil
+}
+
+// FetchCarriers lists the carriers that have a profile or made a request, a carrier ID of 0 lists every carrier
+func (r *repository) FetchCarriers(ctx context.Context, carrierID int, limit int, offset int) ([]model.CarrierProfile, error) {
+	carriers := []model.CarrierProfile{}
+	err := r.db.SelectContext(ctx, &carriers, fetchCarriersQuery, model.ParcelStatusAssigned, model.ParcelStatusPickedUp, model.ParcelStatusDelivered, carrierID, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchCarriers] failed to fetch carriers Error: %v", err)
+		return nil, err
+	}
+	return carriers, nil
+}
+
+// UpdateCarrierSuspension suspends or reinstates the carrier, creating its profile when it has none
+func (r *repository) UpdateCarrierSuspension(ctx context.Context, suspension model.CarrierSuspension) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[UpdateCarrierSuspension] failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	previous := model.CarrierSuspension{CarrierID: suspension.CarrierID}
+	err = tx.QueryRowxContext(ctx, lockSuspendedQuery, suspension.CarrierID).Scan(&previous.Suspended, &previous.Reason)
+	if err != nil && err != sql.ErrNoRows {
+		log.Error().Err(err).Msgf("[UpdateCarrierSuspension] failed to lock carrier %d Error: %v", suspension.CarrierID, err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, upsertSuspendedQuery, suspension.CarrierID, suspension.Suspended, suspension.Reason); err != nil {
+		log.Error().Err(err).Msgf("[UpdateCarrierSuspension] failed to update carrier %d Error: %v", suspension.CarrierID, err)
+		return err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditCarrier, suspension.CarrierID, previous, suspension); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msgf("[UpdateCarrierSuspension] failed to commit Error: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package console
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2021, time.March, 2, 15, 0, 0, 0, time.UTC)
+
+func TestRepository_SearchParcels(t *testing.T) {
+	t.Run("should return matching parcels", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(searchParcelsQuery)).
+			WithArgs(0, 3, 0, model.ParcelStatusCreated, "dhaka", 50, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "source_address"}).
+				AddRow(2, 3, model.ParcelStatusCreated, "Dhaka, Mirpur").
+				AddRow(1, 3, model.ParcelStatusCreated, "Dhaka, Bangladesh"))
+
+		parcels, err := NewRepository(sqlxDB).SearchParcels(context.Background(), model.ParcelSearch{UserID: 3, Status: model.ParcelStatusCreated, Query: "dhaka", Limit: 50})
+		assert.Nil(t, err)
+		assert.Equal(t, []model.Parcel{
+			{ID: 2, UserID: 3, Status: model.ParcelStatusCreated, SourceAddress: "Dhaka, Mirpur"},
+			{ID: 1, UserID: 3, Status: model.ParcelStatusCreated, SourceAddress: "Dhaka, Bangladesh"},
+		}, parcels)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(searchParcelsQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).SearchParcels(context.Background(), model.ParcelSearch{Limit: 50})
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchParcelEvents(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchEventsQuery)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "parcel_id", "payload", "attempts", "last_error", "created_at", "sent_at"}).
+			AddRow(4, model.EventParcelStatusChanged, 1, `{"type":"parcel.status_changed"}`, 1, "", now, now))
+
+	messages, err := NewRepository(sqlxDB).FetchParcelEvents(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.OutboxMessage{
+		{ID: 4, Topic: model.EventParcelStatusChanged, ParcelID: 1, Payload: `{"type":"parcel.status_changed"}`, Attempts: 1, CreatedAt: now, SentAt: &now},
+	}, messages)
+}
+
+func TestRepository_FetchParcelRequests(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchRequestsQuery)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"parcel_id", "carrier_id", "status", "expires_at", "rating", "rating_count"}).
+			AddRow(1, 7, model.CarrierRequestAccepted, now, 4.5, 2).
+			AddRow(1, 9, model.CarrierRequestRejected, now, 0, 0))
+
+	requests, err := NewRepository(sqlxDB).FetchParcelRequests(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.CarrierRequest{
+		{ParcelID: 1, CarrierID: 7, Status: model.CarrierRequestAccepted, ExpiresAt: now, Rating: 4.5, RatingCount: 2},
+		{ParcelID: 1, CarrierID: 9, Status: model.CarrierRequestRejected, ExpiresAt: now},
+	}, requests)
+}
+
+func TestRepository_ForceParcelStatus(t *testing.T) {
+	override := model.StatusOverride{ParcelID: 1, AdminID: 2, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}
+
+	t.Run("should update the status and write the event", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(lockParcelQuery)).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "carrier_id", "status"}).AddRow(3, 7, model.ParcelStatusPickedUp))
+		m.ExpectExec(regexp.QuoteMeta(forceStatusQuery)).
+			WithArgs(model.ParcelStatusDelivered, 1, model.ParcelStatusPickedUp, model.ParcelStatusDelivered, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox").
+			WithArgs(model.EventParcelStatusChanged, 1, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log").
+			WithArgs(2, model.RoleAdmin, model.AuditUpdate, model.AuditParcel, 1, `{"reason":{"before":null,"after":"confirmed by phone"},"status":{"before":3,"after":4}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		ctx := model.WithAuditActor(context.Background(), model.AuditActor{ID: 2, Role: model.RoleAdmin})
+		changed, err := NewRepository(sqlxDB).ForceParcelStatus(ctx, override, now)
+		assert.Nil(t, err)
+		assert.Equal(t, model.StatusOverride{ParcelID: 1, AdminID: 2, UserID: 3, CarrierID: 7, PreviousStatus: model.ParcelStatusPickedUp, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}, changed)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(lockParcelQuery)).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "carrier_id", "status"}))
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).ForceParcelStatus(context.Background(), override, now)
+		assert.EqualError(t, err, "parcel with the ID 1 is not found. :not found")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse the current status", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(lockParcelQuery)).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "carrier_id", "status"}).AddRow(3, 7, model.ParcelStatusDelivered))
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).ForceParcelStatus(context.Background(), override, now)
+		assert.EqualError(t, err, "parcel 1 is already delivered :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FetchCarriers(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchCarriersQuery)).
+		WithArgs(model.ParcelStatusAssigned, model.ParcelStatusPickedUp, model.ParcelStatusDelivered, 0, 50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"carrier_id", "rating", "rating_count", "suspended", "suspended_reason", "active_parcels", "delivered"}).
+			AddRow(7, 4.5, 2, false, "", 1, 12).
+			AddRow(9, 0, 0, true, "fake pickups", 0, 0))
+
+	carriers, err := NewRepository(sqlxDB).FetchCarriers(context.Background(), 0, 50, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.CarrierProfile{
+		{CarrierID: 7, Rating: 4.5, RatingCount: 2, ActiveParcels: 1, Delivered: 12},
+		{CarrierID: 9, Suspended: true, SuspendedReason: "fake pickups"},
+	}, carriers)
+}
+
+func TestRepository_UpdateCarrierSuspension(t *testing.T) {
+	t.Run("should suspend the carrier", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(lockSuspendedQuery)).WithArgs(9).WillReturnError(sql.ErrNoRows)
+		m.ExpectExec(regexp.QuoteMeta(upsertSuspendedQuery)).
+			WithArgs(9, true, "fake pickups").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditCarrier, 9, `{"reason":{"before":"","after":"fake pickups"},"suspended":{"before":false,"after":true}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		err := NewRepository(sqlxDB).UpdateCarrierSuspension(context.Background(), model.CarrierSuspension{CarrierID: 9, Suspended: true, Reason: "fake pickups"})
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(lockSuspendedQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"suspended", "suspended_reason"}).AddRow(true, "fake pickups"))
+		m.ExpectExec(regexp.QuoteMeta(upsertSuspendedQuery)).WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		err := NewRepository(sqlxDB).UpdateCarrierSuspension(context.Background(), model.CarrierSuspension{CarrierID: 9})
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,82 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type service struct {
+	repo     svc.ConsoleRepository
+	notifier svc.EventNotifier
+}
+
+// NewService initiates the admin console service, forced status changes are published to the notifier
+func NewService(repo svc.ConsoleRepository, notifier svc.EventNotifier) *service {
+	return &service{
+		repo:     repo,
+		notifier: notifier,
+	}
+}
+
+func (s *service) SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error) {
+	return s.repo.SearchParcels(ctx, search)
+}
+
+// GetParcelHistory returns the events of the parcel from the outbox, the oldest first. A message that cannot be
+// read is skipped so one bad payload does not hide the rest of the history.
+func (s *service) GetParcelHistory(ctx context.Context, parcelID int) ([]model.Event, error) {
+	messages, err := s.repo.FetchParcelEvents(ctx, parcelID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]model.Event, 0, len(messages))
+	for _, message := range messages {
+		var event model.Event
+		if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+			log.Error().Err(err).Msgf("[GetParcelHistory] failed to read outbox message %d Error: %v", message.ID, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (s *service) GetParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	return s.repo.FetchParcelRequests(ctx, parcelID)
+}
+
+// ForceStatus sets the status of a parcel whatever its current status and notifies its owner and carrier with the reason
+func (s *service) ForceStatus(ctx context.Context, override model.StatusOverride) (model.StatusOverride, error) {
+	now := time.Now()
+	override, err := s.repo.ForceParcelStatus(ctx, override, now)
+	if err != nil {
+		return model.StatusOverride{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:       model.EventParcelStatusChanged,
+		ParcelID:   override.ParcelID,
+		UserID:     override.UserID,
+		CarrierID:  override.CarrierID,
+		Status:     override.Status,
+		Reason:     override.Reason,
+		OccurredAt: now,
+	})
+	log.Info().Msgf("[ForceStatus] admin %d changed parcel %d from %s to %s: %s", override.AdminID, override.ParcelID,
+		model.ParcelStatusName(override.PreviousStatus), model.ParcelStatusName(override.Status), override.Reason)
+	return override, nil
+}
+
+func (s *service) GetCarriers(ctx context.Context, carrierID int, limit int, offset int) ([]model.CarrierProfile, error) {
+	return s.repo.FetchCarriers(ctx, carrierID, limit, offset)
+}
+
+func (s *service) SuspendCarrier(ctx context.Context, suspension model.CarrierSuspension) error {
+	return s.repo.UpdateCarrierSuspension(ctx, suspension)
+}
@@ -0,0 +1,75 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_GetParcelHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should return the events and skip unreadable messages", func(t *testing.T) {
+		repo := mocks.NewMockConsoleRepository(ctrl)
+		repo.EXPECT().FetchParcelEvents(gomock.Any(), 1).Return([]model.OutboxMessage{
+			{ID: 1, Topic: model.EventParcelCreated, ParcelID: 1, Payload: `{"type":"parcel.created","parcel_id":1,"user_id":3,"occurred_at":"2021-03-02T15:00:00Z"}`},
+			{ID: 2, Topic: model.EventParcelStatusChanged, ParcelID: 1, Payload: `not json`},
+			{ID: 3, Topic: model.EventParcelStatusChanged, ParcelID: 1, Payload: `{"type":"parcel.status_changed","parcel_id":1,"status":4,"reason":"confirmed by phone","occurred_at":"2021-03-02T15:00:00Z"}`},
+		}, nil)
+
+		events, err := NewService(repo, mocks.NewMockEventNotifier(ctrl)).GetParcelHistory(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.Event{
+			{Type: model.EventParcelCreated, ParcelID: 1, UserID: 3, OccurredAt: now},
+			{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone", OccurredAt: now},
+		}, events)
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		repo := mocks.NewMockConsoleRepository(ctrl)
+		repo.EXPECT().FetchParcelEvents(gomock.Any(), 1).Return(nil, errors.New("db-error"))
+
+		_, err := NewService(repo, mocks.NewMockEventNotifier(ctrl)).GetParcelHistory(context.Background(), 1)
+		assert.EqualError(t, err, "db-error")
+	})
+}
+
+func TestService_ForceStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	override := model.StatusOverride{ParcelID: 1, AdminID: 2, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}
+
+	t.Run("should notify the owner and carrier", func(t *testing.T) {
+		changed := override
+		changed.UserID, changed.CarrierID, changed.PreviousStatus = 3, 7, model.ParcelStatusPickedUp
+
+		repo := mocks.NewMockConsoleRepository(ctrl)
+		repo.EXPECT().ForceParcelStatus(gomock.Any(), override, gomock.Any()).Return(changed, nil)
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, event model.Event) {
+			assert.WithinDuration(t, time.Now(), event.OccurredAt, time.Second)
+			event.OccurredAt = time.Time{}
+			assert.Equal(t, model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}, event)
+		})
+
+		result, err := NewService(repo, notifier).ForceStatus(context.Background(), override)
+		assert.Nil(t, err)
+		assert.Equal(t, changed, result)
+	})
+
+	t.Run("should not notify when the change fails", func(t *testing.T) {
+		repo := mocks.NewMockConsoleRepository(ctrl)
+		repo.EXPECT().ForceParcelStatus(gomock.Any(), override, gomock.Any()).Return(model.StatusOverride{}, errors.New("db-error"))
+
+		_, err := NewService(repo, mocks.NewMockEventNotifier(ctrl)).ForceStatus(context.Background(), override)
+		assert.EqualError(t, err, "db-error")
+	})
+}
@@ -0,0 +1,283 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// QUEUE_SIZE is the number of created parcels waiting to be dispatched, parcels are dropped when the queue is full
+	QUEUE_SIZE = 1000
+	// MAX_DECLINES is the number of declined or expired offers before a parcel falls back to the open marketplace
+	MAX_DECLINES = 3
+	// OFFER_TIMEOUT is the time a carrier has to accept an offer
+	OFFER_TIMEOUT = 30 * time.Second
+	// RATING_WEIGHT is the distance in kilometers a rating star is worth when ranking carriers
+	RATING_WEIGHT = 2
+	// DEFAULT_RATING ranks carriers without reviews in the middle of the scale
+	DEFAULT_RATING = 3
+)
+
+type matcher struct {
+	parcelRepo  svc.ParcelRepository
+	carrierRepo svc.CarrierRepository
+	carrierSvc  svc.CarrierService
+	feed        svc.JobFeed
+	queue       chan model.Event
+
+	maxDeclines  int
+	offerTimeout time.Duration
+
+	mu       sync.Mutex
+	sequence int64
+	offers   map[int64]*pendingOffer
+}
+
+type pendingOffer struct {
+	offer     model.Offer
+	responses chan response
+}
+
+type response struct {
+	accept bool
+	result chan error
+}
+
+// NewMatcher initiates the dispatcher of parcels created with auto dispatch
+func NewMatcher(parcelRepo svc.ParcelRepository, carrierRepo svc.CarrierRepository, carrierSvc svc.CarrierService, feed svc.JobFeed) *matcher {
+	return &matcher{
+		parcelRepo:   parcelRepo,
+		carrierRepo:  carrierRepo,
+		carrierSvc:   carrierSvc,
+		feed:         feed,
+		queue:        make(chan model.Event, QUEUE_SIZE),
+		maxDeclines:  MAX_DECLINES,
+		offerTimeout: OFFER_TIMEOUT,
+		offers:       make(map[int64]*pendingOffer),
+	}
+}
+
+// Notify queues created parcels to be dispatched by Run, other events are ignored
+func (m *matcher) Notify(ctx context.Context, event model.Event) {
+	if event.Type != model.EventParcelCreated {
+		return
+	}
+
+	select {
+	case m.queue <- event:
+	default:
+		log.Error().Msgf("[Notify] dispatch queue is full, dropping parcel %d", event.ParcelID)
+	}
+}
+
+// Run dispatches every queued parcel concurrently until the context is cancelled. Parcels still being
+// dispatched then stay in the open marketplace.
+func (m *matcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case event := <-m.queue:
+			wg.Add(1)
+			go func(parcelID int) {
+				defer wg.Done()
+				m.dispatch(ctx, parcelID)
+			}(event.ParcelID)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatch offers the parcel to the ranked carriers one at a time until one accepts, the parcel is no
+// longer waiting for a carrier, or MAX_DECLINES offers were declined or expired. The parcel stays in the
+// marketplace meanwhile, an offer accepted after a carrier was assigned there fails with ErrConflict.
+func (m *matcher) dispatch(ctx context.Context, parcelID int) {
+	parcel, err := m.parcelRepo.FetchParcelByID(ctx, parcelID)
+	if err != nil {
+		log.Error().Err(err).Msgf("[dispatch] failed to fetch parcel %d. Error: %v", parcelID, err)
+		return
+	}
+	if !parcel.AutoDispatch {
+		return
+	}
+
+	declines := 0
+	for _, candidate := range m.rank(ctx, parcel) {
+		if declines >= m.maxDeclines {
+			break
+		}
+
+		// the parcel can be taken from the marketplace or cancelled while offers are made
+		if parcel, err = m.parcelRepo.FetchParcelByID(ctx, parcelID); err != nil || parcel.Status != model.ParcelStatusCreated {
+			return
+		}
+
+		accepted, err := m.offer(ctx, candidate)
+		if err != nil {
+			return
+		}
+		if accepted {
+			log.Info().Msgf("[dispatch] parcel %d is assigned to carrier %d", parcelID, candidate.CarrierID)
+			return
+		}
+		declines++
+	}
+
+	log.Info().Msgf("[dispatch] no carrier accepted parcel %d after %d offers, publishing it to the marketplace", parcelID, declines)
+	m.feed.Publish(parcel)
+}
+
+// rank returns the available carriers the parcel fits, nearest first. Each rating star brings a carrier
+// RATING_WEIGHT kilometers closer and spare vehicle capacity moves it up to a kilometer further.
+func (m *matcher) rank(ctx context.Context, parcel model.Parcel) []model.Candidate {
+	var candidates []model.Candidate
+	var carrierIDs []int
+	for carrierID, filter := range m.feed.Available() {
+		job, ok := filter.Match(parcel)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, model.Candidate{CarrierID: carrierID, Filter: filter, Job: job, Rating: DEFAULT_RATING})
+		carrierIDs = append(carrierIDs, carrierID)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	ratings := make(map[int]float64)
+	if fetched, err := m.carrierRepo.FetchCarrierRatings(ctx, carrierIDs); err != nil {
+		log.Error().Err(err).Msgf("[rank] failed to fetch carrier ratings, ranking parcel %d by distance. Error: %v", parcel.ID, err)
+	} else {
+		for _, rating := range fetched {
+			ratings[rating.CarrierID] = rating.Rating
+		}
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		if rating, ok := ratings[c.CarrierID]; ok {
+			c.Rating = rating
+		}
+		spare := float64((c.Filter.Capacity - parcel.Weight) / c.Filter.Capacity)
+		c.Score = c.Job.Distance - RATING_WEIGHT*c.Rating + spare
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score == candidates[j].Score {
+			return candidates[i].CarrierID < candidates[j].CarrierID
+		}
+		return candidates[i].Score < candidates[j].Score
+	})
+	return candidates
+}
+
+// offer makes a timed offer to the candidate and waits for its answer, it returns an error when the
+// dispatch should stop without falling back
+func (m *matcher) offer(ctx context.Context, candidate model.Candidate) (bool, error) {
+	m.mu.Lock()
+	m.sequence++
+	pending := &pendingOffer{
+		offer: model.Offer{
+			ID:        m.sequence,
+			CarrierID: candidate.CarrierID,
+			Job:       candidate.Job,
+			ExpiresAt: time.Now().Add(m.offerTimeout),
+		},
+		responses: make(chan response, 1),
+	}
+	m.offers[pending.offer.ID] = pending
+	m.mu.Unlock()
+
+	if !m.feed.Offer(pending.offer) {
+		m.withdraw(pending)
+		return false, nil
+	}
+
+	timer := time.NewTimer(m.offerTimeout)
+	defer timer.Stop()
+
+	var r response
+	select {
+	case r = <-pending.responses:
+	case <-timer.C:
+		if m.withdraw(pending) {
+			return false, nil
+		}
+		// the carrier answered as the offer expired
+		r = <-pending.responses
+	case <-ctx.Done():
+		if m.withdraw(pending) {
+			return false, ctx.Err()
+		}
+		r = <-pending.responses
+	}
+
+	if !r.accept {
+		r.result <- nil
+		return false, nil
+	}
+
+	err := m.assign(ctx, pending.offer)
+	r.result <- err
+	if errors.Is(err, model.ErrConflict) {
+		log.Info().Msgf("[offer] parcel %d was assigned in the marketplace before carrier %d accepted the offer", pending.offer.Job.Parcel.ID, pending.offer.CarrierID)
+		return false, err
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("[offer] failed to assign parcel %d to carrier %d. Error: %v", pending.offer.Job.Parcel.ID, pending.offer.CarrierID, err)
+		return false, nil
+	}
+	return true, nil
+}
+
+// withdraw removes the offer unless the carrier has already answered it, it reports whether it was removed
+func (m *matcher) withdraw(pending *pendingOffer) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.offers[pending.offer.ID]; !ok {
+		return false
+	}
+	delete(m.offers, pending.offer.ID)
+	return true
+}
+
+// assign requests the parcel on behalf of the carrier and accepts the request like a sender would
+func (m *matcher) assign(ctx context.Context, offer model.Offer) error {
+	request := model.CarrierRequest{ParcelID: offer.Job.Parcel.ID, CarrierID: offer.CarrierID}
+	if err := m.carrierSvc.NewCarrierRequest(ctx, request); err != nil && !errors.Is(err, model.ErrInvalid) {
+		return err
+	}
+	return m.carrierSvc.AssignCarrierToParcel(ctx, request)
+}
+
+// Respond accepts or declines a pending offer made to the carrier, an accepted offer returns once the
+// parcel is assigned
+func (m *matcher) Respond(ctx context.Context, carrierID int, offerID int64, accept bool) error {
+	m.mu.Lock()
+	pending, ok := m.offers[offerID]
+	if !ok || pending.offer.CarrierID != carrierID {
+		m.mu.Unlock()
+		return fmt.Errorf("offer %d is not pending for carrier %d :%w", offerID, carrierID, model.ErrNotFound)
+	}
+	delete(m.offers, offerID)
+	m.mu.Unlock()
+
+	result := make(chan error, 1)
+	pending.responses <- response{accept: accept, result: result}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
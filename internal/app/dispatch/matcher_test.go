@@ -0,0 +1,269 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var parcel = model.Parcel{ID: 1, Status: model.ParcelStatusCreated, SourceLatitude: 23.7806, SourceLongitude: 90.4193, Weight: 5, AutoDispatch: true}
+
+// available carriers around the pickup address of the parcel
+var available = map[int]model.JobFilter{
+	// about 3.4 km away
+	1: {Latitude: 23.8103, Longitude: 90.4125, Radius: 10, Capacity: 20},
+	// about 0.6 km away
+	2: {Latitude: 23.7750, Longitude: 90.4180, Radius: 10, Capacity: 10},
+	// about 1.9 km away but the parcel does not fit
+	3: {Latitude: 23.7900, Longitude: 90.4050, Radius: 10, Capacity: 2},
+	// about 5.7 km away
+	4: {Latitude: 23.7300, Longitude: 90.4100, Radius: 10, Capacity: 5},
+}
+
+func TestMatcher_Rank(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should rank by distance, rating and spare capacity", func(t *testing.T) {
+		feed := mocks.NewMockJobFeed(ctrl)
+		feed.EXPECT().Available().Return(available)
+		carrierRepo := mocks.NewMockCarrierRepository(ctrl)
+		carrierRepo.EXPECT().FetchCarrierRatings(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ids []int) ([]model.CarrierRating, error) {
+			assert.ElementsMatch(t, []int{1, 2, 4}, ids)
+			return []model.CarrierRating{{CarrierID: 1, Rating: 5, Count: 10}, {CarrierID: 2, Rating: 1.5, Count: 2}}, nil
+		})
+
+		m := NewMatcher(nil, carrierRepo, nil, feed)
+		candidates := m.rank(context.Background(), parcel)
+
+		var ranked []int
+		for _, c := range candidates {
+			ranked = append(ranked, c.CarrierID)
+		}
+		// 1: 3.4 - 10 + 0.75, 4: 5.7 - 6 + 0, 2: 0.6 - 3 + 0.5
+		assert.Equal(t, []int{1, 2, 4}, ranked)
+		assert.InDelta(t, -5.88, candidates[0].Score, 0.01)
+	})
+
+	t.Run("should rank by distance when ratings fail", func(t *testing.T) {
+		feed := mocks.NewMockJobFeed(ctrl)
+		feed.EXPECT().Available().Return(available)
+		carrierRepo := mocks.NewMockCarrierRepository(ctrl)
+		carrierRepo.EXPECT().FetchCarrierRatings(gomock.Any(), gomock.Any()).Return(nil, errors.New("db-error"))
+
+		candidates := NewMatcher(nil, carrierRepo, nil, feed).rank(context.Background(), parcel)
+		assert.Len(t, candidates, 3)
+		assert.Equal(t, 2, candidates[0].CarrierID)
+		assert.Equal(t, 1, candidates[1].CarrierID)
+		assert.Equal(t, 4, candidates[2].CarrierID)
+	})
+
+	t.Run("should return no candidates", func(t *testing.T) {
+		feed := mocks.NewMockJobFeed(ctrl)
+		feed.EXPECT().Available().Return(map[int]model.JobFilter{3: available[3]})
+
+		assert.Empty(t, NewMatcher(nil, nil, nil, feed).rank(context.Background(), parcel))
+	})
+}
+
+// newTestMatcher returns a matcher whose carriers 2, 1 and 4 are ranked in that order
+func newTestMatcher(ctrl *gomock.Controller, feed *mocks.MockJobFeed, parcelRepo *mocks.MockParcelRepository, carrierSvc *mocks.MockCarrierService) *matcher {
+	carrierRepo := mocks.NewMockCarrierRepository(ctrl)
+	carrierRepo.EXPECT().FetchCarrierRatings(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	feed.EXPECT().Available().Return(available).AnyTimes()
+
+	m := NewMatcher(parcelRepo, carrierRepo, carrierSvc, feed)
+	m.maxDeclines = 2
+	m.offerTimeout = 50 * time.Millisecond
+	return m
+}
+
+func TestMatcher_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should assign the carrier accepting the offer", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(3)
+		carrierSvc := mocks.NewMockCarrierService(ctrl)
+		request := model.CarrierRequest{ParcelID: 1, CarrierID: 1}
+		gomock.InOrder(
+			carrierSvc.EXPECT().NewCarrierRequest(gomock.Any(), request).Return(nil),
+			carrierSvc.EXPECT().AssignCarrierToParcel(gomock.Any(), request).Return(nil),
+		)
+		feed := mocks.NewMockJobFeed(ctrl)
+		m := newTestMatcher(ctrl, feed, parcelRepo, carrierSvc)
+
+		responses := make(chan error, 2)
+		feed.EXPECT().Offer(gomock.Any()).DoAndReturn(func(offer model.Offer) bool {
+			go func() {
+				assert.Equal(t, 1, offer.Job.Parcel.ID)
+				responses <- m.Respond(context.Background(), offer.CarrierID, offer.ID, offer.CarrierID == 1)
+			}()
+			return true
+		}).Times(2)
+
+		m.dispatch(context.Background(), 1)
+		assert.Nil(t, <-responses)
+		assert.Nil(t, <-responses)
+	})
+
+	t.Run("should fall back to the marketplace after declines", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(3)
+		feed := mocks.NewMockJobFeed(ctrl)
+		m := newTestMatcher(ctrl, feed, parcelRepo, mocks.NewMockCarrierService(ctrl))
+
+		gomock.InOrder(
+			// carrier 2 is not connected anymore and carrier 1 lets the offer expire
+			feed.EXPECT().Offer(gomock.Any()).Return(false),
+			feed.EXPECT().Offer(gomock.Any()).DoAndReturn(func(offer model.Offer) bool {
+				assert.Equal(t, 1, offer.CarrierID)
+				return true
+			}),
+			feed.EXPECT().Publish(parcel),
+		)
+
+		m.dispatch(context.Background(), 1)
+		assert.Empty(t, m.offers)
+	})
+
+	t.Run("should continue when the assignment fails", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(3)
+		carrierSvc := mocks.NewMockCarrierService(ctrl)
+		carrierSvc.EXPECT().NewCarrierRequest(gomock.Any(), gomock.Any()).Return(errors.New("carrier request exists :invalid")).Times(2)
+		feed := mocks.NewMockJobFeed(ctrl)
+		m := newTestMatcher(ctrl, feed, parcelRepo, carrierSvc)
+
+		responses := make(chan error, 2)
+		feed.EXPECT().Offer(gomock.Any()).DoAndReturn(func(offer model.Offer) bool {
+			go func() {
+				responses <- m.Respond(context.Background(), offer.CarrierID, offer.ID, true)
+			}()
+			return true
+		}).Times(2)
+		feed.EXPECT().Publish(parcel)
+
+		m.dispatch(context.Background(), 1)
+		assert.EqualError(t, <-responses, "carrier request exists :invalid")
+		assert.EqualError(t, <-responses, "carrier request exists :invalid")
+	})
+
+	t.Run("should stop when the parcel is taken", func(t *testing.T) {
+		assigned := parcel
+		assigned.Status = model.ParcelStatusAssigned
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		gomock.InOrder(
+			parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(2),
+			parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(assigned, nil),
+		)
+		feed := mocks.NewMockJobFeed(ctrl)
+		m := newTestMatcher(ctrl, feed, parcelRepo, nil)
+		feed.EXPECT().Offer(gomock.Any()).Return(false)
+
+		m.dispatch(context.Background(), 1)
+	})
+
+	t.Run("should stop when the parcel is assigned in the marketplace before the offer is accepted", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(2)
+		carrierSvc := mocks.NewMockCarrierService(ctrl)
+		request := model.CarrierRequest{ParcelID: 1, CarrierID: 2}
+		gomock.InOrder(
+			carrierSvc.EXPECT().NewCarrierRequest(gomock.Any(), request).Return(nil),
+			carrierSvc.EXPECT().AssignCarrierToParcel(gomock.Any(), request).Return(fmt.Errorf("parcel 1 is assigned and can not be assigned :%w", model.ErrConflict)),
+		)
+		feed := mocks.NewMockJobFeed(ctrl)
+		m := newTestMatcher(ctrl, feed, parcelRepo, carrierSvc)
+
+		responses := make(chan error, 1)
+		feed.EXPECT().Offer(gomock.Any()).DoAndReturn(func(offer model.Offer) bool {
+			go func() {
+				responses <- m.Respond(context.Background(), offer.CarrierID, offer.ID, true)
+			}()
+			return true
+		})
+
+		m.dispatch(context.Background(), 1)
+		assert.True(t, errors.Is(<-responses, model.ErrConflict))
+		assert.Empty(t, m.offers)
+	})
+
+	t.Run("should ignore parcels without auto dispatch", func(t *testing.T) {
+		manual := parcel
+		manual.AutoDispatch = false
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(manual, nil)
+
+		NewMatcher(parcelRepo, nil, nil, mocks.NewMockJobFeed(ctrl)).dispatch(context.Background(), 1)
+	})
+}
+
+func TestMatcher_Respond(t *testing.T) {
+	m := NewMatcher(nil, nil, nil, nil)
+	m.offers[1] = &pendingOffer{offer: model.Offer{ID: 1, CarrierID: 2}, responses: make(chan response, 1)}
+
+	err := m.Respond(context.Background(), 3, 1, true)
+	assert.EqualError(t, err, "offer 1 is not pending for carrier 3 :not found")
+
+	err = m.Respond(context.Background(), 2, 5, true)
+	assert.True(t, errors.Is(err, model.ErrNotFound))
+}
+
+func TestMatcher_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	done := make(chan struct{})
+	parcelRepo := mocks.NewMockParcelRepository(ctrl)
+	parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, id int) (model.Parcel, error) {
+		close(done)
+		return model.Parcel{ID: 1}, nil
+	})
+
+	m := NewMatcher(parcelRepo, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(stopped)
+	}()
+
+	m.Notify(ctx, model.Event{Type: model.EventCarrierAssigned, ParcelID: 2})
+	m.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 1})
+	<-done
+	cancel()
+	<-stopped
+}
+
+func TestMatcher_Assign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	offer := model.Offer{ID: 1, CarrierID: 2, Job: model.Job{Parcel: parcel}}
+	request := model.CarrierRequest{ParcelID: 1, CarrierID: 2}
+
+	t.Run("should accept an existing carrier request", func(t *testing.T) {
+		carrierSvc := mocks.NewMockCarrierService(ctrl)
+		carrierSvc.EXPECT().NewCarrierRequest(gomock.Any(), request).Return(fmt.Errorf("duplicate key :%w", model.ErrInvalid))
+		carrierSvc.EXPECT().AssignCarrierToParcel(gomock.Any(), request).Return(nil)
+
+		assert.Nil(t, NewMatcher(nil, nil, carrierSvc, nil).assign(context.Background(), offer))
+	})
+
+	t.Run("should return assignment error", func(t *testing.T) {
+		carrierSvc := mocks.NewMockCarrierService(ctrl)
+		carrierSvc.EXPECT().NewCarrierRequest(gomock.Any(), request).Return(nil)
+		carrierSvc.EXPECT().AssignCarrierToParcel(gomock.Any(), request).Return(errors.New("db-error"))
+
+		assert.EqualError(t, NewMatcher(nil, nil, carrierSvc, nil).assign(context.Background(), offer), "db-error")
+	})
+}
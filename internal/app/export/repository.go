@@ -0,0 +1,65 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// exportBatchSize is the number of parcels fetched from the cursor at a time, it must match fetchParcelsQuery
+const exportBatchSize = 500
+
+// SQL Query
+const (
+	declareCursorQuery = `DECLARE parcel_export NO SCROLL CURSOR FOR SELECT id, user_id, COALESCE(carrier_id, 0) AS carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, assigned_at, picked_up_at, delivered_at, pickup_deadline, delivery_deadline, COALESCE(return_of, 0) AS return_of, COALESCE(shipment_id, 0) AS shipment_id, stop, created_at, updated_at FROM parcel WHERE ($1 = 0 OR status = $1) AND ($2 = 0 OR user_id = $2) AND ($3 = 0 OR carrier_id = $3) AND ($4 = '' OR type = $4) AND ($6::timestamp IS NULL OR (CASE WHEN $5 = 'delivered' THEN delivered_at ELSE created_at END) >= $6) AND ($7::timestamp IS NULL OR (CASE WHEN $5 = 'delivered' THEN delivered_at ELSE created_at END) < $7) ORDER BY id`
+	fetchParcelsQuery  = `FETCH 500 FROM parcel_export`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates export repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// StreamParcels calls fn with every parcel matching the filter in ID order. The parcels are read from a cursor
+// in batches, so only one batch is held in memory however many parcels match.
+func (r *repository) StreamParcels(ctx context.Context, filter model.ExportFilter, fn func(model.Parcel) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		log.Error().Err(err).Msg("[StreamParcels] failed to begin transaction")
+		return err
+	}
+	// the export only reads, the cursor is closed with the transaction
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, declareCursorQuery, filter.Status, filter.UserID, filter.CarrierID, filter.Type, filter.Date, filter.From, filter.To); err != nil {
+		log.Error().Err(err).Msgf("[StreamParcels] failed to declare cursor Error: %v", err)
+		return err
+	}
+
+	for {
+		parcels := []model.Parcel{}
+		if err := tx.SelectContext(ctx, &parcels, fetchParcelsQuery); err != nil {
+			log.Error().Err(err).Msgf("[StreamParcels] failed to fetch parcels Error: %v", err)
+			return err
+		}
+
+		for _, parcel := range parcels {
+			if err := fn(parcel); err != nil {
+				return err
+			}
+		}
+
+		if len(parcels) < exportBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_StreamParcels(t *testing.T) {
+	from := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	filter := model.ExportFilter{Status: model.ParcelStatusDelivered, Date: model.ExportDateDelivered, From: &from}
+
+	t.Run("should read every parcel from the cursor", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("^DECLARE parcel_export NO SCROLL CURSOR FOR SELECT (.+) FROM parcel WHERE (.+) ORDER BY id").
+			WithArgs(model.ParcelStatusDelivered, 0, 0, "", model.ExportDateDelivered, &from, nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery("^FETCH 500 FROM parcel_export").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(1, 3, 4).AddRow(2, 3, 4))
+		m.ExpectRollback()
+
+		var ids []int
+		repo := NewRepository(sqlxDB)
+		err := repo.StreamParcels(context.Background(), filter, func(parcel model.Parcel) error {
+			ids = append(ids, parcel.ID)
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2}, ids)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should stop when the callback fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("^DECLARE parcel_export (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery("^FETCH 500 FROM parcel_export").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		m.ExpectRollback()
+
+		calls := 0
+		repo := NewRepository(sqlxDB)
+		err := repo.StreamParcels(context.Background(), filter, func(parcel model.Parcel) error {
+			calls++
+			return errors.New("write-error")
+		})
+
+		assert.EqualError(t, err, "write-error")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("^DECLARE parcel_export (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.StreamParcels(context.Background(), filter, func(parcel model.Parcel) error { return nil })
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+type service struct {
+	repo svc.ExportRepository
+}
+
+func NewService(repo svc.ExportRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// ExportParcels writes the parcels matching the filter to w as CSV with a header row or as one JSON object per line
+// and returns the number of parcels written. Output is buffered and reaches w in chunks as the parcels are read.
+func (s *service) ExportParcels(ctx context.Context, w io.Writer, format string, filter model.ExportFilter) (int, error) {
+	count := 0
+	if format == model.ExportFormatNDJSON {
+		buf := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buf)
+		err := s.repo.StreamParcels(ctx, filter, func(parcel model.Parcel) error {
+			count++
+			return encoder.Encode(parcel)
+		})
+		if err != nil {
+			return count, err
+		}
+		return count, buf.Flush()
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(model.ExportColumns); err != nil {
+		return 0, err
+	}
+	err := s.repo.StreamParcels(ctx, filter, func(parcel model.Parcel) error {
+		count++
+		return writer.Write(parcel.ExportRecord())
+	})
+	if err != nil {
+		return count, err
+	}
+	writer.Flush()
+	return count, writer.Error()
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var delivered = time.Date(2021, time.March, 2, 15, 0, 0, 0, time.UTC)
+
+var parcel = model.Parcel{
+	ID:                 1,
+	UserID:             3,
+	CarrierID:          7,
+	Status:             model.ParcelStatusDelivered,
+	SourceAddress:      "Dhaka, Bangladesh",
+	DestinationAddress: "Pabna Shadar",
+	ParcelType:         "Document",
+	Weight:             1.5,
+	Price:              200,
+	CarrierFee:         180,
+	CompanyFee:         20,
+	CreatedAt:          time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC),
+	DeliveredAt:        &delivered,
+}
+
+func streamParcels(parcels ...model.Parcel) func(context.Context, model.ExportFilter, func(model.Parcel) error) error {
+	return func(_ context.Context, _ model.ExportFilter, fn func(model.Parcel) error) error {
+		for _, p := range parcels {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestService_ExportParcels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	filter := model.ExportFilter{Status: model.ParcelStatusDelivered}
+
+	t.Run("should write csv with a header row", func(t *testing.T) {
+		repo := mocks.NewMockExportRepository(ctrl)
+		repo.EXPECT().StreamParcels(gomock.Any(), filter, gomock.Any()).DoAndReturn(streamParcels(parcel))
+
+		var buf bytes.Buffer
+		count, err := NewService(repo).ExportParcels(context.Background(), &buf, model.ExportFormatCSV, filter)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, strings.Join(model.ExportColumns, ",")+"\n"+
+			`1,3,7,4,Document,"Dhaka, Bangladesh",Pabna Shadar,1.5,0,200,180,20,0,0,,,0,0,2021-03-01T10:00:00Z,,,2021-03-02T15:00:00Z`+"\n", buf.String())
+	})
+
+	t.Run("should write one json object per line", func(t *testing.T) {
+		repo := mocks.NewMockExportRepository(ctrl)
+		repo.EXPECT().StreamParcels(gomock.Any(), filter, gomock.Any()).DoAndReturn(streamParcels(model.Parcel{ID: 1}, model.Parcel{ID: 2}))
+
+		var buf bytes.Buffer
+		count, err := NewService(repo).ExportParcels(context.Background(), &buf, model.ExportFormatNDJSON, filter)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], `{"id":2,`))
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		repo := mocks.NewMockExportRepository(ctrl)
+		repo.EXPECT().StreamParcels(gomock.Any(), filter, gomock.Any()).Return(errors.New("sql-error"))
+
+		var buf bytes.Buffer
+		_, err := NewService(repo).ExportParcels(context.Background(), &buf, model.ExportFormatCSV, filter)
+
+		assert.EqualError(t, err, "sql-error")
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,132 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	fetchInvoiceByParcelIDQuery = `SELECT id, number, parcel_id, user_id, source_address, destination_address, type, carrier_fee, company_fee, discount, tax, tax_name, tax_rate, tax_inclusive, total, issued_at FROM invoice WHERE parcel_id = $1`
+	lockParcelQuery             = `SELECT id, user_id, status, source_address, destination_address, type, price, carrier_fee, company_fee, discount, tax, tax_name, tax_rate, tax_inclusive FROM parcel WHERE id = $1 FOR UPDATE`
+	nextInvoiceNumberQuery      = `UPDATE invoice_counter SET last_number = last_number + 1 WHERE id = 1 RETURNING last_number`
+	insertInvoiceQuery          = `INSERT INTO invoice (number, parcel_id, user_id, source_address, destination_address, type, carrier_fee, company_fee, discount, tax, tax_name, tax_rate, tax_inclusive, total) VALUES (:number, :parcel_id, :user_id, :source_address, :destination_address, :type, :carrier_fee, :company_fee, :discount, :tax, :tax_name, :tax_rate, :tax_inclusive, :total) RETURNING id, issued_at`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates invoice repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) FetchInvoiceByParcelID(ctx context.Context, parcelID int) (model.Invoice, error) {
+	return fetchInvoice(ctx, r.db, parcelID)
+}
+
+// IssueInvoice creates the invoice of a delivered parcel. The parcel row is locked while the next number
+// is taken from the counter, so numbers have no gaps and a parcel never gets two invoices.
+func (r *repository) IssueInvoice(ctx context.Context, parcelID int) (model.Invoice, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[IssueInvoice] failed to begin transaction")
+		return model.Invoice{}, err
+	}
+
+	var parcel model.Parcel
+	if err := tx.GetContext(ctx, &parcel, lockParcelQuery, parcelID); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return model.Invoice{}, fmt.Errorf("parcel with the ID %d is not found. :%w", parcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[IssueInvoice] failed to fetch parcel Error: %v", err)
+		return model.Invoice{}, err
+	}
+
+	if parcel.Status != model.ParcelStatusDelivered {
+		tx.Rollback()
+		return model.Invoice{}, fmt.Errorf("parcel %d is not delivered yet :%w", parcelID, model.ErrInvalid)
+	}
+
+	// another request may have issued the invoice while we were waiting for the lock
+	invoice, err := fetchInvoice(ctx, tx, parcelID)
+	if err == nil || !errors.Is(err, model.ErrNotFound) {
+		tx.Rollback()
+		return invoice, err
+	}
+
+	var sequence int
+	if err := tx.GetContext(ctx, &sequence, nextInvoiceNumberQuery); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[IssueInvoice] failed to get next invoice number Error: %v", err)
+		return model.Invoice{}, err
+	}
+
+	invoice = model.Invoice{
+		Number:             model.InvoiceNumber(sequence),
+		ParcelID:           parcel.ID,
+		UserID:             parcel.UserID,
+		SourceAddress:      parcel.SourceAddress,
+		DestinationAddress: parcel.DestinationAddress,
+		ParcelType:         parcel.ParcelType,
+		CarrierFee:         parcel.CarrierFee,
+		CompanyFee:         parcel.CompanyFee,
+		Discount:           parcel.Discount,
+		Tax:                parcel.Tax,
+		TaxName:            parcel.TaxName,
+		TaxRate:            parcel.TaxRate,
+		TaxInclusive:       parcel.TaxInclusive,
+		Total:              parcel.Price,
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertInvoiceQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[IssueInvoice] PrepareNamedContext Error: %v", err)
+		return model.Invoice{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &invoice, &invoice); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[IssueInvoice] GetContext Error: %v", err)
+		return model.Invoice{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditInvoice, invoice.ID, nil, invoice); err != nil {
+		tx.Rollback()
+		return model.Invoice{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("[IssueInvoice] Failed to commit")
+		return model.Invoice{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	return invoice, nil
+}
+
+func fetchInvoice(ctx context.Context, q sqlx.QueryerContext, parcelID int) (model.Invoice, error) {
+	var invoice model.Invoice
+
+	if err := sqlx.GetContext(ctx, q, &invoice, fetchInvoiceByParcelIDQuery, parcelID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Invoice{}, fmt.Errorf("invoice for parcel %d is not found. :%w", parcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchInvoiceByParcelID] failed to fetch invoice Error: %v", err)
+		return model.Invoice{}, err
+	}
+
+	return invoice, nil
+}
@@ -0,0 +1,175 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var parcelColumns = []string{"id", "user_id", "status", "source_address", "destination_address", "type", "price", "carrier_fee", "company_fee", "discount"}
+
+func TestRepository_FetchInvoiceByParcelID(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "number", "parcel_id", "total"}).AddRow(1, "INV-000001", 1, 200))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchInvoiceByParcelID(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Invoice{ID: 1, Number: "INV-000001", ParcelID: 1, Total: 200}, result)
+	})
+
+	t.Run("should return not found error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchInvoiceByParcelID(context.Background(), 1)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchInvoiceByParcelID(context.Background(), 1)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_IssueInvoice(t *testing.T) {
+	issuedAt := time.Now()
+
+	t.Run("should issue next invoice number", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(parcelColumns).AddRow(1, 3, model.ParcelStatusDelivered, "Dhaka Bangladesh", "Pabna Shadar", "Document", 180, 180, 20, 20))
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WithArgs(1).
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectQuery("UPDATE invoice_counter SET (.+) RETURNING last_number").
+			WillReturnRows(sqlmock.NewRows([]string{"last_number"}).AddRow(42))
+		m.ExpectPrepare("INSERT INTO invoice (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "issued_at"}).AddRow(7, issuedAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditInvoice, 7, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.IssueInvoice(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Invoice{
+			ID:                 7,
+			Number:             "INV-000042",
+			ParcelID:           1,
+			UserID:             3,
+			SourceAddress:      "Dhaka Bangladesh",
+			DestinationAddress: "Pabna Shadar",
+			ParcelType:         "Document",
+			CarrierFee:         180,
+			CompanyFee:         20,
+			Discount:           20,
+			Total:              180,
+			IssuedAt:           issuedAt,
+		}, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invoice issued by concurrent request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(parcelColumns).AddRow(1, 3, model.ParcelStatusDelivered, "Dhaka Bangladesh", "Pabna Shadar", "Document", 180, 180, 20, 20))
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "number", "parcel_id"}).AddRow(1, "INV-000001", 1))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.IssueInvoice(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "INV-000001", result.Number)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid for undelivered parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(parcelColumns).AddRow(1, 3, model.ParcelStatusAssigned, "Dhaka Bangladesh", "Pabna Shadar", "Document", 180, 180, 20, 20))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.IssueInvoice(context.Background(), 1)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+	})
+
+	t.Run("should return not found for unknown parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.IssueInvoice(context.Background(), 1)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+
+	t.Run("should return counter error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(parcelColumns).AddRow(1, 3, model.ParcelStatusDelivered, "Dhaka Bangladesh", "Pabna Shadar", "Document", 180, 180, 20, 20))
+		m.ExpectQuery("^SELECT (.+) FROM invoice WHERE (.+)").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectQuery("UPDATE invoice_counter SET (.+) RETURNING last_number").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.IssueInvoice(context.Background(), 1)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
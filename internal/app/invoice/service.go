@@ -0,0 +1,130 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	companyName = "Parcel Service"
+	dateLayout  = "02 Jan 2006"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": money,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Invoice.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 40px; }
+table { border-collapse: collapse; width: 100%; }
+td, th { padding: 6px; border-bottom: 1px solid #ddd; text-align: left; }
+.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Company}}</h1>
+<h2>Invoice {{.Invoice.Number}}</h2>
+<p>Issued: {{.Invoice.IssuedAt.Format "02 Jan 2006"}}<br>
+Parcel: #{{.Invoice.ParcelID}} ({{.Invoice.ParcelType}})<br>
+From: {{.Invoice.SourceAddress}}<br>
+To: {{.Invoice.DestinationAddress}}</p>
+<table>
+<tr><th>Description</th><th class="amount">Amount</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}{{if .Included}} (included){{end}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{end}}<tr><th>Total</th><th class="amount">{{money .Invoice.Total}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+type service struct {
+	repo svc.InvoiceRepository
+}
+
+func NewService(repo svc.InvoiceRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// GetInvoice returns the stored invoice of the parcel, issuing it on the first request
+func (s *service) GetInvoice(ctx context.Context, parcelID int) (model.Invoice, error) {
+	invoice, err := s.repo.FetchInvoiceByParcelID(ctx, parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return s.repo.IssueInvoice(ctx, parcelID)
+		}
+		return model.Invoice{}, err
+	}
+	return invoice, nil
+}
+
+func (s *service) RenderHTML(w io.Writer, invoice model.Invoice) error {
+	return htmlTemplate.Execute(w, struct {
+		Company string
+		Invoice model.Invoice
+		Lines   []model.InvoiceLine
+	}{
+		Company: companyName,
+		Invoice: invoice,
+		Lines:   invoice.Lines(),
+	})
+}
+
+func (s *service) RenderPDF(w io.Writer, invoice model.Invoice) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+invoice.Number, true)
+	pdf.SetCreationDate(invoice.IssuedAt)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.Cell(0, 10, companyName)
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.Cell(0, 8, "Invoice "+invoice.Number)
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 11)
+	for _, line := range []string{
+		"Issued: " + invoice.IssuedAt.Format(dateLayout),
+		fmt.Sprintf("Parcel: #%d (%s)", invoice.ParcelID, invoice.ParcelType),
+		"From: " + invoice.SourceAddress,
+		"To: " + invoice.DestinationAddress,
+	} {
+		pdf.Cell(0, 6, line)
+		pdf.Ln(6)
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(140, 8, "Description", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 8, "Amount", "B", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, line := range invoice.Lines() {
+		description := line.Description
+		if line.Included {
+			description += " (included)"
+		}
+		pdf.CellFormat(140, 8, description, "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 8, money(line.Amount), "B", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(140, 8, "Total", "", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 8, money(invoice.Total), "", 1, "R", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+func money(amount float32) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,126 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var invoice = model.Invoice{
+	ID:                 1,
+	Number:             "INV-000001",
+	ParcelID:           1,
+	UserID:             1,
+	SourceAddress:      "Dhaka Bangladesh",
+	DestinationAddress: "Pabna Shadar",
+	ParcelType:         "Document",
+	CarrierFee:         180,
+	CompanyFee:         20,
+	Discount:           20,
+	Total:              180,
+	IssuedAt:           time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC),
+}
+
+func TestService_GetInvoice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc       string
+		mockRepo   func() *mocks.MockInvoiceRepository
+		expInvoice model.Invoice
+		expErr     error
+	}{
+		{
+			desc: "should return stored invoice",
+			mockRepo: func() *mocks.MockInvoiceRepository {
+				r := mocks.NewMockInvoiceRepository(ctrl)
+				r.EXPECT().FetchInvoiceByParcelID(gomock.Any(), 1).Return(invoice, nil)
+				return r
+			},
+			expInvoice: invoice,
+		},
+		{
+			desc: "should issue invoice on first request",
+			mockRepo: func() *mocks.MockInvoiceRepository {
+				r := mocks.NewMockInvoiceRepository(ctrl)
+				r.EXPECT().FetchInvoiceByParcelID(gomock.Any(), 1).Return(model.Invoice{}, model.ErrNotFound)
+				r.EXPECT().IssueInvoice(gomock.Any(), 1).Return(invoice, nil)
+				return r
+			},
+			expInvoice: invoice,
+		},
+		{
+			desc: "should return invalid for undelivered parcel",
+			mockRepo: func() *mocks.MockInvoiceRepository {
+				r := mocks.NewMockInvoiceRepository(ctrl)
+				r.EXPECT().FetchInvoiceByParcelID(gomock.Any(), 1).Return(model.Invoice{}, model.ErrNotFound)
+				r.EXPECT().IssueInvoice(gomock.Any(), 1).Return(model.Invoice{}, model.ErrInvalid)
+				return r
+			},
+			expInvoice: model.Invoice{},
+			expErr:     model.ErrInvalid,
+		},
+		{
+			desc: "should return db error",
+			mockRepo: func() *mocks.MockInvoiceRepository {
+				r := mocks.NewMockInvoiceRepository(ctrl)
+				r.EXPECT().FetchInvoiceByParcelID(gomock.Any(), 1).Return(model.Invoice{}, errors.New("db-error"))
+				return r
+			},
+			expInvoice: model.Invoice{},
+			expErr:     errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewService(tc.mockRepo())
+			result, err := s.GetInvoice(context.Background(), 1)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expInvoice, result)
+		})
+	}
+}
+
+func TestService_RenderHTML(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := NewService(nil).RenderHTML(&buf, invoice)
+
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Invoice INV-000001")
+	assert.Contains(t, buf.String(), "<td>Discount</td><td class=\"amount\">-20.00</td>")
+	assert.Contains(t, buf.String(), "<th>Total</th><th class=\"amount\">180.00</th>")
+}
+
+func TestService_RenderPDF(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := NewService(nil).RenderPDF(&buf, invoice)
+
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
+
+func TestService_RenderHTMLWithInclusiveTax(t *testing.T) {
+	var buf bytes.Buffer
+
+	taxed := invoice
+	taxed.Tax = 1.82
+	taxed.TaxName = "VAT"
+	taxed.TaxRate = 0.1
+	taxed.TaxInclusive = true
+
+	err := NewService(nil).RenderHTML(&buf, taxed)
+
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "<td>VAT 10% (included)</td><td class=\"amount\">1.82</td>")
+}
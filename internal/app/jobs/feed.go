@@ -0,0 +1,256 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// QUEUE_SIZE is the number of created parcels waiting to be offered, parcels are dropped when the queue is full
+	QUEUE_SIZE = 1000
+	// SUBSCRIBER_BUFFER is the number of jobs a connection can fall behind, the oldest job is dropped to make room
+	SUBSCRIBER_BUFFER = 16
+	// MAX_CONNECTIONS_PER_CARRIER is the number of job connections a carrier can open at the same time
+	MAX_CONNECTIONS_PER_CARRIER = 3
+)
+
+type feed struct {
+	parcelRepo svc.ParcelRepository
+	queue      chan model.Event
+
+	mu          sync.Mutex
+	subscribers map[*subscription]struct{}
+	connections map[int]int
+	closed      bool
+}
+
+// NewFeed initiates the feed pushing newly created parcels to the carriers subscribed near their pickup address
+func NewFeed(parcelRepo svc.ParcelRepository) *feed {
+	return &feed{
+		parcelRepo:  parcelRepo,
+		queue:       make(chan model.Event, QUEUE_SIZE),
+		subscribers: make(map[*subscription]struct{}),
+		connections: make(map[int]int),
+	}
+}
+
+// Notify queues created parcels to be offered by Run, other events are ignored
+func (f *feed) Notify(ctx context.Context, event model.Event) {
+	if event.Type != model.EventParcelCreated {
+		return
+	}
+
+	select {
+	case f.queue <- event:
+	default:
+		log.Error().Msgf("[Notify] job queue is full, dropping parcel %d", event.ParcelID)
+	}
+}
+
+// Run offers queued parcels to the matching subscribers until the context is cancelled
+func (f *feed) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-f.queue:
+			parcel, err := f.parcelRepo.FetchParcelByID(ctx, event.ParcelID)
+			if err != nil {
+				log.Error().Err(err).Msgf("[Run] failed to fetch parcel %d. Error: %v", event.ParcelID, err)
+				continue
+			}
+			if parcel.AutoDispatch {
+				// offered by the dispatcher first and published when it falls back to the open marketplace
+				continue
+			}
+			f.Publish(parcel)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Publish sends the parcel to every subscriber whose filter it matches
+func (f *feed) Publish(parcel model.Parcel) {
+	if parcel.Status != model.ParcelStatusCreated {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		sub.publish(parcel)
+	}
+}
+
+// Available returns the most recently updated filter of every connected carrier that has set one
+func (f *feed) Available() map[int]model.JobFilter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	available := make(map[int]model.JobFilter)
+	updatedAt := make(map[int]time.Time)
+	for sub := range f.subscribers {
+		sub.mu.Lock()
+		if sub.filter != nil && !sub.updatedAt.Before(updatedAt[sub.carrierID]) {
+			available[sub.carrierID] = *sub.filter
+			updatedAt[sub.carrierID] = sub.updatedAt
+		}
+		sub.mu.Unlock()
+	}
+	return available
+}
+
+// Offer sends the offer to every connection of its carrier, it returns false when none could receive it
+func (f *feed) Offer(offer model.Offer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delivered := false
+	for sub := range f.subscribers {
+		if sub.carrierID == offer.CarrierID && sub.offer(offer) {
+			delivered = true
+		}
+	}
+	return delivered
+}
+
+// Subscribe opens a job connection of the carrier, it receives nothing until its filter is set with Update
+func (f *feed) Subscribe(carrierID int) (svc.JobSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sub := &subscription{
+		feed:      f,
+		carrierID: carrierID,
+		jobs:      make(chan model.Job, SUBSCRIBER_BUFFER),
+		offers:    make(chan model.Offer, SUBSCRIBER_BUFFER),
+	}
+	if f.closed {
+		sub.closed = true
+		close(sub.jobs)
+		return sub, nil
+	}
+
+	if f.connections[carrierID] >= MAX_CONNECTIONS_PER_CARRIER {
+		return nil, fmt.Errorf("carrier %d already has %d job connections :%w", carrierID, MAX_CONNECTIONS_PER_CARRIER, model.ErrLimitExceeded)
+	}
+	f.connections[carrierID]++
+	f.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close ends every subscription, it is called when the server shuts down
+func (f *feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for sub := range f.subscribers {
+		f.remove(sub)
+	}
+}
+
+// remove must be called with the feed lock held
+func (f *feed) remove(sub *subscription) {
+	if _, ok := f.subscribers[sub]; !ok {
+		return
+	}
+	delete(f.subscribers, sub)
+	f.connections[sub.carrierID]--
+	if f.connections[sub.carrierID] == 0 {
+		delete(f.connections, sub.carrierID)
+	}
+
+	sub.mu.Lock()
+	sub.closed = true
+	close(sub.jobs)
+	sub.mu.Unlock()
+}
+
+type subscription struct {
+	feed      *feed
+	carrierID int
+	offers    chan model.Offer
+
+	mu        sync.Mutex
+	filter    *model.JobFilter
+	updatedAt time.Time
+	jobs      chan model.Job
+	closed    bool
+}
+
+// Jobs returns the matching parcels, the channel is closed when the subscription or the feed is closed
+func (s *subscription) Jobs() <-chan model.Job {
+	return s.jobs
+}
+
+// Offers returns the offers made to the carrier, it is never closed
+func (s *subscription) Offers() <-chan model.Offer {
+	return s.offers
+}
+
+// Update replaces the location, radius and capacity the carrier receives jobs for
+func (s *subscription) Update(filter model.JobFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filter = &filter
+	s.updatedAt = time.Now()
+}
+
+// Close ends the subscription and frees its connection slot
+func (s *subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.remove(s)
+}
+
+// publish sends the job without blocking the feed, when the connection has fallen behind the
+// oldest pending job is dropped to make room for the new one
+func (s *subscription) publish(parcel model.Parcel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.filter == nil {
+		return
+	}
+	job, ok := s.filter.Match(parcel)
+	if !ok {
+		return
+	}
+
+	for {
+		select {
+		case s.jobs <- job:
+			return
+		default:
+		}
+
+		select {
+		case dropped := <-s.jobs:
+			log.Warn().Msgf("[publish] carrier %d is falling behind, dropping job of parcel %d", s.carrierID, dropped.Parcel.ID)
+		default:
+		}
+	}
+}
+
+// offer sends the offer without blocking the feed, unlike jobs an offer is never dropped for a newer one
+func (s *subscription) offer(offer model.Offer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.filter == nil {
+		return false
+	}
+
+	select {
+	case s.offers <- offer:
+		return true
+	default:
+		return false
+	}
+}
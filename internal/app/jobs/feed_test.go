@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// dhaka is the location of the subscribed carrier, the parcels below are picked up around it
+var dhaka = model.JobFilter{Latitude: 23.8103, Longitude: 90.4125, Radius: 10, Capacity: 20}
+
+func nearbyParcel(id int) model.Parcel {
+	return model.Parcel{ID: id, Status: model.ParcelStatusCreated, SourceLatitude: 23.7806, SourceLongitude: 90.4193, Weight: 5}
+}
+
+func TestFeed_Publish(t *testing.T) {
+	f := NewFeed(nil)
+	sub, err := f.Subscribe(7)
+	assert.Nil(t, err)
+	defer sub.Close()
+
+	f.Publish(nearbyParcel(1))
+	assert.Len(t, sub.Jobs(), 0, "no job before the filter is set")
+
+	sub.Update(dhaka)
+	far := nearbyParcel(2)
+	far.SourceLatitude, far.SourceLongitude = 24.0064, 89.2372 // Pabna
+	heavy := nearbyParcel(3)
+	heavy.Weight = 50
+	unknown := nearbyParcel(4)
+	unknown.SourceLatitude, unknown.SourceLongitude = 0, 0
+	assigned := nearbyParcel(5)
+	assigned.Status = model.ParcelStatusAssigned
+	for _, parcel := range []model.Parcel{far, heavy, unknown, assigned, nearbyParcel(6)} {
+		f.Publish(parcel)
+	}
+
+	assert.Len(t, sub.Jobs(), 1)
+	job := <-sub.Jobs()
+	assert.Equal(t, 6, job.Parcel.ID)
+	assert.InDelta(t, 3.37, job.Distance, 0.01)
+}
+
+func TestFeed_DropsOldestJob(t *testing.T) {
+	f := NewFeed(nil)
+	sub, _ := f.Subscribe(7)
+	defer sub.Close()
+	sub.Update(dhaka)
+
+	for id := 1; id <= SUBSCRIBER_BUFFER+2; id++ {
+		f.Publish(nearbyParcel(id))
+	}
+
+	assert.Len(t, sub.Jobs(), SUBSCRIBER_BUFFER)
+	assert.Equal(t, 3, (<-sub.Jobs()).Parcel.ID)
+}
+
+func TestFeed_ConnectionLimit(t *testing.T) {
+	f := NewFeed(nil)
+
+	var subs []interface{ Close() }
+	for i := 0; i < MAX_CONNECTIONS_PER_CARRIER; i++ {
+		sub, err := f.Subscribe(7)
+		assert.Nil(t, err)
+		subs = append(subs, sub)
+	}
+
+	_, err := f.Subscribe(7)
+	assert.True(t, errors.Is(err, model.ErrLimitExceeded))
+
+	_, err = f.Subscribe(8)
+	assert.Nil(t, err, "the limit is per carrier")
+
+	subs[0].Close()
+	subs[0].Close()
+	_, err = f.Subscribe(7)
+	assert.Nil(t, err, "a closed connection frees its slot once")
+	_, err = f.Subscribe(7)
+	assert.True(t, errors.Is(err, model.ErrLimitExceeded))
+}
+
+func TestFeed_Close(t *testing.T) {
+	f := NewFeed(nil)
+	sub, _ := f.Subscribe(7)
+
+	f.Close()
+	_, ok := <-sub.Jobs()
+	assert.False(t, ok)
+	sub.Close()
+
+	late, err := f.Subscribe(7)
+	assert.Nil(t, err)
+	_, ok = <-late.Jobs()
+	assert.False(t, ok)
+}
+
+func TestFeed_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockParcelRepository(ctrl)
+	repo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{}, errors.New("db-error"))
+	repo.EXPECT().FetchParcelByID(gomock.Any(), 2).Return(nearbyParcel(2), nil)
+
+	f := NewFeed(repo)
+	sub, _ := f.Subscribe(7)
+	defer sub.Close()
+	sub.Update(dhaka)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+
+	f.Notify(ctx, model.Event{Type: model.EventParcelStatusChanged, ParcelID: 3})
+	f.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 1})
+	f.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 2})
+
+	select {
+	case job := <-sub.Jobs():
+		assert.Equal(t, 2, job.Parcel.ID)
+	case <-time.After(time.Second):
+		t.Fatal("job was not published")
+	}
+}
+
+func TestFeed_Available(t *testing.T) {
+	f := NewFeed(nil)
+	first, _ := f.Subscribe(7)
+	second, _ := f.Subscribe(7)
+	idle, _ := f.Subscribe(8)
+	defer first.Close()
+	defer second.Close()
+	defer idle.Close()
+
+	first.Update(dhaka)
+	moved := dhaka
+	moved.Latitude = 23.7806
+	time.Sleep(time.Millisecond)
+	second.Update(moved)
+
+	assert.Equal(t, map[int]model.JobFilter{7: moved}, f.Available())
+}
+
+func TestFeed_Offer(t *testing.T) {
+	f := NewFeed(nil)
+	sub, _ := f.Subscribe(7)
+	other, _ := f.Subscribe(8)
+	defer sub.Close()
+	defer other.Close()
+	offer := model.Offer{ID: 1, CarrierID: 7, Job: model.Job{Parcel: nearbyParcel(1)}}
+
+	assert.False(t, f.Offer(offer), "no offer before the filter is set")
+
+	sub.Update(dhaka)
+	other.Update(dhaka)
+	assert.True(t, f.Offer(offer))
+	assert.Equal(t, offer, <-sub.Offers())
+	assert.Len(t, other.Offers(), 0)
+
+	sub.Close()
+	assert.False(t, f.Offer(offer))
+}
+
+func TestFeed_RunSkipsAutoDispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dispatched := nearbyParcel(1)
+	dispatched.AutoDispatch = true
+	repo := mocks.NewMockParcelRepository(ctrl)
+	repo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(dispatched, nil)
+	repo.EXPECT().FetchParcelByID(gomock.Any(), 2).Return(nearbyParcel(2), nil)
+
+	f := NewFeed(repo)
+	sub, _ := f.Subscribe(7)
+	defer sub.Close()
+	sub.Update(dhaka)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+
+	f.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 1})
+	f.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 2})
+
+	select {
+	case job := <-sub.Jobs():
+		assert.Equal(t, 2, job.Parcel.ID)
+	case <-time.After(time.Second):
+		t.Fatal("job was not published")
+	}
+}
@@ -0,0 +1,161 @@
+package label
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/parcel"
+	"parcel-service/internal/app/relay"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation    = pq.ErrorCode("23505")
+	fetchLabelQuery       = `SELECT l.parcel_id, l.tracking_code, p.user_id, COALESCE(p.carrier_id, 0) AS carrier_id, p.status, p.source_address, p.destination_address, p.type, p.weight, l.created_at FROM parcel_label l JOIN parcel p ON p.id = l.parcel_id WHERE l.parcel_id = $1`
+	fetchLabelByCodeQuery = `SELECT l.parcel_id, l.tracking_code, p.user_id, COALESCE(p.carrier_id, 0) AS carrier_id, p.status, p.source_address, p.destination_address, p.type, p.weight, l.created_at FROM parcel_label l JOIN parcel p ON p.id = l.parcel_id WHERE l.tracking_code = $1`
+	insertLabelQuery      = `INSERT INTO parcel_label (parcel_id, tracking_code) SELECT id, $2 FROM parcel WHERE id = $1 ON CONFLICT (parcel_id) DO NOTHING`
+	insertScanQuery       = `INSERT INTO scan_event (parcel_id, leg_id, hub_id, role, scanner_id, previous_status, status, parcel_status, scanned_at) VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5, $6, $7, $8, $9) RETURNING id`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates label repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) FetchLabel(ctx context.Context, parcelID int) (model.Label, error) {
+	var label model.Label
+	if err := r.db.GetContext(ctx, &label, fetchLabelQuery, parcelID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Label{}, fmt.Errorf("label of parcel %d is not found :%w", parcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchLabel] failed to fetch label Error: %v", err)
+		return model.Label{}, err
+	}
+	return label, nil
+}
+
+func (r *repository) FetchLabelByCode(ctx context.Context, code string) (model.Label, error) {
+	var label model.Label
+	if err := r.db.GetContext(ctx, &label, fetchLabelByCodeQuery, code); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Label{}, fmt.Errorf("no parcel has the tracking code %s :%w", code, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchLabelByCode] failed to fetch label Error: %v", err)
+		return model.Label{}, err
+	}
+	return label, nil
+}
+
+// IssueLabel stores the tracking code of a parcel that has no label yet. When another request labelled
+// the parcel first its label is returned, so a parcel never gets two tracking codes.
+func (r *repository) IssueLabel(ctx context.Context, parcelID int, code string) (model.Label, error) {
+	result, err := r.db.ExecContext(ctx, insertLabelQuery, parcelID, code)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.Label{}, fmt.Errorf("tracking code %s is taken :%w", code, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[IssueLabel] failed to insert label Error: %v", err)
+		return model.Label{}, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Msgf("[IssueLabel] failed to read affected rows Error: %v", err)
+		return model.Label{}, err
+	}
+
+	// nothing is inserted for a parcel that does not exist or already has a label
+	label, err := r.FetchLabel(ctx, parcelID)
+	if rows == 0 && errors.Is(err, model.ErrNotFound) {
+		return model.Label{}, fmt.Errorf("parcel with the ID %d is not found. :%w", parcelID, model.ErrNotFound)
+	}
+	return label, err
+}
+
+// InsertParcelScan advances the parcel to the status of the scan and stores the scan in one transaction. The scan
+// is refused when the parcel has moved on since its label was read.
+func (r *repository) InsertParcelScan(ctx context.Context, event model.ScanEvent) (model.ScanEvent, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertParcelScan] failed to begin transaction")
+		return model.ScanEvent{}, err
+	}
+
+	update := model.Parcel{ID: event.ParcelID, Status: event.Status}
+	switch event.Status {
+	case model.ParcelStatusPickedUp:
+		update.PickedUpAt = &event.ScannedAt
+	case model.ParcelStatusDelivered:
+		update.DeliveredAt = &event.ScannedAt
+	}
+	previousStatus, err := parcel.UpdateStatus(ctx, tx, update)
+	if err != nil {
+		tx.Rollback()
+		return model.ScanEvent{}, err
+	}
+	if previousStatus != event.PreviousStatus {
+		tx.Rollback()
+		return model.ScanEvent{}, fmt.Errorf("parcel %d is %s and can not be advanced by a scan :%w", event.ParcelID, model.ParcelStatusName(previousStatus), model.ErrInvalid)
+	}
+
+	if err := insertScan(ctx, tx, &event); err != nil {
+		tx.Rollback()
+		return model.ScanEvent{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertParcelScan] failed to commit")
+		return model.ScanEvent{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return event, nil
+}
+
+// InsertLegScan advances the leg and its parcel and stores the scan in one transaction
+func (r *repository) InsertLegScan(ctx context.Context, event model.ScanEvent, update model.LegUpdate) (model.ScanEvent, model.LegChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertLegScan] failed to begin transaction")
+		return model.ScanEvent{}, model.LegChange{}, err
+	}
+
+	change, err := relay.UpdateLegStatus(ctx, tx, update, event.ScannedAt)
+	if err != nil {
+		tx.Rollback()
+		return model.ScanEvent{}, model.LegChange{}, err
+	}
+	event.Status = change.Leg.Status
+	event.ParcelStatus = change.ParcelStatus
+
+	if err := insertScan(ctx, tx, &event); err != nil {
+		tx.Rollback()
+		return model.ScanEvent{}, model.LegChange{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertLegScan] failed to commit")
+		return model.ScanEvent{}, model.LegChange{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return event, change, nil
+}
+
+func insertScan(ctx context.Context, tx *sqlx.Tx, event *model.ScanEvent) error {
+	if err := tx.QueryRowxContext(ctx, insertScanQuery, event.ParcelID, event.LegID, event.HubID, event.Role, event.ScannerID,
+		event.PreviousStatus, event.Status, event.ParcelStatus, event.ScannedAt).Scan(&event.ID); err != nil {
+		log.Error().Err(err).Msgf("[insertScan] failed to insert scan Error: %v", err)
+		return err
+	}
+	return audit.Write(ctx, tx, model.AuditCreate, model.AuditScan, event.ID, nil, *event)
+}
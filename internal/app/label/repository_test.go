@@ -0,0 +1,286 @@
+package label
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var labelColumns = []string{"parcel_id", "tracking_code", "user_id", "carrier_id", "status", "source_address", "destination_address", "type", "weight"}
+
+func TestRepository_FetchLabel(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM parcel_label l JOIN parcel p (.+) WHERE l.parcel_id = (.+)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(labelColumns).AddRow(1, "PS7K3M9Q2X4B", 3, 7, 2, "Dhaka Bangladesh", "Pabna Shadar", "Document", 1.5))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchLabel(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Label{
+			ParcelID:           1,
+			TrackingCode:       "PS7K3M9Q2X4B",
+			UserID:             3,
+			CarrierID:          7,
+			Status:             model.ParcelStatusAssigned,
+			SourceAddress:      "Dhaka Bangladesh",
+			DestinationAddress: "Pabna Shadar",
+			ParcelType:         "Document",
+			Weight:             1.5,
+		}, result)
+	})
+
+	t.Run("should return not found error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM parcel_label (.+)").
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchLabel(context.Background(), 1)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
+
+func TestRepository_FetchLabelByCode(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery("^SELECT (.+) FROM parcel_label l JOIN parcel p (.+) WHERE l.tracking_code = (.+)").
+		WithArgs("PS7K3M9Q2X4B").
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewRepository(sqlxDB)
+	_, err := repo.FetchLabelByCode(context.Background(), "PS7K3M9Q2X4B")
+	assert.EqualError(t, err, "no parcel has the tracking code PS7K3M9Q2X4B :not found")
+}
+
+func TestRepository_IssueLabel(t *testing.T) {
+	t.Run("should return the new label", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectExec("^INSERT INTO parcel_label (.+) ON CONFLICT (.+) DO NOTHING").
+			WithArgs(1, "PS7K3M9Q2X4B").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectQuery("^SELECT (.+) FROM parcel_label (.+)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(labelColumns).AddRow(1, "PS7K3M9Q2X4B", 3, 0, 1, "Dhaka Bangladesh", "Pabna Shadar", "Document", 0))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.IssueLabel(context.Background(), 1, "PS7K3M9Q2X4B")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "PS7K3M9Q2X4B", result.TrackingCode)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return the label issued by another request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectExec("^INSERT INTO parcel_label (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery("^SELECT (.+) FROM parcel_label (.+)").
+			WillReturnRows(sqlmock.NewRows(labelColumns).AddRow(1, "PSAB12CD34EF", 3, 0, 1, "Dhaka Bangladesh", "Pabna Shadar", "Document", 0))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.IssueLabel(context.Background(), 1, "PS7K3M9Q2X4B")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "PSAB12CD34EF", result.TrackingCode)
+	})
+
+	t.Run("should return parcel not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectExec("^INSERT INTO parcel_label (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectQuery("^SELECT (.+) FROM parcel_label (.+)").
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.IssueLabel(context.Background(), 1, "PS7K3M9Q2X4B")
+		assert.EqualError(t, err, "parcel with the ID 1 is not found. :not found")
+	})
+}
+
+func TestRepository_InsertParcelScan(t *testing.T) {
+	scannedAt := time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC)
+	event := model.ScanEvent{
+		ParcelID:       1,
+		Role:           model.RoleCarrier,
+		ScannerID:      7,
+		PreviousStatus: model.ParcelStatusAssigned,
+		Status:         model.ParcelStatusPickedUp,
+		ParcelStatus:   model.ParcelStatusPickedUp,
+		ScannedAt:      scannedAt,
+	}
+
+	t.Run("should advance the parcel and store the scan in one transaction", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WithArgs(model.ParcelStatusPickedUp, 1, &scannedAt, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("^INSERT INTO scan_event (.+) RETURNING id").
+			WithArgs(1, 0, 0, model.RoleCarrier, 7, model.ParcelStatusAssigned, model.ParcelStatusPickedUp, model.ParcelStatusPickedUp, scannedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditScan, 6, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertParcelScan(context.Background(), event)
+
+		assert.Nil(t, err)
+		expected := event
+		expected.ID = 6
+		assert.Equal(t, expected, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not advance a parcel that moved on since the label was read", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusPickedUp))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcelScan(context.Background(), event)
+		assert.EqualError(t, err, "parcel 1 is picked up and can not be advanced by a scan :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back the status change when the scan can not be stored", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("^INSERT INTO scan_event (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcelScan(context.Background(), event)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_InsertLegScan(t *testing.T) {
+	scannedAt := time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC)
+	legFields := []string{"id", "parcel_id", "sequence", "from_hub_id", "to_hub_id", "from_address", "to_address", "carrier_id", "status", "assigned_at", "picked_up_at", "delivered_at", "created_at"}
+
+	t.Run("should drop the leg off at the hub and store the scan in one transaction", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusPickedUp, scannedAt, scannedAt, nil, scannedAt))
+		m.ExpectExec("UPDATE parcel_leg SET status = (.+)").
+			WithArgs(model.ParcelStatusDelivered, &scannedAt, &scannedAt, 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditLeg, 10, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusPickedUp, 7))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) ORDER BY sequence").
+			WillReturnRows(sqlmock.NewRows(legFields).
+				AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusPickedUp, scannedAt, scannedAt, nil, scannedAt).
+				AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 0, model.ParcelStatusCreated, nil, nil, nil, scannedAt))
+		m.ExpectExec("UPDATE parcel SET status = (.+), carrier_id = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("^INSERT INTO scan_event (.+) RETURNING id").
+			WithArgs(1, 10, 2, model.RoleHub, 2, model.ParcelStatusPickedUp, model.ParcelStatusDelivered, sqlmock.AnyArg(), scannedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditScan, 6, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		event := model.ScanEvent{ParcelID: 1, LegID: 10, HubID: 2, Role: model.RoleHub, ScannerID: 2, PreviousStatus: model.ParcelStatusPickedUp, ScannedAt: scannedAt}
+		result, change, err := repo.InsertLegScan(context.Background(), event, model.LegUpdate{LegID: 10, CarrierID: 7, Status: model.ParcelStatusDelivered})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 6, result.ID)
+		assert.Equal(t, model.ParcelStatusDelivered, result.Status)
+		assert.Equal(t, change.ParcelStatus, result.ParcelStatus)
+		assert.Equal(t, model.ParcelStatusDelivered, change.Leg.Status)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should store nothing when the leg can not be advanced", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusDelivered, scannedAt, scannedAt, scannedAt, scannedAt))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, _, err := repo.InsertLegScan(context.Background(), model.ScanEvent{ParcelID: 1, ScannedAt: scannedAt}, model.LegUpdate{LegID: 10, CarrierID: 7, Status: model.ParcelStatusDelivered})
+		assert.EqualError(t, err, "leg 10 is delivered and can not be delivered :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,289 @@
+package label
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const companyName = "Parcel Service"
+
+// sizes of a PNG label in pixels, 4 inches wide at 150 dpi
+const (
+	pngWidth       = 600
+	pngMargin      = 20
+	pngTextScale   = 3
+	pngLineHeight  = (glyphHeight + 3) * pngTextScale
+	pngBarHeight   = 120
+	pngModuleWidth = 3
+)
+
+// sizes of a PDF label in millimetres, a 4x6 inch page
+const (
+	pdfWidth       = 101.6
+	pdfHeight      = 152.4
+	pdfMargin      = 6
+	pdfBarHeight   = 25
+	pdfModuleWidth = 0.5
+)
+
+type service struct {
+	repo     svc.LabelRepository
+	relaySvc svc.RelayService
+	notifier svc.EventNotifier
+}
+
+func NewService(repo svc.LabelRepository, relaySvc svc.RelayService, notifier svc.EventNotifier) *service {
+	return &service{
+		repo:     repo,
+		relaySvc: relaySvc,
+		notifier: notifier,
+	}
+}
+
+// GetLabel returns the label of the parcel, giving the parcel its tracking code on the first request
+func (s *service) GetLabel(ctx context.Context, parcelID int) (model.Label, error) {
+	label, err := s.repo.FetchLabel(ctx, parcelID)
+	if err == nil || !errors.Is(err, model.ErrNotFound) {
+		return label, err
+	}
+
+	code, err := newTrackingCode()
+	if err != nil {
+		return model.Label{}, err
+	}
+	return s.repo.IssueLabel(ctx, parcelID, code)
+}
+
+// Scan records a carrier or a hub scanning the label of a parcel and advances the parcel, or the current leg
+// of a relayed parcel, to its next status. A carrier scan picks up or drops off what the carrier is carrying,
+// a hub scan takes in a leg arriving at the hub or hands the parcel over to the carrier of the leg leaving it.
+// The status change and the scan are stored together, the events are sent once both are.
+func (s *service) Scan(ctx context.Context, scan model.Scan) (model.ScanEvent, error) {
+	label, err := s.repo.FetchLabelByCode(ctx, scan.Code)
+	if err != nil {
+		return model.ScanEvent{}, err
+	}
+
+	event := model.ScanEvent{
+		ParcelID:  label.ParcelID,
+		Role:      scan.Role,
+		ScannerID: scan.ScannerID,
+		ScannedAt: time.Now(),
+	}
+
+	relay, err := s.relaySvc.GetRelay(ctx, label.ParcelID)
+	switch {
+	case err == nil:
+		return s.scanLeg(ctx, scan, relay, event)
+	case errors.Is(err, model.ErrNotFound):
+		return s.scanParcel(ctx, scan, label, event)
+	}
+	return model.ScanEvent{}, err
+}
+
+func (s *service) scanParcel(ctx context.Context, scan model.Scan, label model.Label, event model.ScanEvent) (model.ScanEvent, error) {
+	if scan.Role != model.RoleCarrier {
+		return model.ScanEvent{}, fmt.Errorf("parcel %d is not relayed through hub %d :%w", label.ParcelID, scan.ScannerID, model.ErrInvalid)
+	}
+	if label.CarrierID != scan.ScannerID {
+		return model.ScanEvent{}, fmt.Errorf("carrier %d is not carrying parcel %d :%w", scan.ScannerID, label.ParcelID, model.ErrForbidden)
+	}
+	if label.Status != model.ParcelStatusAssigned && label.Status != model.ParcelStatusPickedUp {
+		return model.ScanEvent{}, fmt.Errorf("parcel %d is %s and can not be advanced by a scan :%w", label.ParcelID, model.ParcelStatusName(label.Status), model.ErrInvalid)
+	}
+
+	event.PreviousStatus = label.Status
+	event.Status = label.Status + 1
+	event.ParcelStatus = event.Status
+	event, err := s.repo.InsertParcelScan(ctx, event)
+	if err != nil {
+		return model.ScanEvent{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventParcelStatusChanged,
+		ParcelID:  label.ParcelID,
+		UserID:    label.UserID,
+		CarrierID: label.CarrierID,
+		Status:    event.Status,
+	})
+	return event, nil
+}
+
+func (s *service) scanLeg(ctx context.Context, scan model.Scan, relay model.Relay, event model.ScanEvent) (model.ScanEvent, error) {
+	leg, ok := scannedLeg(scan, relay.Legs)
+	if !ok {
+		if scan.Role == model.RoleHub {
+			return model.ScanEvent{}, fmt.Errorf("parcel %d is not expected at hub %d :%w", relay.ParcelID, scan.ScannerID, model.ErrInvalid)
+		}
+		return model.ScanEvent{}, fmt.Errorf("carrier %d is not carrying a leg of parcel %d :%w", scan.ScannerID, relay.ParcelID, model.ErrForbidden)
+	}
+
+	event.LegID = leg.ID
+	event.HubID = leg.ToHubID
+	if leg.Status == model.ParcelStatusAssigned {
+		event.HubID = leg.FromHubID
+	}
+	event.PreviousStatus = leg.Status
+	event, change, err := s.repo.InsertLegScan(ctx, event, model.LegUpdate{LegID: leg.ID, CarrierID: leg.CarrierID, Status: leg.Status + 1})
+	if err != nil {
+		return model.ScanEvent{}, err
+	}
+
+	if change.Transfer != nil {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventCustodyTransferred,
+			ParcelID:  relay.ParcelID,
+			CarrierID: change.Transfer.ToCarrierID,
+			Leg:       change.Leg.Sequence,
+			Hub:       change.Transfer.HubName,
+		})
+	}
+	if change.Changed {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventParcelStatusChanged,
+			ParcelID:  relay.ParcelID,
+			UserID:    relay.UserID,
+			CarrierID: change.CarrierID,
+			Status:    change.ParcelStatus,
+		})
+	}
+	return event, nil
+}
+
+// scannedLeg finds the first leg in travel order the scanner can advance
+func scannedLeg(scan model.Scan, legs []model.Leg) (model.Leg, bool) {
+	for _, leg := range legs {
+		switch scan.Role {
+		case model.RoleCarrier:
+			if leg.CarrierID == scan.ScannerID && (leg.Status == model.ParcelStatusAssigned || leg.Status == model.ParcelStatusPickedUp) {
+				return leg, true
+			}
+		case model.RoleHub:
+			if leg.Status == model.ParcelStatusPickedUp && leg.ToHubID == scan.ScannerID ||
+				leg.Status == model.ParcelStatusAssigned && leg.FromHubID == scan.ScannerID {
+				return leg, true
+			}
+		}
+	}
+	return model.Leg{}, false
+}
+
+// RenderPNG draws the label with the barcode of its tracking code for thermal label printers
+func (s *service) RenderPNG(w io.Writer, label model.Label) error {
+	modules, err := code128(label.TrackingCode)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, line := range labelLines(label) {
+		lines = append(lines, wrapText(line, (pngWidth-2*pngMargin)/(glyphAdvance*pngTextScale))...)
+	}
+
+	height := 2*pngMargin + (len(lines)+2)*pngLineHeight + pngBarHeight
+	img := image.NewGray(image.Rect(0, 0, pngWidth, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	y := pngMargin
+	for _, line := range lines {
+		drawText(img, pngMargin, y, pngTextScale, line)
+		y += pngLineHeight
+	}
+
+	y += pngLineHeight / 2
+	x := (pngWidth - len(modules)*pngModuleWidth) / 2
+	for i, bar := range modules {
+		if bar {
+			fill(img, x+i*pngModuleWidth, y, pngModuleWidth, pngBarHeight)
+		}
+	}
+
+	y += pngBarHeight + pngTextScale*2
+	drawText(img, (pngWidth-len(label.TrackingCode)*glyphAdvance*pngTextScale)/2, y, pngTextScale, label.TrackingCode)
+
+	return png.Encode(w, img)
+}
+
+// RenderPDF lays the label out on a 4x6 inch page with the barcode drawn as vector bars
+func (s *service) RenderPDF(w io.Writer, label model.Label) error {
+	modules, err := code128(label.TrackingCode)
+	if err != nil {
+		return err
+	}
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: pdfWidth, Ht: pdfHeight},
+	})
+	pdf.SetTitle("Label "+label.TrackingCode, true)
+	pdf.SetCreationDate(label.CreatedAt)
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AddPage()
+
+	lines := labelLines(label)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 8, lines[0])
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "", 12)
+	for _, line := range lines[1:] {
+		pdf.MultiCell(0, 6, line, "", "L", false)
+	}
+	pdf.Ln(6)
+
+	y := pdf.GetY()
+	x := (pdfWidth - float64(len(modules))*pdfModuleWidth) / 2
+	for i, bar := range modules {
+		if bar {
+			pdf.Rect(x+float64(i)*pdfModuleWidth, y, pdfModuleWidth, pdfBarHeight, "F")
+		}
+	}
+	pdf.SetY(y + pdfBarHeight + 2)
+	pdf.SetFont("Courier", "B", 14)
+	pdf.CellFormat(0, 7, label.TrackingCode, "", 1, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// labelLines returns the company name followed by the parcel, its sender and its recipient
+func labelLines(label model.Label) []string {
+	parcel := fmt.Sprintf("Parcel #%d (%s)", label.ParcelID, label.ParcelType)
+	if label.Weight > 0 {
+		parcel += fmt.Sprintf(" %.1f kg", label.Weight)
+	}
+	return []string{
+		companyName,
+		parcel,
+		fmt.Sprintf("From: User #%d", label.UserID),
+		label.SourceAddress,
+		"To:",
+		label.DestinationAddress,
+	}
+}
+
+// newTrackingCode draws the characters of a tracking code from crypto/rand
+func newTrackingCode() (string, error) {
+	random := make([]byte, model.TrackingCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate tracking code: %v :%w", err, model.IntServerErr)
+	}
+
+	code := []byte(model.TrackingCodePrefix)
+	for _, b := range random {
+		// the alphabet has 32 characters so every byte maps onto it evenly
+		code = append(code, model.TrackingCodeAlphabet[int(b)%len(model.TrackingCodeAlphabet)])
+	}
+	return string(code), nil
+}
@@ -0,0 +1,262 @@
+package label
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var label = model.Label{
+	ParcelID:           1,
+	TrackingCode:       "PS7K3M9Q2X4B",
+	UserID:             3,
+	CarrierID:          7,
+	Status:             model.ParcelStatusAssigned,
+	SourceAddress:      "House 12, Road 5, Dhanmondi, Dhaka 1205",
+	DestinationAddress: "Pabna Shadar",
+	ParcelType:         "Document",
+	Weight:             1.5,
+	CreatedAt:          time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC),
+}
+
+func TestService_GetLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should return stored label", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabel(gomock.Any(), 1).Return(label, nil)
+
+		s := NewService(repo, mocks.NewMockRelayService(ctrl), mocks.NewMockEventNotifier(ctrl))
+		result, err := s.GetLabel(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, label, result)
+	})
+
+	t.Run("should issue label with a new tracking code", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabel(gomock.Any(), 1).Return(model.Label{}, model.ErrNotFound)
+		repo.EXPECT().IssueLabel(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, code string) (model.Label, error) {
+			assert.True(t, model.ValidTrackingCode(code))
+			return model.Label{ParcelID: 1, TrackingCode: code}, nil
+		})
+
+		s := NewService(repo, mocks.NewMockRelayService(ctrl), mocks.NewMockEventNotifier(ctrl))
+		_, err := s.GetLabel(context.Background(), 1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabel(gomock.Any(), 1).Return(model.Label{}, errors.New("sql-error"))
+
+		s := NewService(repo, mocks.NewMockRelayService(ctrl), mocks.NewMockEventNotifier(ctrl))
+		_, err := s.GetLabel(context.Background(), 1)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestService_Scan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	relay := model.Relay{
+		ParcelID: 1,
+		UserID:   3,
+		Legs: []model.Leg{
+			{ID: 10, ParcelID: 1, Sequence: 1, ToHubID: 2, CarrierID: 7, Status: model.ParcelStatusPickedUp},
+			{ID: 11, ParcelID: 1, Sequence: 2, FromHubID: 2, CarrierID: 8, Status: model.ParcelStatusAssigned},
+		},
+	}
+
+	t.Run("should pick up parcel scanned by its carrier", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		repo.EXPECT().InsertParcelScan(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event model.ScanEvent) (model.ScanEvent, error) {
+			event.ID = 5
+			return event, nil
+		})
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(model.Relay{}, model.ErrNotFound)
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{
+			Type:      model.EventParcelStatusChanged,
+			ParcelID:  1,
+			UserID:    3,
+			CarrierID: 7,
+			Status:    model.ParcelStatusPickedUp,
+		})
+
+		s := NewService(repo, relaySvc, notifier)
+		event, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 7})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, event.ID)
+		assert.Equal(t, model.ParcelStatusAssigned, event.PreviousStatus)
+		assert.Equal(t, model.ParcelStatusPickedUp, event.Status)
+		assert.Equal(t, model.ParcelStatusPickedUp, event.ParcelStatus)
+	})
+
+	t.Run("should not notify when the scan is not stored", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		repo.EXPECT().InsertParcelScan(gomock.Any(), gomock.Any()).Return(model.ScanEvent{}, errors.New("sql-error"))
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(model.Relay{}, model.ErrNotFound)
+
+		s := NewService(repo, relaySvc, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 7})
+		assert.EqualError(t, err, "sql-error")
+	})
+
+	t.Run("should return forbidden for another carrier", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(model.Relay{}, model.ErrNotFound)
+
+		s := NewService(repo, relaySvc, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 8})
+		assert.EqualError(t, err, "carrier 8 is not carrying parcel 1 :forbidden")
+	})
+
+	t.Run("should not advance a delivered parcel", func(t *testing.T) {
+		delivered := label
+		delivered.Status = model.ParcelStatusDelivered
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(delivered, nil)
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(model.Relay{}, model.ErrNotFound)
+
+		s := NewService(repo, relaySvc, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 7})
+		assert.EqualError(t, err, "parcel 1 is delivered and can not be advanced by a scan :invalid")
+	})
+
+	t.Run("should drop off the leg arriving at the hub", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		repo.EXPECT().InsertLegScan(gomock.Any(), gomock.Any(), model.LegUpdate{LegID: 10, CarrierID: 7, Status: model.ParcelStatusDelivered}).
+			DoAndReturn(func(_ context.Context, event model.ScanEvent, _ model.LegUpdate) (model.ScanEvent, model.LegChange, error) {
+				event.Status = model.ParcelStatusDelivered
+				event.ParcelStatus = model.ParcelStatusPickedUp
+				return event, model.LegChange{Leg: model.Leg{ID: 10, Status: model.ParcelStatusDelivered}, ParcelStatus: model.ParcelStatusPickedUp}, nil
+			})
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(relay, nil)
+
+		s := NewService(repo, relaySvc, mocks.NewMockEventNotifier(ctrl))
+		event, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleHub, ScannerID: 2})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, event.LegID)
+		assert.Equal(t, 2, event.HubID)
+		assert.Equal(t, model.ParcelStatusDelivered, event.Status)
+		assert.Equal(t, model.ParcelStatusPickedUp, event.ParcelStatus)
+	})
+
+	t.Run("should pick up the leg carried by the scanning carrier", func(t *testing.T) {
+		arrived := relay
+		arrived.Legs = []model.Leg{relay.Legs[0], relay.Legs[1]}
+		arrived.Legs[0].Status = model.ParcelStatusDelivered
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		repo.EXPECT().InsertLegScan(gomock.Any(), gomock.Any(), model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusPickedUp}).
+			DoAndReturn(func(_ context.Context, event model.ScanEvent, _ model.LegUpdate) (model.ScanEvent, model.LegChange, error) {
+				return event, model.LegChange{
+					Leg:          model.Leg{ID: 11, Sequence: 2, Status: model.ParcelStatusPickedUp},
+					ParcelStatus: model.ParcelStatusPickedUp,
+					CarrierID:    8,
+					Changed:      true,
+					Transfer:     &model.CustodyTransfer{HubName: "Bogura", ToCarrierID: 8},
+				}, nil
+			})
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(arrived, nil)
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCustodyTransferred, ParcelID: 1, CarrierID: 8, Leg: 2, Hub: "Bogura"})
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, UserID: 3, CarrierID: 8, Status: model.ParcelStatusPickedUp})
+
+		s := NewService(repo, relaySvc, notifier)
+		event, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 8})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 11, event.LegID)
+		assert.Equal(t, 2, event.HubID)
+	})
+
+	t.Run("should return parcel not expected at the hub", func(t *testing.T) {
+		repo := mocks.NewMockLabelRepository(ctrl)
+		repo.EXPECT().FetchLabelByCode(gomock.Any(), "PS7K3M9Q2X4B").Return(label, nil)
+		relaySvc := mocks.NewMockRelayService(ctrl)
+		relaySvc.EXPECT().GetRelay(gomock.Any(), 1).Return(relay, nil)
+
+		s := NewService(repo, relaySvc, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.Scan(context.Background(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleHub, ScannerID: 3})
+		assert.EqualError(t, err, "parcel 1 is not expected at hub 3 :invalid")
+	})
+}
+
+func TestService_RenderPNG(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewService(nil, nil, nil).RenderPNG(&buf, label)
+	assert.Nil(t, err)
+
+	img, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, pngWidth, img.Bounds().Dx())
+}
+
+func TestService_RenderPDF(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewService(nil, nil, nil).RenderPDF(&buf, label)
+
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
+
+func TestCode128(t *testing.T) {
+	t.Run("should have eleven modules in every symbol", func(t *testing.T) {
+		seen := map[string]bool{}
+		for value, pattern := range code128Patterns {
+			width := 0
+			for _, c := range pattern {
+				width += int(c - '0')
+			}
+			if value == code128Stop {
+				assert.Equal(t, 13, width)
+			} else {
+				assert.Equal(t, 11, width, "symbol %d", value)
+			}
+			assert.False(t, seen[pattern], "symbol %d", value)
+			seen[pattern] = true
+		}
+	})
+
+	t.Run("should encode the start, data, checksum and stop symbols", func(t *testing.T) {
+		modules, err := code128("PJJ123C")
+
+		assert.Nil(t, err)
+		assert.Len(t, modules, 10*11+2)
+		// the checksum of PJJ123C is 879 mod 103
+		checksum := modules[8*11 : 9*11]
+		for i, width := range code128Patterns[55] {
+			assert.Equal(t, i%2 == 0, checksum[0], "element %d", i)
+			checksum = checksum[int(width-'0'):]
+		}
+	})
+
+	t.Run("should refuse characters outside code set B", func(t *testing.T) {
+		_, err := code128("PSঢ")
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+	})
+}
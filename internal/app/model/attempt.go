@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reasons a delivery can fail
+const (
+	ReasonRecipientAbsent = "recipient_absent"
+	ReasonAddressNotFound = "address_not_found"
+	ReasonRefused         = "refused"
+	ReasonOther           = "other"
+)
+
+// MaxAttemptNote is the longest note a carrier can leave on a failed delivery
+const MaxAttemptNote = 500
+
+// DeliveryAttempt is a failed attempt of the carrier to deliver a picked up parcel, the return parcel is set on
+// the attempt that sent the parcel back to its sender
+type DeliveryAttempt struct {
+	ID             int       `json:"id"`
+	ParcelID       int       `json:"parcel_id" db:"parcel_id"`
+	CarrierID      int       `json:"carrier_id" db:"carrier_id"`
+	Attempt        int       `json:"attempt"`
+	Reason         string    `json:"reason"`
+	Note           string    `json:"note,omitempty"`
+	ReturnParcelID int       `json:"return_parcel_id,omitempty" db:"-"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// ValidateDeliveryAttemptInput validates failed delivery input given by carrier
+func (a *DeliveryAttempt) ValidateDeliveryAttemptInput() error {
+	switch a.Reason {
+	case ReasonRecipientAbsent, ReasonAddressNotFound, ReasonRefused:
+	case ReasonOther:
+		if a.Note == "" {
+			return fmt.Errorf("note is required when the reason is other :%w", ErrEmpty)
+		}
+	case "":
+		return fmt.Errorf("reason is required :%w", ErrEmpty)
+	default:
+		return fmt.Errorf("reason must be recipient_absent, address_not_found, refused or other :%w", ErrInvalid)
+	}
+
+	if len(a.Note) > MaxAttemptNote {
+		return fmt.Errorf("note can not be longer than %d characters :%w", MaxAttemptNote, ErrInvalid)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditAccept = "accept"
+	AuditCancel = "cancel"
+)
+
+// Audited entities, a carrier request is audited under the ID of its parcel and a leg request under the ID of its leg
+const (
+	AuditParcel              = "parcel"
+	AuditCarrierRequest      = "carrier_request"
+	AuditDeliveryAttempt     = "delivery_attempt"
+	AuditClaim               = "claim"
+	AuditCarrier             = "carrier"
+	AuditShipment            = "shipment"
+	AuditHub                 = "hub"
+	AuditLeg                 = "leg"
+	AuditLegRequest          = "leg_request"
+	AuditScan                = "scan"
+	AuditReview              = "review"
+	AuditPromotion           = "promotion"
+	AuditTaxRule             = "tax_rule"
+	AuditWebhookSubscription = "webhook_subscription"
+	AuditWebhookDelivery     = "webhook_delivery"
+	AuditInvoice             = "invoice"
+	AuditCarrierLocation     = "carrier_location"
+)
+
+// RoleSystem is the role of the changes made without a request, like the command line import
+const RoleSystem = "system"
+
+// Page sizes of the audit log
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+// AuditActor is who made a request and from where, it travels in the request context down to the repositories
+type AuditActor struct {
+	ID        int
+	Role      string
+	RequestID string
+	IP        string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context carrying the actor of the request
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor of the request, changes made outside of a request are made by the system
+func AuditActorFrom(ctx context.Context) AuditActor {
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+		return actor
+	}
+	return AuditActor{Role: RoleSystem}
+}
+
+// AuditEntry records one change of an entity. Changes holds the value before and after of every changed field.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	ActorID   int             `json:"actor_id" db:"actor_id"`
+	Role      string          `json:"role" db:"role"`
+	Action    string          `json:"action" db:"action"`
+	Entity    string          `json:"entity" db:"entity"`
+	EntityID  int             `json:"entity_id" db:"entity_id"`
+	Changes   json.RawMessage `json:"changes" db:"changes"`
+	RequestID string          `json:"request_id" db:"request_id"`
+	IP        string          `json:"ip" db:"ip"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditChange is the value of a field before and after a change, nil when the field did not exist
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges compares the JSON fields of before and after and returns the fields that differ.
+// A nil before or after is an entity that did not exist, so every field of the other one is a change.
+func AuditChanges(before interface{}, after interface{}) (json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]AuditChange{}
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changes[field] = AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok && value != nil {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("audited value must be a JSON object: %v :%w", err, ErrInvalid)
+	}
+	return fields, nil
+}
+
+// AuditFilter selects audit entries, zero values match every entry
+type AuditFilter struct {
+	Entity   string
+	EntityID int
+	ActorID  int
+	Role     string
+	Limit    int
+	Offset   int
+}
+
+// ValidateAuditFilter checks the filter and defaults the limit to DefaultAuditLimit
+func (f *AuditFilter) ValidateAuditFilter() error {
+	if f.EntityID != 0 && f.Entity == "" {
+		return fmt.Errorf("entity is required with an entity ID :%w", ErrEmpty)
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultAuditLimit
+	}
+	if f.Limit < 0 || f.Limit > MaxAuditLimit {
+		return fmt.Errorf("limit must be between 1 and %d :%w", MaxAuditLimit, ErrInvalid)
+	}
+	if f.Offset < 0 {
+		return fmt.Errorf("offset must not be negative :%w", ErrInvalid)
+	}
+	return nil
+}
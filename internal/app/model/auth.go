@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Roles an access token can be issued for
+const (
+	RoleUser    = "user"
+	RoleCarrier = "carrier"
+	RoleAdmin   = "admin"
+	RoleHub     = "hub"
+)
+
+// Claims identify the holder of an access token
+type Claims struct {
+	Role      string `json:"role"`
+	ID        int    `json:"id"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// ValidateClaims validates the claims of a token before it is issued
+func (c *Claims) ValidateClaims() error {
+	if c.Role != RoleUser && c.Role != RoleCarrier && c.Role != RoleAdmin && c.Role != RoleHub {
+		return fmt.Errorf("role must be one of user, carrier, hub or admin :%w", ErrInvalid)
+	}
+
+	if c.Role != RoleAdmin && c.ID <= 0 {
+		return fmt.Errorf("ID is required :%w", ErrEmpty)
+	}
+
+	if c.ExpiresAt == 0 {
+		return fmt.Errorf("expiry is required :%w", ErrEmpty)
+	}
+
+	return nil
+}
+
+// Expired reports whether the token has expired at the given time
+func (c *Claims) Expired(now time.Time) bool {
+	return now.Unix() >= c.ExpiresAt
+}
+
+// Allows reports whether the holder may act as the carrier or user with the given ID, admins may act as anyone
+func (c *Claims) Allows(role string, id int) bool {
+	if c.Role == RoleAdmin {
+		return true
+	}
+	return c.Role == role && c.ID == id
+}
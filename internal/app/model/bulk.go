@@ -0,0 +1,181 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxBulkParcels is the most parcels one bulk import can hold
+	MaxBulkParcels = 1000
+	// BulkBatchSize is the number of parcels of a bulk import stored in one transaction
+	BulkBatchSize = 100
+)
+
+// BulkRow is one parcel of a bulk import, numbered from 1 without the CSV header. Err holds the reason a CSV row could not be read.
+type BulkRow struct {
+	Row    int
+	Parcel Parcel
+	Err    error
+}
+
+// BulkCreated is a row of a bulk import stored as a parcel
+type BulkCreated struct {
+	Row      int `json:"row"`
+	ParcelID int `json:"parcel_id"`
+}
+
+// BulkRowError is a row of a bulk import that was not stored
+type BulkRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// BulkReport lists the created parcels and the errors of a bulk import by row
+type BulkReport struct {
+	Total   int            `json:"total"`
+	Created []BulkCreated  `json:"created"`
+	Errors  []BulkRowError `json:"errors"`
+}
+
+// BulkRows numbers the parcels of a JSON bulk import
+func BulkRows(parcels []Parcel) []BulkRow {
+	rows := make([]BulkRow, len(parcels))
+	for i, parcel := range parcels {
+		rows[i] = BulkRow{Row: i + 1, Parcel: parcel}
+	}
+	return rows
+}
+
+// ParseParcelCSV reads a CSV bulk import. The header names the columns with the JSON names of the parcel fields,
+// times are RFC 3339. A row with a bad value is returned with its error, a bad header fails the whole file.
+func ParseParcelCSV(r io.Reader) ([]BulkRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV header is required :%w", ErrEmpty)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v :%w", err, ErrInvalid)
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumns[header[i]]; !ok {
+			return nil, fmt.Errorf("unknown CSV column %q :%w", column, ErrInvalid)
+		}
+	}
+
+	rows := []BulkRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		row := BulkRow{Row: len(rows) + 1}
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return nil, fmt.Errorf("failed to read CSV row %d: %v :%w", row.Row, err, ErrInvalid)
+			}
+			row.Err = fmt.Errorf("row has %d columns instead of %d :%w", len(record), len(header), ErrInvalid)
+		} else {
+			row.Err = row.Parcel.setCSVColumns(header, record)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func (p *Parcel) setCSVColumns(header, record []string) error {
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if err := csvColumns[header[i]](p, value); err != nil {
+			return fmt.Errorf("column %s: %v :%w", header[i], err, ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// csvColumns sets the parcel field of each column a CSV bulk import can have
+var csvColumns = map[string]func(p *Parcel, value string) error{
+	"user_id":               func(p *Parcel, v string) error { return parseInt(v, &p.UserID) },
+	"source_address":        func(p *Parcel, v string) error { p.SourceAddress = v; return nil },
+	"destination_address":   func(p *Parcel, v string) error { p.DestinationAddress = v; return nil },
+	"type":                  func(p *Parcel, v string) error { p.ParcelType = v; return nil },
+	"source_time":           func(p *Parcel, v string) error { return parseTime(v, &p.SourceTime) },
+	"source_latitude":       func(p *Parcel, v string) error { return parseFloat(v, &p.SourceLatitude) },
+	"source_longitude":      func(p *Parcel, v string) error { return parseFloat(v, &p.SourceLongitude) },
+	"destination_latitude":  func(p *Parcel, v string) error { return parseFloat(v, &p.DestLatitude) },
+	"destination_longitude": func(p *Parcel, v string) error { return parseFloat(v, &p.DestLongitude) },
+	"weight":                func(p *Parcel, v string) error { return parseFloat32(v, &p.Weight) },
+	"declared_value":        func(p *Parcel, v string) error { return parseFloat32(v, &p.DeclaredValue) },
+	"auto_dispatch":         func(p *Parcel, v string) error { return parseBool(v, &p.AutoDispatch) },
+	"pickup_window_start":   func(p *Parcel, v string) error { return parseTimePtr(v, &p.PickupStart) },
+	"pickup_window_end":     func(p *Parcel, v string) error { return parseTimePtr(v, &p.PickupEnd) },
+	"delivery_window_start": func(p *Parcel, v string) error { return parseTimePtr(v, &p.DeliveryStart) },
+	"delivery_window_end":   func(p *Parcel, v string) error { return parseTimePtr(v, &p.DeliveryEnd) },
+	"promo_code":            func(p *Parcel, v string) error { p.PromoCode = v; return nil },
+}
+
+func parseInt(value string, dst *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("not a whole number")
+	}
+	*dst = n
+	return nil
+}
+
+func parseFloat(value string, dst *float64) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.New("not a number")
+	}
+	*dst = f
+	return nil
+}
+
+func parseFloat32(value string, dst *float32) error {
+	f, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return errors.New("not a number")
+	}
+	*dst = float32(f)
+	return nil
+}
+
+func parseBool(value string, dst *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return errors.New("not true or false")
+	}
+	*dst = b
+	return nil
+}
+
+func parseTime(value string, dst *time.Time) error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return errors.New("not an RFC 3339 time")
+	}
+	*dst = t
+	return nil
+}
+
+func parseTimePtr(value string, dst **time.Time) error {
+	var t time.Time
+	if err := parseTime(value, &t); err != nil {
+		return err
+	}
+	*dst = &t
+	return nil
+}
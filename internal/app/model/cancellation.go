@@ -0,0 +1,25 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type Cancellation struct {
+	ParcelID            int       `json:"parcel_id" db:"parcel_id"`
+	PreviousStatus      int       `json:"previous_status" db:"previous_status"`
+	Reason              string    `json:"reason"`
+	Fee                 float32   `json:"fee"`
+	Refund              float32   `json:"refund"`
+	CarrierID           int       `json:"carrier_id,omitempty" db:"-"`
+	CarrierCompensation float32   `json:"carrier_compensation" db:"carrier_compensation"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+}
+
+// ValidateCancellationInput validates cancellation input given by user
+func (c *Cancellation) ValidateCancellationInput() error {
+	if c.Reason == "" {
+		return fmt.Errorf("cancellation reason is required :%w", ErrEmpty)
+	}
+	return nil
+}
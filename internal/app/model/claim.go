@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Claim status values
+const (
+	ClaimOpen          = "open"
+	ClaimInvestigating = "investigating"
+	ClaimApproved      = "approved"
+	ClaimRejected      = "rejected"
+)
+
+// MaxClaimEvidence is the most evidence links a claim can have
+const MaxClaimEvidence = 10
+
+// claimTransitions lists the statuses a claim can move to from each status, approved and rejected are final
+var claimTransitions = map[string][]string{
+	ClaimOpen:          {ClaimInvestigating, ClaimApproved, ClaimRejected},
+	ClaimInvestigating: {ClaimApproved, ClaimRejected},
+}
+
+// Claim is opened by the sender of a parcel that arrived damaged or never arrived and decided by an admin,
+// an approved claim is compensated up to the declared value of the parcel
+type Claim struct {
+	ID           int            `json:"id"`
+	ParcelID     int            `json:"parcel_id" db:"parcel_id"`
+	UserID       int            `json:"user_id" db:"user_id"`
+	Status       string         `json:"status"`
+	Description  string         `json:"description"`
+	Evidence     pq.StringArray `json:"evidence" db:"evidence"`
+	Compensation float32        `json:"compensation" db:"compensation"`
+	Note         string         `json:"note,omitempty" db:"note"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	DecidedAt    *time.Time     `json:"decided_at,omitempty" db:"decided_at"`
+}
+
+// ClaimDecision is the status an admin moves a claim to, with the compensation of an approved claim
+type ClaimDecision struct {
+	ClaimID      int     `json:"-"`
+	Status       string  `json:"status"`
+	Compensation float32 `json:"compensation"`
+	Note         string  `json:"note"`
+}
+
+// ValidateClaimInput validates claim input given by user
+func (c *Claim) ValidateClaimInput() error {
+	if c.Description == "" {
+		return fmt.Errorf("description is required :%w", ErrEmpty)
+	}
+
+	if len(c.Evidence) > MaxClaimEvidence {
+		return fmt.Errorf("at most %d evidence links can be attached :%w", MaxClaimEvidence, ErrInvalid)
+	}
+
+	for _, link := range c.Evidence {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("evidence must be absolute http or https URLs :%w", ErrInvalid)
+		}
+	}
+
+	return nil
+}
+
+// ValidateClaimDecision validates the decision of an admin on a claim
+func (d *ClaimDecision) ValidateClaimDecision() error {
+	if d.Status != ClaimInvestigating && d.Status != ClaimApproved && d.Status != ClaimRejected {
+		return fmt.Errorf("status must be investigating, approved or rejected :%w", ErrInvalid)
+	}
+
+	if d.Compensation < 0 {
+		return fmt.Errorf("compensation can not be negative :%w", ErrInvalid)
+	}
+
+	if d.Compensation > 0 && d.Status != ClaimApproved {
+		return fmt.Errorf("only approved claims are compensated :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+// CanMoveTo reports whether the claim can move from its status to the given status
+func (c *Claim) CanMoveTo(status string) bool {
+	for _, next := range claimTransitions[c.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateClaimStatus accepts an empty status, which means every status
+func ValidateClaimStatus(status string) error {
+	switch status {
+	case "", ClaimOpen, ClaimInvestigating, ClaimApproved, ClaimRejected:
+		return nil
+	}
+	return fmt.Errorf("status must be open, investigating, approved or rejected :%w", ErrInvalid)
+}
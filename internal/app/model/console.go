@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Page sizes of the admin console lists
+const (
+	DefaultConsolePageSize = 50
+	MaxConsolePageSize     = 200
+)
+
+// ParcelSearch selects the parcels listed in the admin console, zero values match every parcel.
+// Query matches the source or destination address or the tracking code.
+type ParcelSearch struct {
+	ParcelID  int
+	UserID    int
+	CarrierID int
+	Status    int
+	Query     string
+	Limit     int
+	Offset    int
+}
+
+// ValidateParcelSearch checks the search and defaults the limit to DefaultConsolePageSize
+func (s *ParcelSearch) ValidateParcelSearch() error {
+	s.Query = strings.TrimSpace(s.Query)
+
+	if s.Status != 0 && ParcelStatusName(s.Status) == "unknown" {
+		return fmt.Errorf("status %d is unknown :%w", s.Status, ErrInvalid)
+	}
+
+	if s.Limit == 0 {
+		s.Limit = DefaultConsolePageSize
+	}
+	if s.Limit < 0 || s.Limit > MaxConsolePageSize {
+		return fmt.Errorf("limit must be between 1 and %d :%w", MaxConsolePageSize, ErrInvalid)
+	}
+	if s.Offset < 0 {
+		return fmt.Errorf("offset must not be negative :%w", ErrInvalid)
+	}
+	return nil
+}
+
+// StatusOverride is a status an admin forces on a parcel outside of the usual carrier and sender actions
+type StatusOverride struct {
+	ParcelID       int    `json:"parcel_id"`
+	AdminID        int    `json:"admin_id"`
+	UserID         int    `json:"user_id"`
+	CarrierID      int    `json:"carrier_id,omitempty"`
+	PreviousStatus int    `json:"previous_status"`
+	Status         int    `json:"status"`
+	Reason         string `json:"reason"`
+}
+
+// ValidateStatusOverride requires a known status and the reason of the change
+func (o *StatusOverride) ValidateStatusOverride() error {
+	if ParcelStatusName(o.Status) == "unknown" {
+		return fmt.Errorf("status %d is unknown :%w", o.Status, ErrInvalid)
+	}
+
+	o.Reason = strings.TrimSpace(o.Reason)
+	if o.Reason == "" {
+		return fmt.Errorf("reason is required :%w", ErrEmpty)
+	}
+	return nil
+}
+
+// CarrierProfile is a carrier as the admin console lists it, with its rating and parcels
+type CarrierProfile struct {
+	CarrierID       int     `json:"carrier_id" db:"carrier_id"`
+	Rating          float64 `json:"rating" db:"rating"`
+	RatingCount     int     `json:"rating_count" db:"rating_count"`
+	Suspended       bool    `json:"suspended" db:"suspended"`
+	SuspendedReason string  `json:"suspended_reason,omitempty" db:"suspended_reason"`
+	ActiveParcels   int     `json:"active_parcels" db:"active_parcels"`
+	Delivered       int     `json:"delivered" db:"delivered"`
+}
+
+// CarrierSuspension suspends a carrier from requesting parcels or reinstates it
+type CarrierSuspension struct {
+	CarrierID int    `json:"carrier_id"`
+	Suspended bool   `json:"suspended"`
+	Reason    string `json:"reason"`
+}
+
+// ValidateCarrierSuspension requires the reason of a suspension, a reinstated carrier keeps no reason
+func (s *CarrierSuspension) ValidateCarrierSuspension() error {
+	if s.CarrierID <= 0 {
+		return fmt.Errorf("carrier ID is required :%w", ErrEmpty)
+	}
+
+	s.Reason = strings.TrimSpace(s.Reason)
+	if !s.Suspended {
+		s.Reason = ""
+	} else if s.Reason == "" {
+		return fmt.Errorf("reason is required :%w", ErrEmpty)
+	}
+	return nil
+}
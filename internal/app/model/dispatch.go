@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Offer is an auto-dispatched parcel offered to a single carrier until it expires
+type Offer struct {
+	ID        int64     `json:"id"`
+	CarrierID int       `json:"carrier_id"`
+	Job       Job       `json:"job"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CarrierRating is the average rating of a carrier from the reviews of its deliveries
+type CarrierRating struct {
+	CarrierID int     `json:"carrier_id" db:"carrier_id"`
+	Rating    float64 `json:"rating" db:"rating"`
+	Count     int     `json:"rating_count" db:"rating_count"`
+}
+
+// Candidate is an available carrier ranked for an auto-dispatched parcel, a lower score ranks first
+type Candidate struct {
+	CarrierID int
+	Filter    JobFilter
+	Job       Job
+	Rating    float64
+	Score     float64
+}
@@ -5,27 +5,88 @@ import (
 	"time"
 )
 
+// Parcel status values, as seeded into the parcel_status table
+const (
+	ParcelStatusCreated   = 1
+	ParcelStatusAssigned  = 2
+	ParcelStatusPickedUp  = 3
+	ParcelStatusDelivered = 4
+	ParcelStatusCancelled = 5
+	ParcelStatusReturned  = 6
+)
+
+var parcelStatusNames = map[int]string{
+	ParcelStatusCreated:   "created",
+	ParcelStatusAssigned:  "assigned",
+	ParcelStatusPickedUp:  "picked up",
+	ParcelStatusDelivered: "delivered",
+	ParcelStatusCancelled: "cancelled",
+	ParcelStatusReturned:  "returned",
+}
+
+// MaxTimeWindow is the longest pickup or delivery window a sender can request
+const MaxTimeWindow = 7 * 24 * time.Hour
+
+// Carrier request status values, as seeded into the carrier_request_status table
+const (
+	CarrierRequestPending  = 1
+	CarrierRequestAccepted = 2
+	CarrierRequestRejected = 3
+	CarrierRequestExpired  = 4
+)
+
 type Parcel struct {
-	ID                 int       `json:"id"`
-	UserID             int       `json:"user_id" db:"user_id"`
-	CarrierID          int       `json:"carrier_id" db:"carrier_id"`
-	Status             int       `json:"status"`
-	SourceAddress      string    `json:"source_address" db:"source_address"`
-	DestinationAddress string    `json:"destination_address" db:"destination_address"`
-	SourceTime         time.Time `json:"source_time" db:"source_time"`
-	ParcelType         string    `json:"type" db:"type"`
-	Price              float32   `json:"price" db:"price"`
-	CarrierFee         float32   `json:"carrier_fee" db:"carrier_fee"`
-	CompanyFee         float32   `json:"company_fee" db:"company_fee"`
-	CreatedAt          time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	ID                 int        `json:"id"`
+	UserID             int        `json:"user_id" db:"user_id"`
+	CarrierID          int        `json:"carrier_id" db:"carrier_id"`
+	Status             int        `json:"status"`
+	SourceAddress      string     `json:"source_address" db:"source_address"`
+	DestinationAddress string     `json:"destination_address" db:"destination_address"`
+	SourceTime         time.Time  `json:"source_time" db:"source_time"`
+	SourceLatitude     float64    `json:"source_latitude,omitempty" db:"source_latitude"`
+	SourceLongitude    float64    `json:"source_longitude,omitempty" db:"source_longitude"`
+	DestLatitude       float64    `json:"destination_latitude,omitempty" db:"destination_latitude"`
+	DestLongitude      float64    `json:"destination_longitude,omitempty" db:"destination_longitude"`
+	Weight             float32    `json:"weight,omitempty" db:"weight"`
+	DeclaredValue      float32    `json:"declared_value,omitempty" db:"declared_value"`
+	AutoDispatch       bool       `json:"auto_dispatch,omitempty" db:"auto_dispatch"`
+	PickupStart        *time.Time `json:"pickup_window_start,omitempty" db:"pickup_window_start"`
+	PickupEnd          *time.Time `json:"pickup_window_end,omitempty" db:"pickup_window_end"`
+	DeliveryStart      *time.Time `json:"delivery_window_start,omitempty" db:"delivery_window_start"`
+	DeliveryEnd        *time.Time `json:"delivery_window_end,omitempty" db:"delivery_window_end"`
+	AssignedAt         *time.Time `json:"assigned_at,omitempty" db:"assigned_at"`
+	PickedUpAt         *time.Time `json:"picked_up_at,omitempty" db:"picked_up_at"`
+	DeliveredAt        *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	PickupDeadline     *time.Time `json:"pickup_deadline,omitempty" db:"pickup_deadline"`
+	DeliveryDeadline   *time.Time `json:"delivery_deadline,omitempty" db:"delivery_deadline"`
+	ReturnOf           int        `json:"return_of,omitempty" db:"return_of"`
+	ShipmentID         int        `json:"shipment_id,omitempty" db:"shipment_id"`
+	Stop               int        `json:"stop,omitempty" db:"stop"`
+	ParcelType         string     `json:"type" db:"type"`
+	Price              float32    `json:"price" db:"price"`
+	CarrierFee         float32    `json:"carrier_fee" db:"carrier_fee"`
+	CompanyFee         float32    `json:"company_fee" db:"company_fee"`
+	Discount           float32    `json:"discount,omitempty" db:"discount"`
+	PromoCode          string     `json:"promo_code,omitempty" db:"-"`
+	PromotionID        int        `json:"-" db:"-"`
+	Region             string     `json:"region,omitempty" db:"region"`
+	Tax                float32    `json:"tax,omitempty" db:"tax"`
+	TaxName            string     `json:"tax_name,omitempty" db:"tax_name"`
+	TaxRate            float32    `json:"tax_rate,omitempty" db:"tax_rate"`
+	TaxInclusive       bool       `json:"tax_inclusive,omitempty" db:"tax_inclusive"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type CarrierRequest struct {
-	ID        int `json:"id"`
-	ParcelID  int `json:"parcel_id" db:"parcel_id"`
-	CarrierID int `json:"carrier_id" db:"carrier_id"`
-	Status    int `json:"status" db:"status"`
+	ID        int       `json:"id"`
+	ParcelID  int       `json:"parcel_id" db:"parcel_id"`
+	CarrierID int       `json:"carrier_id" db:"carrier_id"`
+	Status    int       `json:"status" db:"status"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	// Rating and RatingCount are the aggregate rating of the carrier, shown to the sender choosing a request
+	Rating      float64 `json:"rating,omitempty" db:"rating"`
+	RatingCount int     `json:"rating_count,omitempty" db:"rating_count"`
 }
 
 func (p *Parcel) ValidateParcelInput() error {
@@ -49,9 +110,89 @@ func (p *Parcel) ValidateParcelInput() error {
 		return fmt.Errorf("source time must be future date:%w", ErrEmpty)
 	}
 
+	if err := validateCoordinates(p.SourceLatitude, p.SourceLongitude); err != nil {
+		return err
+	}
+
+	if err := validateCoordinates(p.DestLatitude, p.DestLongitude); err != nil {
+		return fmt.Errorf("destination %w", err)
+	}
+
+	if p.Weight < 0 {
+		return fmt.Errorf("weight can not be negative :%w", ErrInvalid)
+	}
+
+	if p.DeclaredValue < 0 {
+		return fmt.Errorf("declared value can not be negative :%w", ErrInvalid)
+	}
+
+	return p.validateWindows(time.Now())
+}
+
+// validateWindows checks that the pickup and delivery windows are complete, in the future, no longer than
+// MaxTimeWindow, and that delivery can not start before pickup or end before pickup may start
+func (p *Parcel) validateWindows(now time.Time) error {
+	if err := validateWindow("pickup", p.PickupStart, p.PickupEnd, now); err != nil {
+		return err
+	}
+
+	if err := validateWindow("delivery", p.DeliveryStart, p.DeliveryEnd, now); err != nil {
+		return err
+	}
+
+	if p.PickupStart != nil && !p.SourceTime.IsZero() && (p.SourceTime.Before(*p.PickupStart) || p.SourceTime.After(*p.PickupEnd)) {
+		return fmt.Errorf("source time must be within the pickup window :%w", ErrInvalid)
+	}
+
+	if p.PickupStart != nil && p.DeliveryStart != nil && p.DeliveryStart.Before(*p.PickupStart) {
+		return fmt.Errorf("delivery window can not start before the pickup window :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+func validateWindow(name string, start *time.Time, end *time.Time, now time.Time) error {
+	if start == nil && end == nil {
+		return nil
+	}
+
+	if start == nil || end == nil {
+		return fmt.Errorf("%s window needs a start and an end :%w", name, ErrEmpty)
+	}
+
+	if start.Before(now) {
+		return fmt.Errorf("%s window must start in the future :%w", name, ErrInvalid)
+	}
+
+	if !end.After(*start) {
+		return fmt.Errorf("%s window must end after it starts :%w", name, ErrInvalid)
+	}
+
+	if end.Sub(*start) > MaxTimeWindow {
+		return fmt.Errorf("%s window can not be longer than %s :%w", name, MaxTimeWindow, ErrInvalid)
+	}
+
 	return nil
 }
 
+// HasSourceLocation reports whether the coordinates of the pickup address are known
+func (p *Parcel) HasSourceLocation() bool {
+	return p.SourceLatitude != 0 || p.SourceLongitude != 0
+}
+
+// HasDestinationLocation reports whether the coordinates of the destination address are known
+func (p *Parcel) HasDestinationLocation() bool {
+	return p.DestLatitude != 0 || p.DestLongitude != 0
+}
+
+// ParcelStatusName returns the readable name of the parcel status
+func ParcelStatusName(status int) string {
+	if name, ok := parcelStatusNames[status]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // Validates carrier request input credentials
 func (cr *CarrierRequest) ValidateCarrierId() error {
 	if cr.CarrierID == 0 {
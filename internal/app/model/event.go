@@ -0,0 +1,92 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event types published by the parcel and carrier services
+const (
+	EventParcelCreated       = "parcel.created"
+	EventParcelStatusChanged = "parcel.status_changed"
+	EventParcelCancelled     = "parcel.cancelled"
+	EventCarrierRequested    = "carrier.requested"
+	EventCarrierAssigned     = "carrier.assigned"
+	EventCarrierLocation     = "carrier.location_updated"
+	EventParcelSLABreached   = "parcel.sla_breached"
+	EventDeliveryFailed      = "parcel.delivery_failed"
+	EventLegAssigned         = "parcel.leg_assigned"
+	EventCustodyTransferred  = "parcel.custody_transferred"
+)
+
+// EventTypes lists every event type that can be subscribed to
+var EventTypes = []string{
+	EventParcelCreated,
+	EventParcelStatusChanged,
+	EventParcelCancelled,
+	EventCarrierRequested,
+	EventCarrierAssigned,
+	EventParcelSLABreached,
+	EventDeliveryFailed,
+	EventLegAssigned,
+	EventCustodyTransferred,
+}
+
+// Notification channels
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Event is something that happened to a parcel. User and carrier are filled in from the parcel when not known.
+type Event struct {
+	Type       string    `json:"type"`
+	ParcelID   int       `json:"parcel_id"`
+	UserID     int       `json:"user_id,omitempty"`
+	CarrierID  int       `json:"carrier_id,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	Latitude   float64   `json:"latitude,omitempty"`
+	Longitude  float64   `json:"longitude,omitempty"`
+	Breach     string    `json:"breach,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Leg        int       `json:"leg,omitempty"`
+	Hub        string    `json:"hub,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// StreamEvent is an event numbered in the order it was broadcast, the number is the server-sent event ID
+type StreamEvent struct {
+	ID    int64
+	Event Event
+}
+
+// ParcelTopic is the broadcast topic of the events of a parcel
+func ParcelTopic(parcelID int) string {
+	return fmt.Sprintf("parcel:%d", parcelID)
+}
+
+// CarrierTopic is the broadcast topic of the events of a carrier
+func CarrierTopic(carrierID int) string {
+	return fmt.Sprintf("carrier:%d", carrierID)
+}
+
+// IsEventType reports whether the event type is known
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is an event rendered for one recipient on one channel
+type Notification struct {
+	Channel   string    `json:"channel"`
+	Recipient string    `json:"recipient"`
+	Event     string    `json:"event"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
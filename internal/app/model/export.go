@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Formats parcels can be exported in
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// Parcel dates an export can be filtered on
+const (
+	ExportDateCreated   = "created"
+	ExportDateDelivered = "delivered"
+)
+
+const exportDateLayout = "2006-01-02"
+
+// ExportColumns is the header of a CSV parcel export
+var ExportColumns = []string{
+	"id", "user_id", "carrier_id", "status", "type", "source_address", "destination_address", "weight", "declared_value",
+	"price", "carrier_fee", "company_fee", "discount", "tax", "tax_name", "region", "shipment_id", "return_of",
+	"created_at", "assigned_at", "picked_up_at", "delivered_at",
+}
+
+// ExportFilter selects the parcels of an export, zero values match every parcel.
+// From and To bound the created or delivered date, From is inclusive and To exclusive.
+type ExportFilter struct {
+	Status    int
+	UserID    int
+	CarrierID int
+	Type      string
+	Date      string
+	From      *time.Time
+	To        *time.Time
+}
+
+// ValidateExportFilter checks the export format and filter and defaults the date to the created date
+func (f *ExportFilter) ValidateExportFilter(format string) error {
+	if format != ExportFormatCSV && format != ExportFormatNDJSON {
+		return fmt.Errorf("format must be csv or ndjson :%w", ErrInvalid)
+	}
+
+	if f.Status != 0 && ParcelStatusName(f.Status) == "unknown" {
+		return fmt.Errorf("status %d is unknown :%w", f.Status, ErrInvalid)
+	}
+
+	if f.Date == "" {
+		f.Date = ExportDateCreated
+	}
+	if f.Date != ExportDateCreated && f.Date != ExportDateDelivered {
+		return fmt.Errorf("date must be created or delivered :%w", ErrInvalid)
+	}
+
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("from must be before to :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+// ParseExportTime reads a date like 2021-03-01 or an RFC 3339 time
+func ParseExportTime(value string) (*time.Time, error) {
+	if t, err := time.Parse(exportDateLayout, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a date or an RFC 3339 time :%w", value, ErrInvalid)
+	}
+	return &t, nil
+}
+
+// ExportRecord returns the parcel as a CSV row in the order of ExportColumns
+func (p Parcel) ExportRecord() []string {
+	return []string{
+		strconv.Itoa(p.ID),
+		strconv.Itoa(p.UserID),
+		strconv.Itoa(p.CarrierID),
+		strconv.Itoa(p.Status),
+		p.ParcelType,
+		p.SourceAddress,
+		p.DestinationAddress,
+		formatFloat(p.Weight),
+		formatFloat(p.DeclaredValue),
+		formatFloat(p.Price),
+		formatFloat(p.CarrierFee),
+		formatFloat(p.CompanyFee),
+		formatFloat(p.Discount),
+		formatFloat(p.Tax),
+		p.TaxName,
+		p.Region,
+		strconv.Itoa(p.ShipmentID),
+		strconv.Itoa(p.ReturnOf),
+		p.CreatedAt.Format(time.RFC3339),
+		formatTime(p.AssignedAt),
+		formatTime(p.PickedUpAt),
+		formatTime(p.DeliveredAt),
+	}
+}
+
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
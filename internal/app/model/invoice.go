@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const invoiceNumberPrefix = "INV"
+
+type Invoice struct {
+	ID                 int       `json:"id"`
+	Number             string    `json:"number"`
+	ParcelID           int       `json:"parcel_id" db:"parcel_id"`
+	UserID             int       `json:"user_id" db:"user_id"`
+	SourceAddress      string    `json:"source_address" db:"source_address"`
+	DestinationAddress string    `json:"destination_address" db:"destination_address"`
+	ParcelType         string    `json:"type" db:"type"`
+	CarrierFee         float32   `json:"carrier_fee" db:"carrier_fee"`
+	CompanyFee         float32   `json:"company_fee" db:"company_fee"`
+	Discount           float32   `json:"discount"`
+	Tax                float32   `json:"tax"`
+	TaxName            string    `json:"tax_name" db:"tax_name"`
+	TaxRate            float32   `json:"tax_rate" db:"tax_rate"`
+	TaxInclusive       bool      `json:"tax_inclusive" db:"tax_inclusive"`
+	Total              float32   `json:"total"`
+	IssuedAt           time.Time `json:"issued_at" db:"issued_at"`
+}
+
+type InvoiceLine struct {
+	Description string  `json:"description"`
+	Amount      float32 `json:"amount"`
+	Included    bool    `json:"included,omitempty"`
+}
+
+// InvoiceNumber formats the sequence number of an invoice
+func InvoiceNumber(sequence int) string {
+	return fmt.Sprintf("%s-%06d", invoiceNumberPrefix, sequence)
+}
+
+// Lines returns the price breakdown of the invoice. Discount and tax lines are only added when they apply,
+// an inclusive tax line is marked as included because its amount is already part of the fees.
+func (i *Invoice) Lines() []InvoiceLine {
+	lines := []InvoiceLine{
+		{Description: "Carrier fee", Amount: i.CarrierFee},
+		{Description: "Service fee", Amount: i.CompanyFee},
+	}
+
+	if i.Discount > 0 {
+		lines = append(lines, InvoiceLine{Description: "Discount", Amount: -i.Discount})
+	}
+
+	if i.Tax > 0 {
+		lines = append(lines, InvoiceLine{
+			Description: fmt.Sprintf("%s %g%%", i.TaxName, math.Round(float64(i.TaxRate)*10000)/100),
+			Amount:      i.Tax,
+			Included:    i.TaxInclusive,
+		})
+	}
+
+	return lines
+}
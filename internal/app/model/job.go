@@ -0,0 +1,50 @@
+package model
+
+import "fmt"
+
+// MaxJobRadius is the largest radius in kilometers a carrier can look for jobs in
+const MaxJobRadius = 50
+
+// JobFilter is sent by a carrier to receive the new parcels picked up near them that fit in their vehicle
+type JobFilter struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"`
+	Capacity  float32 `json:"capacity"`
+}
+
+// Job is a new parcel offered to a carrier with its distance in kilometers to the pickup address
+type Job struct {
+	Parcel   Parcel  `json:"parcel"`
+	Distance float64 `json:"distance"`
+}
+
+// ValidateJobFilter validates the location, radius and vehicle capacity sent by a carrier
+func (f *JobFilter) ValidateJobFilter() error {
+	if err := validateCoordinates(f.Latitude, f.Longitude); err != nil {
+		return err
+	}
+
+	if f.Radius <= 0 || f.Radius > MaxJobRadius {
+		return fmt.Errorf("radius must be between 0 and %d km :%w", MaxJobRadius, ErrInvalid)
+	}
+
+	if f.Capacity <= 0 {
+		return fmt.Errorf("capacity is required :%w", ErrEmpty)
+	}
+
+	return nil
+}
+
+// Match returns the job when the parcel is picked up within the radius and fits in the vehicle
+func (f *JobFilter) Match(parcel Parcel) (Job, bool) {
+	if !parcel.HasSourceLocation() || parcel.Weight > f.Capacity {
+		return Job{}, false
+	}
+
+	distance := Distance(f.Latitude, f.Longitude, parcel.SourceLatitude, parcel.SourceLongitude)
+	if distance > f.Radius {
+		return Job{}, false
+	}
+	return Job{Parcel: parcel, Distance: distance}, true
+}
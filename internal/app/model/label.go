@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrackingCodePrefix starts every tracking code printed on a shipping label
+const TrackingCodePrefix = "PS"
+
+// TrackingCodeAlphabet holds the characters of a tracking code after its prefix, without the easily confused I, L, O and U
+const TrackingCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// TrackingCodeLength is the number of characters of a tracking code after its prefix
+const TrackingCodeLength = 10
+
+// Label is the shipping label of a parcel. The tracking code is printed as a barcode and scanned by carriers and hubs.
+type Label struct {
+	ParcelID           int       `json:"parcel_id" db:"parcel_id"`
+	TrackingCode       string    `json:"tracking_code" db:"tracking_code"`
+	UserID             int       `json:"user_id" db:"user_id"`
+	CarrierID          int       `json:"carrier_id" db:"carrier_id"`
+	Status             int       `json:"status"`
+	SourceAddress      string    `json:"source_address" db:"source_address"`
+	DestinationAddress string    `json:"destination_address" db:"destination_address"`
+	ParcelType         string    `json:"type" db:"type"`
+	Weight             float32   `json:"weight,omitempty" db:"weight"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// Scan is a tracking code read from a label by a carrier or a hub
+type Scan struct {
+	Code      string `json:"code"`
+	Role      string `json:"-"`
+	ScannerID int    `json:"-"`
+}
+
+// ScanEvent records a scan with the status the parcel, or the leg of a relayed parcel, was moved to
+type ScanEvent struct {
+	ID             int       `json:"id"`
+	ParcelID       int       `json:"parcel_id" db:"parcel_id"`
+	LegID          int       `json:"leg_id,omitempty" db:"leg_id"`
+	HubID          int       `json:"hub_id,omitempty" db:"hub_id"`
+	Role           string    `json:"role" db:"role"`
+	ScannerID      int       `json:"scanner_id" db:"scanner_id"`
+	PreviousStatus int       `json:"previous_status" db:"previous_status"`
+	Status         int       `json:"status"`
+	ParcelStatus   int       `json:"parcel_status" db:"parcel_status"`
+	ScannedAt      time.Time `json:"scanned_at" db:"scanned_at"`
+}
+
+// ValidateScanInput normalises the scanned code and checks it is a tracking code
+func (s *Scan) ValidateScanInput() error {
+	s.Code = strings.ToUpper(strings.TrimSpace(s.Code))
+	if s.Code == "" {
+		return fmt.Errorf("code is required :%w", ErrEmpty)
+	}
+
+	if !ValidTrackingCode(s.Code) {
+		return fmt.Errorf("code %q is not a tracking code :%w", s.Code, ErrInvalid)
+	}
+
+	return nil
+}
+
+// ValidTrackingCode reports whether the code has the prefix, length and alphabet of a tracking code
+func ValidTrackingCode(code string) bool {
+	if len(code) != len(TrackingCodePrefix)+TrackingCodeLength || !strings.HasPrefix(code, TrackingCodePrefix) {
+		return false
+	}
+
+	for _, c := range code[len(TrackingCodePrefix):] {
+		if !strings.ContainsRune(TrackingCodeAlphabet, c) {
+			return false
+		}
+	}
+
+	return true
+}
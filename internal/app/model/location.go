@@ -0,0 +1,46 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// earthRadius is the mean radius of the earth in kilometers
+const earthRadius = 6371.0
+
+// CarrierLocation is the last reported position of a carrier
+type CarrierLocation struct {
+	CarrierID int       `json:"carrier_id" db:"carrier_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ValidateLocationInput validates the position reported by a carrier
+func (l *CarrierLocation) ValidateLocationInput() error {
+	return validateCoordinates(l.Latitude, l.Longitude)
+}
+
+func validateCoordinates(latitude float64, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90 :%w", ErrInvalid)
+	}
+
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180 :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+// Distance returns the great-circle distance in kilometers between two coordinates
+func Distance(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
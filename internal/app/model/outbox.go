@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// OutboxMessage is an event stored in the same transaction as the state change it describes,
+// waiting to be published by the outbox dispatcher
+type OutboxMessage struct {
+	ID        int64      `json:"id"`
+	Topic     string     `json:"topic"`
+	ParcelID  int        `json:"parcel_id" db:"parcel_id"`
+	Payload   string     `json:"payload"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error" db:"last_error"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	SentAt    *time.Time `json:"sent_at" db:"sent_at"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+type Promotion struct {
+	ID            int        `json:"id"`
+	Code          string     `json:"code"`
+	DiscountType  string     `json:"discount_type" db:"discount_type"`
+	DiscountValue float32    `json:"discount_value" db:"discount_value"`
+	MinOrder      float32    `json:"min_order" db:"min_order"`
+	MaxUses       int        `json:"max_uses" db:"max_uses"`
+	PerUserLimit  int        `json:"per_user_limit" db:"per_user_limit"`
+	UsedCount     int        `json:"used_count" db:"used_count"`
+	ExpiresAt     *time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type PromotionRedemption struct {
+	ID          int       `json:"id"`
+	PromotionID int       `json:"promotion_id" db:"promotion_id"`
+	ParcelID    int       `json:"parcel_id" db:"parcel_id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Discount    float32   `json:"discount"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// ValidatePromotionInput validates promotion input given by admin
+func (p *Promotion) ValidatePromotionInput() error {
+	if p.Code == "" {
+		return fmt.Errorf("promo code is required :%w", ErrEmpty)
+	}
+
+	if p.DiscountType != DiscountTypePercentage && p.DiscountType != DiscountTypeFixed {
+		return fmt.Errorf("discount type must be %s or %s :%w", DiscountTypePercentage, DiscountTypeFixed, ErrInvalid)
+	}
+
+	if p.DiscountValue <= 0 {
+		return fmt.Errorf("discount value must be positive :%w", ErrInvalid)
+	}
+
+	if p.DiscountType == DiscountTypePercentage && p.DiscountValue > 100 {
+		return fmt.Errorf("percentage discount can not exceed 100 :%w", ErrInvalid)
+	}
+
+	if p.MinOrder < 0 || p.MaxUses < 0 || p.PerUserLimit < 0 {
+		return fmt.Errorf("min order, max uses and per user limit can not be negative :%w", ErrInvalid)
+	}
+
+	if p.ExpiresAt != nil && p.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("expiry must be future date :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+// Discount calculates the discount of the promotion for the given price.
+// The discount never exceeds the price.
+func (p *Promotion) Discount(price float32) float32 {
+	discount := p.DiscountValue
+	if p.DiscountType == DiscountTypePercentage {
+		discount = price * p.DiscountValue / 100
+	}
+
+	if discount > price {
+		return price
+	}
+	return discount
+}
+
+// IsExpired reports whether the promotion can no longer be redeemed at the given time
+func (p *Promotion) IsExpired(at time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(at)
+}
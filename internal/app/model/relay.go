@@ -0,0 +1,189 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRelayHubs is the most hubs a parcel can be relayed through
+const MaxRelayHubs = 5
+
+// Hub is a depot where a parcel travelling in legs is handed from one carrier to the next
+type Hub struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Latitude  float64   `json:"latitude,omitempty"`
+	Longitude float64   `json:"longitude,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Leg is one stretch of a relayed parcel, from its source or a hub to the next hub or its destination.
+// Each leg has its own carrier and moves through the created, assigned, picked up and delivered parcel statuses.
+type Leg struct {
+	ID          int        `json:"id"`
+	ParcelID    int        `json:"parcel_id" db:"parcel_id"`
+	Sequence    int        `json:"sequence" db:"sequence"`
+	FromHubID   int        `json:"from_hub_id,omitempty" db:"from_hub_id"`
+	ToHubID     int        `json:"to_hub_id,omitempty" db:"to_hub_id"`
+	FromAddress string     `json:"from_address" db:"from_address"`
+	ToAddress   string     `json:"to_address" db:"to_address"`
+	CarrierID   int        `json:"carrier_id" db:"carrier_id"`
+	Status      int        `json:"status" db:"status"`
+	AssignedAt  *time.Time `json:"assigned_at,omitempty" db:"assigned_at"`
+	PickedUpAt  *time.Time `json:"picked_up_at,omitempty" db:"picked_up_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CustodyTransfer records the hand over of a parcel at a hub from the carrier of one leg to the carrier of the next
+type CustodyTransfer struct {
+	ID            int       `json:"id"`
+	ParcelID      int       `json:"parcel_id" db:"parcel_id"`
+	HubID         int       `json:"hub_id" db:"hub_id"`
+	HubName       string    `json:"hub_name" db:"hub_name"`
+	FromLegID     int       `json:"from_leg_id" db:"from_leg_id"`
+	ToLegID       int       `json:"to_leg_id" db:"to_leg_id"`
+	FromCarrierID int       `json:"from_carrier_id" db:"from_carrier_id"`
+	ToCarrierID   int       `json:"to_carrier_id" db:"to_carrier_id"`
+	ArrivedAt     time.Time `json:"arrived_at" db:"arrived_at"`
+	TransferredAt time.Time `json:"transferred_at" db:"transferred_at"`
+}
+
+// Relay is a parcel delivered in legs with the custody transfers at its hubs
+type Relay struct {
+	ParcelID  int               `json:"parcel_id"`
+	UserID    int               `json:"user_id"`
+	CarrierID int               `json:"carrier_id"`
+	Status    int               `json:"status"`
+	Legs      []Leg             `json:"legs"`
+	Transfers []CustodyTransfer `json:"transfers"`
+}
+
+// RelaySplit is the sender splitting a parcel into legs through the given hubs, in travel order
+type RelaySplit struct {
+	ParcelID int   `json:"-"`
+	UserID   int   `json:"-"`
+	HubIDs   []int `json:"hub_ids"`
+}
+
+// LegRequest is a carrier asking to carry a leg, or the sender accepting the carrier
+type LegRequest struct {
+	LegID     int       `json:"leg_id" db:"leg_id"`
+	CarrierID int       `json:"carrier_id" db:"carrier_id"`
+	Status    int       `json:"status,omitempty" db:"status"`
+	ExpiresAt time.Time `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// LegUpdate is the carrier of a leg picking the parcel up or dropping it off
+type LegUpdate struct {
+	LegID     int `json:"-"`
+	CarrierID int `json:"-"`
+	Status    int `json:"status"`
+}
+
+// LegChange is a leg after it changed with the status of its parcel derived from every leg and the custody
+// transfer recorded by the change. Changed tells whether the status of the parcel moved.
+type LegChange struct {
+	Leg          Leg              `json:"leg"`
+	ParcelStatus int              `json:"parcel_status"`
+	CarrierID    int              `json:"carrier_id"`
+	Changed      bool             `json:"-"`
+	Transfer     *CustodyTransfer `json:"transfer,omitempty"`
+}
+
+// ValidateHubInput validates hub input given by admin
+func (h *Hub) ValidateHubInput() error {
+	if h.Name == "" {
+		return fmt.Errorf("name is required :%w", ErrEmpty)
+	}
+
+	if h.Address == "" {
+		return fmt.Errorf("address is required :%w", ErrEmpty)
+	}
+
+	return validateCoordinates(h.Latitude, h.Longitude)
+}
+
+// ValidateRelayInput validates the hubs a sender relays a parcel through, a hub can only be visited once
+func (s *RelaySplit) ValidateRelayInput() error {
+	if len(s.HubIDs) == 0 {
+		return fmt.Errorf("at least one hub is required :%w", ErrEmpty)
+	}
+
+	if len(s.HubIDs) > MaxRelayHubs {
+		return fmt.Errorf("a parcel can be relayed through at most %d hubs :%w", MaxRelayHubs, ErrInvalid)
+	}
+
+	seen := make(map[int]bool, len(s.HubIDs))
+	for _, hubID := range s.HubIDs {
+		if hubID <= 0 {
+			return fmt.Errorf("hub ID %d is not valid :%w", hubID, ErrInvalid)
+		}
+		if seen[hubID] {
+			return fmt.Errorf("hub %d can only be visited once :%w", hubID, ErrInvalid)
+		}
+		seen[hubID] = true
+	}
+
+	return nil
+}
+
+// ValidateCarrierId validates the carrier the sender accepts for a leg
+func (r *LegRequest) ValidateCarrierId() error {
+	if r.CarrierID == 0 {
+		return fmt.Errorf("Carrier ID is required :%w", ErrEmpty)
+	}
+	return nil
+}
+
+// ValidateLegStatus accepts the statuses a carrier can move its leg to
+func (u *LegUpdate) ValidateLegStatus() error {
+	if u.Status != ParcelStatusPickedUp && u.Status != ParcelStatusDelivered {
+		return fmt.Errorf("status of a leg must be %d (picked up) or %d (delivered) :%w", ParcelStatusPickedUp, ParcelStatusDelivered, ErrInvalid)
+	}
+	return nil
+}
+
+// NewLegs splits the parcel into the legs from its source through the hubs to its destination
+func NewLegs(parcel Parcel, hubs []Hub) []Leg {
+	legs := make([]Leg, 0, len(hubs)+1)
+	from := Leg{FromAddress: parcel.SourceAddress}
+	for _, hub := range hubs {
+		leg := from
+		leg.ToHubID = hub.ID
+		leg.ToAddress = hub.Address
+		legs = append(legs, leg)
+		from = Leg{FromHubID: hub.ID, FromAddress: hub.Address}
+	}
+	from.ToAddress = parcel.DestinationAddress
+	legs = append(legs, from)
+
+	for i := range legs {
+		legs[i].ParcelID = parcel.ID
+		legs[i].Sequence = i + 1
+		legs[i].Status = ParcelStatusCreated
+	}
+	return legs
+}
+
+// RelayState derives the status of a relayed parcel from its legs, in sequence order, and the carrier holding it.
+// The parcel is assigned and picked up with its first leg and delivered with its last, the carrier is the one of
+// the first leg not delivered yet and no one while the parcel waits at a hub for a carrier.
+func RelayState(legs []Leg) (int, int) {
+	if len(legs) == 0 {
+		return ParcelStatusCreated, 0
+	}
+
+	for _, leg := range legs {
+		if leg.Status != ParcelStatusDelivered {
+			status := legs[0].Status
+			if status == ParcelStatusDelivered {
+				status = ParcelStatusPickedUp
+			}
+			return status, leg.CarrierID
+		}
+	}
+	last := legs[len(legs)-1]
+	return ParcelStatusDelivered, last.CarrierID
+}
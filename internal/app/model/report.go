@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Periods reports group parcels by
+const (
+	ReportPeriodDay  = "day"
+	ReportPeriodWeek = "week"
+)
+
+// Number of carriers in the top carriers report
+const (
+	DefaultTopCarriers = 10
+	MaxTopCarriers     = 100
+)
+
+// ReportFilter bounds a report to a date range, From is inclusive and To exclusive, and groups it by day or week.
+// Limit only applies to the top carriers.
+type ReportFilter struct {
+	Period string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+// ValidateReportFilter checks the filter and defaults the period to a day and the limit to DefaultTopCarriers
+func (f *ReportFilter) ValidateReportFilter() error {
+	if f.Period == "" {
+		f.Period = ReportPeriodDay
+	}
+	if f.Period != ReportPeriodDay && f.Period != ReportPeriodWeek {
+		return fmt.Errorf("period must be day or week :%w", ErrInvalid)
+	}
+
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("from must be before to :%w", ErrInvalid)
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultTopCarriers
+	}
+	if f.Limit < 0 || f.Limit > MaxTopCarriers {
+		return fmt.Errorf("limit must be between 1 and %d :%w", MaxTopCarriers, ErrInvalid)
+	}
+
+	return nil
+}
+
+// VolumeReport is the number of parcels of a status and type created in a period
+type VolumeReport struct {
+	Period  time.Time `json:"period" db:"period"`
+	Status  int       `json:"status" db:"status"`
+	Type    string    `json:"type" db:"type"`
+	Parcels int       `json:"parcels" db:"parcels"`
+}
+
+// RevenueReport splits the price of the parcels delivered in a period into the carrier fee and the company fee
+type RevenueReport struct {
+	Period     time.Time `json:"period" db:"period"`
+	Parcels    int       `json:"parcels" db:"parcels"`
+	Price      float32   `json:"price" db:"price"`
+	CarrierFee float32   `json:"carrier_fee" db:"carrier_fee"`
+	CompanyFee float32   `json:"company_fee" db:"company_fee"`
+}
+
+// DeliveryTimeReport is the average time the parcels created in a period took to be assigned to a carrier and to be delivered,
+// averages are in seconds and only count the parcels that got that far
+type DeliveryTimeReport struct {
+	Period               time.Time `json:"period" db:"period"`
+	Parcels              int       `json:"parcels" db:"parcels"`
+	Assigned             int       `json:"assigned" db:"assigned"`
+	AvgAssignmentSeconds float64   `json:"avg_assignment_seconds" db:"avg_assignment_seconds"`
+	Delivered            int       `json:"delivered" db:"delivered"`
+	AvgDeliverySeconds   float64   `json:"avg_delivery_seconds" db:"avg_delivery_seconds"`
+}
+
+// CarrierReport is the performance of a carrier, requests count the carrier requests made in the range
+// and deliveries the parcels the carrier delivered in it
+type CarrierReport struct {
+	CarrierID          int     `json:"carrier_id" db:"carrier_id"`
+	Requests           int     `json:"requests" db:"requests"`
+	Accepted           int     `json:"accepted" db:"accepted"`
+	Delivered          int     `json:"delivered" db:"delivered"`
+	Earnings           float32 `json:"earnings" db:"earnings"`
+	AvgDeliverySeconds float64 `json:"avg_delivery_seconds" db:"avg_delivery_seconds"`
+}
@@ -8,6 +8,10 @@ var ErrNotFound = fmt.Errorf("not found")
 var ErrInvalid = fmt.Errorf("invalid")
 var ErrEmpty = fmt.Errorf("empty")
 var IntServerErr = fmt.Errorf("internal server error")
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+var ErrForbidden = fmt.Errorf("forbidden")
+var ErrLimitExceeded = fmt.Errorf("limit exceeded")
+var ErrConflict = fmt.Errorf("conflict")
 
 type GenericResponse struct {
 	Success bool                   `json:"success"`
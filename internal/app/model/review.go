@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxReviewComment is the longest comment a review can have
+const MaxReviewComment = 1000
+
+// Review is the rating the sender gives the carrier of a delivered parcel or the carrier gives the sender,
+// a parcel is rated once from each side
+type Review struct {
+	ID        int       `json:"id"`
+	ParcelID  int       `json:"parcel_id" db:"parcel_id"`
+	Role      string    `json:"role" db:"role"`
+	AuthorID  int       `json:"author_id" db:"author_id"`
+	SubjectID int       `json:"subject_id" db:"subject_id"`
+	Rating    int       `json:"rating" db:"rating"`
+	Comment   string    `json:"comment,omitempty" db:"comment"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (r *Review) ValidateReviewInput() error {
+	if r.Role != RoleUser && r.Role != RoleCarrier {
+		return fmt.Errorf("only the sender and the carrier can rate a parcel :%w", ErrForbidden)
+	}
+
+	if r.Rating < 1 || r.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5 :%w", ErrInvalid)
+	}
+
+	if len(r.Comment) > MaxReviewComment {
+		return fmt.Errorf("comment can not be longer than %d characters :%w", MaxReviewComment, ErrInvalid)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Route stop kinds
+const (
+	RouteStopPickup = "pickup"
+	RouteStopDrop   = "drop"
+)
+
+// RouteSpeed is the average speed in km/h used to estimate the arrival at each stop of a route
+const RouteSpeed = 30.0
+
+// RouteStopTime is the time spent at a stop to pick up or drop a parcel
+const RouteStopTime = 5 * time.Minute
+
+// RouteStop is a pickup or drop of a parcel on the route of a carrier. The arrival is estimated from the
+// distance to the previous stop, a carrier early for a window waits for it to open.
+type RouteStop struct {
+	ParcelID    int        `json:"parcel_id"`
+	Kind        string     `json:"kind"`
+	Address     string     `json:"address"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+	Distance    float64    `json:"distance"`
+	Arrival     time.Time  `json:"arrival"`
+	Late        bool       `json:"late,omitempty"`
+}
+
+// Route is the order in which a carrier picks up and drops its parcels, starting from its last reported location.
+// Parcels without coordinates for a stop they still need can not be routed and are listed apart.
+type Route struct {
+	CarrierID int         `json:"carrier_id"`
+	Stops     []RouteStop `json:"stops"`
+	Distance  float64     `json:"distance"`
+	Unrouted  []int       `json:"unrouted,omitempty"`
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxShipmentParcels is the most parcels a shipment can have
+const MaxShipmentParcels = 50
+
+// Shipment groups the parcels a sender books from one pickup point, a carrier accepts all of them at once.
+// When the parcels are given stops the destinations are dropped in that order, otherwise the carrier picks the order.
+type Shipment struct {
+	ID              int        `json:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	CarrierID       int        `json:"carrier_id" db:"carrier_id"`
+	SourceAddress   string     `json:"source_address" db:"source_address"`
+	SourceTime      time.Time  `json:"source_time" db:"source_time"`
+	SourceLatitude  float64    `json:"source_latitude,omitempty" db:"source_latitude"`
+	SourceLongitude float64    `json:"source_longitude,omitempty" db:"source_longitude"`
+	PickupStart     *time.Time `json:"pickup_window_start,omitempty" db:"pickup_window_start"`
+	PickupEnd       *time.Time `json:"pickup_window_end,omitempty" db:"pickup_window_end"`
+	Ordered         bool       `json:"ordered" db:"ordered"`
+	Parcels         []Parcel   `json:"parcels" db:"-"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ShipmentRequest is a carrier asking to deliver every parcel of a shipment, or the sender accepting the carrier
+type ShipmentRequest struct {
+	ShipmentID int       `json:"shipment_id"`
+	CarrierID  int       `json:"carrier_id"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+}
+
+// ValidateShipmentInput validates shipment input given by user. The pickup of the shipment is copied to every
+// parcel before the parcel is validated, stops must be left out or number the drops from 1.
+func (s *Shipment) ValidateShipmentInput() error {
+	if s.SourceAddress == "" {
+		return fmt.Errorf("source Address is required :%w", ErrEmpty)
+	}
+
+	if len(s.Parcels) == 0 {
+		return fmt.Errorf("a shipment needs at least one parcel :%w", ErrEmpty)
+	}
+
+	if len(s.Parcels) > MaxShipmentParcels {
+		return fmt.Errorf("a shipment can have at most %d parcels :%w", MaxShipmentParcels, ErrInvalid)
+	}
+
+	s.SetPickup()
+	for i := range s.Parcels {
+		if s.Parcels[i].PromoCode != "" {
+			return fmt.Errorf("parcel %d: promo codes can not be applied to shipments :%w", i+1, ErrInvalid)
+		}
+		if err := s.Parcels[i].ValidateParcelInput(); err != nil {
+			return fmt.Errorf("parcel %d: %w", i+1, err)
+		}
+	}
+
+	return s.validateStops()
+}
+
+// SetPickup copies the sender and the pickup of the shipment to its parcels
+func (s *Shipment) SetPickup() {
+	for i := range s.Parcels {
+		p := &s.Parcels[i]
+		p.UserID = s.UserID
+		p.SourceAddress = s.SourceAddress
+		p.SourceTime = s.SourceTime
+		p.SourceLatitude = s.SourceLatitude
+		p.SourceLongitude = s.SourceLongitude
+		p.PickupStart = s.PickupStart
+		p.PickupEnd = s.PickupEnd
+	}
+}
+
+// validateStops accepts parcels without stops or stops that number every parcel once from 1, and marks the
+// shipment ordered in the latter case
+func (s *Shipment) validateStops() error {
+	seen := make(map[int]bool, len(s.Parcels))
+	for _, p := range s.Parcels {
+		if p.Stop != 0 {
+			seen[p.Stop] = true
+		}
+	}
+	if len(seen) == 0 {
+		s.Ordered = false
+		return nil
+	}
+
+	for stop := 1; stop <= len(s.Parcels); stop++ {
+		if !seen[stop] {
+			return fmt.Errorf("stops must number the %d parcels from 1 to %d once each :%w", len(s.Parcels), len(s.Parcels), ErrInvalid)
+		}
+	}
+	s.Ordered = true
+	return nil
+}
+
+// Validates shipment request input credentials
+func (sr *ShipmentRequest) ValidateCarrierId() error {
+	if sr.CarrierID == 0 {
+		return fmt.Errorf("Carrier ID is required :%w", ErrEmpty)
+	}
+	return nil
+}
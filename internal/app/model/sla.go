@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SLA breach kinds
+const (
+	SLABreachPickup   = "pickup"
+	SLABreachDelivery = "delivery"
+)
+
+// SLA is how long a parcel of a type may wait to be picked up after its source time and how long it may then be in transit
+type SLA struct {
+	Pickup  time.Duration
+	Transit time.Duration
+}
+
+// DefaultSLA applies to the parcel types without an SLA of their own
+var DefaultSLA = SLA{Pickup: 4 * time.Hour, Transit: 48 * time.Hour}
+
+// slaByType is keyed by the lower case parcel type
+var slaByType = map[string]SLA{
+	"document": {Pickup: 2 * time.Hour, Transit: 24 * time.Hour},
+	"food":     {Pickup: time.Hour, Transit: 4 * time.Hour},
+	"fragile":  {Pickup: 4 * time.Hour, Transit: 72 * time.Hour},
+}
+
+// SLAForType returns the SLA of a parcel type
+func SLAForType(parcelType string) SLA {
+	if sla, ok := slaByType[strings.ToLower(parcelType)]; ok {
+		return sla
+	}
+	return DefaultSLA
+}
+
+// SetDeadlines computes the SLA deadlines of the parcel. The end of a requested window is the deadline,
+// otherwise the pickup deadline is the SLA of the type after the source time and delivery follows within the transit SLA.
+// A parcel without a source time is due from its creation, or from now when it is not stored yet.
+func (p *Parcel) SetDeadlines() {
+	sla := SLAForType(p.ParcelType)
+
+	start := p.SourceTime
+	if start.IsZero() {
+		start = p.CreatedAt
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	pickup := start.Add(sla.Pickup)
+	if p.PickupEnd != nil {
+		pickup = *p.PickupEnd
+	}
+	delivery := pickup.Add(sla.Transit)
+	if p.DeliveryEnd != nil {
+		delivery = *p.DeliveryEnd
+	}
+	p.PickupDeadline = &pickup
+	p.DeliveryDeadline = &delivery
+}
+
+// SLABreach is a parcel that was not picked up or not delivered by its deadline
+type SLABreach struct {
+	ID         int       `json:"id"`
+	ParcelID   int       `json:"parcel_id" db:"parcel_id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	CarrierID  int       `json:"carrier_id,omitempty" db:"carrier_id"`
+	Status     int       `json:"status" db:"status"`
+	Kind       string    `json:"kind" db:"kind"`
+	Deadline   time.Time `json:"deadline" db:"deadline"`
+	DetectedAt time.Time `json:"detected_at" db:"detected_at"`
+}
+
+// ValidateSLABreachKind accepts an empty kind, which means every kind
+func ValidateSLABreachKind(kind string) error {
+	if kind != "" && kind != SLABreachPickup && kind != SLABreachDelivery {
+		return fmt.Errorf("kind must be %s or %s :%w", SLABreachPickup, SLABreachDelivery, ErrInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TaxRule is the tax rate for a region and parcel type. Empty region or type matches any value.
+type TaxRule struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Region     string    `json:"region"`
+	ParcelType string    `json:"type" db:"type"`
+	Rate       float32   `json:"rate"`
+	Inclusive  bool      `json:"inclusive"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Tax is the tax calculated for a parcel
+type Tax struct {
+	Name      string  `json:"name"`
+	Rate      float32 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	Amount    float32 `json:"amount"`
+}
+
+// ValidateTaxRuleInput validates tax rule input given by admin
+func (t *TaxRule) ValidateTaxRuleInput() error {
+	if t.Name == "" {
+		return fmt.Errorf("tax name is required :%w", ErrEmpty)
+	}
+
+	if t.Rate < 0 || t.Rate >= 1 {
+		return fmt.Errorf("tax rate must be a fraction between 0 and 1 :%w", ErrInvalid)
+	}
+
+	return nil
+}
+
+// Matches reports whether the rule applies to the region and parcel type
+func (t *TaxRule) Matches(region string, parcelType string) bool {
+	return (t.Region == "" || t.Region == region) && (t.ParcelType == "" || t.ParcelType == parcelType)
+}
+
+// Specificity ranks matching rules, a rule for the exact region beats a rule for the exact parcel type
+func (t *TaxRule) Specificity() int {
+	specificity := 0
+	if t.Region != "" {
+		specificity += 2
+	}
+	if t.ParcelType != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// Calculate returns the tax of the rule for the taxable amount. Inclusive tax is already part of the amount.
+func (t *TaxRule) Calculate(amount float32) Tax {
+	tax := Tax{Name: t.Name, Rate: t.Rate, Inclusive: t.Inclusive}
+	if amount <= 0 {
+		return tax
+	}
+
+	if t.Inclusive {
+		tax.Amount = roundCents(amount - amount/(1+t.Rate))
+	} else {
+		tax.Amount = roundCents(amount * t.Rate)
+	}
+	return tax
+}
+
+func roundCents(amount float32) float32 {
+	return float32(math.Round(float64(amount)*100) / 100)
+}
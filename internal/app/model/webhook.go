@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhook delivery status values
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription is a callback URL of a user for the selected event types. The signing secret is never
+// sent back once the subscription is created.
+type WebhookSubscription struct {
+	ID         int            `json:"id"`
+	UserID     int            `json:"user_id" db:"user_id"`
+	URL        string         `json:"url"`
+	Secret     string         `json:"-"`
+	EventTypes pq.StringArray `json:"event_types" db:"event_types"`
+	Active     bool           `json:"active"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// WebhookSubscriptionSecret is a subscription with its signing secret, as given when subscribing and
+// returned only in the response to it
+type WebhookSubscriptionSecret struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookDelivery is the log of sending one event to one subscription
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id" db:"subscription_id"`
+	EventType      string     `json:"event_type" db:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseCode   int        `json:"response_code" db:"response_code"`
+	Error          string     `json:"error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at" db:"delivered_at"`
+}
+
+// ValidateWebhookSubscriptionInput validates webhook subscription input given by user
+func (w *WebhookSubscription) ValidateWebhookSubscriptionInput() error {
+	if w.UserID == 0 {
+		return fmt.Errorf("user ID is required :%w", ErrEmpty)
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL :%w", ErrInvalid)
+	}
+
+	if len(w.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required :%w", ErrEmpty)
+	}
+
+	for _, eventType := range w.EventTypes {
+		if !IsEventType(eventType) {
+			return fmt.Errorf("unknown event type %s :%w", eventType, ErrInvalid)
+		}
+	}
+
+	return nil
+}
+
+// Subscribes reports whether the subscription wants the event type
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
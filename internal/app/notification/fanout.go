@@ -0,0 +1,21 @@
+package notification
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+// fanout passes every event to each of its event notifiers
+type fanout []svc.EventNotifier
+
+// NewFanout returns an event notifier that notifies all of the given notifiers
+func NewFanout(notifiers ...svc.EventNotifier) fanout {
+	return fanout(notifiers)
+}
+
+func (f fanout) Notify(ctx context.Context, event model.Event) {
+	for _, notifier := range f {
+		notifier.Notify(ctx, event)
+	}
+}
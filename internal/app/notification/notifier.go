@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"parcel-service/internal/app/model"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// fileNotifier is a local channel that writes every notification as a JSON line, used instead of
+// a real SMS, email or push provider during development
+type fileNotifier struct {
+	channel string
+	mu      sync.Mutex
+	w       io.Writer
+}
+
+func NewFileNotifier(channel string, w io.Writer) *fileNotifier {
+	return &fileNotifier{
+		channel: channel,
+		w:       w,
+	}
+}
+
+func (n *fileNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *fileNotifier) Send(ctx context.Context, notification model.Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.w.Write(append(line, '\n'))
+	return err
+}
+
+// logNotifier is a local channel that writes every notification to the application log
+type logNotifier struct {
+	channel string
+}
+
+func NewLogNotifier(channel string) *logNotifier {
+	return &logNotifier{
+		channel: channel,
+	}
+}
+
+func (n *logNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *logNotifier) Send(ctx context.Context, notification model.Notification) error {
+	log.Info().
+		Str("channel", notification.Channel).
+		Str("recipient", notification.Recipient).
+		Str("event", notification.Event).
+		Msgf("%s: %s", notification.Subject, notification.Body)
+	return nil
+}
@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// QUEUE_SIZE is the number of events waiting for delivery, events are dropped when the queue is full
+const QUEUE_SIZE = 1000
+
+type service struct {
+	parcelRepo svc.ParcelRepository
+	notifiers  []svc.Notifier
+	queue      chan model.Event
+}
+
+func NewService(parcelRepo svc.ParcelRepository, notifiers ...svc.Notifier) *service {
+	return &service{
+		parcelRepo: parcelRepo,
+		notifiers:  notifiers,
+		queue:      make(chan model.Event, QUEUE_SIZE),
+	}
+}
+
+// Notify queues the event for delivery by Run. A full queue drops the event so a slow
+// channel never blocks a parcel or carrier request.
+func (s *service) Notify(ctx context.Context, event model.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		log.Error().Msgf("[Notify] notification queue is full, dropping event %s of parcel %d", event.Type, event.ParcelID)
+	}
+}
+
+// Run delivers queued events until the context is cancelled, then delivers what is left in the queue
+func (s *service) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-s.queue:
+			s.deliver(ctx, event)
+		case <-ctx.Done():
+			s.drain()
+			return
+		}
+	}
+}
+
+func (s *service) drain() {
+	for {
+		select {
+		case event := <-s.queue:
+			s.deliver(context.Background(), event)
+		default:
+			return
+		}
+	}
+}
+
+// deliver renders the event for the parcel owner and the carrier and sends it on every channel
+func (s *service) deliver(ctx context.Context, event model.Event) {
+	messages, ok := templates[event.Type]
+	if !ok {
+		return
+	}
+
+	parcel, err := s.parcelRepo.FetchParcelByID(ctx, event.ParcelID)
+	if err != nil {
+		log.Error().Err(err).Msgf("[deliver] failed to fetch parcel %d for event %s. Error: %v", event.ParcelID, event.Type, err)
+		parcel = model.Parcel{ID: event.ParcelID}
+	}
+	if event.UserID == 0 {
+		event.UserID = parcel.UserID
+	}
+	if event.CarrierID == 0 {
+		event.CarrierID = parcel.CarrierID
+	}
+
+	recipients := map[string]int{
+		roleUser:    event.UserID,
+		roleCarrier: event.CarrierID,
+	}
+	data := templateData{Event: event, Parcel: parcel}
+
+	for _, role := range []string{roleUser, roleCarrier} {
+		msg, ok := messages[role]
+		if !ok || recipients[role] == 0 {
+			continue
+		}
+
+		subject, body, err := msg.render(data)
+		if err != nil {
+			log.Error().Err(err).Msgf("[deliver] failed to render %s notification for %s. Error: %v", event.Type, role, err)
+			continue
+		}
+
+		for _, notifier := range s.notifiers {
+			notification := model.Notification{
+				Channel:   notifier.Channel(),
+				Recipient: fmt.Sprintf("%s:%d", role, recipients[role]),
+				Event:     event.Type,
+				Subject:   subject,
+				Body:      body,
+				CreatedAt: time.Now(),
+			}
+			if err := notifier.Send(ctx, notification); err != nil {
+				log.Error().Err(err).Msgf("[deliver] failed to send %s notification to %s. Error: %v", notification.Channel, notification.Recipient, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var parcel = model.Parcel{
+	ID:                 1,
+	UserID:             3,
+	CarrierID:          7,
+	SourceAddress:      "Dhaka Bangladesh",
+	DestinationAddress: "Pabna Shadar",
+	Price:              200,
+}
+
+func TestService_Deliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc     string
+		event    model.Event
+		mockRepo func() *mocks.MockParcelRepository
+		expSent  []model.Notification
+	}{
+		{
+			desc:  "should notify user and carrier of status change",
+			event: model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: model.ParcelStatusPickedUp},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "user:3",
+					Event:     model.EventParcelStatusChanged,
+					Subject:   "Parcel #1 is picked up",
+					Body:      "Your parcel from Dhaka Bangladesh to Pabna Shadar is now picked up.",
+				},
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "carrier:7",
+					Event:     model.EventParcelStatusChanged,
+					Subject:   "Parcel #1 is picked up",
+					Body:      "Parcel #1 from Dhaka Bangladesh to Pabna Shadar is now picked up.",
+				},
+			},
+		},
+		{
+			desc:  "should notify only user of carrier request",
+			event: model.Event{Type: model.EventCarrierRequested, ParcelID: 1, CarrierID: 9},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "user:3",
+					Event:     model.EventCarrierRequested,
+					Subject:   "New carrier request for parcel #1",
+					Body:      "Carrier #9 wants to deliver your parcel from Dhaka Bangladesh to Pabna Shadar.",
+				},
+			},
+		},
+		{
+			desc:  "should notify known recipients when parcel can not be fetched",
+			event: model.Event{Type: model.EventCarrierAssigned, ParcelID: 1, CarrierID: 7},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{}, errors.New("db-error"))
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "carrier:7",
+					Event:     model.EventCarrierAssigned,
+					Subject:   "Parcel #1 assigned to you",
+					Body:      "Pick up parcel #1 at  and deliver it to .",
+				},
+			},
+		},
+		{
+			desc:  "should notify user and carrier of late delivery",
+			event: model.Event{Type: model.EventParcelSLABreached, ParcelID: 1, UserID: 3, CarrierID: 7, Breach: model.SLABreachDelivery},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "user:3",
+					Event:     model.EventParcelSLABreached,
+					Subject:   "Parcel #1 is late",
+					Body:      "Your parcel from Dhaka Bangladesh to Pabna Shadar was not delivered in time. We are looking into it.",
+				},
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "carrier:7",
+					Event:     model.EventParcelSLABreached,
+					Subject:   "Parcel #1 is late",
+					Body:      "Parcel #1 from Dhaka Bangladesh to Pabna Shadar was not delivered in time.",
+				},
+			},
+		},
+		{
+			desc:  "should notify only user of failed delivery",
+			event: model.Event{Type: model.EventDeliveryFailed, ParcelID: 1, UserID: 3, CarrierID: 7, Reason: model.ReasonRecipientAbsent},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "user:3",
+					Event:     model.EventDeliveryFailed,
+					Subject:   "Delivery of parcel #1 failed",
+					Body:      "Your parcel to Pabna Shadar could not be delivered because nobody was there to receive it. The carrier will try again or bring it back to Dhaka Bangladesh.",
+				},
+			},
+		},
+		{
+			desc:  "should notify only user of custody transfer",
+			event: model.Event{Type: model.EventCustodyTransferred, ParcelID: 1, UserID: 3, CarrierID: 8, Leg: 2, Hub: "Bogura Hub"},
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return r
+			},
+			expSent: []model.Notification{
+				{
+					Channel:   model.ChannelSMS,
+					Recipient: "user:3",
+					Event:     model.EventCustodyTransferred,
+					Subject:   "Parcel #1 handed over at Bogura Hub",
+					Body:      "Your parcel to Pabna Shadar was handed over to carrier #8 at Bogura Hub for leg 2.",
+				},
+			},
+		},
+		{
+			desc:  "should skip event without template",
+			event: model.Event{Type: "unknown", ParcelID: 1},
+			mockRepo: func() *mocks.MockParcelRepository {
+				return mocks.NewMockParcelRepository(ctrl)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var sent []model.Notification
+			n := mocks.NewMockNotifier(ctrl)
+			n.EXPECT().Channel().Return(model.ChannelSMS).AnyTimes()
+			n.EXPECT().Send(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, notification model.Notification) error {
+				notification.CreatedAt = time.Time{}
+				sent = append(sent, notification)
+				return nil
+			}).Times(len(tc.expSent))
+
+			s := NewService(tc.mockRepo(), n)
+			s.deliver(context.Background(), tc.event)
+			assert.Equal(t, tc.expSent, sent)
+		})
+	}
+}
+
+func TestService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockParcelRepository(ctrl)
+	r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil).Times(2)
+
+	var buf bytes.Buffer
+	s := NewService(r, NewFileNotifier(model.ChannelEmail, &buf))
+	s.Notify(context.Background(), model.Event{Type: model.EventParcelCreated, ParcelID: 1, UserID: 3})
+	s.Notify(context.Background(), model.Event{Type: model.EventParcelCancelled, ParcelID: 1})
+
+	// a cancelled context still delivers the queued events before Run returns
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 3)
+
+	var notification model.Notification
+	assert.Nil(t, json.Unmarshal(lines[0], &notification))
+	assert.Equal(t, model.ChannelEmail, notification.Channel)
+	assert.Equal(t, "user:3", notification.Recipient)
+	assert.Equal(t, "Parcel #1 created", notification.Subject)
+	assert.Equal(t, "Your parcel from Dhaka Bangladesh to Pabna Shadar has been created. Price: 200.00.", notification.Body)
+}
+
+func TestService_NotifyDropsWhenQueueIsFull(t *testing.T) {
+	s := NewService(nil)
+	for i := 0; i < QUEUE_SIZE+1; i++ {
+		s.Notify(context.Background(), model.Event{Type: model.EventParcelCreated, ParcelID: i})
+	}
+	assert.Len(t, s.queue, QUEUE_SIZE)
+}
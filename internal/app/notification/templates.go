@@ -0,0 +1,144 @@
+package notification
+
+import (
+	"bytes"
+	"parcel-service/internal/app/model"
+	"text/template"
+)
+
+const (
+	roleUser    = "user"
+	roleCarrier = "carrier"
+)
+
+type message struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+type templateData struct {
+	Event  model.Event
+	Parcel model.Parcel
+}
+
+var funcs = template.FuncMap{
+	"status": model.ParcelStatusName,
+	"breach": breachAction,
+	"reason": attemptReason,
+}
+
+func breachAction(kind string) string {
+	if kind == model.SLABreachPickup {
+		return "picked up"
+	}
+	return "delivered"
+}
+
+// attemptReason describes why a delivery failed, the carrier note is not shared with the sender
+func attemptReason(reason string) string {
+	switch reason {
+	case model.ReasonRecipientAbsent:
+		return "nobody was there to receive it"
+	case model.ReasonAddressNotFound:
+		return "the address could not be found"
+	case model.ReasonRefused:
+		return "it was refused"
+	}
+	return "of a problem on the way"
+}
+
+// templates holds the messages of every event per recipient role, roles without a message are not notified
+var templates = map[string]map[string]message{
+	model.EventParcelCreated: {
+		roleUser: newMessage(
+			"Parcel #{{.Parcel.ID}} created",
+			"Your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} has been created. Price: {{printf \"%.2f\" .Parcel.Price}}.",
+		),
+	},
+	model.EventParcelStatusChanged: {
+		roleUser: newMessage(
+			"Parcel #{{.Parcel.ID}} is {{status .Event.Status}}",
+			"Your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} is now {{status .Event.Status}}.",
+		),
+		roleCarrier: newMessage(
+			"Parcel #{{.Parcel.ID}} is {{status .Event.Status}}",
+			"Parcel #{{.Parcel.ID}} from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} is now {{status .Event.Status}}.",
+		),
+	},
+	model.EventParcelCancelled: {
+		roleUser: newMessage(
+			"Parcel #{{.Parcel.ID}} cancelled",
+			"Your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} has been cancelled.",
+		),
+		roleCarrier: newMessage(
+			"Parcel #{{.Parcel.ID}} cancelled",
+			"Parcel #{{.Parcel.ID}} from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} has been cancelled by the sender.",
+		),
+	},
+	model.EventCarrierRequested: {
+		roleUser: newMessage(
+			"New carrier request for parcel #{{.Parcel.ID}}",
+			"Carrier #{{.Event.CarrierID}} wants to deliver your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}}.",
+		),
+	},
+	model.EventCarrierAssigned: {
+		roleUser: newMessage(
+			"Carrier assigned to parcel #{{.Parcel.ID}}",
+			"Carrier #{{.Event.CarrierID}} will deliver your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}}.",
+		),
+		roleCarrier: newMessage(
+			"Parcel #{{.Parcel.ID}} assigned to you",
+			"Pick up parcel #{{.Parcel.ID}} at {{.Parcel.SourceAddress}} and deliver it to {{.Parcel.DestinationAddress}}.",
+		),
+	},
+	model.EventParcelSLABreached: {
+		roleUser: newMessage(
+			"Parcel #{{.Parcel.ID}} is late",
+			"Your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} was not {{breach .Event.Breach}} in time. We are looking into it.",
+		),
+		roleCarrier: newMessage(
+			"Parcel #{{.Parcel.ID}} is late",
+			"Parcel #{{.Parcel.ID}} from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}} was not {{breach .Event.Breach}} in time.",
+		),
+	},
+	model.EventDeliveryFailed: {
+		roleUser: newMessage(
+			"Delivery of parcel #{{.Parcel.ID}} failed",
+			"Your parcel to {{.Parcel.DestinationAddress}} could not be delivered because {{reason .Event.Reason}}. The carrier will try again or bring it back to {{.Parcel.SourceAddress}}.",
+		),
+	},
+	model.EventLegAssigned: {
+		roleUser: newMessage(
+			"Carrier assigned to leg {{.Event.Leg}} of parcel #{{.Parcel.ID}}",
+			"Carrier #{{.Event.CarrierID}} will carry leg {{.Event.Leg}} of your parcel from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}}.",
+		),
+		roleCarrier: newMessage(
+			"Leg {{.Event.Leg}} of parcel #{{.Parcel.ID}} assigned to you",
+			"Carry parcel #{{.Parcel.ID}} on leg {{.Event.Leg}} of its relay from {{.Parcel.SourceAddress}} to {{.Parcel.DestinationAddress}}.",
+		),
+	},
+	model.EventCustodyTransferred: {
+		roleUser: newMessage(
+			"Parcel #{{.Parcel.ID}} handed over at {{.Event.Hub}}",
+			"Your parcel to {{.Parcel.DestinationAddress}} was handed over to carrier #{{.Event.CarrierID}} at {{.Event.Hub}} for leg {{.Event.Leg}}.",
+		),
+	},
+}
+
+func newMessage(subject string, body string) message {
+	return message{
+		subject: template.Must(template.New("subject").Funcs(funcs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(funcs).Parse(body)),
+	}
+}
+
+func (m message) render(data templateData) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := m.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := m.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"context"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// POLL_INTERVAL is the wait between two reads of the outbox when it has no pending messages
+	POLL_INTERVAL = time.Second
+	// BATCH_SIZE is the number of pending messages read at once
+	BATCH_SIZE = 100
+)
+
+type dispatcher struct {
+	repo      svc.OutboxRepository
+	publisher svc.Publisher
+	interval  time.Duration
+}
+
+func NewDispatcher(repo svc.OutboxRepository, publisher svc.Publisher) *dispatcher {
+	return &dispatcher{
+		repo:      repo,
+		publisher: publisher,
+		interval:  POLL_INTERVAL,
+	}
+}
+
+// Run publishes pending outbox messages until the context is cancelled
+func (d *dispatcher) Run(ctx context.Context) {
+	for {
+		sent, err := d.Dispatch(ctx)
+		if err == nil && sent == BATCH_SIZE {
+			// more messages are probably waiting
+			continue
+		}
+
+		select {
+		case <-time.After(d.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Dispatch publishes one batch of pending messages in order and marks them sent. A message is marked
+// only after the publisher accepted it, so a crash in between publishes it again: delivery is at least once.
+// Publishing stops at the first failure to keep the order of the messages.
+func (d *dispatcher) Dispatch(ctx context.Context) (int, error) {
+	messages, err := d.repo.FetchPendingMessages(ctx, BATCH_SIZE)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, message := range messages {
+		if err := d.publisher.Publish(ctx, message.Topic, []byte(message.Payload)); err != nil {
+			log.Error().Err(err).Msgf("[Dispatch] failed to publish outbox message %d Error: %v", message.ID, err)
+			_ = d.repo.MarkMessageFailed(ctx, message.ID, err.Error())
+			return i, err
+		}
+
+		if err := d.repo.MarkMessageSent(ctx, message.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(messages), nil
+}
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	messages := []model.OutboxMessage{
+		{ID: 1, Topic: model.EventParcelStatusChanged, ParcelID: 1, Payload: `{"type":"parcel.status_changed"}`},
+		{ID: 2, Topic: model.EventCarrierAssigned, ParcelID: 1, Payload: `{"type":"carrier.assigned"}`},
+	}
+
+	testCases := []struct {
+		desc          string
+		mockRepo      func() *mocks.MockOutboxRepository
+		mockPublisher func() *mocks.MockPublisher
+		expSent       int
+		expErr        error
+	}{
+		{
+			desc: "should publish and mark messages in order",
+			mockRepo: func() *mocks.MockOutboxRepository {
+				r := mocks.NewMockOutboxRepository(ctrl)
+				r.EXPECT().FetchPendingMessages(gomock.Any(), BATCH_SIZE).Return(messages, nil)
+				gomock.InOrder(
+					r.EXPECT().MarkMessageSent(gomock.Any(), int64(1)).Return(nil),
+					r.EXPECT().MarkMessageSent(gomock.Any(), int64(2)).Return(nil),
+				)
+				return r
+			},
+			mockPublisher: func() *mocks.MockPublisher {
+				p := mocks.NewMockPublisher(ctrl)
+				gomock.InOrder(
+					p.EXPECT().Publish(gomock.Any(), model.EventParcelStatusChanged, []byte(messages[0].Payload)).Return(nil),
+					p.EXPECT().Publish(gomock.Any(), model.EventCarrierAssigned, []byte(messages[1].Payload)).Return(nil),
+				)
+				return p
+			},
+			expSent: 2,
+		},
+		{
+			desc: "should stop at first publish failure",
+			mockRepo: func() *mocks.MockOutboxRepository {
+				r := mocks.NewMockOutboxRepository(ctrl)
+				r.EXPECT().FetchPendingMessages(gomock.Any(), BATCH_SIZE).Return(messages, nil)
+				r.EXPECT().MarkMessageFailed(gomock.Any(), int64(1), "broker-down").Return(nil)
+				return r
+			},
+			mockPublisher: func() *mocks.MockPublisher {
+				p := mocks.NewMockPublisher(ctrl)
+				p.EXPECT().Publish(gomock.Any(), model.EventParcelStatusChanged, gomock.Any()).Return(errors.New("broker-down"))
+				return p
+			},
+			expSent: 0,
+			expErr:  errors.New("broker-down"),
+		},
+		{
+			desc: "should return db error",
+			mockRepo: func() *mocks.MockOutboxRepository {
+				r := mocks.NewMockOutboxRepository(ctrl)
+				r.EXPECT().FetchPendingMessages(gomock.Any(), BATCH_SIZE).Return(nil, errors.New("db-error"))
+				return r
+			},
+			mockPublisher: func() *mocks.MockPublisher {
+				return mocks.NewMockPublisher(ctrl)
+			},
+			expSent: 0,
+			expErr:  errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			d := NewDispatcher(tc.mockRepo(), tc.mockPublisher())
+			sent, err := d.Dispatch(context.Background())
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expSent, sent)
+		})
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	p := NewMemoryPublisher()
+
+	var received []Message
+	p.Subscribe(func(m Message) {
+		received = append(received, m)
+	})
+
+	assert.Nil(t, p.Publish(context.Background(), model.EventParcelCreated, []byte("{}")))
+	assert.Equal(t, []Message{{Topic: model.EventParcelCreated, Payload: []byte("{}")}}, p.Messages())
+	assert.Equal(t, p.Messages(), received)
+}
@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDefaultPort = "4222"
+	natsTimeout     = 5 * time.Second
+)
+
+// natsPublisher publishes to a NATS server with the NATS text protocol. Every publish is followed by a
+// PING and waits for the PONG, so a nil error means the server has received the message.
+type natsPublisher struct {
+	address string
+	user    string
+	pass    string
+	prefix  string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher returns a publisher for a nats://[user:pass@]host[:port] URL, the prefix is prepended to every topic
+func NewNATSPublisher(natsURL string, prefix string) (*natsPublisher, error) {
+	u, err := url.Parse(natsURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %s", natsURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+
+	p := &natsPublisher{
+		address: net.JoinHostPort(u.Hostname(), port),
+		prefix:  prefix,
+	}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.pass, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.publish(ctx, p.prefix+topic, payload); err != nil {
+		p.close()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection to the server
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.close()
+}
+
+func (p *natsPublisher) close() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.reader = nil
+	return err
+}
+
+func (p *natsPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	p.setDeadline(ctx)
+
+	line, err := p.readLine()
+	if err != nil {
+		p.close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		p.close()
+		return fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+
+	options, err := json.Marshal(struct {
+		Verbose  bool   `json:"verbose"`
+		Pedantic bool   `json:"pedantic"`
+		Name     string `json:"name"`
+		Lang     string `json:"lang"`
+		Version  string `json:"version"`
+		User     string `json:"user,omitempty"`
+		Pass     string `json:"pass,omitempty"`
+	}{
+		Name:    "parcel-service",
+		Lang:    "go",
+		Version: "1.0.0",
+		User:    p.user,
+		Pass:    p.pass,
+	})
+	if err != nil {
+		p.close()
+		return err
+	}
+
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\nPING\r\n", options); err != nil {
+		p.close()
+		return err
+	}
+	if err := p.waitPong(); err != nil {
+		p.close()
+		return err
+	}
+	return nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, subject string, payload []byte) error {
+	p.setDeadline(ctx)
+
+	msg := make([]byte, 0, len(subject)+len(payload)+32)
+	msg = append(msg, fmt.Sprintf("PUB %s %d\r\n", subject, len(payload))...)
+	msg = append(msg, payload...)
+	msg = append(msg, "\r\nPING\r\n"...)
+	if _, err := p.conn.Write(msg); err != nil {
+		return err
+	}
+	return p.waitPong()
+}
+
+// waitPong reads until the server answers the PING, answering the PINGs of the server on the way
+func (p *natsPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *natsPublisher) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsTimeout)
+	}
+	p.conn.SetDeadline(deadline)
+}
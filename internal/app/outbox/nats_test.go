@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNATS accepts one connection and answers the NATS text protocol, sending received messages to the channel
+func fakeNATS(t *testing.T, received chan<- string, errOn string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case line == "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "CONNECT "):
+				received <- line
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				io.ReadFull(r, payload)
+				if subject == errOn {
+					fmt.Fprint(conn, "-ERR 'Permissions Violation'\r\n")
+					continue
+				}
+				received <- subject + " " + string(payload[:size])
+			}
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	received := make(chan string, 10)
+	addr := fakeNATS(t, received, "")
+
+	p, err := NewNATSPublisher("nats://parcel:s3cret@"+addr, "parcels.")
+	assert.Nil(t, err)
+	defer p.Close()
+
+	assert.Nil(t, p.Publish(context.Background(), "parcel.created", []byte(`{"parcel_id":1}`)))
+	assert.Nil(t, p.Publish(context.Background(), "parcel.cancelled", []byte(`{"parcel_id":2}`)))
+
+	assert.Contains(t, <-received, `"user":"parcel","pass":"s3cret"`)
+	assert.Equal(t, `parcels.parcel.created {"parcel_id":1}`, <-received)
+	assert.Equal(t, `parcels.parcel.cancelled {"parcel_id":2}`, <-received)
+}
+
+func TestNATSPublisher_PublishError(t *testing.T) {
+	received := make(chan string, 10)
+	addr := fakeNATS(t, received, "parcel.created")
+
+	p, err := NewNATSPublisher("nats://"+addr, "")
+	assert.Nil(t, err)
+	defer p.Close()
+
+	err = p.Publish(context.Background(), "parcel.created", []byte(`{}`))
+	assert.EqualError(t, err, "NATS error: 'Permissions Violation'")
+}
+
+func TestNewNATSPublisher(t *testing.T) {
+	p, err := NewNATSPublisher("nats://localhost", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:4222", p.address)
+
+	_, err = NewNATSPublisher("http://localhost:4222", "")
+	assert.EqualError(t, err, "invalid NATS URL http://localhost:4222")
+}
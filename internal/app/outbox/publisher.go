@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Message is a payload published to a topic
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// memoryPublisher keeps published messages in process and passes them to its subscribers,
+// for development and tests without a message broker
+type memoryPublisher struct {
+	mu          sync.Mutex
+	messages    []Message
+	subscribers []func(Message)
+}
+
+func NewMemoryPublisher() *memoryPublisher {
+	return &memoryPublisher{}
+}
+
+// Subscribe registers a function called with every published message
+func (p *memoryPublisher) Subscribe(fn func(Message)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, fn)
+}
+
+func (p *memoryPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	message := Message{Topic: topic, Payload: payload}
+
+	p.mu.Lock()
+	p.messages = append(p.messages, message)
+	subscribers := p.subscribers
+	p.mu.Unlock()
+
+	log.Debug().Msgf("[Publish] %s: %s", topic, payload)
+	for _, fn := range subscribers {
+		fn(message)
+	}
+	return nil
+}
+
+// Messages returns the messages published so far
+func (p *memoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
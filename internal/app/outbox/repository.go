@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"parcel-service/internal/app/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	insertMessageQuery        = `INSERT INTO outbox (topic, parcel_id, payload) VALUES ($1, $2, $3)`
+	fetchPendingMessagesQuery = `SELECT id, topic, parcel_id, payload, attempts, last_error, created_at, sent_at FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1`
+	markMessageSentQuery      = `UPDATE outbox SET sent_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = '' WHERE id = $1`
+	markMessageFailedQuery    = `UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2`
+)
+
+// Execer is satisfied by the database transactions the outbox message is written in
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write stores the event in the outbox within the transaction of the state change, so the event
+// exists exactly when the change is committed
+func Write(ctx context.Context, tx Execer, event model.Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, insertMessageQuery, event.Type, event.ParcelID, string(payload)); err != nil {
+		log.Error().Err(err).Msgf("[Write] failed to write %s event of parcel %d to outbox Error: %v", event.Type, event.ParcelID, err)
+		return err
+	}
+	return nil
+}
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates outbox repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) FetchPendingMessages(ctx context.Context, limit int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+
+	if err := r.db.SelectContext(ctx, &messages, fetchPendingMessagesQuery, limit); err != nil {
+		log.Error().Err(err).Msgf("[FetchPendingMessages] failed to fetch outbox messages Error: %v", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *repository) MarkMessageSent(ctx context.Context, messageID int64) error {
+	if _, err := r.db.ExecContext(ctx, markMessageSentQuery, messageID); err != nil {
+		log.Error().Err(err).Msgf("[MarkMessageSent] failed to mark outbox message %d sent Error: %v", messageID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) MarkMessageFailed(ctx context.Context, messageID int64, reason string) error {
+	if _, err := r.db.ExecContext(ctx, markMessageFailedQuery, reason, messageID); err != nil {
+		log.Error().Err(err).Msgf("[MarkMessageFailed] failed to record outbox message %d failure Error: %v", messageID, err)
+		return err
+	}
+	return nil
+}
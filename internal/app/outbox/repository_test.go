@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	occurredAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	event := model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: model.ParcelStatusDelivered, OccurredAt: occurredAt}
+
+	t.Run("should write event in transaction", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(insertMessageQuery)).
+			WithArgs(model.EventParcelStatusChanged, 1, `{"type":"parcel.status_changed","parcel_id":1,"status":4,"occurred_at":"2020-04-11T21:34:01Z"}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		tx, _ := sqlxDB.Beginx()
+		err := Write(context.Background(), tx, event)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec(regexp.QuoteMeta(insertMessageQuery)).WillReturnError(errors.New("sql-error"))
+
+		tx, _ := sqlxDB.Beginx()
+		err := Write(context.Background(), tx, event)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchPendingMessages(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchPendingMessagesQuery)).WithArgs(BATCH_SIZE).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "parcel_id", "payload", "attempts", "last_error", "sent_at"}).
+			AddRow(1, model.EventParcelCreated, 1, "{}", 0, "", nil))
+
+	repo := NewRepository(sqlxDB)
+	messages, err := repo.FetchPendingMessages(context.Background(), BATCH_SIZE)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.OutboxMessage{{ID: 1, Topic: model.EventParcelCreated, ParcelID: 1, Payload: "{}"}}, messages)
+}
+
+func TestRepository_MarkMessage(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectExec(regexp.QuoteMeta(markMessageSentQuery)).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectExec(regexp.QuoteMeta(markMessageFailedQuery)).WithArgs("broker-down", int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(sqlxDB)
+	assert.Nil(t, repo.MarkMessageSent(context.Background(), 1))
+	assert.Nil(t, repo.MarkMessageFailed(context.Background(), 2, "broker-down"))
+	assert.Nil(t, m.ExpectationsWereMet())
+}
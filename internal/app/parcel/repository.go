@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"parcel-service/internal/app/audit"
 	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/outbox"
+	"parcel-service/internal/app/promotion"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -13,11 +16,23 @@ import (
 
 // SQL Query and error
 const (
-	errUniqueViolation   = pq.ErrorCode("23505")
-	getParcelListQuery   = `SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, type, price, carrier_fee, company_fee, created_at, updated_at FROM parcel WHERE status=$1 LIMIT $2 OFFSET $3`
-	insertParcelQuery    = `INSERT INTO parcel (user_id, source_address, destination_address, source_time, type, price, carrier_fee, company_fee) VALUES (:user_id, :source_address, :destination_address, :source_time, :type, :price, :carrier_fee, :company_fee) RETURNING id, created_at, updated_at`
-	fetchParcelByIDQuery = `SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, type, price, carrier_fee, company_fee, created_at, updated_at FROM parcel WHERE id = $1`
-	updateParcelQuery    = `UPDATE parcel SET status = $1 WHERE id = $2`
+	errUniqueViolation    = pq.ErrorCode("23505")
+	getParcelListQuery    = `SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, assigned_at, picked_up_at, delivered_at, pickup_deadline, delivery_deadline, COALESCE(return_of, 0) AS return_of, COALESCE(shipment_id, 0) AS shipment_id, stop, created_at, updated_at FROM parcel WHERE status=$1 LIMIT $2 OFFSET $3`
+	insertParcelQuery     = `INSERT INTO parcel (user_id, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, pickup_deadline, delivery_deadline) VALUES (:user_id, :source_address, :destination_address, :source_time, :source_latitude, :source_longitude, :destination_latitude, :destination_longitude, :weight, :declared_value, :auto_dispatch, :pickup_window_start, :pickup_window_end, :delivery_window_start, :delivery_window_end, :type, :price, :carrier_fee, :company_fee, :discount, :region, :tax, :tax_name, :tax_rate, :tax_inclusive, :pickup_deadline, :delivery_deadline) RETURNING id, created_at, updated_at`
+	fetchParcelByIDQuery  = `SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, assigned_at, picked_up_at, delivered_at, pickup_deadline, delivery_deadline, COALESCE(return_of, 0) AS return_of, COALESCE(shipment_id, 0) AS shipment_id, stop, created_at, updated_at FROM parcel WHERE id = $1`
+	updateParcelQuery     = `UPDATE parcel SET status = $1, picked_up_at = COALESCE(picked_up_at, $3), delivered_at = COALESCE(delivered_at, $4) WHERE id = $2`
+	claimPromotionQuery   = `UPDATE promotion SET used_count = used_count + 1 WHERE id = $1 AND (max_uses = 0 OR used_count < max_uses) RETURNING per_user_limit`
+	insertRedemptionQuery = `INSERT INTO promotion_redemption (promotion_id, parcel_id, user_id, discount) VALUES ($1, $2, $3, $4)`
+	insertPaymentQuery    = `INSERT INTO payment (parcel_id, user_id, amount) VALUES ($1, $2, $3)`
+	cancelParcelQuery     = `UPDATE parcel SET status = $1 WHERE id = $2 AND status = $3`
+	rejectRequestsQuery   = `UPDATE carrier_request SET status = $1 WHERE parcel_id = $2 AND status = $3`
+	insertCancelQuery     = `INSERT INTO parcel_cancellation (parcel_id, previous_status, reason, fee, refund, carrier_compensation) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	insertRefundQuery     = `INSERT INTO refund (payment_id, parcel_id, amount, reason) SELECT id, parcel_id, $2, $3 FROM payment WHERE parcel_id = $1`
+	insertCompensateQuery = `INSERT INTO carrier_compensation (parcel_id, carrier_id, amount) VALUES ($1, $2, $3)`
+	lockParcelQuery       = `SELECT status FROM parcel WHERE id = $1 FOR UPDATE`
+	insertAttemptQuery    = `INSERT INTO delivery_attempt (parcel_id, carrier_id, attempt, reason, note) SELECT $1, $2, COUNT(*) + 1, $3, $4 FROM delivery_attempt WHERE parcel_id = $1 RETURNING id, attempt, created_at`
+	returnParcelQuery     = `UPDATE parcel SET status = $1 WHERE id = $2`
+	insertReturnQuery     = `INSERT INTO parcel (user_id, carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, type, price, carrier_fee, company_fee, region, assigned_at, picked_up_at, pickup_deadline, delivery_deadline, return_of) VALUES (:user_id, :carrier_id, :status, :source_address, :destination_address, :source_time, :source_latitude, :source_longitude, :destination_latitude, :destination_longitude, :weight, :declared_value, :type, :price, :carrier_fee, :company_fee, :region, :assigned_at, :picked_up_at, :pickup_deadline, :delivery_deadline, :return_of) RETURNING id, created_at, updated_at`
 )
 
 type repository struct {
@@ -31,10 +46,18 @@ func NewRepository(db *sqlx.DB) *repository {
 	}
 }
 
+// InsertParcel stores the parcel with its payment and, when a promotion is applied, records its redemption in the same transaction
 func (r *repository) InsertParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error) {
-	stmt, err := r.db.PrepareNamedContext(ctx, insertParcelQuery)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertParcel] failed to begin transaction")
+		return model.Parcel{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertParcelQuery)
 
 	if err != nil {
+		tx.Rollback()
 		log.Error().Err(err).Msgf("[InsertParcel] PrepareNamedContext Error: %v", err)
 		return model.Parcel{}, err
 	}
@@ -42,6 +65,7 @@ func (r *repository) InsertParcel(ctx context.Context, parcel model.Parcel) (mod
 	err = stmt.GetContext(ctx, &parcel, &parcel)
 
 	if err != nil {
+		tx.Rollback()
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
 			return model.Parcel{}, fmt.Errorf("%v :%w", err, model.ErrInvalid)
 		}
@@ -49,9 +73,120 @@ func (r *repository) InsertParcel(ctx context.Context, parcel model.Parcel) (mod
 		return model.Parcel{}, err
 	}
 
+	if _, err := tx.ExecContext(ctx, insertPaymentQuery, parcel.ID, parcel.UserID, parcel.Price); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertParcel] failed to insert payment Error: %v", err)
+		return model.Parcel{}, err
+	}
+
+	if parcel.PromotionID != 0 {
+		if err := redeemPromotion(ctx, tx, parcel); err != nil {
+			tx.Rollback()
+			return model.Parcel{}, err
+		}
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditParcel, parcel.ID, nil, parcel); err != nil {
+		tx.Rollback()
+		return model.Parcel{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("[InsertParcel] Failed to commit")
+		return model.Parcel{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
 	return parcel, nil
 }
 
+// InsertParcels stores a batch of priced parcels with their payments and promotion redemptions in one transaction,
+// so either every parcel of the batch is stored or none is
+func (r *repository) InsertParcels(ctx context.Context, parcels []model.Parcel) ([]model.Parcel, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertParcels] failed to begin transaction")
+		return nil, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertParcelQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertParcels] PrepareNamedContext Error: %v", err)
+		return nil, err
+	}
+
+	inserted := make([]model.Parcel, len(parcels))
+	copy(inserted, parcels)
+	for i := range inserted {
+		parcel := &inserted[i]
+		if err := stmt.GetContext(ctx, parcel, parcel); err != nil {
+			tx.Rollback()
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+				return nil, fmt.Errorf("%v :%w", err, model.ErrInvalid)
+			}
+			log.Error().Err(err).Msgf("[InsertParcels] GetContext Error: %v", err)
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, insertPaymentQuery, parcel.ID, parcel.UserID, parcel.Price); err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[InsertParcels] failed to insert payment Error: %v", err)
+			return nil, err
+		}
+
+		if parcel.PromotionID != 0 {
+			if err := redeemPromotion(ctx, tx, *parcel); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+
+		if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditParcel, parcel.ID, nil, *parcel); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("[InsertParcels] Failed to commit")
+		return nil, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	return inserted, nil
+}
+
+// redeemPromotion claims one use of the parcel's promotion. The claim locks the promotion row,
+// so the usage limits are checked against concurrent orders as well.
+func redeemPromotion(ctx context.Context, tx *sqlx.Tx, parcel model.Parcel) error {
+	var perUserLimit int
+	if err := tx.GetContext(ctx, &perUserLimit, claimPromotionQuery, parcel.PromotionID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("promo code %s is fully redeemed :%w", parcel.PromoCode, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertParcel] failed to claim promotion Error: %v", err)
+		return err
+	}
+
+	if perUserLimit > 0 {
+		count, err := promotion.CountUserRedemptions(ctx, tx, parcel.PromotionID, parcel.UserID)
+		if err != nil {
+			return err
+		}
+		if count >= perUserLimit {
+			return fmt.Errorf("promo code %s is already used by the user :%w", parcel.PromoCode, model.ErrInvalid)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, insertRedemptionQuery, parcel.PromotionID, parcel.ID, parcel.UserID, parcel.Discount); err != nil {
+		log.Error().Err(err).Msgf("[InsertParcel] failed to insert redemption Error: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func (r *repository) GetParcelsList(ctx context.Context, status int, limit int, offset int) ([]model.Parcel, error) {
 	var parcels []model.Parcel
 	if err := r.db.SelectContext(ctx, &parcels, getParcelListQuery, status, limit, offset); err != nil {
@@ -79,28 +214,251 @@ func (r *repository) FetchParcelByID(ctx context.Context, parcelID int) (model.P
 	return parcel, nil
 }
 
+// UpdateParcel changes the parcel status and writes the status change to the outbox and the audit log in the same transaction
 func (r *repository) UpdateParcel(ctx context.Context, parcel model.Parcel) error {
-	result, err := r.db.ExecContext(ctx, updateParcelQuery, parcel.Status, parcel.ID)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msgf("[UpdateParcel] failed to begin transaction Error: %v", err)
+		return fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	if _, err := UpdateStatus(ctx, tx, parcel); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[UpdateParcel] failed to commit Error: %v", err)
+		return fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	return nil
+}
+
+// UpdateStatus changes the parcel status inside the transaction of the caller, writes the change to the outbox
+// and the audit log and returns the status the parcel had before. The caller rolls back on error.
+func UpdateStatus(ctx context.Context, tx *sqlx.Tx, parcel model.Parcel) (int, error) {
+	var previousStatus int
+	if err := tx.GetContext(ctx, &previousStatus, lockParcelQuery, parcel.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("parcel %d not updated, please provide valid ID. :%w", parcel.ID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[UpdateStatus] failed to lock parcel Error: %v", err)
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, updateParcelQuery, parcel.Status, parcel.ID, parcel.PickedUpAt, parcel.DeliveredAt)
 
 	if err != nil {
-		log.Error().Err(err).Msgf("[UpdateParcel] failed to update parcel Error: %v", err)
+		log.Error().Err(err).Msgf("[UpdateStatus] failed to update parcel Error: %v", err)
 
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
-			return fmt.Errorf("%v :%w", err, model.ErrInvalid)
+			return 0, fmt.Errorf("%v :%w", err, model.ErrInvalid)
 		}
 
-		return err
+		return 0, err
 	}
 
 	rows, err := result.RowsAffected()
 
 	if err != nil {
-		return fmt.Errorf("%v :%w", err, model.ErrInvalid)
+		return 0, fmt.Errorf("%v :%w", err, model.ErrInvalid)
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("parcel %d not updated, please provide valid ID. :%w", parcel.ID, model.ErrNotFound)
+		return 0, fmt.Errorf("parcel %d not updated, please provide valid ID. :%w", parcel.ID, model.ErrNotFound)
 	}
 
-	return nil
+	if err := outbox.Write(ctx, tx, model.Event{
+		Type:     model.EventParcelStatusChanged,
+		ParcelID: parcel.ID,
+		Status:   parcel.Status,
+	}); err != nil {
+		return 0, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditParcel, parcel.ID,
+		map[string]interface{}{"status": previousStatus},
+		map[string]interface{}{"status": parcel.Status, "picked_up_at": parcel.PickedUpAt, "delivered_at": parcel.DeliveredAt},
+	); err != nil {
+		return 0, err
+	}
+
+	return previousStatus, nil
+}
+
+// CancelParcel cancels the parcel if its status has not changed since the cancellation was calculated,
+// and records the refund against the parcel payment and the compensation of the assigned carrier
+func (r *repository) CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[CancelParcel] failed to begin transaction")
+		return model.Cancellation{}, err
+	}
+
+	result, err := tx.ExecContext(ctx, cancelParcelQuery, model.ParcelStatusCancelled, cancellation.ParcelID, cancellation.PreviousStatus)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[CancelParcel] failed to update parcel status Error: %v", err)
+		return model.Cancellation{}, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return model.Cancellation{}, fmt.Errorf("%v :%w", err, model.ErrInvalid)
+	}
+
+	if rows == 0 {
+		tx.Rollback()
+		return model.Cancellation{}, fmt.Errorf("parcel %d status has changed, please try again. :%w", cancellation.ParcelID, model.ErrInvalid)
+	}
+
+	if _, err := tx.ExecContext(ctx, rejectRequestsQuery, model.CarrierRequestRejected, cancellation.ParcelID, model.CarrierRequestPending); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[CancelParcel] failed to reject carrier requests Error: %v", err)
+		return model.Cancellation{}, err
+	}
+
+	if err := tx.GetContext(ctx, &cancellation.CreatedAt, insertCancelQuery, cancellation.ParcelID, cancellation.PreviousStatus,
+		cancellation.Reason, cancellation.Fee, cancellation.Refund, cancellation.CarrierCompensation); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[CancelParcel] failed to insert cancellation Error: %v", err)
+		return model.Cancellation{}, err
+	}
+
+	if cancellation.Refund > 0 {
+		result, err := tx.ExecContext(ctx, insertRefundQuery, cancellation.ParcelID, cancellation.Refund, cancellation.Reason)
+		if err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[CancelParcel] failed to insert refund Error: %v", err)
+			return model.Cancellation{}, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return model.Cancellation{}, err
+		}
+		if rows == 0 {
+			tx.Rollback()
+			return model.Cancellation{}, fmt.Errorf("parcel %d has no payment to refund :%w", cancellation.ParcelID, model.ErrInvalid)
+		}
+	}
+
+	if cancellation.CarrierCompensation > 0 {
+		if _, err := tx.ExecContext(ctx, insertCompensateQuery, cancellation.ParcelID, cancellation.CarrierID, cancellation.CarrierCompensation); err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[CancelParcel] failed to insert carrier compensation Error: %v", err)
+			return model.Cancellation{}, err
+		}
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCancel, model.AuditParcel, cancellation.ParcelID,
+		map[string]interface{}{"status": cancellation.PreviousStatus},
+		map[string]interface{}{"status": model.ParcelStatusCancelled, "reason": cancellation.Reason, "fee": cancellation.Fee, "refund": cancellation.Refund},
+	); err != nil {
+		tx.Rollback()
+		return model.Cancellation{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("[CancelParcel] Failed to commit")
+		return model.Cancellation{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+
+	return cancellation, nil
+}
+
+// RecordFailedAttempt stores a failed delivery attempt of a picked up parcel and numbers it after the earlier attempts.
+// When the attempt reaches maxAttempts and a return parcel is given, the parcel is marked returned and the return
+// parcel is created with its payment in the same transaction.
+func (r *repository) RecordFailedAttempt(ctx context.Context, attempt model.DeliveryAttempt, maxAttempts int, returnParcel *model.Parcel) (model.DeliveryAttempt, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[RecordFailedAttempt] failed to begin transaction")
+		return model.DeliveryAttempt{}, err
+	}
+
+	// the lock keeps concurrent attempts from getting the same number and the parcel from being returned twice
+	var status int
+	if err := tx.GetContext(ctx, &status, lockParcelQuery, attempt.ParcelID); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return model.DeliveryAttempt{}, fmt.Errorf("parcel with the ID %d is not found. :%w", attempt.ParcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] failed to lock parcel Error: %v", err)
+		return model.DeliveryAttempt{}, err
+	}
+	if status != model.ParcelStatusPickedUp {
+		tx.Rollback()
+		return model.DeliveryAttempt{}, fmt.Errorf("parcel %d is %s, only picked up parcels can fail delivery :%w", attempt.ParcelID, model.ParcelStatusName(status), model.ErrInvalid)
+	}
+
+	err = tx.QueryRowContext(ctx, insertAttemptQuery, attempt.ParcelID, attempt.CarrierID, attempt.Reason, attempt.Note).
+		Scan(&attempt.ID, &attempt.Attempt, &attempt.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] failed to insert attempt Error: %v", err)
+		return model.DeliveryAttempt{}, err
+	}
+
+	if returnParcel != nil && attempt.Attempt >= maxAttempts {
+		if err := insertReturn(ctx, tx, returnParcel); err != nil {
+			tx.Rollback()
+			return model.DeliveryAttempt{}, err
+		}
+		attempt.ReturnParcelID = returnParcel.ID
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditDeliveryAttempt, attempt.ID, nil, attempt); err != nil {
+		tx.Rollback()
+		return model.DeliveryAttempt{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[RecordFailedAttempt] failed to commit")
+		return model.DeliveryAttempt{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return attempt, nil
+}
+
+// insertReturn marks the original parcel returned and creates the return parcel with its payment
+func insertReturn(ctx context.Context, tx *sqlx.Tx, returnParcel *model.Parcel) error {
+	if _, err := tx.ExecContext(ctx, returnParcelQuery, model.ParcelStatusReturned, returnParcel.ReturnOf); err != nil {
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] failed to mark parcel returned Error: %v", err)
+		return err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertReturnQuery)
+	if err != nil {
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] PrepareNamedContext Error: %v", err)
+		return err
+	}
+	if err := stmt.GetContext(ctx, returnParcel, returnParcel); err != nil {
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] failed to insert return parcel Error: %v", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, insertPaymentQuery, returnParcel.ID, returnParcel.UserID, returnParcel.Price); err != nil {
+		log.Error().Err(err).Msgf("[RecordFailedAttempt] failed to insert payment Error: %v", err)
+		return err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditParcel, returnParcel.ID, nil, *returnParcel); err != nil {
+		return err
+	}
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditParcel, returnParcel.ReturnOf,
+		map[string]interface{}{"status": model.ParcelStatusPickedUp},
+		map[string]interface{}{"status": model.ParcelStatusReturned},
+	); err != nil {
+		return err
+	}
+
+	return outbox.Write(ctx, tx, model.Event{
+		Type:     model.EventParcelStatusChanged,
+		ParcelID: returnParcel.ReturnOf,
+		Status:   model.ParcelStatusReturned,
+	})
 }
@@ -36,6 +36,7 @@ func TestRepository_InsertParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
 		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().
 			WillReturnRows(sqlmock.NewRows([]string{"user_id",
 				"source_address",
@@ -57,6 +58,11 @@ func TestRepository_InsertParcel(t *testing.T) {
 					parcel.CompanyFee,
 					parcel.CreatedAt,
 					parcel.UpdatedAt))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
 
 		repo := NewRepository(sqlxDB)
 		result, err := repo.InsertParcel(context.Background(), parcel)
@@ -70,6 +76,7 @@ func TestRepository_InsertParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
 		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().
 			WillReturnError(&pq.Error{Code: "23505"})
 
@@ -84,6 +91,7 @@ func TestRepository_InsertParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
 		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().
 			WillReturnError(errors.New("sql-error"))
 
@@ -98,14 +106,236 @@ func TestRepository_InsertParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
 		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").
 			WillReturnError(&pq.Error{})
+		m.ExpectRollback()
 
 		repo := NewRepository(sqlxDB)
 		result, err := repo.InsertParcel(context.Background(), parcel)
 		assert.EqualError(t, err, "pq: ")
 		assert.Equal(t, result, model.Parcel{})
 	})
+
+	t.Run("should return payment insert error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, parcel.CreatedAt, parcel.UpdatedAt))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WithArgs(1, parcel.UserID, parcel.Price).
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertParcel(context.Background(), parcel)
+		assert.EqualError(t, err, "sql-error")
+		assert.Equal(t, result, model.Parcel{})
+	})
+
+	t.Run("should return begin transaction error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin().WillReturnError(errors.New("begin-error"))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertParcel(context.Background(), parcel)
+		assert.EqualError(t, err, "begin-error")
+		assert.Equal(t, result, model.Parcel{})
+	})
+
+	t.Run("should return commit error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, parcel.CreatedAt, parcel.UpdatedAt))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit().WillReturnError(errors.New("commit-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcel(context.Background(), parcel)
+		assert.True(t, errors.Is(err, model.IntServerErr))
+	})
+}
+
+func TestRepository_InsertParcelWithPromotion(t *testing.T) {
+	parcel := model.Parcel{
+		ID:                 1,
+		UserID:             1,
+		SourceAddress:      "Dhaka Bangladesh",
+		DestinationAddress: "Pabna Shadar",
+		ParcelType:         "Document",
+		Price:              180.0,
+		CarrierFee:         180.0,
+		CompanyFee:         20.0,
+		Discount:           20.0,
+		PromoCode:          "WELCOME10",
+		PromotionID:        7,
+	}
+	insertRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(parcel.ID, time.Now(), time.Now())
+	}
+
+	t.Run("should record redemption", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().WillReturnRows(insertRows())
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("UPDATE promotion SET (.+) WHERE (.+) RETURNING per_user_limit").
+			WithArgs(parcel.PromotionID).
+			WillReturnRows(sqlmock.NewRows([]string{"per_user_limit"}).AddRow(1))
+		m.ExpectQuery("SELECT COUNT(.+) FROM promotion_redemption WHERE (.+)").
+			WithArgs(parcel.PromotionID, parcel.UserID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		m.ExpectExec("INSERT INTO promotion_redemption (.+) VALUES (.+)").
+			WithArgs(parcel.PromotionID, parcel.ID, parcel.UserID, parcel.Discount).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcel(context.Background(), parcel)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return fully redeemed error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().WillReturnRows(insertRows())
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("UPDATE promotion SET (.+) WHERE (.+) RETURNING per_user_limit").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcel(context.Background(), parcel)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return per user limit error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().WillReturnRows(insertRows())
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("UPDATE promotion SET (.+) WHERE (.+) RETURNING per_user_limit").
+			WillReturnRows(sqlmock.NewRows([]string{"per_user_limit"}).AddRow(1))
+		m.ExpectQuery("SELECT COUNT(.+) FROM promotion_redemption WHERE (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcel(context.Background(), parcel)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return redemption insert error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+").ExpectQuery().WillReturnRows(insertRows())
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("UPDATE promotion SET (.+) WHERE (.+) RETURNING per_user_limit").
+			WillReturnRows(sqlmock.NewRows([]string{"per_user_limit"}).AddRow(0))
+		m.ExpectExec("INSERT INTO promotion_redemption (.+) VALUES (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertParcel(context.Background(), parcel)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_InsertParcels(t *testing.T) {
+	parcels := []model.Parcel{
+		{UserID: 1, SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Pabna Shadar", ParcelType: "Document", Price: 200},
+		{UserID: 1, SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Rangpur", ParcelType: "Box", Price: 200},
+	}
+
+	t.Run("should store the batch in one transaction", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		prepare := m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+")
+		prepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WithArgs(10, 1, 200.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		prepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WithArgs(11, 1, 200.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertParcels(context.Background(), parcels)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, result[0].ID)
+		assert.Equal(t, 11, result[1].ID)
+		assert.Equal(t, 0, parcels[0].ID)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back the batch on error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		prepare := m.ExpectPrepare("INSERT INTO parcel (.+) VALUES (.+) RETURNING .+")
+		prepare.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		m.ExpectExec("INSERT INTO payment (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		prepare.ExpectQuery().WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertParcels(context.Background(), parcels)
+
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
 }
 
 func TestRepository_GetParcelsList(t *testing.T) {
@@ -155,7 +385,7 @@ func TestRepository_GetParcelsList(t *testing.T) {
 		limit = 2
 		offset = 0
 
-		m.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, type, price, carrier_fee, company_fee, created_at, updated_at FROM parcel WHERE status=$1 LIMIT $2 OFFSET $3")).
+		m.ExpectQuery(regexp.QuoteMeta("SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, assigned_at, picked_up_at, delivered_at, pickup_deadline, delivery_deadline, COALESCE(return_of, 0) AS return_of, COALESCE(shipment_id, 0) AS shipment_id, stop, created_at, updated_at FROM parcel WHERE status=$1 LIMIT $2 OFFSET $3")).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "carrier_id", "status", "source_address", "destination_address", "source_time", "type", "price", "carrier_fee", "company_fee", "created_at", "updated_at"}).
 				AddRow(parcels[0].ID, parcels[0].UserID, parcels[0].CarrierID, parcels[0].Status, parcels[0].SourceAddress, parcels[0].DestinationAddress, parcels[0].SourceTime, parcels[0].ParcelType, parcels[0].Price, parcels[0].CarrierFee, parcels[0].CompanyFee, parcels[0].CreatedAt, parcels[0].UpdatedAt).
 				AddRow(parcels[1].ID, parcels[1].UserID, parcels[1].CarrierID, parcels[1].Status, parcels[1].SourceAddress, parcels[1].DestinationAddress, parcels[1].SourceTime, parcels[1].ParcelType, parcels[1].Price, parcels[1].CarrierFee, parcels[1].CompanyFee, parcels[1].CreatedAt, parcels[1].UpdatedAt))
@@ -304,19 +534,124 @@ func TestRepository_UpdateParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WithArgs(model.EventParcelStatusChanged, parcel.ID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, parcel.ID, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
 
 		repo := NewRepository(sqlxDB)
 		err := repo.UpdateParcel(context.Background(), parcel)
 		assert.Nil(t, err)
 	})
 
+	t.Run("should keep the first pickup time", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		pickedUpAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+		pickedUp := parcel
+		pickedUp.Status = model.ParcelStatusPickedUp
+		pickedUp.PickedUpAt = &pickedUpAt
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
+		m.ExpectExec(regexp.QuoteMeta("UPDATE parcel SET status = $1, picked_up_at = COALESCE(picked_up_at, $3), delivered_at = COALESCE(delivered_at, $4) WHERE id = $2")).
+			WithArgs(model.ParcelStatusPickedUp, parcel.ID, pickedUpAt, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WithArgs(model.EventParcelStatusChanged, parcel.ID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, parcel.ID, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateParcel(context.Background(), pickedUp)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should rollback when outbox write fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateParcel(context.Background(), parcel)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should rollback when audit write fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateParcel(context.Background(), parcel)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found when the parcel does not exist", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.UpdateParcel(context.Background(), parcel)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
 	t.Run("should return invalid ID", func(t *testing.T) {
 		db, m, _ := sqlmock.New()
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
 			WillReturnResult(sqlmock.NewResult(1, 0))
 
@@ -330,6 +665,10 @@ func TestRepository_UpdateParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
 			WillReturnResult(sqlmock.NewErrorResult(model.ErrInvalid))
 
@@ -343,6 +682,10 @@ func TestRepository_UpdateParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
 			WillReturnError(&pq.Error{Code: "23505"})
 
@@ -356,6 +699,10 @@ func TestRepository_UpdateParcel(t *testing.T) {
 		defer db.Close()
 
 		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(parcel.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusAssigned))
 		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
 			WillReturnError(errors.New("sql-error"))
 
@@ -364,3 +711,319 @@ func TestRepository_UpdateParcel(t *testing.T) {
 		assert.EqualError(t, err, "sql-error")
 	})
 }
+
+func TestRepository_CancelParcel(t *testing.T) {
+	createdAt := time.Now()
+	cancellation := model.Cancellation{
+		ParcelID:            1,
+		PreviousStatus:      model.ParcelStatusAssigned,
+		Reason:              "changed my mind",
+		Fee:                 30,
+		Refund:              170,
+		CarrierID:           9,
+		CarrierCompensation: 30,
+	}
+
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WithArgs(model.ParcelStatusCancelled, cancellation.ParcelID, cancellation.PreviousStatus).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+)").
+			WithArgs(model.CarrierRequestRejected, cancellation.ParcelID, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(1, 2))
+		m.ExpectQuery("INSERT INTO parcel_cancellation (.+) VALUES (.+) RETURNING created_at").
+			WithArgs(cancellation.ParcelID, cancellation.PreviousStatus, cancellation.Reason, cancellation.Fee, cancellation.Refund, cancellation.CarrierCompensation).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		m.ExpectExec("INSERT INTO refund (.+) SELECT (.+) FROM payment WHERE (.+)").
+			WithArgs(cancellation.ParcelID, cancellation.Refund, cancellation.Reason).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO carrier_compensation (.+) VALUES (.+)").
+			WithArgs(cancellation.ParcelID, cancellation.CarrierID, cancellation.CarrierCompensation).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCancel, model.AuditParcel, cancellation.ParcelID, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.CancelParcel(context.Background(), cancellation)
+
+		expected := cancellation
+		expected.CreatedAt = createdAt
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should skip refund and compensation when there is nothing to pay", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		free := model.Cancellation{ParcelID: 1, PreviousStatus: model.ParcelStatusCreated, Reason: "changed my mind"}
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("INSERT INTO parcel_cancellation (.+) VALUES (.+) RETURNING created_at").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CancelParcel(context.Background(), free)
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid when status has changed", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CancelParcel(context.Background(), cancellation)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+	})
+
+	t.Run("should refuse when there is no payment to refund", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("INSERT INTO parcel_cancellation (.+) VALUES (.+) RETURNING created_at").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		m.ExpectExec("INSERT INTO refund (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CancelParcel(context.Background(), cancellation)
+		assert.EqualError(t, err, "parcel 1 has no payment to refund :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return refund insert error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("INSERT INTO parcel_cancellation (.+) VALUES (.+) RETURNING created_at").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		m.ExpectExec("INSERT INTO refund (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CancelParcel(context.Background(), cancellation)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return commit error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		free := model.Cancellation{ParcelID: 1, PreviousStatus: model.ParcelStatusCreated, Reason: "changed my mind"}
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("UPDATE parcel SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET (.+) WHERE (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		m.ExpectQuery("INSERT INTO parcel_cancellation (.+) VALUES (.+) RETURNING created_at").
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit().WillReturnError(errors.New("commit-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CancelParcel(context.Background(), free)
+		assert.True(t, errors.Is(err, model.IntServerErr))
+	})
+}
+
+func TestRepository_RecordFailedAttempt(t *testing.T) {
+	createdAt := time.Now()
+	attempt := model.DeliveryAttempt{ParcelID: 1, CarrierID: 9, Reason: model.ReasonRecipientAbsent}
+	returnParcel := model.Parcel{
+		UserID:             3,
+		CarrierID:          9,
+		Status:             model.ParcelStatusPickedUp,
+		SourceAddress:      "Pabna Shadar",
+		DestinationAddress: "Dhaka Bangladesh",
+		Price:              90,
+		CarrierFee:         90,
+		ReturnOf:           1,
+	}
+
+	t.Run("should number the attempt", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(attempt.ParcelID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusPickedUp))
+		m.ExpectQuery("INSERT INTO delivery_attempt (.+) SELECT (.+) RETURNING id, attempt, created_at").
+			WithArgs(attempt.ParcelID, attempt.CarrierID, attempt.Reason, attempt.Note).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "attempt", "created_at"}).AddRow(4, 1, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditDeliveryAttempt, 4, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		rp := returnParcel
+		result, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, &rp)
+
+		expected := attempt
+		expected.ID = 4
+		expected.Attempt = 1
+		expected.CreatedAt = createdAt
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return the parcel on the last attempt", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusPickedUp))
+		m.ExpectQuery("INSERT INTO delivery_attempt (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "attempt", "created_at"}).AddRow(6, 3, createdAt))
+		m.ExpectExec("UPDATE parcel SET status = (.+) WHERE (.+)").
+			WithArgs(model.ParcelStatusReturned, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectPrepare("INSERT INTO parcel (.+) RETURNING id, created_at, updated_at").
+			ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(12, createdAt, createdAt))
+		m.ExpectExec("INSERT INTO payment (.+)").
+			WithArgs(12, returnParcel.UserID, returnParcel.Price).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditParcel, 12, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		rp := returnParcel
+		result, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, &rp)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.Attempt)
+		assert.Equal(t, 12, result.ReturnParcelID)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not return a return parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusPickedUp))
+		m.ExpectQuery("INSERT INTO delivery_attempt (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "attempt", "created_at"}).AddRow(6, 5, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, result.ReturnParcelID)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid when parcel is not picked up", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusReturned))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, nil)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, nil)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when the return parcel can not be created", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.ParcelStatusPickedUp))
+		m.ExpectQuery("INSERT INTO delivery_attempt (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "attempt", "created_at"}).AddRow(6, 3, createdAt))
+		m.ExpectExec("UPDATE parcel SET status = (.+) WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		rp := returnParcel
+		_, err := repo.RecordFailedAttempt(context.Background(), attempt, 3, &rp)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
@@ -2,25 +2,170 @@ package parcel
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"parcel-service/internal/app/model"
 	svc "parcel-service/internal/app/service"
+	"sort"
+	"time"
 )
 
+// ASSIGNED_CANCELLATION_FEE is charged when the sender cancels after a carrier is assigned
+// but before pickup, it goes to the carrier as compensation
+const ASSIGNED_CANCELLATION_FEE = 30.00
+
+// MAX_DELIVERY_ATTEMPTS is the number of failed deliveries after which a parcel is returned to its sender
+const MAX_DELIVERY_ATTEMPTS = 3
+
+// RETURN_CARRIER_FEE_RATE is the share of the original carrier fee paid for the return leg, the return is charged
+// to the sender without company fee, promotion or tax
+const RETURN_CARRIER_FEE_RATE = 0.5
+
 type service struct {
-	repo svc.ParcelRepository
+	repo         svc.ParcelRepository
+	promotionSvc svc.PromotionService
+	taxSvc       svc.TaxService
+	notifier     svc.EventNotifier
+	maxAttempts  int
 }
 
-func NewService(repo svc.ParcelRepository) *service {
-	return &service{
-		repo: repo,
+// Option sets the optional settings of the parcel service
+type Option func(*service)
+
+// WithMaxDeliveryAttempts sets the number of failed deliveries after which a parcel is returned to its sender
+func WithMaxDeliveryAttempts(maxAttempts int) Option {
+	return func(s *service) {
+		s.maxAttempts = maxAttempts
 	}
 }
 
+func NewService(repo svc.ParcelRepository, promotionSvc svc.PromotionService, taxSvc svc.TaxService, notifier svc.EventNotifier, opts ...Option) *service {
+	s := &service{
+		repo:         repo,
+		promotionSvc: promotionSvc,
+		taxSvc:       taxSvc,
+		notifier:     notifier,
+		maxAttempts:  MAX_DELIVERY_ATTEMPTS,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 func (s *service) GetParcels(ctx context.Context, status int, limit int, offset int) ([]model.Parcel, error) {
 	return s.repo.GetParcelsList(ctx, status, limit, offset)
 }
 
 func (s *service) CreateParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error) {
+	parcel, err := s.PriceParcel(ctx, parcel)
+	if err != nil {
+		return model.Parcel{}, err
+	}
+
+	parcel, err = s.repo.InsertParcel(ctx, parcel)
+	if err != nil {
+		return model.Parcel{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:     model.EventParcelCreated,
+		ParcelID: parcel.ID,
+		UserID:   parcel.UserID,
+		Status:   parcel.Status,
+	})
+	return parcel, nil
+}
+
+// ImportParcels validates, prices and stores the rows of a bulk import. Valid rows are stored in batches of
+// model.BulkBatchSize, each in its own transaction, and the report has the parcel or the error of every row.
+// A userID other than 0 imports for that user only, rows without a user get it and rows of other users are refused.
+func (s *service) ImportParcels(ctx context.Context, userID int, rows []model.BulkRow) (model.BulkReport, error) {
+	if len(rows) > model.MaxBulkParcels {
+		return model.BulkReport{}, fmt.Errorf("at most %d parcels can be imported at once :%w", model.MaxBulkParcels, model.ErrInvalid)
+	}
+
+	report := model.BulkReport{Total: len(rows), Created: []model.BulkCreated{}, Errors: []model.BulkRowError{}}
+	var valid []model.BulkRow
+	for _, row := range rows {
+		parcel, err := s.prepareBulkRow(ctx, userID, row)
+		if err != nil {
+			if !isRowError(err) {
+				return model.BulkReport{}, err
+			}
+			report.Errors = append(report.Errors, model.BulkRowError{Row: row.Row, Message: err.Error()})
+			continue
+		}
+		row.Parcel = parcel
+		valid = append(valid, row)
+	}
+
+	for start := 0; start < len(valid); start += model.BulkBatchSize {
+		end := start + model.BulkBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+		batch := valid[start:end]
+
+		parcels := make([]model.Parcel, len(batch))
+		for i, row := range batch {
+			parcels[i] = row.Parcel
+		}
+		parcels, err := s.repo.InsertParcels(ctx, parcels)
+		if err != nil {
+			message := "failed to store the batch of the row, please import it again"
+			if isRowError(err) {
+				message = err.Error()
+			}
+			for _, row := range batch {
+				report.Errors = append(report.Errors, model.BulkRowError{Row: row.Row, Message: message})
+			}
+			continue
+		}
+
+		for i, parcel := range parcels {
+			report.Created = append(report.Created, model.BulkCreated{Row: batch[i].Row, ParcelID: parcel.ID})
+			s.notifier.Notify(ctx, model.Event{
+				Type:     model.EventParcelCreated,
+				ParcelID: parcel.ID,
+				UserID:   parcel.UserID,
+				Status:   parcel.Status,
+			})
+		}
+	}
+
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	return report, nil
+}
+
+func (s *service) prepareBulkRow(ctx context.Context, userID int, row model.BulkRow) (model.Parcel, error) {
+	if row.Err != nil {
+		return model.Parcel{}, row.Err
+	}
+
+	parcel := row.Parcel
+	if userID != 0 {
+		if parcel.UserID == 0 {
+			parcel.UserID = userID
+		}
+		if parcel.UserID != userID {
+			return model.Parcel{}, fmt.Errorf("parcel of user %d can not be imported by user %d :%w", parcel.UserID, userID, model.ErrForbidden)
+		}
+	}
+
+	if err := parcel.ValidateParcelInput(); err != nil {
+		return model.Parcel{}, err
+	}
+	return s.PriceParcel(ctx, parcel)
+}
+
+// isRowError reports whether the error is caused by the data of a row rather than by the service
+func isRowError(err error) bool {
+	return errors.Is(err, model.ErrInvalid) || errors.Is(err, model.ErrEmpty) || errors.Is(err, model.ErrNotFound) || errors.Is(err, model.ErrForbidden)
+}
+
+// PriceParcel sets the fees, the promotion discount, the tax and the SLA deadlines of a new parcel
+func (s *service) PriceParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error) {
 	const (
 		CARRIER_FEE = 180.00
 		COMPANY_FEE = 20.00
@@ -30,13 +175,186 @@ func (s *service) CreateParcel(ctx context.Context, parcel model.Parcel) (model.
 	parcel.CompanyFee = COMPANY_FEE
 	parcel.Price = CARRIER_FEE + COMPANY_FEE
 
-	return s.repo.InsertParcel(ctx, parcel)
+	// the discount is taken from the price only, carrier fee stays untouched
+	if parcel.PromoCode != "" {
+		promotion, discount, err := s.promotionSvc.ApplyPromotion(ctx, parcel.PromoCode, parcel.UserID, parcel.Price)
+		if err != nil {
+			return model.Parcel{}, err
+		}
+		parcel.PromotionID = promotion.ID
+		parcel.Discount = discount
+		parcel.Price -= discount
+	}
+
+	tax, err := s.taxSvc.CalculateTax(ctx, parcel)
+	if err != nil {
+		return model.Parcel{}, err
+	}
+	parcel.Tax = tax.Amount
+	parcel.TaxName = tax.Name
+	parcel.TaxRate = tax.Rate
+	parcel.TaxInclusive = tax.Inclusive
+	if !tax.Inclusive {
+		parcel.Price += tax.Amount
+	}
+
+	parcel.SetDeadlines()
+	return parcel, nil
 }
 
 func (s *service) GetParcelByID(ctx context.Context, parcelID int) (model.Parcel, error) {
 	return s.repo.FetchParcelByID(ctx, parcelID)
 }
 
+// EditParcel updates the status of the parcel, the first move to picked up records the actual pickup time
+// which is kept apart from the requested source time. Cancelled and returned parcels settle their payments,
+// so they can only be reached by cancelling the parcel or by its failed delivery attempts.
 func (s *service) EditParcel(ctx context.Context, parcel model.Parcel) error {
-	return s.repo.UpdateParcel(ctx, parcel)
+	now := time.Now()
+	switch parcel.Status {
+	case model.ParcelStatusCancelled, model.ParcelStatusReturned:
+		return fmt.Errorf("parcel %d can not be %s by a status update :%w", parcel.ID, model.ParcelStatusName(parcel.Status), model.ErrInvalid)
+	case model.ParcelStatusPickedUp:
+		parcel.PickedUpAt = &now
+	case model.ParcelStatusDelivered:
+		parcel.DeliveredAt = &now
+	}
+
+	if err := s.repo.UpdateParcel(ctx, parcel); err != nil {
+		return err
+	}
+
+	event := model.Event{
+		Type:     model.EventParcelStatusChanged,
+		ParcelID: parcel.ID,
+		Status:   parcel.Status,
+	}
+	// the owner and carrier are only needed to route the event, the update has already succeeded
+	if updated, err := s.repo.FetchParcelByID(ctx, parcel.ID); err == nil {
+		event.UserID = updated.UserID
+		event.CarrierID = updated.CarrierID
+	}
+	s.notifier.Notify(ctx, event)
+	return nil
+}
+
+// CancelParcel cancels the parcel for free before a carrier is assigned. After assignment a fixed fee is
+// charged and after pickup the full carrier fee, both are paid to the carrier. The rest of the price is refunded.
+func (s *service) CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error) {
+	parcel, err := s.repo.FetchParcelByID(ctx, cancellation.ParcelID)
+	if err != nil {
+		return model.Cancellation{}, err
+	}
+
+	cancellation.PreviousStatus = parcel.Status
+	cancellation.CarrierID = parcel.CarrierID
+
+	switch parcel.Status {
+	case model.ParcelStatusCreated:
+		cancellation.Fee = 0
+	case model.ParcelStatusAssigned:
+		cancellation.Fee = ASSIGNED_CANCELLATION_FEE
+		cancellation.CarrierCompensation = ASSIGNED_CANCELLATION_FEE
+	case model.ParcelStatusPickedUp:
+		cancellation.Fee = parcel.CarrierFee
+		cancellation.CarrierCompensation = parcel.CarrierFee
+	default:
+		return model.Cancellation{}, fmt.Errorf("parcel %d with status %d can not be cancelled :%w", parcel.ID, parcel.Status, model.ErrInvalid)
+	}
+
+	if parcel.CarrierID == 0 {
+		cancellation.CarrierCompensation = 0
+	}
+
+	if cancellation.Fee > parcel.Price {
+		cancellation.Fee = parcel.Price
+	}
+	cancellation.Refund = parcel.Price - cancellation.Fee
+
+	cancellation, err = s.repo.CancelParcel(ctx, cancellation)
+	if err != nil {
+		return model.Cancellation{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventParcelCancelled,
+		ParcelID:  parcel.ID,
+		UserID:    parcel.UserID,
+		CarrierID: parcel.CarrierID,
+		Status:    model.ParcelStatusCancelled,
+	})
+	return cancellation, nil
+}
+
+// FailDelivery records a failed delivery attempt of the carrier of a picked up parcel. The attempt that reaches
+// the maximum returns the parcel, the carrier takes it back to the source address as a linked return parcel.
+// A return parcel that can not be delivered is never returned again.
+func (s *service) FailDelivery(ctx context.Context, attempt model.DeliveryAttempt) (model.DeliveryAttempt, error) {
+	parcel, err := s.repo.FetchParcelByID(ctx, attempt.ParcelID)
+	if err != nil {
+		return model.DeliveryAttempt{}, err
+	}
+
+	if attempt.CarrierID != parcel.CarrierID {
+		return model.DeliveryAttempt{}, fmt.Errorf("carrier %d is not delivering parcel %d :%w", attempt.CarrierID, parcel.ID, model.ErrForbidden)
+	}
+
+	var returnParcel *model.Parcel
+	if parcel.ReturnOf == 0 {
+		reverse := newReturnParcel(parcel, time.Now())
+		returnParcel = &reverse
+	}
+
+	attempt, err = s.repo.RecordFailedAttempt(ctx, attempt, s.maxAttempts, returnParcel)
+	if err != nil {
+		return model.DeliveryAttempt{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventDeliveryFailed,
+		ParcelID:  parcel.ID,
+		UserID:    parcel.UserID,
+		CarrierID: parcel.CarrierID,
+		Status:    parcel.Status,
+		Reason:    attempt.Reason,
+	})
+	if attempt.ReturnParcelID != 0 {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventParcelStatusChanged,
+			ParcelID:  parcel.ID,
+			UserID:    parcel.UserID,
+			CarrierID: parcel.CarrierID,
+			Status:    model.ParcelStatusReturned,
+		})
+	}
+	return attempt, nil
+}
+
+// newReturnParcel builds the parcel that takes the original back from its destination to its source address.
+// The carrier already holds it, so it starts picked up and its deadlines run from now.
+func newReturnParcel(parcel model.Parcel, now time.Time) model.Parcel {
+	carrierFee := parcel.CarrierFee * RETURN_CARRIER_FEE_RATE
+	reverse := model.Parcel{
+		UserID:             parcel.UserID,
+		CarrierID:          parcel.CarrierID,
+		Status:             model.ParcelStatusPickedUp,
+		SourceAddress:      parcel.DestinationAddress,
+		DestinationAddress: parcel.SourceAddress,
+		SourceLatitude:     parcel.DestLatitude,
+		SourceLongitude:    parcel.DestLongitude,
+		DestLatitude:       parcel.SourceLatitude,
+		DestLongitude:      parcel.SourceLongitude,
+		SourceTime:         now,
+		Weight:             parcel.Weight,
+		DeclaredValue:      parcel.DeclaredValue,
+		ParcelType:         parcel.ParcelType,
+		Price:              carrierFee,
+		CarrierFee:         carrierFee,
+		Region:             parcel.Region,
+		AssignedAt:         &now,
+		PickedUpAt:         &now,
+		ReturnOf:           parcel.ID,
+	}
+	reverse.SetDeadlines()
+	return reverse
 }
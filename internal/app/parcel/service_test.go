@@ -3,6 +3,7 @@ package parcel
 import (
 	"context"
 	"errors"
+	"fmt"
 	"parcel-service/internal/app/model"
 	"parcel-service/internal/app/service/mocks"
 	"testing"
@@ -122,7 +123,7 @@ func TestService_GetParcels(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), nil, nil, nil)
 			parcels, err := s.GetParcels(context.Background(), status, limit, offset)
 			assert.Equal(t, tc.expErr, err)
 			assert.EqualValues(t, tc.expParcel, parcels)
@@ -135,10 +136,12 @@ func TestService_CreateParcel(t *testing.T) {
 	defer ctrl.Finish()
 
 	testCases := []struct {
-		desc      string
-		mockRepo  func() *mocks.MockParcelRepository
-		expParcel model.Parcel
-		expErr    error
+		desc         string
+		mockRepo     func() *mocks.MockParcelRepository
+		mockTaxSvc   func() *mocks.MockTaxService
+		mockNotifier func() *mocks.MockEventNotifier
+		expParcel    model.Parcel
+		expErr       error
 	}{
 		{
 			desc: "should return success",
@@ -147,6 +150,16 @@ func TestService_CreateParcel(t *testing.T) {
 				r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).Return(parcel, nil)
 				return r
 			},
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil)
+				return s
+			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				n := mocks.NewMockEventNotifier(ctrl)
+				n.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelCreated, UserID: 1})
+				return n
+			},
 			expParcel: parcel,
 			expErr:    nil,
 		},
@@ -158,6 +171,30 @@ func TestService_CreateParcel(t *testing.T) {
 				r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).Return(model.Parcel{}, errors.New("db-error"))
 				return r
 			},
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil)
+				return s
+			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				return mocks.NewMockEventNotifier(ctrl)
+			},
+			expParcel: model.Parcel{},
+			expErr:    errors.New("db-error"),
+		},
+		{
+			desc: "should return tax error",
+			mockRepo: func() *mocks.MockParcelRepository {
+				return mocks.NewMockParcelRepository(ctrl)
+			},
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, errors.New("db-error"))
+				return s
+			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				return mocks.NewMockEventNotifier(ctrl)
+			},
 			expParcel: model.Parcel{},
 			expErr:    errors.New("db-error"),
 		},
@@ -165,7 +202,7 @@ func TestService_CreateParcel(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), nil, tc.mockTaxSvc(), tc.mockNotifier())
 			parcel, err := s.CreateParcel(context.Background(), parcel)
 			assert.EqualValues(t, tc.expParcel, parcel)
 			assert.Equal(t, tc.expErr, err)
@@ -173,6 +210,111 @@ func TestService_CreateParcel(t *testing.T) {
 	}
 }
 
+func TestService_CreateParcelWithTax(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc     string
+		tax      model.Tax
+		expPrice float32
+	}{
+		{
+			desc:     "should add exclusive tax to price",
+			tax:      model.Tax{Name: "VAT", Rate: 0.15, Amount: 3},
+			expPrice: 203,
+		},
+		{
+			desc:     "should keep price with inclusive tax",
+			tax:      model.Tax{Name: "VAT", Rate: 0.15, Inclusive: true, Amount: 2.61},
+			expPrice: 200,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := mocks.NewMockParcelRepository(ctrl)
+			r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p model.Parcel) (model.Parcel, error) {
+				return p, nil
+			})
+			taxSvc := mocks.NewMockTaxService(ctrl)
+			taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(tc.tax, nil)
+
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+			s := NewService(r, nil, taxSvc, notifier)
+			result, err := s.CreateParcel(context.Background(), parcel)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expPrice, result.Price)
+			assert.Equal(t, tc.tax.Amount, result.Tax)
+			assert.Equal(t, tc.tax.Inclusive, result.TaxInclusive)
+			assert.EqualValues(t, 180, result.CarrierFee)
+		})
+	}
+}
+
+func TestService_CreateParcelWithPromoCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	promoParcel := parcel
+	promoParcel.PromoCode = "WELCOME10"
+	promotion := model.Promotion{ID: 7, Code: "WELCOME10", DiscountType: model.DiscountTypePercentage, DiscountValue: 10}
+
+	testCases := []struct {
+		desc         string
+		mockRepo     func() *mocks.MockParcelRepository
+		mockPromoSvc func() *mocks.MockPromotionService
+		expErr       error
+	}{
+		{
+			desc: "should apply discount to price only",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p model.Parcel) (model.Parcel, error) {
+					assert.Equal(t, promotion.ID, p.PromotionID)
+					assert.EqualValues(t, 20, p.Discount)
+					assert.EqualValues(t, 180, p.Price)
+					assert.EqualValues(t, 180, p.CarrierFee)
+					return p, nil
+				})
+				return r
+			},
+			mockPromoSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().ApplyPromotion(gomock.Any(), "WELCOME10", parcel.UserID, float32(200)).Return(promotion, float32(20), nil)
+				return s
+			},
+			expErr: nil,
+		},
+		{
+			desc: "should return invalid promo code error",
+			mockRepo: func() *mocks.MockParcelRepository {
+				return mocks.NewMockParcelRepository(ctrl)
+			},
+			mockPromoSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().ApplyPromotion(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(model.Promotion{}, float32(0), model.ErrInvalid)
+				return s
+			},
+			expErr: model.ErrInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			taxSvc := mocks.NewMockTaxService(ctrl)
+			taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil).AnyTimes()
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			notifier.EXPECT().Notify(gomock.Any(), gomock.Any()).AnyTimes()
+			s := NewService(tc.mockRepo(), tc.mockPromoSvc(), taxSvc, notifier)
+			_, err := s.CreateParcel(context.Background(), promoParcel)
+			assert.Equal(t, tc.expErr, err)
+		})
+	}
+}
+
 func TestService_GetParcelByID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -232,7 +374,7 @@ func TestService_GetParcelByID(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), nil, nil, nil)
 			parcel, err := s.GetParcelByID(context.Background(), parcel.ID)
 			assert.Equal(t, tc.expErr, err)
 			assert.EqualValues(t, tc.expParcel, parcel)
@@ -245,17 +387,39 @@ func TestService_EditParcel(t *testing.T) {
 	defer ctrl.Finish()
 
 	testCases := []struct {
-		desc     string
-		mockRepo func() *mocks.MockParcelRepository
-		expErr   error
+		desc         string
+		mockRepo     func() *mocks.MockParcelRepository
+		mockNotifier func() *mocks.MockEventNotifier
+		expErr       error
 	}{
 		{
 			desc: "should return success",
 			mockRepo: func() *mocks.MockParcelRepository {
 				r := mocks.NewMockParcelRepository(ctrl)
 				r.EXPECT().UpdateParcel(gomock.Any(), gomock.Any()).Return(nil)
+				r.EXPECT().FetchParcelByID(gomock.Any(), parcel.ID).Return(model.Parcel{UserID: 1, CarrierID: 7}, nil)
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				n := mocks.NewMockEventNotifier(ctrl)
+				n.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelStatusChanged, UserID: 1, CarrierID: 7})
+				return n
+			},
+			expErr: nil,
+		},
+		{
+			desc: "should notify without recipients when parcel can not be fetched",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().UpdateParcel(gomock.Any(), gomock.Any()).Return(nil)
+				r.EXPECT().FetchParcelByID(gomock.Any(), parcel.ID).Return(model.Parcel{}, errors.New("db-error"))
+				return r
+			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				n := mocks.NewMockEventNotifier(ctrl)
+				n.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelStatusChanged})
+				return n
+			},
 			expErr: nil,
 		},
 
@@ -266,15 +430,468 @@ func TestService_EditParcel(t *testing.T) {
 				r.EXPECT().UpdateParcel(gomock.Any(), gomock.Any()).Return(errors.New("db-error"))
 				return r
 			},
+			mockNotifier: func() *mocks.MockEventNotifier {
+				return mocks.NewMockEventNotifier(ctrl)
+			},
 			expErr: errors.New("db-error"),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewService(tc.mockRepo())
+			s := NewService(tc.mockRepo(), nil, nil, tc.mockNotifier())
 			err := s.EditParcel(context.Background(), parcel)
 			assert.Equal(t, tc.expErr, err)
 		})
 	}
 }
+
+func TestService_EditParcelRecordsPickup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockParcelRepository(ctrl)
+	r.EXPECT().UpdateParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, updated model.Parcel) error {
+		assert.Equal(t, model.ParcelStatusPickedUp, updated.Status)
+		assert.WithinDuration(t, time.Now(), *updated.PickedUpAt, time.Second)
+		assert.Equal(t, parcel.SourceTime, updated.SourceTime)
+		return nil
+	})
+	r.EXPECT().FetchParcelByID(gomock.Any(), parcel.ID).Return(model.Parcel{UserID: 1, CarrierID: 7}, nil)
+	n := mocks.NewMockEventNotifier(ctrl)
+	n.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	pickedUp := parcel
+	pickedUp.Status = model.ParcelStatusPickedUp
+	err := NewService(r, nil, nil, n).EditParcel(context.Background(), pickedUp)
+	assert.Nil(t, err)
+}
+
+func TestService_EditParcelRefusesSettlingStatuses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewService(mocks.NewMockParcelRepository(ctrl), nil, nil, mocks.NewMockEventNotifier(ctrl))
+
+	cancelled := parcel
+	cancelled.ID = 1
+	cancelled.Status = model.ParcelStatusCancelled
+	err := s.EditParcel(context.Background(), cancelled)
+	assert.EqualError(t, err, "parcel 1 can not be cancelled by a status update :invalid")
+
+	returned := cancelled
+	returned.Status = model.ParcelStatusReturned
+	err = s.EditParcel(context.Background(), returned)
+	assert.EqualError(t, err, "parcel 1 can not be returned by a status update :invalid")
+}
+
+func TestService_EditParcelRecordsDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := mocks.NewMockParcelRepository(ctrl)
+	r.EXPECT().UpdateParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, updated model.Parcel) error {
+		assert.WithinDuration(t, time.Now(), *updated.DeliveredAt, time.Second)
+		assert.Nil(t, updated.PickedUpAt)
+		return nil
+	})
+	r.EXPECT().FetchParcelByID(gomock.Any(), parcel.ID).Return(model.Parcel{UserID: 1, CarrierID: 7}, nil)
+	n := mocks.NewMockEventNotifier(ctrl)
+	n.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	delivered := parcel
+	delivered.Status = model.ParcelStatusDelivered
+	err := NewService(r, nil, nil, n).EditParcel(context.Background(), delivered)
+	assert.Nil(t, err)
+}
+
+func TestService_CreateParcelSetsDeadlines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sourceTime := time.Date(2020, time.April, 12, 10, 0, 0, 0, time.UTC)
+	pickupEnd := sourceTime.Add(6 * time.Hour)
+	deliveryEnd := sourceTime.Add(30 * time.Hour)
+
+	testCases := []struct {
+		desc        string
+		parcelType  string
+		pickupEnd   *time.Time
+		deliveryEnd *time.Time
+		expPickup   time.Time
+		expDelivery time.Time
+	}{
+		{
+			desc:        "should use the SLA of the parcel type",
+			parcelType:  "Document",
+			expPickup:   sourceTime.Add(2 * time.Hour),
+			expDelivery: sourceTime.Add(26 * time.Hour),
+		},
+		{
+			desc:        "should use the default SLA for unknown types",
+			parcelType:  "Furniture",
+			expPickup:   sourceTime.Add(4 * time.Hour),
+			expDelivery: sourceTime.Add(52 * time.Hour),
+		},
+		{
+			desc:        "should use the end of the pickup window",
+			parcelType:  "Document",
+			pickupEnd:   &pickupEnd,
+			expPickup:   pickupEnd,
+			expDelivery: pickupEnd.Add(24 * time.Hour),
+		},
+		{
+			desc:        "should use the end of the delivery window",
+			parcelType:  "Document",
+			pickupEnd:   &pickupEnd,
+			deliveryEnd: &deliveryEnd,
+			expPickup:   pickupEnd,
+			expDelivery: deliveryEnd,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := mocks.NewMockParcelRepository(ctrl)
+			r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p model.Parcel) (model.Parcel, error) {
+				assert.Equal(t, tc.expPickup, *p.PickupDeadline)
+				assert.Equal(t, tc.expDelivery, *p.DeliveryDeadline)
+				return p, nil
+			})
+			taxSvc := mocks.NewMockTaxService(ctrl)
+			taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil)
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+			input := parcel
+			input.SourceTime = sourceTime
+			input.ParcelType = tc.parcelType
+			input.PickupEnd = tc.pickupEnd
+			input.DeliveryEnd = tc.deliveryEnd
+			_, err := NewService(r, nil, taxSvc, notifier).CreateParcel(context.Background(), input)
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestService_CreateParcelWithoutSourceTimeSetsDeadlines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := time.Now()
+	r := mocks.NewMockParcelRepository(ctrl)
+	r.EXPECT().InsertParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p model.Parcel) (model.Parcel, error) {
+		after := time.Now()
+		assert.False(t, p.PickupDeadline.Before(before.Add(2*time.Hour)))
+		assert.False(t, p.PickupDeadline.After(after.Add(2*time.Hour)))
+		assert.Equal(t, p.PickupDeadline.Add(24*time.Hour), *p.DeliveryDeadline)
+		return p, nil
+	})
+	taxSvc := mocks.NewMockTaxService(ctrl)
+	taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil)
+	notifier := mocks.NewMockEventNotifier(ctrl)
+	notifier.EXPECT().Notify(gomock.Any(), gomock.Any())
+
+	input := parcel
+	input.SourceTime = time.Time{}
+	input.CreatedAt = time.Time{}
+	input.ParcelType = "Document"
+	_, err := NewService(r, nil, taxSvc, notifier).CreateParcel(context.Background(), input)
+	assert.Nil(t, err)
+}
+
+func TestService_CancelParcel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parcelWithStatus := func(status int, carrierID int) model.Parcel {
+		p := parcel
+		p.ID = 1
+		p.Status = status
+		p.CarrierID = carrierID
+		return p
+	}
+
+	testCases := []struct {
+		desc            string
+		mockRepo        func() *mocks.MockParcelRepository
+		expCancellation model.Cancellation
+		expErr          error
+	}{
+		{
+			desc: "should cancel for free before assignment",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcelWithStatus(model.ParcelStatusCreated, 0), nil)
+				r.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c model.Cancellation) (model.Cancellation, error) {
+					return c, nil
+				})
+				return r
+			},
+			expCancellation: model.Cancellation{ParcelID: 1, PreviousStatus: model.ParcelStatusCreated, Reason: "no longer needed", Refund: 200},
+		},
+		{
+			desc: "should charge fixed fee after assignment",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcelWithStatus(model.ParcelStatusAssigned, 9), nil)
+				r.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c model.Cancellation) (model.Cancellation, error) {
+					return c, nil
+				})
+				return r
+			},
+			expCancellation: model.Cancellation{ParcelID: 1, PreviousStatus: model.ParcelStatusAssigned, Reason: "no longer needed", Fee: 30, Refund: 170, CarrierID: 9, CarrierCompensation: 30},
+		},
+		{
+			desc: "should charge carrier fee after pickup",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcelWithStatus(model.ParcelStatusPickedUp, 9), nil)
+				r.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c model.Cancellation) (model.Cancellation, error) {
+					return c, nil
+				})
+				return r
+			},
+			expCancellation: model.Cancellation{ParcelID: 1, PreviousStatus: model.ParcelStatusPickedUp, Reason: "no longer needed", Fee: 180, Refund: 20, CarrierID: 9, CarrierCompensation: 180},
+		},
+		{
+			desc: "should not cancel delivered parcel",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcelWithStatus(model.ParcelStatusDelivered, 9), nil)
+				return r
+			},
+			expCancellation: model.Cancellation{},
+			expErr:          fmt.Errorf("parcel 1 with status 4 can not be cancelled :%w", model.ErrInvalid),
+		},
+		{
+			desc: "should return not found",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return r
+			},
+			expCancellation: model.Cancellation{},
+			expErr:          model.ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			if tc.expErr == nil {
+				notifier.EXPECT().Notify(gomock.Any(), model.Event{
+					Type:      model.EventParcelCancelled,
+					ParcelID:  1,
+					UserID:    parcel.UserID,
+					CarrierID: tc.expCancellation.CarrierID,
+					Status:    model.ParcelStatusCancelled,
+				})
+			}
+			s := NewService(tc.mockRepo(), nil, nil, notifier)
+			cancellation, err := s.CancelParcel(context.Background(), model.Cancellation{ParcelID: 1, Reason: "no longer needed"})
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expCancellation, cancellation)
+		})
+	}
+}
+
+func TestService_FailDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pickedUp := parcel
+	pickedUp.ID = 1
+	pickedUp.Status = model.ParcelStatusPickedUp
+	pickedUp.CarrierID = 9
+
+	returned := pickedUp
+	returned.ReturnOf = 5
+
+	input := model.DeliveryAttempt{ParcelID: 1, CarrierID: 9, Reason: model.ReasonRefused}
+
+	testCases := []struct {
+		desc       string
+		mockRepo   func() *mocks.MockParcelRepository
+		input      model.DeliveryAttempt
+		expAttempt model.DeliveryAttempt
+		expEvents  []model.Event
+		expErr     error
+	}{
+		{
+			desc: "should record the attempt with a return parcel back to the source",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(pickedUp, nil)
+				r.EXPECT().RecordFailedAttempt(gomock.Any(), input, 2, gomock.Any()).DoAndReturn(func(_ context.Context, a model.DeliveryAttempt, _ int, rp *model.Parcel) (model.DeliveryAttempt, error) {
+					assert.Equal(t, pickedUp.DestinationAddress, rp.SourceAddress)
+					assert.Equal(t, pickedUp.SourceAddress, rp.DestinationAddress)
+					assert.Equal(t, model.ParcelStatusPickedUp, rp.Status)
+					assert.Equal(t, float32(90), rp.CarrierFee)
+					assert.Equal(t, float32(90), rp.Price)
+					assert.Equal(t, float32(0), rp.CompanyFee)
+					assert.Equal(t, 1, rp.ReturnOf)
+					assert.NotNil(t, rp.DeliveryDeadline)
+					a.Attempt = 1
+					return a, nil
+				})
+				return r
+			},
+			input:      input,
+			expAttempt: model.DeliveryAttempt{ParcelID: 1, CarrierID: 9, Reason: model.ReasonRefused, Attempt: 1},
+			expEvents: []model.Event{
+				{Type: model.EventDeliveryFailed, ParcelID: 1, UserID: parcel.UserID, CarrierID: 9, Status: model.ParcelStatusPickedUp, Reason: model.ReasonRefused},
+			},
+		},
+		{
+			desc: "should notify the return",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(pickedUp, nil)
+				r.EXPECT().RecordFailedAttempt(gomock.Any(), input, 2, gomock.Any()).DoAndReturn(func(_ context.Context, a model.DeliveryAttempt, _ int, _ *model.Parcel) (model.DeliveryAttempt, error) {
+					a.Attempt = 2
+					a.ReturnParcelID = 12
+					return a, nil
+				})
+				return r
+			},
+			input:      input,
+			expAttempt: model.DeliveryAttempt{ParcelID: 1, CarrierID: 9, Reason: model.ReasonRefused, Attempt: 2, ReturnParcelID: 12},
+			expEvents: []model.Event{
+				{Type: model.EventDeliveryFailed, ParcelID: 1, UserID: parcel.UserID, CarrierID: 9, Status: model.ParcelStatusPickedUp, Reason: model.ReasonRefused},
+				{Type: model.EventParcelStatusChanged, ParcelID: 1, UserID: parcel.UserID, CarrierID: 9, Status: model.ParcelStatusReturned},
+			},
+		},
+		{
+			desc: "should not return a return parcel",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(returned, nil)
+				r.EXPECT().RecordFailedAttempt(gomock.Any(), input, 2, (*model.Parcel)(nil)).Return(input, nil)
+				return r
+			},
+			input:      input,
+			expAttempt: input,
+			expEvents: []model.Event{
+				{Type: model.EventDeliveryFailed, ParcelID: 1, UserID: parcel.UserID, CarrierID: 9, Status: model.ParcelStatusPickedUp, Reason: model.ReasonRefused},
+			},
+		},
+		{
+			desc: "should return forbidden for another carrier",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(pickedUp, nil)
+				return r
+			},
+			input:  model.DeliveryAttempt{ParcelID: 1, CarrierID: 4, Reason: model.ReasonRefused},
+			expErr: fmt.Errorf("carrier 4 is not delivering parcel 1 :%w", model.ErrForbidden),
+		},
+		{
+			desc: "should return not found",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return r
+			},
+			input:  input,
+			expErr: model.ErrNotFound,
+		},
+		{
+			desc: "should return repository error",
+			mockRepo: func() *mocks.MockParcelRepository {
+				r := mocks.NewMockParcelRepository(ctrl)
+				r.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(pickedUp, nil)
+				r.EXPECT().RecordFailedAttempt(gomock.Any(), input, 2, gomock.Any()).Return(model.DeliveryAttempt{}, model.ErrInvalid)
+				return r
+			},
+			input:  input,
+			expErr: model.ErrInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			for _, event := range tc.expEvents {
+				notifier.EXPECT().Notify(gomock.Any(), event)
+			}
+			s := NewService(tc.mockRepo(), nil, nil, notifier, WithMaxDeliveryAttempts(2))
+			attempt, err := s.FailDelivery(context.Background(), tc.input)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expAttempt, attempt)
+		})
+	}
+}
+
+func TestService_ImportParcels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	row := model.Parcel{SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Pabna Shadar", ParcelType: "Document"}
+	other := row
+	other.UserID = 4
+	missingType := row
+	missingType.ParcelType = ""
+	rows := []model.BulkRow{
+		{Row: 1, Parcel: row},
+		{Row: 2, Err: fmt.Errorf("column weight: not a number :%w", model.ErrInvalid)},
+		{Row: 3, Parcel: other},
+		{Row: 4, Parcel: missingType},
+		{Row: 5, Parcel: row},
+	}
+
+	t.Run("should store valid rows and report the others", func(t *testing.T) {
+		r := mocks.NewMockParcelRepository(ctrl)
+		r.EXPECT().InsertParcels(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, parcels []model.Parcel) ([]model.Parcel, error) {
+			assert.Len(t, parcels, 2)
+			for i := range parcels {
+				assert.Equal(t, 3, parcels[i].UserID)
+				assert.EqualValues(t, 200, parcels[i].Price)
+				parcels[i].ID = 10 + i
+			}
+			return parcels, nil
+		})
+		taxSvc := mocks.NewMockTaxService(ctrl)
+		taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil).Times(2)
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelCreated, ParcelID: 10, UserID: 3})
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelCreated, ParcelID: 11, UserID: 3})
+
+		s := NewService(r, nil, taxSvc, notifier)
+		report, err := s.ImportParcels(context.Background(), 3, rows)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.BulkReport{
+			Total:   5,
+			Created: []model.BulkCreated{{Row: 1, ParcelID: 10}, {Row: 5, ParcelID: 11}},
+			Errors: []model.BulkRowError{
+				{Row: 2, Message: "column weight: not a number :invalid"},
+				{Row: 3, Message: "parcel of user 4 can not be imported by user 3 :forbidden"},
+				{Row: 4, Message: "Parcel type is required :empty"},
+			},
+		}, report)
+	})
+
+	t.Run("should report every row of a failed batch", func(t *testing.T) {
+		r := mocks.NewMockParcelRepository(ctrl)
+		r.EXPECT().InsertParcels(gomock.Any(), gomock.Any()).Return(nil, errors.New("sql-error"))
+		taxSvc := mocks.NewMockTaxService(ctrl)
+		taxSvc.EXPECT().CalculateTax(gomock.Any(), gomock.Any()).Return(model.Tax{}, nil).Times(2)
+
+		s := NewService(r, nil, taxSvc, mocks.NewMockEventNotifier(ctrl))
+		report, err := s.ImportParcels(context.Background(), 3, []model.BulkRow{rows[0], rows[4]})
+
+		assert.Nil(t, err)
+		assert.Empty(t, report.Created)
+		assert.Equal(t, []model.BulkRowError{
+			{Row: 1, Message: "failed to store the batch of the row, please import it again"},
+			{Row: 5, Message: "failed to store the batch of the row, please import it again"},
+		}, report.Errors)
+	})
+
+	t.Run("should refuse too many rows", func(t *testing.T) {
+		s := NewService(mocks.NewMockParcelRepository(ctrl), nil, nil, nil)
+		_, err := s.ImportParcels(context.Background(), 3, make([]model.BulkRow, model.MaxBulkParcels+1))
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+	})
+}
@@ -0,0 +1,103 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation        = pq.ErrorCode("23505")
+	insertPromotionQuery      = `INSERT INTO promotion (code, discount_type, discount_value, min_order, max_uses, per_user_limit, expires_at) VALUES (:code, :discount_type, :discount_value, :min_order, :max_uses, :per_user_limit, :expires_at) RETURNING id, used_count, created_at`
+	fetchPromotionByCodeQuery = `SELECT id, code, discount_type, discount_value, min_order, max_uses, per_user_limit, used_count, expires_at, created_at FROM promotion WHERE code = $1`
+	countUserRedemptionsQuery = `SELECT COUNT(*) FROM promotion_redemption WHERE promotion_id = $1 AND user_id = $2`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates promotion repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) InsertPromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertPromotion] failed to begin transaction")
+		return model.Promotion{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertPromotionQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertPromotion] PrepareNamedContext Error: %v", err)
+		return model.Promotion{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &promotion, &promotion); err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.Promotion{}, fmt.Errorf("promo code %s already exists :%w", promotion.Code, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertPromotion] GetContext Error: %v", err)
+		return model.Promotion{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditPromotion, promotion.ID, nil, promotion); err != nil {
+		tx.Rollback()
+		return model.Promotion{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertPromotion] failed to commit")
+		return model.Promotion{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return promotion, nil
+}
+
+func (r *repository) FetchPromotionByCode(ctx context.Context, code string) (model.Promotion, error) {
+	var promotion model.Promotion
+
+	if err := r.db.GetContext(ctx, &promotion, fetchPromotionByCodeQuery, code); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Promotion{}, fmt.Errorf("promo code %s is not found. :%w", code, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchPromotionByCode] failed to fetch promotion Error: %v", err)
+		return model.Promotion{}, err
+	}
+
+	return promotion, nil
+}
+
+func (r *repository) CountUserRedemptions(ctx context.Context, promotionID int, userID int) (int, error) {
+	return CountUserRedemptions(ctx, r.db, promotionID, userID)
+}
+
+// Getter is satisfied by the database and the transactions the redemptions are counted in
+type Getter interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// CountUserRedemptions counts the redemptions of the promotion by the user. Parcels count them within
+// the transaction holding the promotion row lock, so the per user limit holds for concurrent orders.
+func CountUserRedemptions(ctx context.Context, q Getter, promotionID int, userID int) (int, error) {
+	var count int
+
+	if err := q.GetContext(ctx, &count, countUserRedemptionsQuery, promotionID, userID); err != nil {
+		log.Error().Err(err).Msgf("[CountUserRedemptions] failed to count redemptions Error: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,154 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_InsertPromotion(t *testing.T) {
+	promotion := model.Promotion{
+		Code:          "WELCOME10",
+		DiscountType:  model.DiscountTypePercentage,
+		DiscountValue: 10,
+		MaxUses:       100,
+		PerUserLimit:  1,
+	}
+
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		createdAt := time.Now()
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO promotion (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "used_count", "created_at"}).AddRow(1, 0, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditPromotion, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertPromotion(context.Background(), promotion)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, createdAt, result.CreatedAt)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return unique key violation error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO promotion (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnError(&pq.Error{Code: "23505"})
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertPromotion(context.Background(), promotion)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.Equal(t, model.Promotion{}, result)
+	})
+
+	t.Run("should return prepare statement error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO promotion (.+) VALUES (.+) RETURNING .+").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertPromotion(context.Background(), promotion)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchPromotionByCode(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM promotion WHERE (.+)").
+			WithArgs("WELCOME10").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "code", "discount_type", "discount_value"}).
+				AddRow(1, "WELCOME10", model.DiscountTypeFixed, 20))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchPromotionByCode(context.Background(), "WELCOME10")
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Promotion{ID: 1, Code: "WELCOME10", DiscountType: model.DiscountTypeFixed, DiscountValue: 20}, result)
+	})
+
+	t.Run("should return not found error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM promotion WHERE (.+)").
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchPromotionByCode(context.Background(), "WELCOME10")
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM promotion WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchPromotionByCode(context.Background(), "WELCOME10")
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_CountUserRedemptions(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT COUNT(.+) FROM promotion_redemption WHERE (.+)").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		repo := NewRepository(sqlxDB)
+		count, err := repo.CountUserRedemptions(context.Background(), 1, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT COUNT(.+) FROM promotion_redemption WHERE (.+)").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.CountUserRedemptions(context.Background(), 1, 2)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,67 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"strings"
+	"time"
+)
+
+type service struct {
+	repo svc.PromotionRepository
+}
+
+func NewService(repo svc.PromotionRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) CreatePromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error) {
+	promotion.Code = strings.ToUpper(promotion.Code)
+	return s.repo.InsertPromotion(ctx, promotion)
+}
+
+func (s *service) GetPromotionByCode(ctx context.Context, code string) (model.Promotion, error) {
+	return s.repo.FetchPromotionByCode(ctx, strings.ToUpper(code))
+}
+
+// ApplyPromotion checks that the promo code can be redeemed by the user for the given price and
+// returns the promotion with the discount. Usage limits are checked again when the redemption is
+// recorded, so concurrent orders can not exceed them.
+func (s *service) ApplyPromotion(ctx context.Context, code string, userID int, price float32) (model.Promotion, float32, error) {
+	promotion, err := s.GetPromotionByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return model.Promotion{}, 0, fmt.Errorf("promo code %s does not exist :%w", code, model.ErrInvalid)
+		}
+		return model.Promotion{}, 0, err
+	}
+
+	if promotion.IsExpired(time.Now()) {
+		return model.Promotion{}, 0, fmt.Errorf("promo code %s is expired :%w", code, model.ErrInvalid)
+	}
+
+	if promotion.MaxUses > 0 && promotion.UsedCount >= promotion.MaxUses {
+		return model.Promotion{}, 0, fmt.Errorf("promo code %s is fully redeemed :%w", code, model.ErrInvalid)
+	}
+
+	if price < promotion.MinOrder {
+		return model.Promotion{}, 0, fmt.Errorf("promo code %s requires minimum order of %.2f :%w", code, promotion.MinOrder, model.ErrInvalid)
+	}
+
+	if promotion.PerUserLimit > 0 {
+		count, err := s.repo.CountUserRedemptions(ctx, promotion.ID, userID)
+		if err != nil {
+			return model.Promotion{}, 0, err
+		}
+		if count >= promotion.PerUserLimit {
+			return model.Promotion{}, 0, fmt.Errorf("promo code %s is already used by the user :%w", code, model.ErrInvalid)
+		}
+	}
+
+	return promotion, promotion.Discount(price), nil
+}
@@ -0,0 +1,173 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CreatePromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	promotion := model.Promotion{Code: "welcome10", DiscountType: model.DiscountTypePercentage, DiscountValue: 10}
+
+	testCases := []struct {
+		desc     string
+		mockRepo func() *mocks.MockPromotionRepository
+		expErr   error
+	}{
+		{
+			desc: "should store upper case code",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().InsertPromotion(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p model.Promotion) (model.Promotion, error) {
+					assert.Equal(t, "WELCOME10", p.Code)
+					return p, nil
+				})
+				return r
+			},
+			expErr: nil,
+		},
+		{
+			desc: "should return db error",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().InsertPromotion(gomock.Any(), gomock.Any()).Return(model.Promotion{}, errors.New("db-error"))
+				return r
+			},
+			expErr: errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewService(tc.mockRepo())
+			_, err := s.CreatePromotion(context.Background(), promotion)
+			assert.Equal(t, tc.expErr, err)
+		})
+	}
+}
+
+func TestService_ApplyPromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	past := time.Now().Add(-time.Hour)
+	percentage := model.Promotion{ID: 1, Code: "TEN", DiscountType: model.DiscountTypePercentage, DiscountValue: 10}
+	fixed := model.Promotion{ID: 2, Code: "BIG", DiscountType: model.DiscountTypeFixed, DiscountValue: 500}
+	expired := model.Promotion{ID: 3, Code: "OLD", DiscountType: model.DiscountTypeFixed, DiscountValue: 5, ExpiresAt: &past}
+	usedUp := model.Promotion{ID: 4, Code: "USED", DiscountType: model.DiscountTypeFixed, DiscountValue: 5, MaxUses: 10, UsedCount: 10}
+	minOrder := model.Promotion{ID: 5, Code: "MIN", DiscountType: model.DiscountTypeFixed, DiscountValue: 5, MinOrder: 500}
+	perUser := model.Promotion{ID: 6, Code: "ONCE", DiscountType: model.DiscountTypeFixed, DiscountValue: 5, PerUserLimit: 1}
+
+	testCases := []struct {
+		desc        string
+		code        string
+		mockRepo    func() *mocks.MockPromotionRepository
+		expDiscount float32
+		expErr      error
+	}{
+		{
+			desc: "should return percentage discount",
+			code: "ten",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "TEN").Return(percentage, nil)
+				return r
+			},
+			expDiscount: 20,
+		},
+		{
+			desc: "should cap fixed discount at price",
+			code: "BIG",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "BIG").Return(fixed, nil)
+				return r
+			},
+			expDiscount: 200,
+		},
+		{
+			desc: "should return invalid for unknown code",
+			code: "NOPE",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "NOPE").Return(model.Promotion{}, model.ErrNotFound)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+		{
+			desc: "should return invalid for expired code",
+			code: "OLD",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "OLD").Return(expired, nil)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+		{
+			desc: "should return invalid for fully used code",
+			code: "USED",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "USED").Return(usedUp, nil)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+		{
+			desc: "should return invalid below minimum order",
+			code: "MIN",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "MIN").Return(minOrder, nil)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+		{
+			desc: "should return invalid when user limit is reached",
+			code: "ONCE",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "ONCE").Return(perUser, nil)
+				r.EXPECT().CountUserRedemptions(gomock.Any(), perUser.ID, 1).Return(1, nil)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+		{
+			desc: "should return db error",
+			code: "ONCE",
+			mockRepo: func() *mocks.MockPromotionRepository {
+				r := mocks.NewMockPromotionRepository(ctrl)
+				r.EXPECT().FetchPromotionByCode(gomock.Any(), "ONCE").Return(perUser, nil)
+				r.EXPECT().CountUserRedemptions(gomock.Any(), perUser.ID, 1).Return(0, errors.New("db-error"))
+				return r
+			},
+			expErr: errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewService(tc.mockRepo())
+			_, discount, err := s.ApplyPromotion(context.Background(), tc.code, 1, 200)
+			if tc.expErr != nil {
+				assert.True(t, errors.Is(err, tc.expErr) || err.Error() == tc.expErr.Error())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expDiscount, discount)
+		})
+	}
+}
@@ -0,0 +1,545 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/outbox"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation    = pq.ErrorCode("23505")
+	insertHubQuery        = `INSERT INTO hub (name, address, latitude, longitude) VALUES (:name, :address, :latitude, :longitude) RETURNING id, created_at`
+	fetchHubsQuery        = `SELECT id, name, address, latitude, longitude, created_at FROM hub ORDER BY id`
+	fetchHubsByIDQuery    = `SELECT id, name, address, latitude, longitude, created_at FROM hub WHERE id = ANY($1)`
+	lockParcelQuery       = `SELECT id, user_id, carrier_id, status, source_address, destination_address, COALESCE(shipment_id, 0) AS shipment_id FROM parcel WHERE id = $1 FOR UPDATE`
+	fetchParcelQuery      = `SELECT id, user_id, carrier_id, status FROM parcel WHERE id = $1`
+	countLegsQuery        = `SELECT COUNT(*) FROM parcel_leg WHERE parcel_id = $1`
+	insertLegQuery        = `INSERT INTO parcel_leg (parcel_id, sequence, from_hub_id, to_hub_id, from_address, to_address) VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6) RETURNING id, created_at`
+	rejectRequestsQuery   = `UPDATE carrier_request SET status = $1 WHERE parcel_id = $2 AND status = $3`
+	legColumns            = `id, parcel_id, sequence, COALESCE(from_hub_id, 0) AS from_hub_id, COALESCE(to_hub_id, 0) AS to_hub_id, from_address, to_address, carrier_id, status, assigned_at, picked_up_at, delivered_at, created_at`
+	fetchLegsQuery        = `SELECT ` + legColumns + ` FROM parcel_leg WHERE parcel_id = $1 ORDER BY sequence`
+	fetchLegQuery         = `SELECT ` + legColumns + ` FROM parcel_leg WHERE id = $1`
+	lockLegQuery          = `SELECT ` + legColumns + ` FROM parcel_leg WHERE id = $1 FOR UPDATE`
+	fetchPreviousLegQuery = `SELECT ` + legColumns + ` FROM parcel_leg WHERE parcel_id = $1 AND sequence = $2`
+	fetchTransfersQuery   = `SELECT custody_transfer.id, custody_transfer.parcel_id, custody_transfer.hub_id, hub.name AS hub_name, custody_transfer.from_leg_id, custody_transfer.to_leg_id, custody_transfer.from_carrier_id, custody_transfer.to_carrier_id, custody_transfer.arrived_at, custody_transfer.transferred_at FROM custody_transfer JOIN hub ON hub.id = custody_transfer.hub_id WHERE custody_transfer.parcel_id = $1 ORDER BY custody_transfer.id`
+	insertRequestQuery    = `INSERT INTO leg_request (carrier_id, leg_id, expires_at) SELECT $1, id, $3 FROM parcel_leg WHERE id = $2 AND status = $4 ON CONFLICT (leg_id, carrier_id) DO UPDATE SET status = $5, expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP WHERE leg_request.status = $6 OR (leg_request.status = $5 AND leg_request.expires_at <= $7)`
+	acceptRequestQuery    = `UPDATE leg_request SET status = $1 WHERE leg_id = $2 AND carrier_id = $3 AND status = $4 AND expires_at > $5`
+	rejectLegRequests     = `UPDATE leg_request SET status = $1 WHERE leg_id = $2 AND carrier_id != $3 AND status = $4`
+	assignLegQuery        = `UPDATE parcel_leg SET carrier_id = $1, status = $2, assigned_at = $3 WHERE id = $4`
+	updateLegQuery        = `UPDATE parcel_leg SET status = $1, picked_up_at = COALESCE(picked_up_at, $2), delivered_at = COALESCE(delivered_at, $3) WHERE id = $4`
+	insertTransferQuery   = `WITH transfer AS (INSERT INTO custody_transfer (parcel_id, hub_id, from_leg_id, to_leg_id, from_carrier_id, to_carrier_id, arrived_at, transferred_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, hub_id) SELECT transfer.id, hub.name FROM transfer JOIN hub ON hub.id = transfer.hub_id`
+	lockStatusQuery       = `SELECT status, COALESCE(carrier_id, 0) FROM parcel WHERE id = $1 FOR UPDATE`
+	updateParcelQuery     = `UPDATE parcel SET status = $1, carrier_id = $2, assigned_at = COALESCE(assigned_at, $3), picked_up_at = COALESCE(picked_up_at, $4), delivered_at = COALESCE(delivered_at, $5) WHERE id = $6`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates relay repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) InsertHub(ctx context.Context, hub model.Hub) (model.Hub, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertHub] failed to begin transaction")
+		return model.Hub{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertHubQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertHub] PrepareNamedContext Error: %v", err)
+		return model.Hub{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &hub, hub); err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.Hub{}, fmt.Errorf("hub %s already exists :%w", hub.Name, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertHub] failed to insert hub Error: %v", err)
+		return model.Hub{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditHub, hub.ID, nil, hub); err != nil {
+		tx.Rollback()
+		return model.Hub{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertHub] failed to commit")
+		return model.Hub{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return hub, nil
+}
+
+func (r *repository) FetchHubs(ctx context.Context) ([]model.Hub, error) {
+	hubs := []model.Hub{}
+	if err := r.db.SelectContext(ctx, &hubs, fetchHubsQuery); err != nil {
+		log.Error().Err(err).Msgf("[FetchHubs] failed to fetch hubs Error: %v", err)
+		return nil, err
+	}
+	return hubs, nil
+}
+
+// InsertLegs splits a parcel that is still waiting for a carrier into legs through the hubs in one transaction.
+// The pending carrier requests for the whole parcel are rejected, the legs are requested one by one instead.
+func (r *repository) InsertLegs(ctx context.Context, split model.RelaySplit) ([]model.Leg, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertLegs] failed to begin transaction")
+		return nil, err
+	}
+
+	var parcel model.Parcel
+	if err := tx.GetContext(ctx, &parcel, lockParcelQuery, split.ParcelID); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("parcel with the ID %d is not found. :%w", split.ParcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[InsertLegs] failed to lock parcel %d Error: %v", split.ParcelID, err)
+		return nil, err
+	}
+	if parcel.Status != model.ParcelStatusCreated {
+		tx.Rollback()
+		return nil, fmt.Errorf("parcel %d is %s :%w", parcel.ID, model.ParcelStatusName(parcel.Status), model.ErrInvalid)
+	}
+	if parcel.ShipmentID != 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("parcel %d is delivered with shipment %d :%w", parcel.ID, parcel.ShipmentID, model.ErrInvalid)
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, countLegsQuery, parcel.ID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertLegs] failed to count legs Error: %v", err)
+		return nil, err
+	}
+	if count > 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("parcel %d is already split into legs :%w", parcel.ID, model.ErrInvalid)
+	}
+
+	var found []model.Hub
+	if err := tx.SelectContext(ctx, &found, fetchHubsByIDQuery, pq.Array(split.HubIDs)); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertLegs] failed to fetch hubs Error: %v", err)
+		return nil, err
+	}
+	byID := make(map[int]model.Hub, len(found))
+	for _, hub := range found {
+		byID[hub.ID] = hub
+	}
+	hubs := make([]model.Hub, 0, len(split.HubIDs))
+	for _, hubID := range split.HubIDs {
+		hub, ok := byID[hubID]
+		if !ok {
+			tx.Rollback()
+			return nil, fmt.Errorf("hub with the ID %d is not found. :%w", hubID, model.ErrNotFound)
+		}
+		hubs = append(hubs, hub)
+	}
+
+	legs := model.NewLegs(parcel, hubs)
+	for i := range legs {
+		leg := &legs[i]
+		err := tx.QueryRowxContext(ctx, insertLegQuery, leg.ParcelID, leg.Sequence, leg.FromHubID, leg.ToHubID, leg.FromAddress, leg.ToAddress).Scan(&leg.ID, &leg.CreatedAt)
+		if err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[InsertLegs] failed to insert leg Error: %v", err)
+			return nil, err
+		}
+		if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditLeg, leg.ID, nil, *leg); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, rejectRequestsQuery, model.CarrierRequestRejected, parcel.ID, model.CarrierRequestPending); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertLegs] failed to reject carrier requests Error: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertLegs] failed to commit")
+		return nil, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return legs, nil
+}
+
+// FetchRelay returns the parcel with its legs in sequence and the custody transfers at its hubs
+func (r *repository) FetchRelay(ctx context.Context, parcelID int) (model.Relay, error) {
+	var parcel model.Parcel
+	if err := r.db.GetContext(ctx, &parcel, fetchParcelQuery, parcelID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Relay{}, fmt.Errorf("parcel with the ID %d is not found. :%w", parcelID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchRelay] failed to fetch parcel %d Error: %v", parcelID, err)
+		return model.Relay{}, err
+	}
+
+	relay := model.Relay{
+		ParcelID:  parcel.ID,
+		UserID:    parcel.UserID,
+		CarrierID: parcel.CarrierID,
+		Status:    parcel.Status,
+		Legs:      []model.Leg{},
+		Transfers: []model.CustodyTransfer{},
+	}
+	if err := r.db.SelectContext(ctx, &relay.Legs, fetchLegsQuery, parcelID); err != nil {
+		log.Error().Err(err).Msgf("[FetchRelay] failed to fetch legs of parcel %d Error: %v", parcelID, err)
+		return model.Relay{}, err
+	}
+	if len(relay.Legs) == 0 {
+		return model.Relay{}, fmt.Errorf("parcel %d is not delivered in legs :%w", parcelID, model.ErrNotFound)
+	}
+	if err := r.db.SelectContext(ctx, &relay.Transfers, fetchTransfersQuery, parcelID); err != nil {
+		log.Error().Err(err).Msgf("[FetchRelay] failed to fetch custody transfers of parcel %d Error: %v", parcelID, err)
+		return model.Relay{}, err
+	}
+	return relay, nil
+}
+
+func (r *repository) FetchLeg(ctx context.Context, legID int) (model.Leg, error) {
+	var leg model.Leg
+	if err := r.db.GetContext(ctx, &leg, fetchLegQuery, legID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Leg{}, fmt.Errorf("leg with the ID %d is not found. :%w", legID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchLeg] failed to fetch leg %d Error: %v", legID, err)
+		return model.Leg{}, err
+	}
+	return leg, nil
+}
+
+// InsertLegRequest stores a pending request of the carrier for a leg that still needs a carrier, an expired
+// request of the carrier is renewed while any other existing request is refused
+func (r *repository) InsertLegRequest(ctx context.Context, request model.LegRequest, now time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertLegRequest] failed to begin transaction")
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, insertRequestQuery, request.CarrierID, request.LegID, request.ExpiresAt,
+		model.ParcelStatusCreated, model.CarrierRequestPending, model.CarrierRequestExpired, now)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertLegRequest] failed to insert request of carrier %d Error: %v", request.CarrierID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rows == 0 {
+		tx.Rollback()
+		return fmt.Errorf("carrier %d has already requested leg %d or it has a carrier :%w", request.CarrierID, request.LegID, model.ErrInvalid)
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditLegRequest, request.LegID, nil, request); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertLegRequest] failed to commit")
+		return fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return nil
+}
+
+// AcceptLegRequest accepts the request of the carrier for the leg, rejects the others and assigns the carrier to
+// the leg in one transaction, like the acceptance of a carrier request for a whole parcel. The parcel follows
+// its legs and both changes are written to the outbox.
+func (r *repository) AcceptLegRequest(ctx context.Context, request model.LegRequest, assignedAt time.Time) (model.LegChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[AcceptLegRequest] failed to begin transaction")
+		return model.LegChange{}, err
+	}
+
+	leg, err := lockLeg(ctx, tx, request.LegID)
+	if err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+	if leg.Status != model.ParcelStatusCreated {
+		tx.Rollback()
+		return model.LegChange{}, fmt.Errorf("leg %d of parcel %d is %s :%w", leg.ID, leg.ParcelID, model.ParcelStatusName(leg.Status), model.ErrInvalid)
+	}
+
+	result, err := tx.ExecContext(ctx, acceptRequestQuery, model.CarrierRequestAccepted, leg.ID, request.CarrierID, model.CarrierRequestPending, assignedAt)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptLegRequest] failed to accept request Error: %v", err)
+		return model.LegChange{}, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+	if rows == 0 {
+		tx.Rollback()
+		return model.LegChange{}, fmt.Errorf("carrier %d has no open request for leg %d :%w", request.CarrierID, leg.ID, model.ErrInvalid)
+	}
+
+	if _, err := tx.ExecContext(ctx, rejectLegRequests, model.CarrierRequestRejected, leg.ID, request.CarrierID, model.CarrierRequestPending); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptLegRequest] failed to reject requests Error: %v", err)
+		return model.LegChange{}, err
+	}
+	if _, err := tx.ExecContext(ctx, assignLegQuery, request.CarrierID, model.ParcelStatusAssigned, assignedAt, leg.ID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptLegRequest] failed to assign leg Error: %v", err)
+		return model.LegChange{}, err
+	}
+	if err := audit.Write(ctx, tx, model.AuditAccept, model.AuditLeg, leg.ID,
+		map[string]interface{}{"status": leg.Status, "carrier_id": leg.CarrierID},
+		map[string]interface{}{"status": model.ParcelStatusAssigned, "carrier_id": request.CarrierID, "assigned_at": assignedAt},
+	); err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+	leg.CarrierID = request.CarrierID
+	leg.Status = model.ParcelStatusAssigned
+	leg.AssignedAt = &assignedAt
+
+	change, err := syncParcel(ctx, tx, leg, assignedAt)
+	if err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+	if err := outbox.Write(ctx, tx, model.Event{
+		Type:      model.EventLegAssigned,
+		ParcelID:  leg.ParcelID,
+		CarrierID: leg.CarrierID,
+		Leg:       leg.Sequence,
+	}); err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+	if err := writeStatusChange(ctx, tx, change); err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[AcceptLegRequest] failed to commit")
+		return model.LegChange{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return change, nil
+}
+
+// UpdateLegStatus lets the carrier of a leg pick the parcel up or drop it off. A leg after the first is picked up
+// at its hub once the previous leg was dropped there, the hand over is recorded as a custody transfer. The parcel
+// follows its legs in the same transaction.
+func (r *repository) UpdateLegStatus(ctx context.Context, update model.LegUpdate, now time.Time) (model.LegChange, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[UpdateLegStatus] failed to begin transaction")
+		return model.LegChange{}, err
+	}
+
+	change, err := UpdateLegStatus(ctx, tx, update, now)
+	if err != nil {
+		tx.Rollback()
+		return model.LegChange{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[UpdateLegStatus] failed to commit")
+		return model.LegChange{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return change, nil
+}
+
+// UpdateLegStatus advances the leg and its parcel inside the transaction of the caller, like the method of the
+// repository. The caller rolls back on error.
+func UpdateLegStatus(ctx context.Context, tx *sqlx.Tx, update model.LegUpdate, now time.Time) (model.LegChange, error) {
+	leg, err := lockLeg(ctx, tx, update.LegID)
+	if err != nil {
+		return model.LegChange{}, err
+	}
+	if leg.CarrierID != update.CarrierID {
+		return model.LegChange{}, fmt.Errorf("carrier %d is not carrying leg %d :%w", update.CarrierID, leg.ID, model.ErrForbidden)
+	}
+	// a leg is picked up once it is assigned and delivered once it is picked up
+	if leg.Status != update.Status-1 {
+		return model.LegChange{}, fmt.Errorf("leg %d is %s and can not be %s :%w", leg.ID, model.ParcelStatusName(leg.Status), model.ParcelStatusName(update.Status), model.ErrInvalid)
+	}
+
+	before := map[string]interface{}{"status": leg.Status, "picked_up_at": leg.PickedUpAt, "delivered_at": leg.DeliveredAt}
+	var transfer *model.CustodyTransfer
+	switch update.Status {
+	case model.ParcelStatusPickedUp:
+		leg.PickedUpAt = &now
+		if leg.Sequence > 1 {
+			if transfer, err = insertTransfer(ctx, tx, leg, now); err != nil {
+				return model.LegChange{}, err
+			}
+		}
+	case model.ParcelStatusDelivered:
+		leg.DeliveredAt = &now
+	}
+	leg.Status = update.Status
+
+	if _, err := tx.ExecContext(ctx, updateLegQuery, leg.Status, leg.PickedUpAt, leg.DeliveredAt, leg.ID); err != nil {
+		log.Error().Err(err).Msgf("[UpdateLegStatus] failed to update leg %d Error: %v", leg.ID, err)
+		return model.LegChange{}, err
+	}
+	if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditLeg, leg.ID, before,
+		map[string]interface{}{"status": leg.Status, "picked_up_at": leg.PickedUpAt, "delivered_at": leg.DeliveredAt},
+	); err != nil {
+		return model.LegChange{}, err
+	}
+
+	change, err := syncParcel(ctx, tx, leg, now)
+	if err != nil {
+		return model.LegChange{}, err
+	}
+	change.Transfer = transfer
+	if transfer != nil {
+		if err := outbox.Write(ctx, tx, model.Event{
+			Type:      model.EventCustodyTransferred,
+			ParcelID:  leg.ParcelID,
+			CarrierID: transfer.ToCarrierID,
+			Leg:       leg.Sequence,
+			Hub:       transfer.HubName,
+		}); err != nil {
+			return model.LegChange{}, err
+		}
+	}
+	if err := writeStatusChange(ctx, tx, change); err != nil {
+		return model.LegChange{}, err
+	}
+	return change, nil
+}
+
+func lockLeg(ctx context.Context, tx *sqlx.Tx, legID int) (model.Leg, error) {
+	var leg model.Leg
+	if err := tx.GetContext(ctx, &leg, lockLegQuery, legID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Leg{}, fmt.Errorf("leg with the ID %d is not found. :%w", legID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[lockLeg] failed to lock leg %d Error: %v", legID, err)
+		return model.Leg{}, err
+	}
+	return leg, nil
+}
+
+// insertTransfer records the hand over of the parcel from the carrier of the previous leg, which must have
+// dropped it at the hub the leg starts from
+func insertTransfer(ctx context.Context, tx *sqlx.Tx, leg model.Leg, now time.Time) (*model.CustodyTransfer, error) {
+	var previous model.Leg
+	if err := tx.GetContext(ctx, &previous, fetchPreviousLegQuery, leg.ParcelID, leg.Sequence-1); err != nil {
+		log.Error().Err(err).Msgf("[insertTransfer] failed to fetch leg %d of parcel %d Error: %v", leg.Sequence-1, leg.ParcelID, err)
+		return nil, err
+	}
+	if previous.Status != model.ParcelStatusDelivered || previous.DeliveredAt == nil {
+		return nil, fmt.Errorf("parcel %d has not arrived at the hub of leg %d yet :%w", leg.ParcelID, leg.ID, model.ErrInvalid)
+	}
+
+	transfer := model.CustodyTransfer{
+		ParcelID:      leg.ParcelID,
+		HubID:         leg.FromHubID,
+		FromLegID:     previous.ID,
+		ToLegID:       leg.ID,
+		FromCarrierID: previous.CarrierID,
+		ToCarrierID:   leg.CarrierID,
+		ArrivedAt:     *previous.DeliveredAt,
+		TransferredAt: now,
+	}
+	err := tx.QueryRowxContext(ctx, insertTransferQuery, transfer.ParcelID, transfer.HubID, transfer.FromLegID, transfer.ToLegID,
+		transfer.FromCarrierID, transfer.ToCarrierID, transfer.ArrivedAt, transfer.TransferredAt).Scan(&transfer.ID, &transfer.HubName)
+	if err != nil {
+		log.Error().Err(err).Msgf("[insertTransfer] failed to insert custody transfer Error: %v", err)
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// syncParcel sets the status and carrier of the parcel to the ones derived from its legs and audits the change,
+// a cancelled or returned parcel no longer moves
+func syncParcel(ctx context.Context, tx *sqlx.Tx, leg model.Leg, now time.Time) (model.LegChange, error) {
+	var previous, previousCarrierID int
+	if err := tx.QueryRowContext(ctx, lockStatusQuery, leg.ParcelID).Scan(&previous, &previousCarrierID); err != nil {
+		log.Error().Err(err).Msgf("[syncParcel] failed to lock parcel %d Error: %v", leg.ParcelID, err)
+		return model.LegChange{}, err
+	}
+	if previous == model.ParcelStatusCancelled || previous == model.ParcelStatusReturned {
+		return model.LegChange{}, fmt.Errorf("parcel %d is %s :%w", leg.ParcelID, model.ParcelStatusName(previous), model.ErrInvalid)
+	}
+
+	var legs []model.Leg
+	if err := tx.SelectContext(ctx, &legs, fetchLegsQuery, leg.ParcelID); err != nil {
+		log.Error().Err(err).Msgf("[syncParcel] failed to fetch legs of parcel %d Error: %v", leg.ParcelID, err)
+		return model.LegChange{}, err
+	}
+	for i := range legs {
+		if legs[i].ID == leg.ID {
+			legs[i] = leg
+		}
+	}
+	status, carrierID := model.RelayState(legs)
+
+	var assignedAt, pickedUpAt, deliveredAt *time.Time
+	if status >= model.ParcelStatusAssigned {
+		assignedAt = &now
+	}
+	if status >= model.ParcelStatusPickedUp {
+		pickedUpAt = &now
+	}
+	if status == model.ParcelStatusDelivered {
+		deliveredAt = &now
+	}
+	if _, err := tx.ExecContext(ctx, updateParcelQuery, status, carrierID, assignedAt, pickedUpAt, deliveredAt, leg.ParcelID); err != nil {
+		log.Error().Err(err).Msgf("[syncParcel] failed to update parcel %d Error: %v", leg.ParcelID, err)
+		return model.LegChange{}, err
+	}
+	if status != previous || carrierID != previousCarrierID {
+		if err := audit.Write(ctx, tx, model.AuditUpdate, model.AuditParcel, leg.ParcelID,
+			map[string]interface{}{"status": previous, "carrier_id": previousCarrierID},
+			map[string]interface{}{"status": status, "carrier_id": carrierID},
+		); err != nil {
+			return model.LegChange{}, err
+		}
+	}
+
+	return model.LegChange{
+		Leg:          leg,
+		ParcelStatus: status,
+		CarrierID:    carrierID,
+		Changed:      status != previous,
+	}, nil
+}
+
+func writeStatusChange(ctx context.Context, tx *sqlx.Tx, change model.LegChange) error {
+	if !change.Changed {
+		return nil
+	}
+	return outbox.Write(ctx, tx, model.Event{
+		Type:      model.EventParcelStatusChanged,
+		ParcelID:  change.Leg.ParcelID,
+		CarrierID: change.CarrierID,
+		Status:    change.ParcelStatus,
+	})
+}
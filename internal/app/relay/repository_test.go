@@ -0,0 +1,462 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var legFields = []string{"id", "parcel_id", "sequence", "from_hub_id", "to_hub_id", "from_address", "to_address", "carrier_id", "status", "assigned_at", "picked_up_at", "delivered_at", "created_at"}
+
+func TestRepository_InsertHub(t *testing.T) {
+	createdAt := time.Now()
+	hub := model.Hub{Name: "Bogura Hub", Address: "Bogura Sadar", Latitude: 24.85, Longitude: 89.37}
+
+	t.Run("should store hub", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO hub (.+) RETURNING id, created_at").
+			ExpectQuery().
+			WithArgs("Bogura Hub", "Bogura Sadar", 24.85, 89.37).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditHub, 2, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertHub(context.Background(), hub)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, result.ID)
+		assert.Equal(t, createdAt, result.CreatedAt)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid for a duplicate name", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO hub (.+)").
+			ExpectQuery().
+			WillReturnError(&pq.Error{Code: "23505"})
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertHub(context.Background(), hub)
+		assert.EqualError(t, err, "hub Bogura Hub already exists :invalid")
+	})
+}
+
+func TestRepository_InsertLegs(t *testing.T) {
+	createdAt := time.Now()
+	split := model.RelaySplit{ParcelID: 1, UserID: 3, HubIDs: []int{2, 1}}
+	parcelFields := []string{"id", "user_id", "carrier_id", "status", "source_address", "destination_address", "shipment_id"}
+
+	t.Run("should split parcel through hubs in the given order", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(parcelFields).AddRow(1, 3, 0, model.ParcelStatusCreated, "Dhaka Bangladesh", "Rangpur", 0))
+		m.ExpectQuery("SELECT COUNT(.+) FROM parcel_leg").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		m.ExpectQuery("SELECT (.+) FROM hub WHERE id = ANY(.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "address", "latitude", "longitude", "created_at"}).
+				AddRow(1, "Rangpur Hub", "Rangpur Sadar", 25.74, 89.27, createdAt).
+				AddRow(2, "Bogura Hub", "Bogura Sadar", 24.85, 89.37, createdAt))
+		m.ExpectQuery("INSERT INTO parcel_leg (.+)").
+			WithArgs(1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(10, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditLeg, 10, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("INSERT INTO parcel_leg (.+)").
+			WithArgs(1, 2, 2, 1, "Bogura Sadar", "Rangpur Sadar").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditLeg, 11, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("INSERT INTO parcel_leg (.+)").
+			WithArgs(1, 3, 1, 0, "Rangpur Sadar", "Rangpur").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(12, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditLeg, 12, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE parcel_id = (.+)").
+			WithArgs(model.CarrierRequestRejected, 1, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		legs, err := repo.InsertLegs(context.Background(), split)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []model.Leg{
+			{ID: 10, ParcelID: 1, Sequence: 1, ToHubID: 2, FromAddress: "Dhaka Bangladesh", ToAddress: "Bogura Sadar", Status: model.ParcelStatusCreated, CreatedAt: createdAt},
+			{ID: 11, ParcelID: 1, Sequence: 2, FromHubID: 2, ToHubID: 1, FromAddress: "Bogura Sadar", ToAddress: "Rangpur Sadar", Status: model.ParcelStatusCreated, CreatedAt: createdAt},
+			{ID: 12, ParcelID: 1, Sequence: 3, FromHubID: 1, FromAddress: "Rangpur Sadar", ToAddress: "Rangpur", Status: model.ParcelStatusCreated, CreatedAt: createdAt},
+		}, legs)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not split a parcel with a carrier", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(parcelFields).AddRow(1, 3, 7, model.ParcelStatusAssigned, "Dhaka Bangladesh", "Rangpur", 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertLegs(context.Background(), split)
+		assert.EqualError(t, err, "parcel 1 is assigned :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found for an unknown hub", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(parcelFields).AddRow(1, 3, 0, model.ParcelStatusCreated, "Dhaka Bangladesh", "Rangpur", 0))
+		m.ExpectQuery("SELECT COUNT(.+) FROM parcel_leg").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		m.ExpectQuery("SELECT (.+) FROM hub WHERE id = ANY(.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "address", "latitude", "longitude", "created_at"}).
+				AddRow(1, "Rangpur Hub", "Rangpur Sadar", 25.74, 89.27, createdAt))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertLegs(context.Background(), split)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FetchRelay(t *testing.T) {
+	createdAt := time.Now()
+
+	t.Run("should return legs and custody transfers", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT id, user_id, carrier_id, status FROM parcel WHERE id = (.+)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "carrier_id", "status"}).AddRow(1, 3, 8, model.ParcelStatusPickedUp))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) ORDER BY sequence").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(legFields).
+				AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusDelivered, createdAt, createdAt, createdAt, createdAt).
+				AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusPickedUp, createdAt, createdAt, nil, createdAt))
+		m.ExpectQuery("SELECT (.+) FROM custody_transfer JOIN hub (.+)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "parcel_id", "hub_id", "hub_name", "from_leg_id", "to_leg_id", "from_carrier_id", "to_carrier_id", "arrived_at", "transferred_at"}).
+				AddRow(4, 1, 2, "Bogura Hub", 10, 11, 7, 8, createdAt, createdAt))
+
+		repo := NewRepository(sqlxDB)
+		relay, err := repo.FetchRelay(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 8, relay.CarrierID)
+		assert.Len(t, relay.Legs, 2)
+		assert.Nil(t, relay.Legs[1].DeliveredAt)
+		assert.Equal(t, []model.CustodyTransfer{{ID: 4, ParcelID: 1, HubID: 2, HubName: "Bogura Hub", FromLegID: 10, ToLegID: 11, FromCarrierID: 7, ToCarrierID: 8, ArrivedAt: createdAt, TransferredAt: createdAt}}, relay.Transfers)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found for a parcel without legs", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("SELECT id, user_id, carrier_id, status FROM parcel WHERE id = (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "carrier_id", "status"}).AddRow(1, 3, 0, model.ParcelStatusCreated))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+)").
+			WillReturnRows(sqlmock.NewRows(legFields))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchRelay(context.Background(), 1)
+		assert.EqualError(t, err, "parcel 1 is not delivered in legs :not found")
+	})
+}
+
+func TestRepository_InsertLegRequest(t *testing.T) {
+	now := time.Now()
+	request := model.LegRequest{LegID: 10, CarrierID: 7, ExpiresAt: now.Add(time.Hour)}
+
+	t.Run("should store request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO leg_request (.+) SELECT (.+) FROM parcel_leg WHERE (.+) ON CONFLICT (.+)").
+			WithArgs(7, 10, request.ExpiresAt, model.ParcelStatusCreated, model.CarrierRequestPending, model.CarrierRequestExpired, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditLegRequest, 10, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		assert.Nil(t, repo.InsertLegRequest(context.Background(), request, now))
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid for an existing request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectExec("INSERT INTO leg_request (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		err := repo.InsertLegRequest(context.Background(), request, now)
+		assert.EqualError(t, err, "carrier 7 has already requested leg 10 or it has a carrier :invalid")
+	})
+}
+
+func TestRepository_AcceptLegRequest(t *testing.T) {
+	now := time.Now()
+	request := model.LegRequest{LegID: 10, CarrierID: 7}
+
+	t.Run("should assign carrier to leg and parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WithArgs(10).
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 0, model.ParcelStatusCreated, nil, nil, nil, now))
+		m.ExpectExec("UPDATE leg_request SET status = (.+) WHERE leg_id = (.+) AND carrier_id = (.+) AND status = (.+)").
+			WithArgs(model.CarrierRequestAccepted, 10, 7, model.CarrierRequestPending, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("UPDATE leg_request SET status = (.+) WHERE leg_id = (.+) AND carrier_id != (.+)").
+			WithArgs(model.CarrierRequestRejected, 10, 7, model.CarrierRequestPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("UPDATE parcel_leg SET carrier_id = (.+)").
+			WithArgs(7, model.ParcelStatusAssigned, now, 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditLeg, 10, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusCreated, 0))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) ORDER BY sequence").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(legFields).
+				AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 0, model.ParcelStatusCreated, nil, nil, nil, now).
+				AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 0, model.ParcelStatusCreated, nil, nil, nil, now))
+		m.ExpectExec("UPDATE parcel SET status = (.+), carrier_id = (.+)").
+			WithArgs(model.ParcelStatusAssigned, 7, &now, nil, nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, `{"carrier_id":{"before":0,"after":7},"status":{"before":1,"after":2}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		change, err := repo.AcceptLegRequest(context.Background(), request, now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.ParcelStatusAssigned, change.Leg.Status)
+		assert.Equal(t, 7, change.Leg.CarrierID)
+		assert.Equal(t, model.ParcelStatusAssigned, change.ParcelStatus)
+		assert.Equal(t, 7, change.CarrierID)
+		assert.True(t, change.Changed)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not assign without an open request", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 0, model.ParcelStatusCreated, nil, nil, nil, now))
+		m.ExpectExec("UPDATE leg_request SET status = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptLegRequest(context.Background(), request, now)
+		assert.EqualError(t, err, "carrier 7 has no open request for leg 10 :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found for an unknown leg", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnError(sql.ErrNoRows)
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptLegRequest(context.Background(), request, now)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
+
+func TestRepository_UpdateLegStatus(t *testing.T) {
+	now := time.Now()
+	arrivedAt := now.Add(-time.Hour)
+	pickup := model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusPickedUp}
+
+	t.Run("should record custody transfer when the next leg is picked up at the hub", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WithArgs(11).
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusAssigned, now, nil, nil, now))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) AND sequence = (.+)").
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusDelivered, now, now, arrivedAt, now))
+		m.ExpectQuery("WITH transfer AS (.+) SELECT transfer.id, hub.name (.+)").
+			WithArgs(1, 2, 10, 11, 7, 8, arrivedAt, now).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Bogura Hub"))
+		m.ExpectExec("UPDATE parcel_leg SET status = (.+)").
+			WithArgs(model.ParcelStatusPickedUp, &now, nil, 11).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditLeg, 11, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusPickedUp, 7))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) ORDER BY sequence").
+			WillReturnRows(sqlmock.NewRows(legFields).
+				AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusDelivered, now, now, arrivedAt, now).
+				AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusAssigned, now, nil, nil, now))
+		m.ExpectExec("UPDATE parcel SET status = (.+), carrier_id = (.+)").
+			WithArgs(model.ParcelStatusPickedUp, 8, &now, &now, nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, `{"carrier_id":{"before":7,"after":8}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		change, err := repo.UpdateLegStatus(context.Background(), pickup, now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.ParcelStatusPickedUp, change.Leg.Status)
+		assert.Equal(t, model.ParcelStatusPickedUp, change.ParcelStatus)
+		assert.Equal(t, 8, change.CarrierID)
+		assert.False(t, change.Changed)
+		assert.Equal(t, &model.CustodyTransfer{ID: 4, ParcelID: 1, HubID: 2, HubName: "Bogura Hub", FromLegID: 10, ToLegID: 11, FromCarrierID: 7, ToCarrierID: 8, ArrivedAt: arrivedAt, TransferredAt: now}, change.Transfer)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not pick up before the parcel arrived at the hub", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusAssigned, now, nil, nil, now))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) AND sequence = (.+)").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusPickedUp, now, now, nil, now))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.UpdateLegStatus(context.Background(), pickup, now)
+		assert.EqualError(t, err, "parcel 1 has not arrived at the hub of leg 11 yet :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should deliver parcel with its last leg", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusPickedUp, now, now, nil, now))
+		m.ExpectExec("UPDATE parcel_leg SET status = (.+)").
+			WithArgs(model.ParcelStatusDelivered, &now, &now, 11).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditLeg, 11, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectQuery("SELECT status, (.+) FROM parcel WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"status", "carrier_id"}).AddRow(model.ParcelStatusPickedUp, 8))
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE parcel_id = (.+) ORDER BY sequence").
+			WillReturnRows(sqlmock.NewRows(legFields).
+				AddRow(10, 1, 1, 0, 2, "Dhaka Bangladesh", "Bogura Sadar", 7, model.ParcelStatusDelivered, now, now, arrivedAt, now).
+				AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 8, model.ParcelStatusPickedUp, now, now, nil, now))
+		m.ExpectExec("UPDATE parcel SET status = (.+), carrier_id = (.+)").
+			WithArgs(model.ParcelStatusDelivered, 8, &now, &now, &now, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditUpdate, model.AuditParcel, 1, `{"status":{"before":3,"after":4}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		change, err := repo.UpdateLegStatus(context.Background(), model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusDelivered}, now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.ParcelStatusDelivered, change.ParcelStatus)
+		assert.True(t, change.Changed)
+		assert.Nil(t, change.Transfer)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return forbidden for another carrier", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT (.+) FROM parcel_leg WHERE id = (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows(legFields).AddRow(11, 1, 2, 2, 0, "Bogura Sadar", "Rangpur", 9, model.ParcelStatusAssigned, now, nil, nil, now))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.UpdateLegStatus(context.Background(), pickup, now)
+		assert.EqualError(t, err, "carrier 8 is not carrying leg 11 :forbidden")
+	})
+}
@@ -0,0 +1,151 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/carrier"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+)
+
+type service struct {
+	repo       svc.RelayRepository
+	parcelRepo svc.ParcelRepository
+	notifier   svc.EventNotifier
+	requestTTL time.Duration
+}
+
+func NewService(repo svc.RelayRepository, parcelRepo svc.ParcelRepository, notifier svc.EventNotifier) *service {
+	return &service{
+		repo:       repo,
+		parcelRepo: parcelRepo,
+		notifier:   notifier,
+		requestTTL: carrier.REQUEST_TTL,
+	}
+}
+
+func (s *service) CreateHub(ctx context.Context, hub model.Hub) (model.Hub, error) {
+	return s.repo.InsertHub(ctx, hub)
+}
+
+func (s *service) GetHubs(ctx context.Context) ([]model.Hub, error) {
+	return s.repo.FetchHubs(ctx)
+}
+
+// SplitParcel lets the sender relay a parcel that is still waiting for a carrier through hubs
+func (s *service) SplitParcel(ctx context.Context, split model.RelaySplit) (model.Relay, error) {
+	parcel, err := s.parcelRepo.FetchParcelByID(ctx, split.ParcelID)
+	if err != nil {
+		return model.Relay{}, err
+	}
+
+	if parcel.UserID != split.UserID {
+		return model.Relay{}, fmt.Errorf("user %d did not send parcel %d :%w", split.UserID, parcel.ID, model.ErrForbidden)
+	}
+
+	legs, err := s.repo.InsertLegs(ctx, split)
+	if err != nil {
+		return model.Relay{}, err
+	}
+
+	return model.Relay{
+		ParcelID:  parcel.ID,
+		UserID:    parcel.UserID,
+		Status:    model.ParcelStatusCreated,
+		Legs:      legs,
+		Transfers: []model.CustodyTransfer{},
+	}, nil
+}
+
+func (s *service) GetRelay(ctx context.Context, parcelID int) (model.Relay, error) {
+	return s.repo.FetchRelay(ctx, parcelID)
+}
+
+// RequestLeg lets the carrier request a leg that still needs a carrier
+func (s *service) RequestLeg(ctx context.Context, request model.LegRequest) (model.LegRequest, error) {
+	leg, err := s.repo.FetchLeg(ctx, request.LegID)
+	if err != nil {
+		return model.LegRequest{}, err
+	}
+
+	request.Status = model.CarrierRequestPending
+	request.ExpiresAt = time.Now().Add(s.requestTTL)
+	if err := s.repo.InsertLegRequest(ctx, request, time.Now()); err != nil {
+		return model.LegRequest{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventCarrierRequested,
+		ParcelID:  leg.ParcelID,
+		CarrierID: request.CarrierID,
+		Leg:       leg.Sequence,
+	})
+	return request, nil
+}
+
+// AcceptLeg lets the sender assign the requesting carrier to a leg of the parcel
+func (s *service) AcceptLeg(ctx context.Context, userID int, request model.LegRequest) (model.LegChange, error) {
+	leg, err := s.repo.FetchLeg(ctx, request.LegID)
+	if err != nil {
+		return model.LegChange{}, err
+	}
+
+	parcel, err := s.parcelRepo.FetchParcelByID(ctx, leg.ParcelID)
+	if err != nil {
+		return model.LegChange{}, err
+	}
+
+	if parcel.UserID != userID {
+		return model.LegChange{}, fmt.Errorf("user %d did not send parcel %d :%w", userID, parcel.ID, model.ErrForbidden)
+	}
+
+	change, err := s.repo.AcceptLegRequest(ctx, request, time.Now())
+	if err != nil {
+		return model.LegChange{}, err
+	}
+
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventLegAssigned,
+		ParcelID:  leg.ParcelID,
+		UserID:    parcel.UserID,
+		CarrierID: request.CarrierID,
+		Leg:       leg.Sequence,
+	})
+	s.notifyStatus(ctx, parcel.UserID, change)
+	return change, nil
+}
+
+// UpdateLeg lets the carrier of a leg pick the parcel up or drop it off
+func (s *service) UpdateLeg(ctx context.Context, update model.LegUpdate) (model.LegChange, error) {
+	change, err := s.repo.UpdateLegStatus(ctx, update, time.Now())
+	if err != nil {
+		return model.LegChange{}, err
+	}
+
+	if change.Transfer != nil {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventCustodyTransferred,
+			ParcelID:  change.Leg.ParcelID,
+			CarrierID: change.Transfer.ToCarrierID,
+			Leg:       change.Leg.Sequence,
+			Hub:       change.Transfer.HubName,
+		})
+	}
+	s.notifyStatus(ctx, 0, change)
+	return change, nil
+}
+
+// notifyStatus tells the sender and the carrier holding the parcel that its status moved with the leg
+func (s *service) notifyStatus(ctx context.Context, userID int, change model.LegChange) {
+	if !change.Changed {
+		return
+	}
+	s.notifier.Notify(ctx, model.Event{
+		Type:      model.EventParcelStatusChanged,
+		ParcelID:  change.Leg.ParcelID,
+		UserID:    userID,
+		CarrierID: change.CarrierID,
+		Status:    change.ParcelStatus,
+	})
+}
@@ -0,0 +1,161 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_SplitParcel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	split := model.RelaySplit{ParcelID: 1, UserID: 3, HubIDs: []int{2}}
+
+	t.Run("should split parcel of the sender", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+		legs := []model.Leg{{ID: 10, ParcelID: 1, Sequence: 1}, {ID: 11, ParcelID: 1, Sequence: 2}}
+		repo := mocks.NewMockRelayRepository(ctrl)
+		repo.EXPECT().InsertLegs(gomock.Any(), split).Return(legs, nil)
+
+		s := NewService(repo, parcelRepo, mocks.NewMockEventNotifier(ctrl))
+		relay, err := s.SplitParcel(context.Background(), split)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Relay{ParcelID: 1, UserID: 3, Status: model.ParcelStatusCreated, Legs: legs, Transfers: []model.CustodyTransfer{}}, relay)
+	})
+
+	t.Run("should return forbidden for another user", func(t *testing.T) {
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 4}, nil)
+
+		s := NewService(mocks.NewMockRelayRepository(ctrl), parcelRepo, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.SplitParcel(context.Background(), split)
+		assert.EqualError(t, err, "user 3 did not send parcel 1 :forbidden")
+	})
+}
+
+func TestService_RequestLeg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should request leg and notify the sender", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		repo.EXPECT().FetchLeg(gomock.Any(), 11).Return(model.Leg{ID: 11, ParcelID: 1, Sequence: 2}, nil)
+		repo.EXPECT().InsertLegRequest(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r model.LegRequest, now time.Time) error {
+			assert.Equal(t, 7, r.CarrierID)
+			assert.WithinDuration(t, now.Add(2*time.Hour), r.ExpiresAt, time.Minute)
+			return nil
+		})
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCarrierRequested, ParcelID: 1, CarrierID: 7, Leg: 2})
+
+		s := NewService(repo, mocks.NewMockParcelRepository(ctrl), notifier)
+		request, err := s.RequestLeg(context.Background(), model.LegRequest{LegID: 11, CarrierID: 7})
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.CarrierRequestPending, request.Status)
+	})
+
+	t.Run("should not notify when the request is refused", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		repo.EXPECT().FetchLeg(gomock.Any(), 11).Return(model.Leg{ID: 11, ParcelID: 1, Sequence: 2}, nil)
+		repo.EXPECT().InsertLegRequest(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.ErrInvalid)
+
+		s := NewService(repo, mocks.NewMockParcelRepository(ctrl), mocks.NewMockEventNotifier(ctrl))
+		_, err := s.RequestLeg(context.Background(), model.LegRequest{LegID: 11, CarrierID: 7})
+		assert.Equal(t, model.ErrInvalid, err)
+	})
+}
+
+func TestService_AcceptLeg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	request := model.LegRequest{LegID: 10, CarrierID: 7}
+
+	t.Run("should assign carrier and notify the parcel status", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		repo.EXPECT().FetchLeg(gomock.Any(), 10).Return(model.Leg{ID: 10, ParcelID: 1, Sequence: 1}, nil)
+		change := model.LegChange{Leg: model.Leg{ID: 10, ParcelID: 1, Sequence: 1, CarrierID: 7}, ParcelStatus: model.ParcelStatusAssigned, CarrierID: 7, Changed: true}
+		repo.EXPECT().AcceptLegRequest(gomock.Any(), request, gomock.Any()).Return(change, nil)
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		gomock.InOrder(
+			notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventLegAssigned, ParcelID: 1, UserID: 3, CarrierID: 7, Leg: 1}),
+			notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusAssigned}),
+		)
+
+		s := NewService(repo, parcelRepo, notifier)
+		result, err := s.AcceptLeg(context.Background(), 3, request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, change, result)
+	})
+
+	t.Run("should return forbidden for another user", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		repo.EXPECT().FetchLeg(gomock.Any(), 10).Return(model.Leg{ID: 10, ParcelID: 1, Sequence: 1}, nil)
+		parcelRepo := mocks.NewMockParcelRepository(ctrl)
+		parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 4}, nil)
+
+		s := NewService(repo, parcelRepo, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.AcceptLeg(context.Background(), 3, request)
+		assert.True(t, errors.Is(err, model.ErrForbidden))
+	})
+}
+
+func TestService_UpdateLeg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	update := model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusPickedUp}
+
+	t.Run("should notify the custody transfer at the hub", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		change := model.LegChange{
+			Leg:          model.Leg{ID: 11, ParcelID: 1, Sequence: 2, CarrierID: 8, Status: model.ParcelStatusPickedUp},
+			ParcelStatus: model.ParcelStatusPickedUp,
+			CarrierID:    8,
+			Transfer:     &model.CustodyTransfer{ID: 4, ParcelID: 1, HubID: 2, HubName: "Bogura Hub", FromCarrierID: 7, ToCarrierID: 8},
+		}
+		repo.EXPECT().UpdateLegStatus(gomock.Any(), update, gomock.Any()).Return(change, nil)
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCustodyTransferred, ParcelID: 1, CarrierID: 8, Leg: 2, Hub: "Bogura Hub"})
+
+		s := NewService(repo, mocks.NewMockParcelRepository(ctrl), notifier)
+		result, err := s.UpdateLeg(context.Background(), update)
+
+		assert.Nil(t, err)
+		assert.Equal(t, change, result)
+	})
+
+	t.Run("should notify the delivery of the parcel", func(t *testing.T) {
+		repo := mocks.NewMockRelayRepository(ctrl)
+		change := model.LegChange{
+			Leg:          model.Leg{ID: 11, ParcelID: 1, Sequence: 2, CarrierID: 8, Status: model.ParcelStatusDelivered},
+			ParcelStatus: model.ParcelStatusDelivered,
+			CarrierID:    8,
+			Changed:      true,
+		}
+		repo.EXPECT().UpdateLegStatus(gomock.Any(), gomock.Any(), gomock.Any()).Return(change, nil)
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, CarrierID: 8, Status: model.ParcelStatusDelivered})
+
+		s := NewService(repo, mocks.NewMockParcelRepository(ctrl), notifier)
+		_, err := s.UpdateLeg(context.Background(), model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusDelivered})
+		assert.Nil(t, err)
+	})
+}
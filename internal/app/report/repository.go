@@ -0,0 +1,72 @@
+package report
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	fetchVolumesQuery       = `SELECT date_trunc($1, created_at) AS period, status, type, COUNT(*) AS parcels FROM parcel WHERE ($2::timestamp IS NULL OR created_at >= $2) AND ($3::timestamp IS NULL OR created_at < $3) GROUP BY period, status, type ORDER BY period, status, type`
+	fetchRevenueQuery       = `SELECT date_trunc($1, delivered_at) AS period, COUNT(*) AS parcels, COALESCE(SUM(price), 0) AS price, COALESCE(SUM(carrier_fee), 0) AS carrier_fee, COALESCE(SUM(company_fee), 0) AS company_fee FROM parcel WHERE status = $4 AND delivered_at IS NOT NULL AND ($2::timestamp IS NULL OR delivered_at >= $2) AND ($3::timestamp IS NULL OR delivered_at < $3) GROUP BY period ORDER BY period`
+	fetchDeliveryTimesQuery = `SELECT date_trunc($1, created_at) AS period, COUNT(*) AS parcels, COUNT(assigned_at) AS assigned, COALESCE(AVG(EXTRACT(EPOCH FROM assigned_at - created_at)), 0) AS avg_assignment_seconds, COUNT(delivered_at) AS delivered, COALESCE(AVG(EXTRACT(EPOCH FROM delivered_at - created_at)), 0) AS avg_delivery_seconds FROM parcel WHERE ($2::timestamp IS NULL OR created_at >= $2) AND ($3::timestamp IS NULL OR created_at < $3) GROUP BY period ORDER BY period`
+	fetchTopCarriersQuery   = `WITH requests AS (SELECT carrier_id, COUNT(*) AS requests, COUNT(*) FILTER (WHERE status = $3) AS accepted FROM carrier_request WHERE ($1::timestamp IS NULL OR created_at >= $1) AND ($2::timestamp IS NULL OR created_at < $2) GROUP BY carrier_id), deliveries AS (SELECT carrier_id, COUNT(*) AS delivered, SUM(carrier_fee) AS earnings, AVG(EXTRACT(EPOCH FROM delivered_at - assigned_at)) AS avg_delivery_seconds FROM parcel WHERE status = $4 AND carrier_id > 0 AND ($1::timestamp IS NULL OR delivered_at >= $1) AND ($2::timestamp IS NULL OR delivered_at < $2) GROUP BY carrier_id) SELECT COALESCE(deliveries.carrier_id, requests.carrier_id) AS carrier_id, COALESCE(requests.requests, 0) AS requests, COALESCE(requests.accepted, 0) AS accepted, COALESCE(deliveries.delivered, 0) AS delivered, COALESCE(deliveries.earnings, 0) AS earnings, COALESCE(deliveries.avg_delivery_seconds, 0) AS avg_delivery_seconds FROM deliveries FULL JOIN requests ON requests.carrier_id = deliveries.carrier_id ORDER BY delivered DESC, earnings DESC, carrier_id LIMIT $5`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates report repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// FetchVolumes counts the parcels created in every period by status and type
+func (r *repository) FetchVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error) {
+	volumes := []model.VolumeReport{}
+	err := r.db.SelectContext(ctx, &volumes, fetchVolumesQuery, filter.Period, filter.From, filter.To)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchVolumes] failed to fetch parcel volumes: %v", err)
+		return nil, err
+	}
+	return volumes, nil
+}
+
+// FetchRevenue sums the price and fees of the parcels delivered in every period
+func (r *repository) FetchRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error) {
+	revenue := []model.RevenueReport{}
+	err := r.db.SelectContext(ctx, &revenue, fetchRevenueQuery, filter.Period, filter.From, filter.To, model.ParcelStatusDelivered)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchRevenue] failed to fetch revenue: %v", err)
+		return nil, err
+	}
+	return revenue, nil
+}
+
+// FetchDeliveryTimes averages the time to assignment and to delivery of the parcels created in every period
+func (r *repository) FetchDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error) {
+	times := []model.DeliveryTimeReport{}
+	err := r.db.SelectContext(ctx, &times, fetchDeliveryTimesQuery, filter.Period, filter.From, filter.To)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchDeliveryTimes] failed to fetch delivery times: %v", err)
+		return nil, err
+	}
+	return times, nil
+}
+
+// FetchTopCarriers returns the carriers with the most deliveries in the range first, ties go to the higher earnings
+func (r *repository) FetchTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error) {
+	carriers := []model.CarrierReport{}
+	err := r.db.SelectContext(ctx, &carriers, fetchTopCarriersQuery, filter.From, filter.To, model.CarrierRequestAccepted, model.ParcelStatusDelivered, filter.Limit)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchTopCarriers] failed to fetch top carriers: %v", err)
+		return nil, err
+	}
+	return carriers, nil
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	day    = time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to     = time.Date(2021, time.March, 8, 0, 0, 0, 0, time.UTC)
+	filter = model.ReportFilter{Period: model.ReportPeriodDay, From: &day, To: &to, Limit: 10}
+)
+
+func TestRepository_FetchVolumes(t *testing.T) {
+	t.Run("should return parcel counts by period, status and type", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchVolumesQuery)).
+			WithArgs(model.ReportPeriodDay, &day, &to).
+			WillReturnRows(sqlmock.NewRows([]string{"period", "status", "type", "parcels"}).
+				AddRow(day, model.ParcelStatusCreated, "Document", 4).
+				AddRow(day, model.ParcelStatusDelivered, "Box", 2))
+
+		volumes, err := NewRepository(sqlxDB).FetchVolumes(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.VolumeReport{
+			{Period: day, Status: model.ParcelStatusCreated, Type: "Document", Parcels: 4},
+			{Period: day, Status: model.ParcelStatusDelivered, Type: "Box", Parcels: 2},
+		}, volumes)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchVolumesQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchVolumes(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchRevenue(t *testing.T) {
+	t.Run("should return revenue of delivered parcels by period", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchRevenueQuery)).
+			WithArgs(model.ReportPeriodDay, &day, &to, model.ParcelStatusDelivered).
+			WillReturnRows(sqlmock.NewRows([]string{"period", "parcels", "price", "carrier_fee", "company_fee"}).
+				AddRow(day, 2, 400, 360, 40))
+
+		revenue, err := NewRepository(sqlxDB).FetchRevenue(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.RevenueReport{{Period: day, Parcels: 2, Price: 400, CarrierFee: 360, CompanyFee: 40}}, revenue)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchRevenueQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchRevenue(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchDeliveryTimes(t *testing.T) {
+	t.Run("should return average times by period", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchDeliveryTimesQuery)).
+			WithArgs(model.ReportPeriodDay, &day, &to).
+			WillReturnRows(sqlmock.NewRows([]string{"period", "parcels", "assigned", "avg_assignment_seconds", "delivered", "avg_delivery_seconds"}).
+				AddRow(day, 5, 4, 900.5, 3, 86400))
+
+		times, err := NewRepository(sqlxDB).FetchDeliveryTimes(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.DeliveryTimeReport{{Period: day, Parcels: 5, Assigned: 4, AvgAssignmentSeconds: 900.5, Delivered: 3, AvgDeliverySeconds: 86400}}, times)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchDeliveryTimesQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchDeliveryTimes(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchTopCarriers(t *testing.T) {
+	t.Run("should return carriers with the most deliveries", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchTopCarriersQuery)).
+			WithArgs(&day, &to, model.CarrierRequestAccepted, model.ParcelStatusDelivered, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"carrier_id", "requests", "accepted", "delivered", "earnings", "avg_delivery_seconds"}).
+				AddRow(7, 12, 9, 8, 1440, 7200).
+				AddRow(9, 3, 0, 0, 0, 0))
+
+		carriers, err := NewRepository(sqlxDB).FetchTopCarriers(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.CarrierReport{
+			{CarrierID: 7, Requests: 12, Accepted: 9, Delivered: 8, Earnings: 1440, AvgDeliverySeconds: 7200},
+			{CarrierID: 9, Requests: 3},
+		}, carriers)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchTopCarriersQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchTopCarriers(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
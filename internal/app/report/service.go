@@ -0,0 +1,33 @@
+package report
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+type service struct {
+	repo svc.ReportRepository
+}
+
+func NewService(repo svc.ReportRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) GetVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error) {
+	return s.repo.FetchVolumes(ctx, filter)
+}
+
+func (s *service) GetRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error) {
+	return s.repo.FetchRevenue(ctx, filter)
+}
+
+func (s *service) GetDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error) {
+	return s.repo.FetchDeliveryTimes(ctx, filter)
+}
+
+func (s *service) GetTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error) {
+	return s.repo.FetchTopCarriers(ctx, filter)
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_GetVolumes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockReportRepository(ctrl)
+	repo.EXPECT().FetchVolumes(gomock.Any(), filter).Return([]model.VolumeReport{{Period: day, Status: model.ParcelStatusCreated, Type: "Box", Parcels: 1}}, nil)
+
+	volumes, err := NewService(repo).GetVolumes(context.Background(), filter)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.VolumeReport{{Period: day, Status: model.ParcelStatusCreated, Type: "Box", Parcels: 1}}, volumes)
+}
+
+func TestService_GetTopCarriers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should return top carriers", func(t *testing.T) {
+		repo := mocks.NewMockReportRepository(ctrl)
+		repo.EXPECT().FetchTopCarriers(gomock.Any(), filter).Return([]model.CarrierReport{{CarrierID: 7, Delivered: 8}}, nil)
+
+		carriers, err := NewService(repo).GetTopCarriers(context.Background(), filter)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.CarrierReport{{CarrierID: 7, Delivered: 8}}, carriers)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		repo := mocks.NewMockReportRepository(ctrl)
+		repo.EXPECT().FetchTopCarriers(gomock.Any(), filter).Return(nil, errors.New("sql-error"))
+
+		_, err := NewService(repo).GetTopCarriers(context.Background(), filter)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,72 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation = pq.ErrorCode("23505")
+	insertReviewQuery  = `INSERT INTO review (parcel_id, role, author_id, subject_id, rating, comment) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	rateCarrierQuery   = `INSERT INTO carrier_profile (carrier_id, rating, rating_count) VALUES ($1, $2, 1) ON CONFLICT (carrier_id) DO UPDATE SET rating = (carrier_profile.rating * carrier_profile.rating_count + EXCLUDED.rating) / (carrier_profile.rating_count + 1), rating_count = carrier_profile.rating_count + 1`
+	rateUserQuery      = `INSERT INTO user_profile (user_id, rating, rating_count) VALUES ($1, $2, 1) ON CONFLICT (user_id) DO UPDATE SET rating = (user_profile.rating * user_profile.rating_count + EXCLUDED.rating) / (user_profile.rating_count + 1), rating_count = user_profile.rating_count + 1`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates review repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// InsertReview stores the review and adds its rating to the profile of the rated carrier or sender in the same transaction
+func (r *repository) InsertReview(ctx context.Context, review model.Review) (model.Review, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertReview] failed to begin transaction")
+		return model.Review{}, err
+	}
+
+	err = tx.QueryRowContext(ctx, insertReviewQuery, review.ParcelID, review.Role, review.AuthorID, review.SubjectID, review.Rating, review.Comment).
+		Scan(&review.ID, &review.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.Review{}, fmt.Errorf("parcel %d has already been rated by the %s :%w", review.ParcelID, review.Role, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertReview] failed to insert review Error: %v", err)
+		return model.Review{}, err
+	}
+
+	rateQuery := rateCarrierQuery
+	if review.Role == model.RoleCarrier {
+		rateQuery = rateUserQuery
+	}
+	if _, err := tx.ExecContext(ctx, rateQuery, review.SubjectID, review.Rating); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertReview] failed to update rating of %d Error: %v", review.SubjectID, err)
+		return model.Review{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditReview, review.ID, nil, review); err != nil {
+		tx.Rollback()
+		return model.Review{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertReview] failed to commit")
+		return model.Review{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return review, nil
+}
@@ -0,0 +1,92 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_InsertReview(t *testing.T) {
+	createdAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	userReview := model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, SubjectID: 7, Rating: 5, Comment: "On time"}
+
+	t.Run("should rate the carrier", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertReviewQuery)).
+			WithArgs(1, model.RoleUser, 3, 7, 5, "On time").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+		m.ExpectExec(regexp.QuoteMeta(rateCarrierQuery)).WithArgs(7, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditReview, sqlmock.AnyArg(), sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		review, err := NewRepository(sqlxDB).InsertReview(context.Background(), userReview)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, review.ID)
+		assert.Equal(t, createdAt, review.CreatedAt)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should rate the sender", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertReviewQuery)).
+			WithArgs(1, model.RoleCarrier, 7, 3, 4, "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, createdAt))
+		m.ExpectExec(regexp.QuoteMeta(rateUserQuery)).WithArgs(3, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		_, err := NewRepository(sqlxDB).InsertReview(context.Background(), model.Review{ParcelID: 1, Role: model.RoleCarrier, AuthorID: 7, SubjectID: 3, Rating: 4})
+		assert.Nil(t, err)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should refuse a second review from the same side", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertReviewQuery)).WillReturnError(&pq.Error{Code: errUniqueViolation})
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).InsertReview(context.Background(), userReview)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+		assert.EqualError(t, err, "parcel 1 has already been rated by the user :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should rollback when rating fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery(regexp.QuoteMeta(insertReviewQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+		m.ExpectExec(regexp.QuoteMeta(rateCarrierQuery)).WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		_, err := NewRepository(sqlxDB).InsertReview(context.Background(), userReview)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
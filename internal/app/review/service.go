@@ -0,0 +1,47 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+type service struct {
+	repo       svc.ReviewRepository
+	parcelRepo svc.ParcelRepository
+}
+
+func NewService(repo svc.ReviewRepository, parcelRepo svc.ParcelRepository) *service {
+	return &service{
+		repo:       repo,
+		parcelRepo: parcelRepo,
+	}
+}
+
+// CreateReview lets the sender rate the carrier and the carrier rate the sender once the parcel is delivered
+func (s *service) CreateReview(ctx context.Context, review model.Review) (model.Review, error) {
+	parcel, err := s.parcelRepo.FetchParcelByID(ctx, review.ParcelID)
+	if err != nil {
+		return model.Review{}, err
+	}
+
+	if parcel.Status != model.ParcelStatusDelivered {
+		return model.Review{}, fmt.Errorf("parcel %d can only be rated after delivery :%w", parcel.ID, model.ErrInvalid)
+	}
+
+	switch review.Role {
+	case model.RoleUser:
+		if review.AuthorID != parcel.UserID {
+			return model.Review{}, fmt.Errorf("user %d did not send parcel %d :%w", review.AuthorID, parcel.ID, model.ErrForbidden)
+		}
+		review.SubjectID = parcel.CarrierID
+	case model.RoleCarrier:
+		if review.AuthorID != parcel.CarrierID {
+			return model.Review{}, fmt.Errorf("carrier %d did not deliver parcel %d :%w", review.AuthorID, parcel.ID, model.ErrForbidden)
+		}
+		review.SubjectID = parcel.UserID
+	}
+
+	return s.repo.InsertReview(ctx, review)
+}
@@ -0,0 +1,107 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CreateReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delivered := model.Parcel{ID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusDelivered}
+	pickedUp := delivered
+	pickedUp.Status = model.ParcelStatusPickedUp
+
+	testCases := []struct {
+		desc       string
+		review     model.Review
+		parcel     model.Parcel
+		parcelErr  error
+		mockRepo   func() *mocks.MockReviewRepository
+		expErr     string
+		expSubject int
+	}{
+		{
+			desc:   "should let the sender rate the carrier",
+			review: model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, Rating: 5},
+			parcel: delivered,
+			mockRepo: func() *mocks.MockReviewRepository {
+				r := mocks.NewMockReviewRepository(ctrl)
+				r.EXPECT().InsertReview(gomock.Any(), model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, SubjectID: 7, Rating: 5}).
+					Return(model.Review{ID: 1, SubjectID: 7}, nil)
+				return r
+			},
+			expSubject: 7,
+		},
+		{
+			desc:   "should let the carrier rate the sender",
+			review: model.Review{ParcelID: 1, Role: model.RoleCarrier, AuthorID: 7, Rating: 4},
+			parcel: delivered,
+			mockRepo: func() *mocks.MockReviewRepository {
+				r := mocks.NewMockReviewRepository(ctrl)
+				r.EXPECT().InsertReview(gomock.Any(), model.Review{ParcelID: 1, Role: model.RoleCarrier, AuthorID: 7, SubjectID: 3, Rating: 4}).
+					Return(model.Review{ID: 2, SubjectID: 3}, nil)
+				return r
+			},
+			expSubject: 3,
+		},
+		{
+			desc:   "should refuse parcels that are not delivered",
+			review: model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, Rating: 5},
+			parcel: pickedUp,
+			mockRepo: func() *mocks.MockReviewRepository {
+				return mocks.NewMockReviewRepository(ctrl)
+			},
+			expErr: "parcel 1 can only be rated after delivery :invalid",
+		},
+		{
+			desc:   "should refuse other senders",
+			review: model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 4, Rating: 5},
+			parcel: delivered,
+			mockRepo: func() *mocks.MockReviewRepository {
+				return mocks.NewMockReviewRepository(ctrl)
+			},
+			expErr: "user 4 did not send parcel 1 :forbidden",
+		},
+		{
+			desc:   "should refuse other carriers",
+			review: model.Review{ParcelID: 1, Role: model.RoleCarrier, AuthorID: 8, Rating: 5},
+			parcel: delivered,
+			mockRepo: func() *mocks.MockReviewRepository {
+				return mocks.NewMockReviewRepository(ctrl)
+			},
+			expErr: "carrier 8 did not deliver parcel 1 :forbidden",
+		},
+		{
+			desc:      "should return parcel error",
+			review:    model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, Rating: 5},
+			parcelErr: errors.New("db-error"),
+			mockRepo: func() *mocks.MockReviewRepository {
+				return mocks.NewMockReviewRepository(ctrl)
+			},
+			expErr: "db-error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parcelRepo := mocks.NewMockParcelRepository(ctrl)
+			parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(tc.parcel, tc.parcelErr)
+
+			review, err := NewService(tc.mockRepo(), parcelRepo).CreateReview(context.Background(), tc.review)
+			if tc.expErr != "" {
+				assert.EqualError(t, err, tc.expErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expSubject, review.SubjectID)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package route
+
+import (
+	"parcel-service/internal/app/model"
+	"sort"
+	"time"
+)
+
+// LATE_PENALTY is the cost in km of every minute a stop is reached after its window closes, a late stop is
+// only planned when being on time costs a far longer detour
+const LATE_PENALTY = 1.0
+
+// MAX_PASSES bounds the 2-opt passes over a route, each pass keeps every improving reversal it finds
+const MAX_PASSES = 50
+
+// planner orders the pickups and drops of a carrier with a nearest neighbour tour improved by 2-opt.
+// A drop comes after the pickup of its parcel and ordered drops of a shipment keep their order.
+type planner struct {
+	stops  []model.RouteStop
+	before [][]int // before[i] lists the stops that have to be visited before stop i
+	start  *model.CarrierLocation
+	now    time.Time
+}
+
+// newPlanner builds the stops of the parcels, an assigned parcel is picked up and dropped while a picked up
+// parcel is only dropped. The parcels missing the coordinates of a stop are returned apart.
+func newPlanner(parcels []model.Parcel, start *model.CarrierLocation, now time.Time) (*planner, []int) {
+	p := &planner{start: start, now: now}
+	var unrouted []int
+	type drop struct{ stop, index int }
+	shipments := map[int][]drop{}
+
+	for _, parcel := range parcels {
+		pickup := parcel.Status == model.ParcelStatusAssigned
+		if !parcel.HasDestinationLocation() || (pickup && !parcel.HasSourceLocation()) {
+			unrouted = append(unrouted, parcel.ID)
+			continue
+		}
+
+		var before []int
+		if pickup {
+			before = []int{p.add(model.RouteStop{
+				ParcelID:    parcel.ID,
+				Kind:        model.RouteStopPickup,
+				Address:     parcel.SourceAddress,
+				Latitude:    parcel.SourceLatitude,
+				Longitude:   parcel.SourceLongitude,
+				WindowStart: parcel.PickupStart,
+				WindowEnd:   parcel.PickupEnd,
+			}, nil)}
+		}
+		index := p.add(model.RouteStop{
+			ParcelID:    parcel.ID,
+			Kind:        model.RouteStopDrop,
+			Address:     parcel.DestinationAddress,
+			Latitude:    parcel.DestLatitude,
+			Longitude:   parcel.DestLongitude,
+			WindowStart: parcel.DeliveryStart,
+			WindowEnd:   parcel.DeliveryEnd,
+		}, before)
+		if parcel.ShipmentID != 0 && parcel.Stop != 0 {
+			shipments[parcel.ShipmentID] = append(shipments[parcel.ShipmentID], drop{stop: parcel.Stop, index: index})
+		}
+	}
+
+	for _, drops := range shipments {
+		sort.Slice(drops, func(i, j int) bool { return drops[i].stop < drops[j].stop })
+		for i := 1; i < len(drops); i++ {
+			p.before[drops[i].index] = append(p.before[drops[i].index], drops[i-1].index)
+		}
+	}
+	return p, unrouted
+}
+
+func (p *planner) add(stop model.RouteStop, before []int) int {
+	p.stops = append(p.stops, stop)
+	p.before = append(p.before, before)
+	return len(p.stops) - 1
+}
+
+// plan returns the stops in the order they should be visited with their estimated arrival
+func (p *planner) plan() ([]model.RouteStop, float64) {
+	order := p.twoOpt(p.nearestNeighbour())
+
+	stops := make([]model.RouteStop, 0, len(order))
+	var total float64
+	p.walk(order, func(index int, distance float64, arrival time.Time, late time.Duration) {
+		stop := p.stops[index]
+		stop.Distance = distance
+		stop.Arrival = arrival
+		stop.Late = late > 0
+		stops = append(stops, stop)
+		total += distance
+	})
+	return stops, total
+}
+
+// nearestNeighbour starts the route at the cheapest stop that can be visited and keeps moving on to the
+// cheapest next one, the cost of a stop is its distance and the penalty of reaching it late
+func (p *planner) nearestNeighbour() []int {
+	order := make([]int, 0, len(p.stops))
+	visited := make([]bool, len(p.stops))
+	at, now := p.start, p.now
+
+	for len(order) < len(p.stops) {
+		next, best := -1, 0.0
+		var arrival time.Time
+		for i := range p.stops {
+			if visited[i] || !p.ready(i, visited) {
+				continue
+			}
+			distance, reached, late := p.visit(at, now, i)
+			cost := distance + late.Minutes()*LATE_PENALTY
+			if next == -1 || cost < best {
+				next, best, arrival = i, cost, reached
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+		at = &model.CarrierLocation{Latitude: p.stops[next].Latitude, Longitude: p.stops[next].Longitude}
+		now = arrival.Add(model.RouteStopTime)
+	}
+	return order
+}
+
+// ready reports whether every stop that has to come before the stop has been visited
+func (p *planner) ready(index int, visited []bool) bool {
+	for _, before := range p.before[index] {
+		if !visited[before] {
+			return false
+		}
+	}
+	return true
+}
+
+// twoOpt reverses parts of the route while that makes it cheaper and keeps every drop after its pickup
+func (p *planner) twoOpt(order []int) []int {
+	best := p.cost(order)
+	for pass := 0; pass < MAX_PASSES; pass++ {
+		improved := false
+		for i := 0; i < len(order)-1; i++ {
+			for j := i + 1; j < len(order); j++ {
+				candidate := reverse(order, i, j)
+				if !p.valid(candidate) {
+					continue
+				}
+				if cost := p.cost(candidate); cost < best-1e-9 {
+					order, best, improved = candidate, cost, true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return order
+}
+
+// reverse returns a copy of the order with the stops from i to j reversed
+func reverse(order []int, i int, j int) []int {
+	reversed := make([]int, len(order))
+	copy(reversed, order)
+	for ; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return reversed
+}
+
+func (p *planner) valid(order []int) bool {
+	position := make([]int, len(order))
+	for pos, index := range order {
+		position[index] = pos
+	}
+	for index, befores := range p.before {
+		for _, before := range befores {
+			if position[before] > position[index] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cost is the length of the route in km and the penalty of its late stops
+func (p *planner) cost(order []int) float64 {
+	var cost float64
+	p.walk(order, func(_ int, distance float64, _ time.Time, late time.Duration) {
+		cost += distance + late.Minutes()*LATE_PENALTY
+	})
+	return cost
+}
+
+// walk follows the route from the start and reports the distance, arrival and lateness of every stop
+func (p *planner) walk(order []int, fn func(index int, distance float64, arrival time.Time, late time.Duration)) {
+	at, now := p.start, p.now
+	for _, index := range order {
+		distance, arrival, late := p.visit(at, now, index)
+		fn(index, distance, arrival, late)
+		at = &model.CarrierLocation{Latitude: p.stops[index].Latitude, Longitude: p.stops[index].Longitude}
+		now = arrival.Add(model.RouteStopTime)
+	}
+}
+
+// visit returns the distance from the location to the stop, when the stop can be worked on and how late that is.
+// Without a location the route starts at the stop.
+func (p *planner) visit(at *model.CarrierLocation, now time.Time, index int) (float64, time.Time, time.Duration) {
+	stop := p.stops[index]
+	var distance float64
+	if at != nil {
+		distance = model.Distance(at.Latitude, at.Longitude, stop.Latitude, stop.Longitude)
+	}
+
+	arrival := now.Add(time.Duration(distance / model.RouteSpeed * float64(time.Hour)))
+	if stop.WindowStart != nil && arrival.Before(*stop.WindowStart) {
+		arrival = *stop.WindowStart
+	}
+	var late time.Duration
+	if stop.WindowEnd != nil && arrival.After(*stop.WindowEnd) {
+		late = arrival.Sub(*stop.WindowEnd)
+	}
+	return distance, arrival, late
+}
@@ -0,0 +1,119 @@
+package route
+
+import (
+	"parcel-service/internal/app/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// points on a line east of the start, 0.01 degree of longitude is about 1.1 km at the equator
+func east(longitude float64) (float64, float64) {
+	return 0, longitude
+}
+
+func parcelFrom(id int, status int, from float64, to float64) model.Parcel {
+	p := model.Parcel{ID: id, Status: status}
+	p.SourceLatitude, p.SourceLongitude = east(from)
+	p.DestLatitude, p.DestLongitude = east(to)
+	return p
+}
+
+func stopsOf(stops []model.RouteStop) []string {
+	var order []string
+	for _, stop := range stops {
+		order = append(order, stop.Kind[:1]+string(rune('0'+stop.ParcelID)))
+	}
+	return order
+}
+
+func TestPlanner_PicksUpBeforeDrop(t *testing.T) {
+	start := &model.CarrierLocation{}
+	start.Latitude, start.Longitude = east(0.001)
+	parcels := []model.Parcel{
+		parcelFrom(1, model.ParcelStatusAssigned, 0.05, 0.01),
+		parcelFrom(2, model.ParcelStatusAssigned, 0.02, 0.06),
+	}
+
+	planner, unrouted := newPlanner(parcels, start, time.Now())
+	stops, distance := planner.plan()
+
+	assert.Empty(t, unrouted)
+	assert.Equal(t, []string{"p2", "p1", "d2", "d1"}, stopsOf(stops))
+	assert.InDelta(t, model.Distance(0, 0.001, 0, 0.06)+model.Distance(0, 0.06, 0, 0.01), distance, 0.01)
+}
+
+func TestPlanner_DropsPickedUpParcels(t *testing.T) {
+	parcels := []model.Parcel{
+		parcelFrom(1, model.ParcelStatusPickedUp, 0, 0.03),
+		parcelFrom(2, model.ParcelStatusPickedUp, 0, 0.01),
+		parcelFrom(3, model.ParcelStatusPickedUp, 0, 0.02),
+	}
+
+	planner, _ := newPlanner(parcels, &model.CarrierLocation{}, time.Now())
+	stops, _ := planner.plan()
+
+	assert.Equal(t, []string{"d2", "d3", "d1"}, stopsOf(stops))
+	assert.True(t, stops[1].Arrival.After(stops[0].Arrival))
+}
+
+func TestPlanner_ImprovesTourWithTwoOpt(t *testing.T) {
+	// nearest neighbour goes to 3 first and then has to cross back, reversing fixes the crossing
+	parcels := []model.Parcel{
+		parcelFrom(1, model.ParcelStatusPickedUp, 0, -0.02),
+		parcelFrom(2, model.ParcelStatusPickedUp, 0, -0.03),
+		parcelFrom(3, model.ParcelStatusPickedUp, 0, 0.011),
+		parcelFrom(4, model.ParcelStatusPickedUp, 0, 0.05),
+	}
+
+	planner, _ := newPlanner(parcels, &model.CarrierLocation{}, time.Now())
+	nearest := planner.nearestNeighbour()
+	improved := planner.twoOpt(nearest)
+
+	assert.True(t, planner.cost(improved) < planner.cost(nearest))
+	assert.True(t, planner.valid(improved))
+}
+
+func TestPlanner_RespectsTimeWindows(t *testing.T) {
+	now := time.Now()
+	soon := now.Add(30 * time.Minute)
+	later := now.Add(5 * time.Hour)
+
+	near := parcelFrom(1, model.ParcelStatusPickedUp, 0, 0.01)
+	near.DeliveryStart, near.DeliveryEnd = &later, &later
+	far := parcelFrom(2, model.ParcelStatusPickedUp, 0, 0.05)
+	far.DeliveryStart, far.DeliveryEnd = &now, &soon
+
+	planner, _ := newPlanner([]model.Parcel{near, far}, &model.CarrierLocation{}, now)
+	stops, _ := planner.plan()
+
+	assert.Equal(t, []string{"d2", "d1"}, stopsOf(stops))
+	assert.False(t, stops[0].Late)
+	assert.Equal(t, later, stops[1].Arrival)
+}
+
+func TestPlanner_KeepsShipmentStopOrder(t *testing.T) {
+	first := parcelFrom(1, model.ParcelStatusPickedUp, 0, 0.05)
+	first.ShipmentID, first.Stop = 9, 1
+	second := parcelFrom(2, model.ParcelStatusPickedUp, 0, 0.01)
+	second.ShipmentID, second.Stop = 9, 2
+
+	planner, _ := newPlanner([]model.Parcel{first, second}, &model.CarrierLocation{}, time.Now())
+	stops, _ := planner.plan()
+
+	assert.Equal(t, []string{"d1", "d2"}, stopsOf(stops))
+}
+
+func TestPlanner_SkipsParcelsWithoutCoordinates(t *testing.T) {
+	missingDrop := model.Parcel{ID: 1, Status: model.ParcelStatusPickedUp, SourceLatitude: 1}
+	missingPickup := model.Parcel{ID: 2, Status: model.ParcelStatusAssigned, DestLatitude: 1}
+	pickedUp := model.Parcel{ID: 3, Status: model.ParcelStatusPickedUp, DestLatitude: 1}
+
+	planner, unrouted := newPlanner([]model.Parcel{missingDrop, missingPickup, pickedUp}, nil, time.Now())
+	stops, distance := planner.plan()
+
+	assert.Equal(t, []int{1, 2}, unrouted)
+	assert.Equal(t, []string{"d3"}, stopsOf(stops))
+	assert.Equal(t, 0.0, distance)
+}
@@ -0,0 +1,50 @@
+package route
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	fetchParcelsQuery  = `SELECT id, status, source_address, destination_address, source_latitude, source_longitude, destination_latitude, destination_longitude, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, COALESCE(shipment_id, 0) AS shipment_id, stop FROM parcel WHERE carrier_id = $1 AND status IN ($2, $3) ORDER BY id`
+	fetchLocationQuery = `SELECT carrier_id, latitude, longitude, updated_at FROM carrier_location WHERE carrier_id = $1`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates route repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// FetchActiveParcels returns the parcels the carrier still has to pick up or drop
+func (r *repository) FetchActiveParcels(ctx context.Context, carrierID int) ([]model.Parcel, error) {
+	parcels := []model.Parcel{}
+	if err := r.db.SelectContext(ctx, &parcels, fetchParcelsQuery, carrierID, model.ParcelStatusAssigned, model.ParcelStatusPickedUp); err != nil {
+		log.Error().Err(err).Msgf("[FetchActiveParcels] failed to fetch parcels of carrier %d Error: %v", carrierID, err)
+		return nil, err
+	}
+	return parcels, nil
+}
+
+func (r *repository) FetchCarrierLocation(ctx context.Context, carrierID int) (model.CarrierLocation, error) {
+	var location model.CarrierLocation
+	if err := r.db.GetContext(ctx, &location, fetchLocationQuery, carrierID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.CarrierLocation{}, fmt.Errorf("location of carrier %d is not known :%w", carrierID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchCarrierLocation] failed to fetch location of carrier %d Error: %v", carrierID, err)
+		return model.CarrierLocation{}, err
+	}
+	return location, nil
+}
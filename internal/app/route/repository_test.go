@@ -0,0 +1,85 @@
+package route
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_FetchActiveParcels(t *testing.T) {
+	t.Run("should return assigned and picked up parcels", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchParcelsQuery)).
+			WithArgs(7, model.ParcelStatusAssigned, model.ParcelStatusPickedUp).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "destination_latitude", "destination_longitude", "shipment_id", "stop"}).
+				AddRow(1, model.ParcelStatusAssigned, 23.8, 90.4, 0, 0).
+				AddRow(2, model.ParcelStatusPickedUp, 24.0, 89.2, 5, 1))
+
+		repo := NewRepository(sqlxDB)
+		parcels, err := repo.FetchActiveParcels(context.Background(), 7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []model.Parcel{
+			{ID: 1, Status: model.ParcelStatusAssigned, DestLatitude: 23.8, DestLongitude: 90.4},
+			{ID: 2, Status: model.ParcelStatusPickedUp, DestLatitude: 24.0, DestLongitude: 89.2, ShipmentID: 5, Stop: 1},
+		}, parcels)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchParcelsQuery)).
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchActiveParcels(context.Background(), 7)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchCarrierLocation(t *testing.T) {
+	updatedAt := time.Now()
+
+	t.Run("should return location", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchLocationQuery)).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"carrier_id", "latitude", "longitude", "updated_at"}).AddRow(7, 23.8, 90.4, updatedAt))
+
+		repo := NewRepository(sqlxDB)
+		location, err := repo.FetchCarrierLocation(context.Background(), 7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.CarrierLocation{CarrierID: 7, Latitude: 23.8, Longitude: 90.4, UpdatedAt: updatedAt}, location)
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchLocationQuery)).
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchCarrierLocation(context.Background(), 7)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+)
+
+type service struct {
+	repo svc.RouteRepository
+}
+
+func NewService(repo svc.RouteRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// PlanRoute orders the pickups and drops of the parcels assigned to the carrier, starting from its last
+// reported location. A carrier that never reported a location starts at the first stop.
+func (s *service) PlanRoute(ctx context.Context, carrierID int) (model.Route, error) {
+	parcels, err := s.repo.FetchActiveParcels(ctx, carrierID)
+	if err != nil {
+		return model.Route{}, err
+	}
+
+	var start *model.CarrierLocation
+	location, err := s.repo.FetchCarrierLocation(ctx, carrierID)
+	if err == nil {
+		start = &location
+	} else if !errors.Is(err, model.ErrNotFound) {
+		return model.Route{}, err
+	}
+
+	planner, unrouted := newPlanner(parcels, start, time.Now())
+	stops, distance := planner.plan()
+	return model.Route{
+		CarrierID: carrierID,
+		Stops:     stops,
+		Distance:  distance,
+		Unrouted:  unrouted,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_PlanRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parcels := []model.Parcel{
+		{ID: 1, Status: model.ParcelStatusAssigned, SourceLatitude: 23.81, SourceLongitude: 90.41, DestLatitude: 24.00, DestLongitude: 89.23},
+		{ID: 2, Status: model.ParcelStatusPickedUp, DestLatitude: 23.78, DestLongitude: 90.40},
+		{ID: 3, Status: model.ParcelStatusPickedUp},
+	}
+
+	t.Run("should plan from the carrier location", func(t *testing.T) {
+		repo := mocks.NewMockRouteRepository(ctrl)
+		repo.EXPECT().FetchActiveParcels(gomock.Any(), 7).Return(parcels, nil)
+		repo.EXPECT().FetchCarrierLocation(gomock.Any(), 7).Return(model.CarrierLocation{CarrierID: 7, Latitude: 23.80, Longitude: 90.40}, nil)
+
+		s := NewService(repo)
+		route, err := s.PlanRoute(context.Background(), 7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 7, route.CarrierID)
+		assert.Equal(t, []int{3}, route.Unrouted)
+		assert.Len(t, route.Stops, 3)
+		assert.Equal(t, model.RouteStopDrop, route.Stops[2].Kind)
+		assert.Equal(t, 1, route.Stops[2].ParcelID)
+		assert.True(t, route.Distance > 0)
+	})
+
+	t.Run("should plan without a known location", func(t *testing.T) {
+		repo := mocks.NewMockRouteRepository(ctrl)
+		repo.EXPECT().FetchActiveParcels(gomock.Any(), 7).Return(parcels[1:2], nil)
+		repo.EXPECT().FetchCarrierLocation(gomock.Any(), 7).Return(model.CarrierLocation{}, model.ErrNotFound)
+
+		s := NewService(repo)
+		route, err := s.PlanRoute(context.Background(), 7)
+
+		assert.Nil(t, err)
+		assert.Len(t, route.Stops, 1)
+		assert.Equal(t, 0.0, route.Distance)
+	})
+
+	t.Run("should return repository error", func(t *testing.T) {
+		repo := mocks.NewMockRouteRepository(ctrl)
+		repo.EXPECT().FetchActiveParcels(gomock.Any(), 7).Return(nil, errors.New("db-error"))
+
+		s := NewService(repo)
+		_, err := s.PlanRoute(context.Background(), 7)
+		assert.EqualError(t, err, "db-error")
+	})
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// failDelivery records a failed delivery attempt reported by the carrier of the parcel
+func (s *server) failDelivery(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleCarrier {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the carrier can report a failed delivery :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.DeliveryAttempt
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ParcelID = parcelID
+	data.CarrierID = claims.ID
+
+	if err := data.ValidateDeliveryAttemptInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	attempt, err := s.parcelService.FailDelivery(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "delivery can not fail", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[failDelivery] failed to record delivery attempt of parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to record delivery attempt", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, attempt)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	createdAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		parcelId      string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockParcelService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:     "should success",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			payload:  `{"reason":"recipient_absent"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().FailDelivery(gomock.Any(), model.DeliveryAttempt{ParcelID: 1, CarrierID: 7, Reason: model.ReasonRecipientAbsent}).
+					Return(model.DeliveryAttempt{ID: 4, ParcelID: 1, CarrierID: 7, Attempt: 3, Reason: model.ReasonRecipientAbsent, ReturnParcelID: 12, CreatedAt: createdAt}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":4,"parcel_id":1,"carrier_id":7,"attempt":3,"reason":"recipient_absent","return_parcel_id":12,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:     "should return unauthorized without token",
+			parcelId: "1",
+			payload:  `{"reason":"refused"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for users",
+			parcelId: "1",
+			token:    "Bearer " + userToken(t, signer, 3),
+			payload:  `{"reason":"refused"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the carrier can report a failed delivery :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return missing note",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			payload:  `{"reason":"other"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"note is required when the reason is other :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return invalid when parcel is not picked up",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			payload:  `{"reason":"refused"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().FailDelivery(gomock.Any(), gomock.Any()).Return(model.DeliveryAttempt{}, fmt.Errorf("parcel 1 is returned, only picked up parcels can fail delivery :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 1 is returned, only picked up parcels can fail delivery :invalid","message_title":"delivery can not fail","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for another carrier",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 8),
+			payload:  `{"reason":"refused"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().FailDelivery(gomock.Any(), gomock.Any()).Return(model.DeliveryAttempt{}, fmt.Errorf("carrier 8 is not delivering parcel 1 :%w", model.ErrForbidden))
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"carrier 8 is not delivering parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found",
+			parcelId: "2",
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			payload:  `{"reason":"refused"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().FailDelivery(gomock.Any(), gomock.Any()).Return(model.DeliveryAttempt{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", tc.mockSvc(), nil, WithAuthenticator(signer))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/parcel/%s/attempts", tc.parcelId), strings.NewReader(tc.payload))
+			if tc.token != "" {
+				r.Header.Set("Authorization", tc.token)
+			}
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
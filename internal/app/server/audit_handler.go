@@ -0,0 +1,84 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxRequestIDLength bounds the request IDs taken from clients, longer ones are replaced
+const maxRequestIDLength = 64
+
+// auditActor puts the actor of the request in its context for the audit entries written by the repositories.
+// The request ID is taken from the X-Request-ID header or generated, and sent back in the response.
+// A request without a valid token has no actor ID or role, the handlers refuse it when it needs one.
+func (s *server) auditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		actor := model.AuditActor{RequestID: requestID, IP: r.RemoteAddr}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			actor.IP = host
+		}
+		if claims, err := s.consoleClaims(r); err == nil {
+			actor.ID = claims.ID
+			actor.Role = claims.Role
+		}
+
+		next.ServeHTTP(w, r.WithContext(model.WithAuditActor(r.Context(), actor)))
+	})
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		log.Error().Err(err).Msgf("[newRequestID] failed to generate request ID: %v", err)
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+func (s *server) getAuditEntries(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.AuditFilter{Entity: query.Get("entity"), Role: query.Get("role")}
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"entity_id", &filter.EntityID}, {"actor_id", &filter.ActorID}, {"limit", &filter.Limit}, {"offset", &filter.Offset}} {
+		if value := query.Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				ErrInvalidEntityResponse(w, "Invalid "+param.name+" value", err)
+				return
+			}
+			*param.dst = n
+		}
+	}
+
+	if err := filter.ValidateAuditFilter(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	entries, err := s.auditService.GetEntries(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getAuditEntries] failed to fetch audit entries of %s %d: %v", filter.Entity, filter.EntityID, err)
+		ErrInternalServerResponse(w, "Failed to fetch audit entries", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, entries)
+}
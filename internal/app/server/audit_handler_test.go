@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	createdAt := time.Date(2021, time.March, 2, 15, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		token         string
+		url           string
+		mockSvc       func() *mocks.MockAuditService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should return the entries of the parcel",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/audit?entity=parcel&entity_id=1",
+			mockSvc: func() *mocks.MockAuditService {
+				s := mocks.NewMockAuditService(ctrl)
+				s.EXPECT().GetEntries(gomock.Any(), model.AuditFilter{Entity: model.AuditParcel, EntityID: 1, Limit: model.DefaultAuditLimit}).
+					Return([]model.AuditEntry{{ID: 2, ActorID: 4, Role: model.RoleAdmin, Action: model.AuditUpdate, Entity: model.AuditParcel, EntityID: 1, Changes: []byte(`{"status":{"before":3,"after":4}}`), RequestID: "req-1", IP: "10.0.0.1", CreatedAt: createdAt}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"id":2,"actor_id":4,"role":"admin","action":"update","entity":"parcel","entity_id":1,"changes":{"status":{"before":3,"after":4}},"request_id":"req-1","ip":"10.0.0.1","created_at":"2021-03-02T15:00:00Z"}]}`,
+		},
+		{
+			desc:  "should return the entries of the actor",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/audit?actor_id=3&role=user&limit=10&offset=20",
+			mockSvc: func() *mocks.MockAuditService {
+				s := mocks.NewMockAuditService(ctrl)
+				s.EXPECT().GetEntries(gomock.Any(), model.AuditFilter{ActorID: 3, Role: model.RoleUser, Limit: 10, Offset: 20}).
+					Return([]model.AuditEntry{}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[]}`,
+		},
+		{
+			desc:  "should return forbidden for users",
+			token: "Bearer " + userToken(t, signer, 3),
+			url:   "/api/v1/admin/audit",
+			mockSvc: func() *mocks.MockAuditService {
+				return mocks.NewMockAuditService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid actor ID",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/audit?actor_id=me",
+			mockSvc: func() *mocks.MockAuditService {
+				return mocks.NewMockAuditService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"me\": invalid syntax","message_title":"Invalid actor_id value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return entity required",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/audit?entity_id=1",
+			mockSvc: func() *mocks.MockAuditService {
+				return mocks.NewMockAuditService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"entity is required with an entity ID :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return server error",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/audit",
+			mockSvc: func() *mocks.MockAuditService {
+				s := mocks.NewMockAuditService(ctrl)
+				s.EXPECT().GetEntries(gomock.Any(), gomock.Any()).Return(nil, errors.New("db-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"db-error","message_title":"Failed to fetch audit entries","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithAuditService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestAuditActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	token, err := signer.Issue(model.Claims{Role: model.RoleAdmin, ID: 4, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.Nil(t, err)
+
+	t.Run("should pass the actor and request ID of the request", func(t *testing.T) {
+		svc := mocks.NewMockAuditService(ctrl)
+		svc.EXPECT().GetEntries(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+			assert.Equal(t, model.AuditActor{ID: 4, Role: model.RoleAdmin, RequestID: "req-1", IP: "10.0.0.1"}, model.AuditActorFrom(ctx))
+			return []model.AuditEntry{}, nil
+		})
+		s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithAuditService(svc))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit", nil)
+		r.RemoteAddr = "10.0.0.1:52100"
+		r.Header.Set("Authorization", "Bearer "+token)
+		r.Header.Set("X-Request-ID", "req-1")
+
+		s.route().ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("should generate a request ID when the client sends none or a long one", func(t *testing.T) {
+		for _, requestID := range []string{"", strings.Repeat("a", maxRequestIDLength+1)} {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithAuditService(mocks.NewMockAuditService(ctrl)))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?limit=501", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			r.Header.Set("X-Request-ID", requestID)
+
+			s.route().ServeHTTP(w, r)
+			assert.Len(t, w.Header().Get("X-Request-ID"), 16)
+		}
+	})
+}
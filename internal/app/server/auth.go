@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strings"
+)
+
+// authenticate returns the claims of the access token sent in the Authorization header as a bearer token,
+// or in the access_token query parameter for clients such as browsers that can not set headers on WebSockets
+func (s *server) authenticate(r *http.Request) (model.Claims, error) {
+	if s.authenticator == nil {
+		return model.Claims{}, fmt.Errorf("authentication is not configured :%w", model.ErrUnauthorized)
+	}
+
+	token := r.URL.Query().Get("access_token")
+	if header := r.Header.Get("Authorization"); header != "" {
+		if !strings.HasPrefix(header, "Bearer ") {
+			return model.Claims{}, fmt.Errorf("authorization must be a bearer token :%w", model.ErrUnauthorized)
+		}
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	if token == "" {
+		return model.Claims{}, fmt.Errorf("access token is required :%w", model.ErrUnauthorized)
+	}
+
+	return s.authenticator.Verify(token)
+}
+
+// authorize writes the error response and returns false unless the request is made by the carrier or user with the given ID
+func (s *server) authorize(w http.ResponseWriter, r *http.Request, role string, id int) bool {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return false
+	}
+
+	if !claims.Allows(role, id) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for %s %d :%w", role, id, model.ErrForbidden))
+		return false
+	}
+	return true
+}
+
+// authorizeAdmin writes the error response and returns false unless the request is made by an admin
+func (s *server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return false
+	}
+
+	if claims.Role != model.RoleAdmin {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("admin role is required :%w", model.ErrForbidden))
+		return false
+	}
+	return true
+}
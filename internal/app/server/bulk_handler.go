@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"parcel-service/internal/app/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// importParcels creates the parcels of a CSV or JSON bulk import and reports the result of every row.
+// Users import their own parcels, admins import for any user.
+func (s *server) importParcels(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser && claims.Role != model.RoleAdmin {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only senders and admins can import parcels :%w", model.ErrForbidden))
+		return
+	}
+
+	var rows []model.BulkRow
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		rows, err = model.ParseParcelCSV(r.Body)
+		if err != nil {
+			ErrInvalidEntityResponse(w, "Invalid CSV", err)
+			return
+		}
+	} else {
+		var parcels []model.Parcel
+		if err := json.NewDecoder(r.Body).Decode(&parcels); err != nil {
+			ErrUnprocessableEntityResponse(w, "Decode Error", err)
+			return
+		}
+		rows = model.BulkRows(parcels)
+	}
+
+	if len(rows) == 0 {
+		ErrInvalidEntityResponse(w, "Invalid Input", fmt.Errorf("at least one parcel is required :%w", model.ErrEmpty))
+		return
+	}
+
+	userID := 0
+	if claims.Role == model.RoleUser {
+		userID = claims.ID
+	}
+
+	report, err := s.parcelService.ImportParcels(r.Context(), userID, rows)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "Invalid Input", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[importParcels] failed to import parcels: %v", err)
+		ErrInternalServerResponse(w, "failed to import parcels", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, report)
+}
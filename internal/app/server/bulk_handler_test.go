@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportParcels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	report := model.BulkReport{
+		Total:   2,
+		Created: []model.BulkCreated{{Row: 1, ParcelID: 10}},
+		Errors:  []model.BulkRowError{{Row: 2, Message: "column weight: not a number :invalid"}},
+	}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		contentType   string
+		payload       string
+		mockSvc       func() *mocks.MockParcelService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:        "should import csv of the user",
+			token:       "Bearer " + userToken(t, signer, 3),
+			contentType: "text/csv; charset=utf-8",
+			payload:     "source_address,destination_address,type,weight\nDhaka Bangladesh,Pabna Shadar,Document,1.5\n\"Dhaka, Mirpur\",Rangpur,Box,heavy\n",
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().ImportParcels(gomock.Any(), 3, []model.BulkRow{
+					{Row: 1, Parcel: model.Parcel{SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Pabna Shadar", ParcelType: "Document", Weight: 1.5}},
+					{Row: 2, Parcel: model.Parcel{SourceAddress: "Dhaka, Mirpur", DestinationAddress: "Rangpur", ParcelType: "Box"}, Err: fmt.Errorf("column weight: not a number :%w", model.ErrInvalid)},
+				}).Return(report, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"total":2,"created":[{"row":1,"parcel_id":10}],"errors":[{"row":2,"message":"column weight: not a number :invalid"}]}}`,
+		},
+		{
+			desc:        "should import json for any user as admin",
+			token:       "Bearer " + adminToken(t, signer),
+			contentType: "application/json",
+			payload:     `[{"user_id":4,"source_address":"Dhaka Bangladesh","destination_address":"Pabna Shadar","type":"Document"}]`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().ImportParcels(gomock.Any(), 0, []model.BulkRow{
+					{Row: 1, Parcel: model.Parcel{UserID: 4, SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Pabna Shadar", ParcelType: "Document"}},
+				}).Return(model.BulkReport{Total: 1, Created: []model.BulkCreated{{Row: 1, ParcelID: 11}}, Errors: []model.BulkRowError{}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"total":1,"created":[{"row":1,"parcel_id":11}],"errors":[]}}`,
+		},
+		{
+			desc:    "should return forbidden for carriers",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `[]`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only senders and admins can import parcels :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:        "should return unknown csv column",
+			token:       "Bearer " + userToken(t, signer, 3),
+			contentType: "text/csv",
+			payload:     "source_address,colour\nDhaka Bangladesh,red\n",
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"unknown CSV column \"colour\" :invalid","message_title":"Invalid CSV","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return empty import",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `[]`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"at least one parcel is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return decode error",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"source_address":"Dhaka Bangladesh"}`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusUnprocessableEntity,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"json: cannot unmarshal object into Go value of type []model.Parcel","message_title":"Decode Error","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", tc.mockSvc(), nil, WithAuthenticator(signer))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/parcel/bulk", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+			r.Header.Set("Content-Type", tc.contentType)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
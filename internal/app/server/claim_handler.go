@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultClaimLimit = 20
+
+// newClaim opens a claim on a parcel for the user of the access token
+func (s *server) newClaim(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the sender can claim a parcel :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.Claim
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ParcelID = parcelID
+	data.UserID = claims.ID
+
+	if err := data.ValidateClaimInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	claim, err := s.claimService.OpenClaim(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "parcel can not be claimed", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newClaim] failed to open claim on parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to open claim", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, claim)
+}
+
+// getClaim shows a claim to the user who opened it and to admins
+func (s *server) getClaim(w http.ResponseWriter, r *http.Request) {
+	claimID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Claim ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	claim, err := s.claimService.GetClaim(r.Context(), claimID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getClaim] failed to fetch claim '%d': %v", claimID, err)
+		ErrInternalServerResponse(w, "Failed to fetch claim", err)
+		return
+	}
+
+	if !claims.Allows(model.RoleUser, claim.UserID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for %s %d :%w", model.RoleUser, claim.UserID, model.ErrForbidden))
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, claim)
+}
+
+func (s *server) getClaims(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if err := model.ValidateClaimStatus(status); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid status value", err)
+		return
+	}
+
+	var err error
+	limit, offset := defaultClaimLimit, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid limit value", err)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid offset value", err)
+			return
+		}
+	}
+
+	claims, err := s.claimService.GetClaims(r.Context(), status, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getClaims] failed to fetch claims with status '%s': %v", status, err)
+		ErrInternalServerResponse(w, "Failed to fetch claims", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, claims)
+}
+
+func (s *server) decideClaim(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	claimID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Claim ID", err)
+		return
+	}
+
+	var data model.ClaimDecision
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ClaimID = claimID
+
+	if err := data.ValidateClaimDecision(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	claim, err := s.claimService.DecideClaim(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "claim can not be decided", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[decideClaim] failed to decide claim '%d': %v", claimID, err)
+		ErrInternalServerResponse(w, "failed to decide claim", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, claim)
+}
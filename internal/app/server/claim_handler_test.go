@@ -0,0 +1,293 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func userToken(t *testing.T, signer interface {
+	Issue(model.Claims) (string, error)
+}, userID int) string {
+	token, err := signer.Issue(model.Claims{Role: model.RoleUser, ID: userID, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.Nil(t, err)
+	return token
+}
+
+func TestNewClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	createdAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	input := model.Claim{ParcelID: 1, UserID: 3, Description: "Screen is broken", Evidence: []string{"https://img.example/1.jpg"}}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockClaimService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"description":"Screen is broken","evidence":["https://img.example/1.jpg"]}`,
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				opened := input
+				opened.ID = 1
+				opened.Status = model.ClaimOpen
+				opened.CreatedAt = createdAt
+				s.EXPECT().OpenClaim(gomock.Any(), input).Return(opened, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"parcel_id":1,"user_id":3,"status":"open","description":"Screen is broken","evidence":["https://img.example/1.jpg"],"compensation":0,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:    "should return forbidden for carriers",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{"description":"Screen is broken"}`,
+			mockSvc: func() *mocks.MockClaimService {
+				return mocks.NewMockClaimService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the sender can claim a parcel :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid evidence",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"description":"Screen is broken","evidence":["img.jpg"]}`,
+			mockSvc: func() *mocks.MockClaimService {
+				return mocks.NewMockClaimService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"evidence must be absolute http or https URLs :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid parcel state",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"description":"Screen is broken"}`,
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				s.EXPECT().OpenClaim(gomock.Any(), gomock.Any()).Return(model.Claim{}, fmt.Errorf("parcel 1 can only be claimed once it is delivered or overdue :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 1 can only be claimed once it is delivered or overdue :invalid","message_title":"parcel can not be claimed","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithClaimService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/parcel/1/claims", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	claim := model.Claim{ID: 1, ParcelID: 1, UserID: 3, Status: model.ClaimInvestigating, Description: "Lost", Evidence: []string{}, CreatedAt: time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:          "should show claim to its user",
+			token:         "Bearer " + userToken(t, signer, 3),
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"parcel_id":1,"user_id":3,"status":"investigating","description":"Lost","evidence":[],"compensation":0,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:          "should show claim to admins",
+			token:         "Bearer " + adminToken(t, signer),
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"parcel_id":1,"user_id":3,"status":"investigating","description":"Lost","evidence":[],"compensation":0,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:          "should return forbidden for other users",
+			token:         "Bearer " + userToken(t, signer, 4),
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for user 3 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := mocks.NewMockClaimService(ctrl)
+			svc.EXPECT().GetClaim(gomock.Any(), 1).Return(claim, nil)
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithClaimService(svc))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/claims/1", nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetClaims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		url           string
+		token         string
+		mockSvc       func() *mocks.MockClaimService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should success",
+			url:   "/api/v1/admin/claims?status=open&limit=5",
+			token: "Bearer " + adminToken(t, signer),
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				s.EXPECT().GetClaims(gomock.Any(), model.ClaimOpen, 5, 0).Return([]model.Claim{}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[]}`,
+		},
+		{
+			desc:  "should return invalid status",
+			url:   "/api/v1/admin/claims?status=closed",
+			token: "Bearer " + adminToken(t, signer),
+			mockSvc: func() *mocks.MockClaimService {
+				return mocks.NewMockClaimService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"status must be open, investigating, approved or rejected :invalid","message_title":"Invalid status value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return forbidden for users",
+			url:   "/api/v1/admin/claims",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockSvc: func() *mocks.MockClaimService {
+				return mocks.NewMockClaimService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithClaimService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestDecideClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	createdAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	decidedAt := createdAt.Add(time.Hour)
+
+	testCases := []struct {
+		desc          string
+		payload       string
+		mockSvc       func() *mocks.MockClaimService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			payload: `{"status":"approved","compensation":150,"note":"Confirmed by carrier"}`,
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				s.EXPECT().DecideClaim(gomock.Any(), model.ClaimDecision{ClaimID: 1, Status: model.ClaimApproved, Compensation: 150, Note: "Confirmed by carrier"}).
+					Return(model.Claim{ID: 1, ParcelID: 1, UserID: 3, Status: model.ClaimApproved, Description: "Lost", Evidence: []string{}, Compensation: 150, Note: "Confirmed by carrier", CreatedAt: createdAt, DecidedAt: &decidedAt}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"parcel_id":1,"user_id":3,"status":"approved","description":"Lost","evidence":[],"compensation":150,"note":"Confirmed by carrier","created_at":"2020-04-11T21:34:01Z","decided_at":"2020-04-11T22:34:01Z"}}`,
+		},
+		{
+			desc:    "should return invalid status",
+			payload: `{"status":"open"}`,
+			mockSvc: func() *mocks.MockClaimService {
+				return mocks.NewMockClaimService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"status must be investigating, approved or rejected :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return compensation above declared value",
+			payload: `{"status":"approved","compensation":600}`,
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				s.EXPECT().DecideClaim(gomock.Any(), gomock.Any()).Return(model.Claim{}, fmt.Errorf("compensation 600.00 exceeds the declared value 500.00 of parcel 1 :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"compensation 600.00 exceeds the declared value 500.00 of parcel 1 :invalid","message_title":"claim can not be decided","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return not found",
+			payload: `{"status":"rejected"}`,
+			mockSvc: func() *mocks.MockClaimService {
+				s := mocks.NewMockClaimService(ctrl)
+				s.EXPECT().DecideClaim(gomock.Any(), gomock.Any()).Return(model.Claim{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithClaimService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/admin/claims/1", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", "Bearer "+adminToken(t, signer))
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"parcel-service/internal/app/model"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// consoleCookie holds the access token of the admin logged in to the console, it is only sent to the console pages
+// and never to other sites so the forms of the console need no other protection against forged requests
+const consoleCookie = "admin_token"
+
+// consolePage is the data of every console page, a page only fills in what it shows
+type consolePage struct {
+	Title     string
+	Admin     bool
+	Error     string
+	Notice    string
+	Search    model.ParcelSearch
+	Parcels   []model.Parcel
+	Parcel    model.Parcel
+	History   []model.Event
+	Requests  []model.CarrierRequest
+	CarrierID int
+	Carriers  []model.CarrierProfile
+	PrevURL   string
+	NextURL   string
+}
+
+func (s *server) renderConsole(w http.ResponseWriter, status int, name string, page consolePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := consoleTemplates.ExecuteTemplate(w, name, page); err != nil {
+		log.Error().Err(err).Msgf("[renderConsole] failed to render console page %s: %v", name, err)
+	}
+}
+
+// consoleAdmin returns the claims of the admin using the console from the console cookie or the authorization header.
+// Visitors without a valid token are sent to the login page and other roles get a forbidden page.
+func (s *server) consoleAdmin(w http.ResponseWriter, r *http.Request) (model.Claims, bool) {
+	claims, err := s.consoleClaims(r)
+	if err != nil {
+		http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+		return model.Claims{}, false
+	}
+
+	if claims.Role != model.RoleAdmin {
+		s.renderConsole(w, http.StatusForbidden, "error", consolePage{Title: "Forbidden", Error: "The admin role is required to use the console."})
+		return model.Claims{}, false
+	}
+	return claims, true
+}
+
+// consoleClaims returns the claims of the token in the console cookie, or of the token sent like to the API without one
+func (s *server) consoleClaims(r *http.Request) (model.Claims, error) {
+	if cookie, err := r.Cookie(consoleCookie); err == nil && s.authenticator != nil {
+		return s.authenticator.Verify(cookie.Value)
+	}
+	return s.authenticate(r)
+}
+
+// consoleInt reads an optional number of a form or query, a blank value is 0
+func consoleInt(value string, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number :%w", name, model.ErrInvalid)
+	}
+	return n, nil
+}
+
+// pageURL returns the URL of the list page at the offset, or nothing when the offset is before the first page
+func pageURL(path string, query url.Values, offset int) string {
+	if offset < 0 {
+		return ""
+	}
+	page := url.Values{}
+	for key, values := range query {
+		page[key] = values
+	}
+	page.Set("offset", strconv.Itoa(offset))
+	return path + "?" + page.Encode()
+}
+
+func (s *server) consoleHome(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/admin/parcels", http.StatusSeeOther)
+}
+
+func (s *server) consoleLoginPage(w http.ResponseWriter, r *http.Request) {
+	s.renderConsole(w, http.StatusOK, "login", consolePage{Title: "Log in"})
+}
+
+// consoleLogin stores the admin access token in the console cookie, the cookie expires with the token
+func (s *server) consoleLogin(w http.ResponseWriter, r *http.Request) {
+	if s.authenticator == nil {
+		s.renderConsole(w, http.StatusUnauthorized, "login", consolePage{Title: "Log in", Error: "Authentication is not configured."})
+		return
+	}
+
+	token := r.PostFormValue("token")
+	claims, err := s.authenticator.Verify(token)
+	if err != nil {
+		s.renderConsole(w, http.StatusUnauthorized, "login", consolePage{Title: "Log in", Error: "The access token is not valid."})
+		return
+	}
+	if claims.Role != model.RoleAdmin {
+		s.renderConsole(w, http.StatusForbidden, "login", consolePage{Title: "Log in", Error: "The admin role is required to use the console."})
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     consoleCookie,
+		Value:    token,
+		Path:     "/admin",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	}
+	if claims.ExpiresAt > 0 {
+		cookie.Expires = time.Unix(claims.ExpiresAt, 0)
+	}
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, "/admin/parcels", http.StatusSeeOther)
+}
+
+func (s *server) consoleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: consoleCookie, Path: "/admin", MaxAge: -1, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
+
+func (s *server) consoleParcels(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.consoleAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page := consolePage{Title: "Parcels", Admin: true}
+	search := model.ParcelSearch{Query: query.Get("q")}
+	var err error
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"parcel_id", &search.ParcelID}, {"user_id", &search.UserID}, {"carrier_id", &search.CarrierID}, {"status", &search.Status}, {"offset", &search.Offset}} {
+		if *param.dst, err = consoleInt(query.Get(param.name), param.name); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = search.ValidateParcelSearch()
+	}
+	page.Search = search
+	if err != nil {
+		page.Error = err.Error()
+		s.renderConsole(w, http.StatusBadRequest, "parcels", page)
+		return
+	}
+
+	parcels, err := s.consoleService.SearchParcels(r.Context(), search)
+	if err != nil {
+		log.Error().Err(err).Msgf("[consoleParcels] failed to search parcels: %v", err)
+		page.Error = "Failed to search parcels."
+		s.renderConsole(w, http.StatusInternalServerError, "parcels", page)
+		return
+	}
+
+	page.Parcels = parcels
+	if search.Offset > 0 {
+		page.PrevURL = pageURL("/admin/parcels", query, maxInt(search.Offset-search.Limit, 0))
+	}
+	if len(parcels) == search.Limit {
+		page.NextURL = pageURL("/admin/parcels", query, search.Offset+search.Limit)
+	}
+	s.renderConsole(w, http.StatusOK, "parcels", page)
+}
+
+func (s *server) consoleParcel(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.consoleAdmin(w, r); !ok {
+		return
+	}
+
+	notice := ""
+	if r.URL.Query().Get("updated") == "status" {
+		notice = "The status of the parcel was changed."
+	}
+	s.renderParcelPage(w, r, http.StatusOK, "", notice)
+}
+
+// renderParcelPage shows the parcel of the request with its history and carrier requests
+func (s *server) renderParcelPage(w http.ResponseWriter, r *http.Request, status int, errMsg string, notice string) {
+	page := consolePage{Title: "Parcel", Admin: true, Error: errMsg, Notice: notice}
+
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		page.Error = "The parcel ID must be a number."
+		s.renderConsole(w, http.StatusBadRequest, "error", page)
+		return
+	}
+	page.Title = fmt.Sprintf("Parcel #%d", parcelID)
+
+	page.Parcel, err = s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			page.Error = fmt.Sprintf("Parcel %d is not found.", parcelID)
+			s.renderConsole(w, http.StatusNotFound, "error", page)
+			return
+		}
+		log.Error().Err(err).Msgf("[renderParcelPage] failed to fetch parcel '%d': %v", parcelID, err)
+		page.Error = "Failed to fetch the parcel."
+		s.renderConsole(w, http.StatusInternalServerError, "error", page)
+		return
+	}
+
+	if page.History, err = s.consoleService.GetParcelHistory(r.Context(), parcelID); err == nil {
+		page.Requests, err = s.consoleService.GetParcelRequests(r.Context(), parcelID)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("[renderParcelPage] failed to fetch history of parcel '%d': %v", parcelID, err)
+		page.Error = "Failed to fetch the history of the parcel."
+		s.renderConsole(w, http.StatusInternalServerError, "error", page)
+		return
+	}
+
+	s.renderConsole(w, status, "parcel", page)
+}
+
+// consoleForceStatus changes the status of a parcel with the reason given by the admin
+func (s *server) consoleForceStatus(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.consoleAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		s.renderConsole(w, http.StatusBadRequest, "error", consolePage{Title: "Parcel", Admin: true, Error: "The parcel ID must be a number."})
+		return
+	}
+
+	override := model.StatusOverride{ParcelID: parcelID, AdminID: claims.ID, Reason: r.PostFormValue("reason")}
+	if override.Status, err = consoleInt(r.PostFormValue("status"), "status"); err == nil {
+		err = override.ValidateStatusOverride()
+	}
+	if err == nil {
+		_, err = s.consoleService.ForceStatus(r.Context(), override)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+			s.renderParcelPage(w, r, http.StatusNotFound, err.Error(), "")
+		case errors.Is(err, model.ErrInvalid), errors.Is(err, model.ErrEmpty):
+			s.renderParcelPage(w, r, http.StatusBadRequest, err.Error(), "")
+		default:
+			log.Error().Err(err).Msgf("[consoleForceStatus] failed to change status of parcel '%d': %v", parcelID, err)
+			s.renderParcelPage(w, r, http.StatusInternalServerError, "Failed to change the status of the parcel.", "")
+		}
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/parcels/%d?updated=status", parcelID), http.StatusSeeOther)
+}
+
+func (s *server) consoleCarriers(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.consoleAdmin(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page := consolePage{Title: "Carriers", Admin: true}
+	if updated := query.Get("updated"); updated == "suspended" || updated == "reinstated" {
+		page.Notice = "The carrier was " + updated + "."
+	}
+
+	carrierID, err := consoleInt(query.Get("carrier_id"), "carrier_id")
+	var offset int
+	if err == nil {
+		offset, err = consoleInt(query.Get("offset"), "offset")
+	}
+	if err == nil && offset < 0 {
+		err = fmt.Errorf("offset must not be negative :%w", model.ErrInvalid)
+	}
+	page.CarrierID = carrierID
+	if err != nil {
+		page.Error = err.Error()
+		s.renderConsole(w, http.StatusBadRequest, "carriers", page)
+		return
+	}
+
+	carriers, err := s.consoleService.GetCarriers(r.Context(), carrierID, model.DefaultConsolePageSize, offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[consoleCarriers] failed to fetch carriers: %v", err)
+		page.Error = "Failed to fetch carriers."
+		s.renderConsole(w, http.StatusInternalServerError, "carriers", page)
+		return
+	}
+
+	page.Carriers = carriers
+	if offset > 0 {
+		page.PrevURL = pageURL("/admin/carriers", query, maxInt(offset-model.DefaultConsolePageSize, 0))
+	}
+	if len(carriers) == model.DefaultConsolePageSize {
+		page.NextURL = pageURL("/admin/carriers", query, offset+model.DefaultConsolePageSize)
+	}
+	s.renderConsole(w, http.StatusOK, "carriers", page)
+}
+
+// consoleSuspendCarrier suspends a carrier from requesting parcels or reinstates it
+func (s *server) consoleSuspendCarrier(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.consoleAdmin(w, r); !ok {
+		return
+	}
+
+	carrierID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		s.renderConsole(w, http.StatusBadRequest, "error", consolePage{Title: "Carriers", Admin: true, Error: "The carrier ID must be a number."})
+		return
+	}
+
+	suspension := model.CarrierSuspension{CarrierID: carrierID, Suspended: r.PostFormValue("suspended") == "true", Reason: r.PostFormValue("reason")}
+	if err := suspension.ValidateCarrierSuspension(); err != nil {
+		s.renderConsole(w, http.StatusBadRequest, "error", consolePage{Title: "Carriers", Admin: true, Error: err.Error()})
+		return
+	}
+
+	if err := s.consoleService.SuspendCarrier(r.Context(), suspension); err != nil {
+		log.Error().Err(err).Msgf("[consoleSuspendCarrier] failed to update carrier '%d': %v", carrierID, err)
+		s.renderConsole(w, http.StatusInternalServerError, "error", consolePage{Title: "Carriers", Admin: true, Error: "Failed to update the carrier."})
+		return
+	}
+
+	updated := "reinstated"
+	if suspension.Suspended {
+		updated = "suspended"
+	}
+	http.Redirect(w, r, fmt.Sprintf("/admin/carriers?carrier_id=%d&updated=%s", carrierID, updated), http.StatusSeeOther)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	admin := adminToken(t, signer)
+
+	testCases := []struct {
+		desc          string
+		method        string
+		url           string
+		cookie        string
+		form          url.Values
+		mockParcelSvc func() *mocks.MockParcelService
+		mockSvc       func() *mocks.MockConsoleService
+		expStatusCode int
+		expLocation   string
+		expCookie     string
+		expContains   []string
+	}{
+		{
+			desc:          "should send visitors without a token to the login page",
+			method:        http.MethodGet,
+			url:           "/admin/parcels",
+			expStatusCode: http.StatusSeeOther,
+			expLocation:   "/admin/login",
+		},
+		{
+			desc:          "should log in an admin",
+			method:        http.MethodPost,
+			url:           "/admin/login",
+			form:          url.Values{"token": {admin}},
+			expStatusCode: http.StatusSeeOther,
+			expLocation:   "/admin/parcels",
+			expCookie:     admin,
+		},
+		{
+			desc:          "should refuse to log in a user",
+			method:        http.MethodPost,
+			url:           "/admin/login",
+			form:          url.Values{"token": {userToken(t, signer, 3)}},
+			expStatusCode: http.StatusForbidden,
+			expContains:   []string{"The admin role is required to use the console."},
+		},
+		{
+			desc:          "should refuse a carrier cookie",
+			method:        http.MethodGet,
+			url:           "/admin/carriers",
+			cookie:        carrierToken(t, signer, 7),
+			expStatusCode: http.StatusForbidden,
+			expContains:   []string{"The admin role is required to use the console."},
+		},
+		{
+			desc:   "should search parcels",
+			method: http.MethodGet,
+			url:    "/admin/parcels?q=dhaka&status=1",
+			cookie: admin,
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().SearchParcels(gomock.Any(), model.ParcelSearch{Status: model.ParcelStatusCreated, Query: "dhaka", Limit: model.DefaultConsolePageSize}).
+					Return([]model.Parcel{{ID: 1, UserID: 3, Status: model.ParcelStatusCreated, SourceAddress: "Dhaka <Mirpur>", ParcelType: "Document"}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expContains:   []string{`<a href="/admin/parcels/1">#1</a>`, "Dhaka &lt;Mirpur&gt;", `<option value="1" selected>created</option>`},
+		},
+		{
+			desc:          "should return invalid search",
+			method:        http.MethodGet,
+			url:           "/admin/parcels?user_id=abc",
+			cookie:        admin,
+			expStatusCode: http.StatusBadRequest,
+			expContains:   []string{"user_id must be a number :invalid"},
+		},
+		{
+			desc:   "should show a parcel with its history and requests",
+			method: http.MethodGet,
+			url:    "/admin/parcels/1",
+			cookie: admin,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusAssigned}, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().GetParcelHistory(gomock.Any(), 1).Return([]model.Event{{Type: model.EventCarrierAssigned, ParcelID: 1, CarrierID: 7}}, nil)
+				s.EXPECT().GetParcelRequests(gomock.Any(), 1).Return([]model.CarrierRequest{{ParcelID: 1, CarrierID: 7, Status: model.CarrierRequestAccepted}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expContains:   []string{"<h1>Parcel #1</h1>", "carrier.assigned", "<td>accepted</td>", `<form method="post" action="/admin/parcels/1/status">`},
+		},
+		{
+			desc:   "should return parcel not found",
+			method: http.MethodGet,
+			url:    "/admin/parcels/5",
+			cookie: admin,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 5).Return(model.Parcel{}, fmt.Errorf("parcel with the ID 5 is not found. :%w", model.ErrNotFound))
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expContains:   []string{"Parcel 5 is not found."},
+		},
+		{
+			desc:   "should return server error",
+			method: http.MethodGet,
+			url:    "/admin/carriers",
+			cookie: admin,
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().GetCarriers(gomock.Any(), 0, model.DefaultConsolePageSize, 0).Return(nil, errors.New("db-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expContains:   []string{"Failed to fetch carriers."},
+		},
+		{
+			desc:   "should force the status of a parcel",
+			method: http.MethodPost,
+			url:    "/admin/parcels/1/status",
+			cookie: admin,
+			form:   url.Values{"status": {"4"}, "reason": {" confirmed by phone "}},
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().ForceStatus(gomock.Any(), model.StatusOverride{ParcelID: 1, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}).
+					Return(model.StatusOverride{ParcelID: 1, PreviousStatus: model.ParcelStatusPickedUp, Status: model.ParcelStatusDelivered, Reason: "confirmed by phone"}, nil)
+				return s
+			},
+			expStatusCode: http.StatusSeeOther,
+			expLocation:   "/admin/parcels/1?updated=status",
+		},
+		{
+			desc:   "should require the reason of a status change",
+			method: http.MethodPost,
+			url:    "/admin/parcels/1/status",
+			cookie: admin,
+			form:   url.Values{"status": {"4"}},
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3, Status: model.ParcelStatusPickedUp}, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().GetParcelHistory(gomock.Any(), 1).Return([]model.Event{}, nil)
+				s.EXPECT().GetParcelRequests(gomock.Any(), 1).Return([]model.CarrierRequest{}, nil)
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expContains:   []string{`<p class="error">reason is required :empty</p>`},
+		},
+		{
+			desc:   "should list carriers",
+			method: http.MethodGet,
+			url:    "/admin/carriers",
+			cookie: admin,
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().GetCarriers(gomock.Any(), 0, model.DefaultConsolePageSize, 0).
+					Return([]model.CarrierProfile{{CarrierID: 7, Rating: 4.5, RatingCount: 2, Delivered: 12}, {CarrierID: 9, Suspended: true, SuspendedReason: "fake pickups"}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expContains:   []string{"4.5 (2)", "suspended: fake pickups", "Reinstate"},
+		},
+		{
+			desc:   "should suspend a carrier",
+			method: http.MethodPost,
+			url:    "/admin/carriers/9/suspension",
+			cookie: admin,
+			form:   url.Values{"suspended": {"true"}, "reason": {"fake pickups"}},
+			mockSvc: func() *mocks.MockConsoleService {
+				s := mocks.NewMockConsoleService(ctrl)
+				s.EXPECT().SuspendCarrier(gomock.Any(), model.CarrierSuspension{CarrierID: 9, Suspended: true, Reason: "fake pickups"}).Return(nil)
+				return s
+			},
+			expStatusCode: http.StatusSeeOther,
+			expLocation:   "/admin/carriers?carrier_id=9&updated=suspended",
+		},
+		{
+			desc:          "should require the reason of a suspension",
+			method:        http.MethodPost,
+			url:           "/admin/carriers/9/suspension",
+			cookie:        admin,
+			form:          url.Values{"suspended": {"true"}},
+			expStatusCode: http.StatusBadRequest,
+			expContains:   []string{"reason is required :empty"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parcelSvc := mocks.NewMockParcelService(ctrl)
+			if tc.mockParcelSvc != nil {
+				parcelSvc = tc.mockParcelSvc()
+			}
+			consoleSvc := mocks.NewMockConsoleService(ctrl)
+			if tc.mockSvc != nil {
+				consoleSvc = tc.mockSvc()
+			}
+			s := NewServer(":8080", parcelSvc, nil, WithAuthenticator(signer), WithConsoleService(consoleSvc))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.form.Encode()))
+			if tc.form != nil {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: consoleCookie, Value: tc.cookie})
+			}
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expLocation, w.Header().Get("Location"))
+			if tc.expCookie != "" {
+				cookies := w.Result().Cookies()
+				assert.Len(t, cookies, 1)
+				assert.Equal(t, consoleCookie, cookies[0].Name)
+				assert.Equal(t, tc.expCookie, cookies[0].Value)
+				assert.True(t, cookies[0].HttpOnly)
+			}
+			for _, part := range tc.expContains {
+				assert.Contains(t, w.Body.String(), part)
+			}
+		})
+	}
+}
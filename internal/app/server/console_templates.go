@@ -0,0 +1,167 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"parcel-service/internal/app/model"
+	"time"
+)
+
+var carrierRequestStatusNames = map[int]string{
+	model.CarrierRequestPending:  "pending",
+	model.CarrierRequestAccepted: "accepted",
+	model.CarrierRequestRejected: "rejected",
+	model.CarrierRequestExpired:  "expired",
+}
+
+// consoleStatuses are offered in the status selects of the console, in the order of the parcel lifecycle
+var consoleStatuses = []int{
+	model.ParcelStatusCreated,
+	model.ParcelStatusAssigned,
+	model.ParcelStatusPickedUp,
+	model.ParcelStatusDelivered,
+	model.ParcelStatusCancelled,
+	model.ParcelStatusReturned,
+}
+
+var consoleTemplates = template.Must(template.New("console").Funcs(template.FuncMap{
+	"status": model.ParcelStatusName,
+	"requestStatus": func(status int) string {
+		return carrierRequestStatusNames[status]
+	},
+	"money": func(amount float32) string {
+		return fmt.Sprintf("%.2f", amount)
+	},
+	"time": func(t interface{}) string {
+		switch v := t.(type) {
+		case time.Time:
+			return v.Format("2006-01-02 15:04")
+		case *time.Time:
+			if v != nil {
+				return v.Format("2006-01-02 15:04")
+			}
+		}
+		return "-"
+	},
+	"statuses": func() []int {
+		return consoleStatuses
+	},
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Parcel Service Admin</title>
+<style>
+body { font-family: sans-serif; margin: 0; }
+nav { background: #263238; padding: 10px 24px; }
+nav a, nav button { color: #fff; margin-right: 16px; text-decoration: none; background: none; border: 0; font: inherit; cursor: pointer; }
+nav form { display: inline; float: right; }
+main { margin: 24px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+td, th { padding: 6px; border-bottom: 1px solid #ddd; text-align: left; }
+.error { background: #ffebee; color: #b71c1c; padding: 8px; }
+.notice { background: #e8f5e9; color: #1b5e20; padding: 8px; }
+form.inline { display: inline; }
+input, select { margin-right: 8px; }
+</style>
+</head>
+<body>
+{{if .Admin}}<nav><a href="/admin/parcels">Parcels</a><a href="/admin/carriers">Carriers</a>
+<form method="post" action="/admin/logout"><button type="submit">Log out</button></form></nav>{{end}}
+<main>
+<h1>{{.Title}}</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{if .Notice}}<p class="notice">{{.Notice}}</p>{{end}}
+{{end}}
+
+{{define "footer"}}</main>
+</body>
+</html>
+{{end}}
+
+{{define "login"}}{{template "header" .}}
+<form method="post" action="/admin/login">
+<label>Admin access token <input type="password" name="token" size="60" autocomplete="off"></label>
+<button type="submit">Log in</button>
+</form>
+<p>Issue a token with <code>parcel-server token --role admin --id &lt;admin id&gt;</code>.</p>
+{{template "footer" .}}{{end}}
+
+{{define "error"}}{{template "header" .}}{{template "footer" .}}{{end}}
+
+{{define "parcels"}}{{template "header" .}}
+<form method="get" action="/admin/parcels">
+<input type="text" name="q" value="{{.Search.Query}}" placeholder="Address or tracking code">
+<input type="number" name="parcel_id" value="{{if .Search.ParcelID}}{{.Search.ParcelID}}{{end}}" placeholder="Parcel ID">
+<input type="number" name="user_id" value="{{if .Search.UserID}}{{.Search.UserID}}{{end}}" placeholder="User ID">
+<input type="number" name="carrier_id" value="{{if .Search.CarrierID}}{{.Search.CarrierID}}{{end}}" placeholder="Carrier ID">
+<select name="status"><option value="">Any status</option>
+{{$current := .Search.Status}}{{range statuses}}<option value="{{.}}"{{if eq . $current}} selected{{end}}>{{status .}}</option>{{end}}
+</select>
+<button type="submit">Search</button>
+</form>
+<table>
+<tr><th>ID</th><th>User</th><th>Carrier</th><th>Status</th><th>Type</th><th>From</th><th>To</th><th>Price</th><th>Created</th></tr>
+{{range .Parcels}}<tr><td><a href="/admin/parcels/{{.ID}}">#{{.ID}}</a></td><td>{{.UserID}}</td><td>{{if .CarrierID}}{{.CarrierID}}{{else}}-{{end}}</td><td>{{status .Status}}</td><td>{{.ParcelType}}</td><td>{{.SourceAddress}}</td><td>{{.DestinationAddress}}</td><td>{{money .Price}}</td><td>{{time .CreatedAt}}</td></tr>
+{{else}}<tr><td colspan="9">No parcels match the search.</td></tr>
+{{end}}</table>
+{{if .PrevURL}}<a href="{{.PrevURL}}">Previous</a>{{end}} {{if .NextURL}}<a href="{{.NextURL}}">Next</a>{{end}}
+{{template "footer" .}}{{end}}
+
+{{define "parcel"}}{{template "header" .}}
+{{with .Parcel}}<table>
+<tr><th>Status</th><td>{{status .Status}}</td></tr>
+<tr><th>User</th><td><a href="/admin/parcels?user_id={{.UserID}}">{{.UserID}}</a></td></tr>
+<tr><th>Carrier</th><td>{{if .CarrierID}}<a href="/admin/carriers?carrier_id={{.CarrierID}}">{{.CarrierID}}</a>{{else}}-{{end}}</td></tr>
+<tr><th>Type</th><td>{{.ParcelType}}</td></tr>
+<tr><th>From</th><td>{{.SourceAddress}}</td></tr>
+<tr><th>To</th><td>{{.DestinationAddress}}</td></tr>
+<tr><th>Weight</th><td>{{.Weight}}</td></tr>
+<tr><th>Price</th><td>{{money .Price}} (carrier fee {{money .CarrierFee}}, company fee {{money .CompanyFee}})</td></tr>
+<tr><th>Created</th><td>{{time .CreatedAt}}</td></tr>
+<tr><th>Assigned</th><td>{{time .AssignedAt}}</td></tr>
+<tr><th>Picked up</th><td>{{time .PickedUpAt}}</td></tr>
+<tr><th>Delivered</th><td>{{time .DeliveredAt}}</td></tr>
+</table>{{end}}
+
+<h2>Force status</h2>
+<form method="post" action="/admin/parcels/{{.Parcel.ID}}/status">
+<select name="status">{{$current := .Parcel.Status}}{{range statuses}}<option value="{{.}}"{{if eq . $current}} selected{{end}}>{{status .}}</option>{{end}}</select>
+<input type="text" name="reason" size="60" placeholder="Reason" required>
+<button type="submit">Change status</button>
+</form>
+
+<h2>History</h2>
+<table>
+<tr><th>Time</th><th>Event</th><th>Status</th><th>Carrier</th><th>Reason</th></tr>
+{{range .History}}<tr><td>{{time .OccurredAt}}</td><td>{{.Type}}</td><td>{{if .Status}}{{status .Status}}{{end}}</td><td>{{if .CarrierID}}{{.CarrierID}}{{end}}</td><td>{{.Reason}}{{.Breach}}</td></tr>
+{{else}}<tr><td colspan="5">No events were recorded for this parcel.</td></tr>
+{{end}}</table>
+
+<h2>Carrier requests</h2>
+<table>
+<tr><th>Carrier</th><th>Status</th><th>Rating</th><th>Expires</th></tr>
+{{range .Requests}}<tr><td><a href="/admin/carriers?carrier_id={{.CarrierID}}">{{.CarrierID}}</a></td><td>{{requestStatus .Status}}</td><td>{{if .RatingCount}}{{printf "%.1f" .Rating}} ({{.RatingCount}}){{else}}-{{end}}</td><td>{{time .ExpiresAt}}</td></tr>
+{{else}}<tr><td colspan="4">No carrier requested this parcel.</td></tr>
+{{end}}</table>
+{{template "footer" .}}{{end}}
+
+{{define "carriers"}}{{template "header" .}}
+<form method="get" action="/admin/carriers">
+<input type="number" name="carrier_id" value="{{if .CarrierID}}{{.CarrierID}}{{end}}" placeholder="Carrier ID">
+<button type="submit">Search</button>
+</form>
+<table>
+<tr><th>Carrier</th><th>Rating</th><th>Active parcels</th><th>Delivered</th><th>Status</th><th></th></tr>
+{{range .Carriers}}<tr><td><a href="/admin/parcels?carrier_id={{.CarrierID}}">{{.CarrierID}}</a></td><td>{{if .RatingCount}}{{printf "%.1f" .Rating}} ({{.RatingCount}}){{else}}-{{end}}</td><td>{{.ActiveParcels}}</td><td>{{.Delivered}}</td>
+<td>{{if .Suspended}}suspended: {{.SuspendedReason}}{{else}}active{{end}}</td>
+<td><form class="inline" method="post" action="/admin/carriers/{{.CarrierID}}/suspension">
+{{if .Suspended}}<input type="hidden" name="suspended" value="false"><button type="submit">Reinstate</button>
+{{else}}<input type="hidden" name="suspended" value="true"><input type="text" name="reason" placeholder="Reason" required><button type="submit">Suspend</button>{{end}}
+</form></td></tr>
+{{else}}<tr><td colspan="6">No carriers found.</td></tr>
+{{end}}</table>
+{{if .PrevURL}}<a href="{{.PrevURL}}">Previous</a>{{end}} {{if .NextURL}}<a href="{{.NextURL}}">Next</a>{{end}}
+{{template "footer" .}}{{end}}
+`))
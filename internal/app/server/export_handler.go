@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// streamWriter sends the response headers with the first chunk of an export and flushes every chunk to the client,
+// so an export failing before it wrote anything can still be answered with an error
+type streamWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.start()
+	n, err := sw.w.Write(p)
+	if flusher, ok := sw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
+func (sw *streamWriter) start() {
+	if sw.started {
+		return
+	}
+	sw.started = true
+	sw.w.Header().Set("Content-Type", sw.contentType)
+	sw.w.Header().Set("Content-Disposition", `attachment; filename="`+sw.filename+`"`)
+	sw.w.WriteHeader(http.StatusOK)
+}
+
+// exportParcels streams the parcels matching the query filters as CSV or NDJSON to admins
+func (s *server) exportParcels(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = model.ExportFormatCSV
+	}
+
+	filter := model.ExportFilter{Type: query.Get("type"), Date: query.Get("date")}
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{{"status", &filter.Status}, {"user_id", &filter.UserID}, {"carrier_id", &filter.CarrierID}} {
+		if value := query.Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				ErrInvalidEntityResponse(w, "Invalid "+param.name+" value", err)
+				return
+			}
+			*param.dst = n
+		}
+	}
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if value := query.Get(param.name); value != "" {
+			t, err := model.ParseExportTime(value)
+			if err != nil {
+				ErrInvalidEntityResponse(w, "Invalid "+param.name+" value", err)
+				return
+			}
+			*param.dst = t
+		}
+	}
+
+	if err := filter.ValidateExportFilter(format); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == model.ExportFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	stream := &streamWriter{w: w, contentType: contentType, filename: "parcels." + format}
+
+	count, err := s.exportService.ExportParcels(r.Context(), stream, format, filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[exportParcels] failed to export parcels after %d rows: %v", count, err)
+		if !stream.started {
+			ErrInternalServerResponse(w, "failed to export parcels", err)
+		}
+		// the client sees a truncated file once rows were sent
+		return
+	}
+
+	stream.start()
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportParcels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	from := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc           string
+		token          string
+		query          string
+		mockSvc        func() *mocks.MockExportService
+		expStatusCode  int
+		expContentType string
+		expResponse    string
+	}{
+		{
+			desc:  "should stream csv export",
+			token: "Bearer " + adminToken(t, signer),
+			query: "?status=4&from=2021-03-01&date=delivered",
+			mockSvc: func() *mocks.MockExportService {
+				s := mocks.NewMockExportService(ctrl)
+				s.EXPECT().ExportParcels(gomock.Any(), gomock.Any(), model.ExportFormatCSV, model.ExportFilter{Status: 4, Date: model.ExportDateDelivered, From: &from}).
+					DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ model.ExportFilter) (int, error) {
+						_, err := io.WriteString(w, "id,user_id\n1,3\n")
+						return 1, err
+					})
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "text/csv; charset=utf-8",
+			expResponse:    "id,user_id\n1,3\n",
+		},
+		{
+			desc:  "should send headers of an empty ndjson export",
+			token: "Bearer " + adminToken(t, signer),
+			query: "?format=ndjson&carrier_id=7",
+			mockSvc: func() *mocks.MockExportService {
+				s := mocks.NewMockExportService(ctrl)
+				s.EXPECT().ExportParcels(gomock.Any(), gomock.Any(), model.ExportFormatNDJSON, model.ExportFilter{CarrierID: 7, Date: model.ExportDateCreated}).Return(0, nil)
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "application/x-ndjson",
+			expResponse:    "",
+		},
+		{
+			desc:  "should return forbidden for users",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockSvc: func() *mocks.MockExportService {
+				return mocks.NewMockExportService(ctrl)
+			},
+			expStatusCode:  http.StatusForbidden,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid status value",
+			token: "Bearer " + adminToken(t, signer),
+			query: "?status=delivered",
+			mockSvc: func() *mocks.MockExportService {
+				return mocks.NewMockExportService(ctrl)
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"delivered\": invalid syntax","message_title":"Invalid status value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid format",
+			token: "Bearer " + adminToken(t, signer),
+			query: "?format=xlsx",
+			mockSvc: func() *mocks.MockExportService {
+				return mocks.NewMockExportService(ctrl)
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"format must be csv or ndjson :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return server error before streaming",
+			token: "Bearer " + adminToken(t, signer),
+			mockSvc: func() *mocks.MockExportService {
+				s := mocks.NewMockExportService(ctrl)
+				s.EXPECT().ExportParcels(gomock.Any(), gomock.Any(), model.ExportFormatCSV, gomock.Any()).Return(0, errors.New("server-error"))
+				return s
+			},
+			expStatusCode:  http.StatusInternalServerError,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to export parcels","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithExportService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/parcels/export"+tc.query, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"parcel-service/internal/app/model"
 	"strconv"
@@ -101,6 +102,22 @@ func (s *server) addCarrierRequest(w http.ResponseWriter, r *http.Request) {
 	SuccessResponse(w, http.StatusCreated, "Success")
 }
 
+func (s *server) getCarrierRequests(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	requests, err := s.carrierService.GetCarrierRequests(r.Context(), parcelID)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getCarrierRequests] failed to get carrier requests for parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to get carrier requests", err)
+		return
+	}
+	SuccessResponse(w, http.StatusOK, requests)
+}
+
 func (s *server) getParcel(w http.ResponseWriter, r *http.Request) {
 	var data model.Parcel
 
@@ -133,6 +150,7 @@ func (s *server) getParcel(w http.ResponseWriter, r *http.Request) {
 	SuccessResponse(w, http.StatusOK, parcel)
 }
 
+// editParcel moves the parcel to its next status for its carrier and admins
 func (s *server) editParcel(w http.ResponseWriter, r *http.Request) {
 	var data model.Parcel
 	vars := mux.Vars(r)
@@ -151,6 +169,21 @@ func (s *server) editParcel(w http.ResponseWriter, r *http.Request) {
 
 	data.ID = parcelID
 
+	parcel, err := s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[editParcel] failed to fetch parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to update parcel", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleCarrier, parcel.CarrierID) {
+		return
+	}
+
 	if err := s.parcelService.EditParcel(r.Context(), data); err != nil {
 		if errors.Is(err, model.ErrInvalid) || errors.Is(err, model.ErrNotFound) {
 			ErrInvalidEntityResponse(w, "invalid Request", err)
@@ -187,9 +220,112 @@ func (s *server) parcelCarrierAccept(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.carrierService.AssignCarrierToParcel(r.Context(), data); err != nil {
+		if errors.Is(err, model.ErrConflict) {
+			ErrConflictResponse(w, "carrier request can not be accepted", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "carrier request can not be accepted", err)
+			return
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
 		log.Error().Err(err).Msgf("[parcelCarrierAccept] failed to assign carrier to parcel: %v", err)
 		ErrInternalServerResponse(w, "failed to assign carrier to parcel", err)
 		return
 	}
 	SuccessResponse(w, http.StatusNoContent, "Successful")
 }
+
+func (s *server) updateCarrierLocation(w http.ResponseWriter, r *http.Request) {
+	var data model.CarrierLocation
+	vars := mux.Vars(r)
+
+	carrierID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Carrier ID", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleCarrier, carrierID) {
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.CarrierID = carrierID
+
+	if err := data.ValidateLocationInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	if err := s.carrierService.UpdateLocation(r.Context(), data); err != nil {
+		log.Error().Err(err).Msgf("[updateCarrierLocation] failed to update location of carrier %d: %v", carrierID, err)
+		ErrInternalServerResponse(w, "failed to update carrier location", err)
+		return
+	}
+	SuccessResponse(w, http.StatusOK, "Successful")
+}
+
+func (s *server) cancelParcel(w http.ResponseWriter, r *http.Request) {
+	var data model.Cancellation
+	vars := mux.Vars(r)
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	parcelID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+	data.ParcelID = parcelID
+
+	if err := data.ValidateCancellationInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	parcel, err := s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[cancelParcel] failed to fetch parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to cancel parcel", err)
+		return
+	}
+	if !claims.Allows(model.RoleUser, parcel.UserID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for %s %d :%w", model.RoleUser, parcel.UserID, model.ErrForbidden))
+		return
+	}
+
+	cancellation, err := s.parcelService.CancelParcel(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "parcel can not be cancelled", err)
+			return
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[cancelParcel] failed to cancel parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to cancel parcel", err)
+		return
+	}
+	SuccessResponse(w, http.StatusOK, cancellation)
+}
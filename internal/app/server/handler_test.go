@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"parcel-service/internal/app/auth"
 	"parcel-service/internal/app/model"
 	"parcel-service/internal/app/service/mocks"
 	"strings"
@@ -69,6 +70,60 @@ func TestNewParcel(t *testing.T) {
 			expStatusCode: http.StatusBadRequest,
 			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"source Address is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
 		},
+		{
+			desc:    "should return invalid source location",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "source_latitude":23.81, "source_longitude":190.41 }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"longitude must be between -180 and 180 :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return incomplete pickup window",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "pickup_window_start":"3021-10-10T09:00:00Z" }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"pickup window needs a start and an end :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return pickup window in the past",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "pickup_window_start":"2020-10-10T09:00:00Z", "pickup_window_end":"2020-10-10T12:00:00Z" }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"pickup window must start in the future :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return reversed delivery window",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "delivery_window_start":"3021-10-11T12:00:00Z", "delivery_window_end":"3021-10-11T09:00:00Z" }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"delivery window must end after it starts :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return delivery before pickup",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "pickup_window_start":"3021-10-10T09:00:00Z", "pickup_window_end":"3021-10-10T12:00:00Z", "delivery_window_start":"3021-10-09T09:00:00Z", "delivery_window_end":"3021-10-11T12:00:00Z" }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"delivery window can not start before the pickup window :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return source time outside pickup window",
+			payload: `{ "user_id":1, "source_address":"Dhaka Bangladesh", "destination_address":"Pabna Shadar", "type":"Document", "source_time":"3021-10-10T10:10:12Z", "pickup_window_start":"3021-10-11T09:00:00Z", "pickup_window_end":"3021-10-11T12:00:00Z" }`,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"source time must be within the pickup window :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
 		{
 			desc:    "should return invalid parcel error",
 			payload: payload,
@@ -466,10 +521,14 @@ func TestEditParcel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	signer := auth.NewSigner([]byte("secret"))
+	assigned := model.Parcel{ID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusAssigned}
+
 	testCases := []struct {
 		desc          string
 		payload       string
 		parcelId      string
+		token         string
 		mockParcelSvc func() *mocks.MockParcelService
 		expStatusCode int
 		expResponse   string
@@ -478,8 +537,10 @@ func TestEditParcel(t *testing.T) {
 			desc:     "should success",
 			parcelId: parcelId["valid"],
 			payload:  payload,
+			token:    "Bearer " + carrierToken(t, signer, 7),
 			mockParcelSvc: func() *mocks.MockParcelService {
 				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(assigned, nil)
 				s.EXPECT().EditParcel(gomock.Any(), gomock.Any()).Return(nil)
 				return s
 			},
@@ -490,6 +551,7 @@ func TestEditParcel(t *testing.T) {
 			desc:     "should return decode error",
 			parcelId: parcelId["valid"],
 			payload:  `------------`,
+			token:    "Bearer " + carrierToken(t, signer, 7),
 			mockParcelSvc: func() *mocks.MockParcelService {
 				return mocks.NewMockParcelService(ctrl)
 			},
@@ -500,8 +562,10 @@ func TestEditParcel(t *testing.T) {
 			desc:     "should return invalid request error",
 			parcelId: parcelId["valid"],
 			payload:  payload,
+			token:    "Bearer " + carrierToken(t, signer, 7),
 			mockParcelSvc: func() *mocks.MockParcelService {
 				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(assigned, nil)
 				s.EXPECT().EditParcel(gomock.Any(), gomock.Any()).Return(model.ErrInvalid)
 				return s
 			},
@@ -512,8 +576,10 @@ func TestEditParcel(t *testing.T) {
 			desc:     "should return internal server error",
 			parcelId: parcelId["valid"],
 			payload:  payload,
+			token:    "Bearer " + carrierToken(t, signer, 7),
 			mockParcelSvc: func() *mocks.MockParcelService {
 				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(assigned, nil)
 				s.EXPECT().EditParcel(gomock.Any(), gomock.Any()).Return(errors.New("server-error"))
 				return s
 			},
@@ -524,23 +590,63 @@ func TestEditParcel(t *testing.T) {
 			desc:     "should return invalid parcel ID",
 			payload:  payload,
 			parcelId: parcelId["invalid"],
+			token:    "Bearer " + carrierToken(t, signer, 7),
 			mockParcelSvc: func() *mocks.MockParcelService {
 				return mocks.NewMockParcelService(ctrl)
 			},
 			expStatusCode: http.StatusBadRequest,
 			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Parcel ID","severity":"error"}],"data":null}`,
 		},
+		{
+			desc:     "should return unauthorized without a token",
+			parcelId: parcelId["valid"],
+			payload:  payload,
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(assigned, nil)
+				return s
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for another carrier",
+			parcelId: parcelId["valid"],
+			payload:  payload,
+			token:    "Bearer " + carrierToken(t, signer, 8),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(assigned, nil)
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for carrier 7 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found",
+			parcelId: parcelId["valid"],
+			payload:  payload,
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			s := NewServer(":8080", tc.mockParcelSvc(), nil)
+			s := NewServer(":8080", tc.mockParcelSvc(), nil, WithAuthenticator(signer))
 
 			w := httptest.NewRecorder()
 			body := strings.NewReader(tc.payload)
 
 			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/parcel/%s", tc.parcelId), body)
 			r = mux.SetURLVars(r, map[string]string{"id": tc.parcelId})
+			r.Header.Set("Authorization", tc.token)
 
 			router := mux.NewRouter()
 			router.Methods(http.MethodPut).Path("/api/v1/parcel/{id}").HandlerFunc(s.editParcel)
@@ -609,6 +715,42 @@ func TestParcelCarrierAccept(t *testing.T) {
 			expStatusCode: http.StatusInternalServerError,
 			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to assign carrier to parcel","severity":"error"}],"data":null}`,
 		},
+		{
+			desc:     "should return expired request error",
+			parcelId: parcelId["valid"],
+			payload:  `{ "carrier_id": 2 }`,
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().AssignCarrierToParcel(gomock.Any(), gomock.Any()).Return(fmt.Errorf("request of carrier 2 for parcel 1 expired at 2020-04-11T21:34:01Z :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"request of carrier 2 for parcel 1 expired at 2020-04-11T21:34:01Z :invalid","message_title":"carrier request can not be accepted","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return conflict error when the parcel is already assigned",
+			parcelId: parcelId["valid"],
+			payload:  `{ "carrier_id": 2 }`,
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().AssignCarrierToParcel(gomock.Any(), gomock.Any()).Return(fmt.Errorf("parcel 1 is assigned and can not be assigned :%w", model.ErrConflict))
+				return s
+			},
+			expStatusCode: http.StatusConflict,
+			expResponse:   `{"success":false,"errors":[{"code":"CONFLICT","message":"parcel 1 is assigned and can not be assigned :conflict","message_title":"carrier request can not be accepted","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found error",
+			parcelId: parcelId["valid"],
+			payload:  `{ "carrier_id": 2 }`,
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().AssignCarrierToParcel(gomock.Any(), gomock.Any()).Return(model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
 		{
 			desc:     "should return invalid parcel ID",
 			payload:  `{ "carrier_id": 2 }`,
@@ -636,3 +778,348 @@ func TestParcelCarrierAccept(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelParcel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	cancellation := model.Cancellation{
+		ParcelID:            1,
+		PreviousStatus:      model.ParcelStatusAssigned,
+		Reason:              "no longer needed",
+		Fee:                 30,
+		Refund:              170,
+		CarrierID:           2,
+		CarrierCompensation: 30,
+		CreatedAt:           time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		parcelId      string
+		mockSvc       func() *mocks.MockParcelService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:     "should success",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+				s.EXPECT().CancelParcel(gomock.Any(), model.Cancellation{ParcelID: 1, Reason: "no longer needed"}).Return(cancellation, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"parcel_id":1,"previous_status":2,"reason":"no longer needed","fee":30,"refund":170,"carrier_id":2,"carrier_compensation":30,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:     "should return decode error",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `------------`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusUnprocessableEntity,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid character '-' in numeric literal","message_title":"Decode Error","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return invalid parcel ID",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "invalid",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Parcel ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return missing reason",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{}`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"cancellation reason is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not cancellable",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+				s.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).Return(model.Cancellation{}, model.ErrInvalid)
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid","message_title":"parcel can not be cancelled","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+				s.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).Return(model.Cancellation{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return unauthorized without a token",
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for another user",
+			token:    "Bearer " + userToken(t, signer, 4),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for user 3 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found parcel",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return internal server error",
+			token:    "Bearer " + userToken(t, signer, 3),
+			parcelId: "1",
+			payload:  `{ "reason": "no longer needed" }`,
+			mockSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+				s.EXPECT().CancelParcel(gomock.Any(), gomock.Any()).Return(model.Cancellation{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to cancel parcel","severity":"error"}],"data":null}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", tc.mockSvc(), nil, WithAuthenticator(signer))
+
+			w := httptest.NewRecorder()
+			body := strings.NewReader(tc.payload)
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/parcel/%s/cancel", tc.parcelId), body)
+			r.Header.Set("Authorization", tc.token)
+			router := mux.NewRouter()
+			router.Methods(http.MethodPost).Path("/api/v1/parcel/{id}/cancel").HandlerFunc(s.cancelParcel)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUpdateCarrierLocation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		payload       string
+		carrierId     string
+		token         string
+		mockSvc       func() *mocks.MockCarrierService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:      "should success",
+			carrierId: "2",
+			payload:   `{"latitude": 23.8103, "longitude": 90.4125}`,
+			token:     "Bearer " + carrierToken(t, signer, 2),
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().UpdateLocation(gomock.Any(), model.CarrierLocation{CarrierID: 2, Latitude: 23.8103, Longitude: 90.4125}).Return(nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":"Successful"}`,
+		},
+		{
+			desc:      "should return decode error",
+			carrierId: "2",
+			payload:   `------------`,
+			token:     "Bearer " + carrierToken(t, signer, 2),
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusUnprocessableEntity,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid character '-' in numeric literal","message_title":"Decode Error","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return invalid carrier ID",
+			carrierId: "invalid",
+			payload:   `{"latitude": 23.8103, "longitude": 90.4125}`,
+			token:     "Bearer " + carrierToken(t, signer, 2),
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Carrier ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return invalid location",
+			carrierId: "2",
+			payload:   `{"latitude": 123.8, "longitude": 90.4125}`,
+			token:     "Bearer " + carrierToken(t, signer, 2),
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"latitude must be between -90 and 90 :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return internal server error",
+			carrierId: "2",
+			payload:   `{"latitude": 23.8103, "longitude": 90.4125}`,
+			token:     "Bearer " + carrierToken(t, signer, 2),
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().UpdateLocation(gomock.Any(), gomock.Any()).Return(errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to update carrier location","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return unauthorized without a token",
+			carrierId: "2",
+			payload:   `{"latitude": 23.8103, "longitude": 90.4125}`,
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return forbidden for another carrier",
+			carrierId: "2",
+			payload:   `{"latitude": 23.8103, "longitude": 90.4125}`,
+			token:     "Bearer " + carrierToken(t, signer, 3),
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for carrier 2 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, tc.mockSvc(), WithAuthenticator(signer))
+
+			w := httptest.NewRecorder()
+			body := strings.NewReader(tc.payload)
+			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/carriers/%s/location", tc.carrierId), body)
+			r.Header.Set("Authorization", tc.token)
+			router := mux.NewRouter()
+			router.Methods(http.MethodPut).Path("/api/v1/carriers/{id}/location").HandlerFunc(s.updateCarrierLocation)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetCarrierRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expiresAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		parcelId      string
+		mockSvc       func() *mocks.MockCarrierService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:     "should success",
+			parcelId: "1",
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().GetCarrierRequests(gomock.Any(), 1).Return([]model.CarrierRequest{
+					{ParcelID: 1, CarrierID: 7, Status: model.CarrierRequestPending, ExpiresAt: expiresAt, Rating: 4.5, RatingCount: 12},
+					{ParcelID: 1, CarrierID: 8, Status: model.CarrierRequestPending, ExpiresAt: expiresAt},
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"id":0,"parcel_id":1,"carrier_id":7,"status":1,"expires_at":"2020-04-11T21:34:01Z","rating":4.5,"rating_count":12},{"id":0,"parcel_id":1,"carrier_id":8,"status":1,"expires_at":"2020-04-11T21:34:01Z"}]}`,
+		},
+		{
+			desc:     "should return invalid parcel ID",
+			parcelId: "invalid",
+			mockSvc: func() *mocks.MockCarrierService {
+				return mocks.NewMockCarrierService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Parcel ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return internal server error",
+			parcelId: "1",
+			mockSvc: func() *mocks.MockCarrierService {
+				s := mocks.NewMockCarrierService(ctrl)
+				s.EXPECT().GetCarrierRequests(gomock.Any(), 1).Return(nil, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to get carrier requests","severity":"error"}],"data":null}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, tc.mockSvc())
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/parcel/%s/requests", tc.parcelId), nil)
+			router := mux.NewRouter()
+			router.Methods(http.MethodGet).Path("/api/v1/parcel/{id}/requests").HandlerFunc(s.getCarrierRequests)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// getParcelInvoice returns the invoice of a parcel to its sender, its carrier and admins
+func (s *server) getParcelInvoice(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "html" && format != "pdf" {
+		ErrInvalidEntityResponse(w, "Invalid format value", errors.New("format must be json, html or pdf"))
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	// the parcel is authorized before its invoice is read, reading the invoice issues it
+	parcel, err := s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getParcelInvoice] failed to fetch parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch parcel "+strconv.Itoa(parcelID), err)
+		return
+	}
+
+	if !claims.Allows(model.RoleUser, parcel.UserID) && !claims.Allows(model.RoleCarrier, parcel.CarrierID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for parcel %d :%w", parcelID, model.ErrForbidden))
+		return
+	}
+
+	invoice, err := s.invoiceService.GetInvoice(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "Invoice is not available", err)
+			return
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getParcelInvoice] failed to get invoice of parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch invoice of parcel "+strconv.Itoa(parcelID), err)
+		return
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	switch format {
+	case "html":
+		contentType = "text/html; charset=utf-8"
+		err = s.invoiceService.RenderHTML(&buf, invoice)
+	case "pdf":
+		contentType = "application/pdf"
+		w.Header().Set("Content-Disposition", `inline; filename="`+invoice.Number+`.pdf"`)
+		err = s.invoiceService.RenderPDF(&buf, invoice)
+	default:
+		SuccessResponse(w, http.StatusOK, invoice)
+		return
+	}
+
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		log.Error().Err(err).Msgf("[getParcelInvoice] failed to render invoice '%s': %v", invoice.Number, err)
+		ErrInternalServerResponse(w, "Failed to render invoice "+invoice.Number, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetParcelInvoice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	parcel := model.Parcel{ID: 1, UserID: 1, CarrierID: 7, Status: model.ParcelStatusDelivered}
+	invoice := model.Invoice{
+		ID:                 1,
+		Number:             "INV-000001",
+		ParcelID:           1,
+		UserID:             1,
+		SourceAddress:      "Dhaka Bangladesh",
+		DestinationAddress: "Pabna Shadar",
+		ParcelType:         "Document",
+		CarrierFee:         180,
+		CompanyFee:         20,
+		Total:              200,
+		IssuedAt:           time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		desc           string
+		url            string
+		token          string
+		mockParcelSvc  func() *mocks.MockParcelService
+		mockInvoiceSvc func() *mocks.MockInvoiceService
+		expStatusCode  int
+		expContentType string
+		expResponse    string
+	}{
+		{
+			desc:  "should return invoice json",
+			url:   "/api/v1/parcel/1/invoice",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoice, nil)
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":true,"errors":null,"data":{"id":1,"number":"INV-000001","parcel_id":1,"user_id":1,"source_address":"Dhaka Bangladesh","destination_address":"Pabna Shadar","type":"Document","carrier_fee":180,"company_fee":20,"discount":0,"tax":0,"tax_name":"","tax_rate":0,"tax_inclusive":false,"total":200,"issued_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:  "should return invoice html",
+			url:   "/api/v1/parcel/1/invoice?format=html",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoice, nil)
+				s.EXPECT().RenderHTML(gomock.Any(), invoice).DoAndReturn(func(w io.Writer, _ model.Invoice) error {
+					_, err := w.Write([]byte("<html></html>"))
+					return err
+				})
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "text/html; charset=utf-8",
+			expResponse:    "<html></html>",
+		},
+		{
+			desc:  "should return invoice pdf",
+			url:   "/api/v1/parcel/1/invoice?format=pdf",
+			token: "Bearer " + adminToken(t, signer),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoice, nil)
+				s.EXPECT().RenderPDF(gomock.Any(), invoice).DoAndReturn(func(w io.Writer, _ model.Invoice) error {
+					_, err := w.Write([]byte("%PDF-1.3"))
+					return err
+				})
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "application/pdf",
+			expResponse:    "%PDF-1.3",
+		},
+		{
+			desc:  "should return invalid format",
+			url:   "/api/v1/parcel/1/invoice?format=doc",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				return mocks.NewMockInvoiceService(ctrl)
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"format must be json, html or pdf","message_title":"Invalid format value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid parcel ID",
+			url:   "/api/v1/parcel/invalid/invoice",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				return mocks.NewMockInvoiceService(ctrl)
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Parcel ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not delivered",
+			url:   "/api/v1/parcel/1/invoice",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(model.Invoice{}, model.ErrInvalid)
+				return s
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"invalid","message_title":"Invoice is not available","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not found",
+			url:   "/api/v1/parcel/1/invoice",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				return mocks.NewMockInvoiceService(ctrl)
+			},
+			expStatusCode:  http.StatusNotFound,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return render error",
+			url:   "/api/v1/parcel/1/invoice?format=pdf",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(invoice, nil)
+				s.EXPECT().RenderPDF(gomock.Any(), invoice).Return(errors.New("render-error"))
+				return s
+			},
+			expStatusCode:  http.StatusInternalServerError,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"render-error","message_title":"Failed to render invoice INV-000001","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return internal server error",
+			url:   "/api/v1/parcel/1/invoice",
+			token: "Bearer " + userToken(t, signer, 1),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				s := mocks.NewMockInvoiceService(ctrl)
+				s.EXPECT().GetInvoice(gomock.Any(), 1).Return(model.Invoice{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode:  http.StatusInternalServerError,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    fmt.Sprintf(`{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"Failed to fetch invoice of parcel %d","severity":"error"}],"data":null}`, 1),
+		},
+		{
+			desc: "should return unauthorized without a token",
+			url:  "/api/v1/parcel/1/invoice",
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				return mocks.NewMockInvoiceService(ctrl)
+			},
+			expStatusCode:  http.StatusUnauthorized,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return forbidden for another user",
+			url:   "/api/v1/parcel/1/invoice",
+			token: "Bearer " + userToken(t, signer, 2),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockInvoiceSvc: func() *mocks.MockInvoiceService {
+				return mocks.NewMockInvoiceService(ctrl)
+			},
+			expStatusCode:  http.StatusForbidden,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", tc.mockParcelSvc(), nil, WithAuthenticator(signer), WithInvoiceService(tc.mockInvoiceSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodGet).Path("/api/v1/parcel/{id}/invoice").HandlerFunc(s.getParcelInvoice)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
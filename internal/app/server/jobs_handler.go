@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// Limits of a carrier job connection
+const (
+	// jobsMaxMessageSize is the largest filter message a carrier can send, larger messages close the connection
+	jobsMaxMessageSize = 1024
+	// jobsWriteWait is the time allowed to write a message before a stalled connection is closed
+	jobsWriteWait = 10 * time.Second
+)
+
+var (
+	// jobsPingInterval is the interval of the pings that keep idle connections open
+	jobsPingInterval = 30 * time.Second
+	// jobsPongWait is the time allowed without a pong or message before the connection is closed
+	jobsPongWait = 60 * time.Second
+	// jobsUpdateInterval is the shortest time between two filter updates of a connection
+	jobsUpdateInterval = time.Second
+)
+
+// Types of the messages sent by a carrier, a message without a type sets the filter
+const (
+	jobRequestFilter  = "filter"
+	jobRequestAccept  = "accept"
+	jobRequestDecline = "decline"
+)
+
+// Types of the messages sent to a carrier job connection
+const (
+	jobMessageSubscribed = "subscribed"
+	jobMessageJob        = "job"
+	jobMessageOffer      = "offer"
+	jobMessageAccepted   = "accepted"
+	jobMessageDeclined   = "declined"
+	jobMessageError      = "error"
+)
+
+type jobRequest struct {
+	Type    string `json:"type"`
+	OfferID int64  `json:"offer_id"`
+	model.JobFilter
+}
+
+type jobMessage struct {
+	Type    string           `json:"type"`
+	Filter  *model.JobFilter `json:"filter,omitempty"`
+	Job     *model.Job       `json:"job,omitempty"`
+	Offer   *model.Offer     `json:"offer,omitempty"`
+	OfferID int64            `json:"offer_id,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Code    int              `json:"code,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  jobsMaxMessageSize,
+	WriteBufferSize: 4096,
+	// connections are authorized by access token rather than cookies, so any origin is allowed
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// carrierJobs upgrades to a WebSocket that pushes newly created parcels to the carrier. The carrier sends
+// its location, radius and capacity as JSON messages and receives the parcels matching the latest one,
+// along with the offers of auto-dispatched parcels which it accepts or declines on the same connection.
+func (s *server) carrierJobs(w http.ResponseWriter, r *http.Request) {
+	carrierID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Carrier ID", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleCarrier, carrierID) {
+		return
+	}
+
+	sub, err := s.jobFeed.Subscribe(carrierID)
+	if err != nil {
+		if errors.Is(err, model.ErrLimitExceeded) {
+			ErrTooManyRequestsResponse(w, "Too many job connections", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[carrierJobs] failed to subscribe carrier '%d': %v", carrierID, err)
+		ErrInternalServerResponse(w, "failed to subscribe to jobs", err)
+		return
+	}
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already written the error response
+		return
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	replies := make(chan jobMessage, 1)
+	go s.readJobRequests(r.Context(), conn, carrierID, sub, replies, done)
+
+	ping := time.NewTicker(jobsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case job, ok := <-sub.Jobs():
+			if !ok {
+				writeClose(conn, websocket.CloseGoingAway, "server is shutting down")
+				return
+			}
+			if err := writeJobMessage(conn, jobMessage{Type: jobMessageJob, Job: &job}); err != nil {
+				return
+			}
+		case offer := <-sub.Offers():
+			if err := writeJobMessage(conn, jobMessage{Type: jobMessageOffer, Offer: &offer}); err != nil {
+				return
+			}
+		case reply, ok := <-replies:
+			if !ok {
+				return
+			}
+			if err := writeJobMessage(conn, reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(jobsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readJobRequests applies the filters and answers to offers sent by the carrier and replies to each one,
+// it returns when the connection is closed, a message is too large or the carrier stops answering pings
+func (s *server) readJobRequests(ctx context.Context, conn *websocket.Conn, carrierID int, sub service.JobSubscription, replies chan<- jobMessage, done <-chan struct{}) {
+	defer close(replies)
+
+	conn.SetReadLimit(jobsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(jobsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(jobsPongWait))
+	})
+
+	var lastUpdate time.Time
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(jobsPongWait))
+
+		var reply jobMessage
+		var request jobRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			reply = jobMessage{Type: jobMessageError, Error: err.Error()}
+		} else {
+			switch request.Type {
+			case "", jobRequestFilter:
+				reply = updateJobFilter(sub, request.JobFilter, &lastUpdate)
+			case jobRequestAccept, jobRequestDecline:
+				reply = s.respondToOffer(ctx, carrierID, request)
+			default:
+				reply = jobMessage{Type: jobMessageError, Error: "unknown message type " + strconv.Quote(request.Type)}
+			}
+		}
+
+		select {
+		case replies <- reply:
+		case <-done:
+			return
+		}
+	}
+}
+
+func updateJobFilter(sub service.JobSubscription, filter model.JobFilter, lastUpdate *time.Time) jobMessage {
+	if time.Since(*lastUpdate) < jobsUpdateInterval {
+		return jobMessage{Type: jobMessageError, Error: "filter can be updated once every " + jobsUpdateInterval.String()}
+	}
+	if err := filter.ValidateJobFilter(); err != nil {
+		return jobMessage{Type: jobMessageError, Error: err.Error()}
+	}
+
+	sub.Update(filter)
+	*lastUpdate = time.Now()
+	return jobMessage{Type: jobMessageSubscribed, Filter: &filter}
+}
+
+func (s *server) respondToOffer(ctx context.Context, carrierID int, request jobRequest) jobMessage {
+	if s.dispatcher == nil {
+		return jobMessage{Type: jobMessageError, OfferID: request.OfferID, Error: "auto dispatch is not enabled"}
+	}
+
+	accept := request.Type == jobRequestAccept
+	if err := s.dispatcher.Respond(ctx, carrierID, request.OfferID, accept); err != nil {
+		// an offer accepted after the parcel was assigned in the marketplace fails like a conflicting request
+		if errors.Is(err, model.ErrConflict) {
+			return jobMessage{Type: jobMessageError, OfferID: request.OfferID, Error: err.Error(), Code: http.StatusConflict}
+		}
+		if !errors.Is(err, model.ErrNotFound) {
+			log.Error().Err(err).Msgf("[respondToOffer] failed to answer offer %d of carrier %d: %v", request.OfferID, carrierID, err)
+		}
+		return jobMessage{Type: jobMessageError, OfferID: request.OfferID, Error: err.Error()}
+	}
+
+	if accept {
+		return jobMessage{Type: jobMessageAccepted, OfferID: request.OfferID}
+	}
+	return jobMessage{Type: jobMessageDeclined, OfferID: request.OfferID}
+}
+
+func writeJobMessage(conn *websocket.Conn, message jobMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(jobsWriteWait))
+	return conn.WriteJSON(message)
+}
+
+func writeClose(conn *websocket.Conn, code int, reason string) {
+	conn.SetWriteDeadline(time.Now().Add(jobsWriteWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/jobs"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func carrierToken(t *testing.T, signer interface {
+	Issue(model.Claims) (string, error)
+}, carrierID int) string {
+	token, err := signer.Issue(model.Claims{Role: model.RoleCarrier, ID: carrierID, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.Nil(t, err)
+	return token
+}
+
+func TestCarrierJobsRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	testCases := []struct {
+		desc          string
+		carrierId     string
+		token         string
+		mockFeed      func() *mocks.MockJobFeed
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:      "should return invalid carrier ID",
+			carrierId: "invalid",
+			mockFeed: func() *mocks.MockJobFeed {
+				return mocks.NewMockJobFeed(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Carrier ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return unauthorized without token",
+			carrierId: "7",
+			mockFeed: func() *mocks.MockJobFeed {
+				return mocks.NewMockJobFeed(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return unauthorized with invalid token",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, auth.NewSigner([]byte("other")), 7),
+			mockFeed: func() *mocks.MockJobFeed {
+				return mocks.NewMockJobFeed(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"invalid token signature :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return forbidden for another carrier",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 8),
+			mockFeed: func() *mocks.MockJobFeed {
+				return mocks.NewMockJobFeed(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for carrier 7 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return too many requests",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 7),
+			mockFeed: func() *mocks.MockJobFeed {
+				f := mocks.NewMockJobFeed(ctrl)
+				f.EXPECT().Subscribe(7).Return(nil, fmt.Errorf("carrier 7 already has 3 job connections :%w", model.ErrLimitExceeded))
+				return f
+			},
+			expStatusCode: http.StatusTooManyRequests,
+			expResponse:   `{"success":false,"errors":[{"code":"LIMIT_EXCEEDED","message":"carrier 7 already has 3 job connections :limit exceeded","message_title":"Too many job connections","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return internal server error",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 7),
+			mockFeed: func() *mocks.MockJobFeed {
+				f := mocks.NewMockJobFeed(ctrl)
+				f.EXPECT().Subscribe(7).Return(nil, errors.New("3 job connections :limit exceeded"))
+				return f
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"3 job connections :limit exceeded","message_title":"failed to subscribe to jobs","severity":"error"}],"data":null}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithJobFeed(tc.mockFeed()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/carriers/"+tc.carrierId+"/jobs", nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", tc.token)
+			}
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestCarrierJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parcel := model.Parcel{ID: 1, UserID: 1, Status: model.ParcelStatusCreated, SourceLatitude: 23.7806, SourceLongitude: 90.4193, Weight: 5}
+	repo := mocks.NewMockParcelRepository(ctrl)
+	repo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(parcel, nil)
+
+	signer := auth.NewSigner([]byte("secret"))
+	feed := jobs.NewFeed(repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feed.Run(ctx)
+
+	s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithJobFeed(feed))
+	ts := httptest.NewServer(s.route())
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/carriers/7/jobs?access_token=" + carrierToken(t, signer, 7)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var message jobMessage
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"latitude": 23.8103, "longitude": 90.4125, "radius": 80, "capacity": 20}`)))
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessage{Type: jobMessageError, Error: "radius must be between 0 and 50 km :invalid"}, message)
+
+	filter := model.JobFilter{Latitude: 23.8103, Longitude: 90.4125, Radius: 10, Capacity: 20}
+	assert.Nil(t, conn.WriteJSON(filter))
+	message = jobMessage{}
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessage{Type: jobMessageSubscribed, Filter: &filter}, message)
+
+	assert.Nil(t, conn.WriteJSON(filter))
+	message = jobMessage{}
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessage{Type: jobMessageError, Error: "filter can be updated once every 1s"}, message)
+
+	feed.Notify(ctx, model.Event{Type: model.EventParcelCreated, ParcelID: 1})
+	message = jobMessage{}
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessageJob, message.Type)
+	assert.Equal(t, parcel, message.Job.Parcel)
+
+	feed.Close()
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+}
+
+func TestCarrierJobsMessageTooLarge(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	feed := jobs.NewFeed(nil)
+	s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithJobFeed(feed))
+	ts := httptest.NewServer(s.route())
+	defer ts.Close()
+
+	header := http.Header{"Authorization": []string{"Bearer " + carrierToken(t, signer, 7)}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/carriers/7/jobs", header)
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat(" ", jobsMaxMessageSize+1))))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig))
+}
+
+func TestCarrierJobsOffers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dispatcher := mocks.NewMockDispatcher(ctrl)
+	gomock.InOrder(
+		dispatcher.EXPECT().Respond(gomock.Any(), 7, int64(1), true).Return(nil),
+		dispatcher.EXPECT().Respond(gomock.Any(), 7, int64(2), false).Return(nil),
+		dispatcher.EXPECT().Respond(gomock.Any(), 7, int64(3), true).Return(fmt.Errorf("offer 3 is not pending for carrier 7 :%w", model.ErrNotFound)),
+		dispatcher.EXPECT().Respond(gomock.Any(), 7, int64(4), true).Return(fmt.Errorf("parcel 4 is assigned and can not be assigned :%w", model.ErrConflict)),
+	)
+
+	signer := auth.NewSigner([]byte("secret"))
+	feed := jobs.NewFeed(nil)
+	s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithJobFeed(feed), WithDispatcher(dispatcher))
+	ts := httptest.NewServer(s.route())
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/carriers/7/jobs?access_token=" + carrierToken(t, signer, 7)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var message jobMessage
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "filter", "latitude": 23.8103, "longitude": 90.4125, "radius": 10, "capacity": 20}`)))
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessageSubscribed, message.Type)
+
+	offer := model.Offer{ID: 1, CarrierID: 7, Job: model.Job{Parcel: model.Parcel{ID: 1}, Distance: 3.37}, ExpiresAt: time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)}
+	assert.True(t, feed.Offer(offer))
+	message = jobMessage{}
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessage{Type: jobMessageOffer, Offer: &offer}, message)
+
+	testCases := []struct {
+		request string
+		reply   jobMessage
+	}{
+		{`{"type": "accept", "offer_id": 1}`, jobMessage{Type: jobMessageAccepted, OfferID: 1}},
+		{`{"type": "decline", "offer_id": 2}`, jobMessage{Type: jobMessageDeclined, OfferID: 2}},
+		{`{"type": "accept", "offer_id": 3}`, jobMessage{Type: jobMessageError, OfferID: 3, Error: "offer 3 is not pending for carrier 7 :not found"}},
+		{`{"type": "accept", "offer_id": 4}`, jobMessage{Type: jobMessageError, OfferID: 4, Error: "parcel 4 is assigned and can not be assigned :conflict", Code: http.StatusConflict}},
+		{`{"type": "cancel"}`, jobMessage{Type: jobMessageError, Error: `unknown message type "cancel"`}},
+	}
+	for _, tc := range testCases {
+		assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(tc.request)))
+		message = jobMessage{}
+		assert.Nil(t, conn.ReadJSON(&message))
+		assert.Equal(t, tc.reply, message)
+	}
+}
+
+func TestCarrierJobsOffersDisabled(t *testing.T) {
+	signer := auth.NewSigner([]byte("secret"))
+	s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithJobFeed(jobs.NewFeed(nil)))
+	ts := httptest.NewServer(s.route())
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/carriers/7/jobs?access_token=" + carrierToken(t, signer, 7)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var message jobMessage
+	assert.Nil(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "accept", "offer_id": 1}`)))
+	assert.Nil(t, conn.ReadJSON(&message))
+	assert.Equal(t, jobMessage{Type: jobMessageError, OfferID: 1, Error: "auto dispatch is not enabled"}, message)
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// getParcelLabel prints the shipping label of a parcel for its sender, its carrier and admins
+func (s *server) getParcelLabel(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "png" && format != "pdf" {
+		ErrInvalidEntityResponse(w, "Invalid format value", errors.New("format must be json, png or pdf"))
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	// the parcel is authorized before its label is read, reading the label gives the parcel its tracking code
+	parcel, err := s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getParcelLabel] failed to fetch parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch parcel "+strconv.Itoa(parcelID), err)
+		return
+	}
+
+	if !claims.Allows(model.RoleUser, parcel.UserID) && !claims.Allows(model.RoleCarrier, parcel.CarrierID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for parcel %d :%w", parcelID, model.ErrForbidden))
+		return
+	}
+
+	label, err := s.labelService.GetLabel(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getParcelLabel] failed to get label of parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch label of parcel "+strconv.Itoa(parcelID), err)
+		return
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	switch format {
+	case "png":
+		contentType = "image/png"
+		w.Header().Set("Content-Disposition", `inline; filename="`+label.TrackingCode+`.png"`)
+		err = s.labelService.RenderPNG(&buf, label)
+	case "pdf":
+		contentType = "application/pdf"
+		w.Header().Set("Content-Disposition", `inline; filename="`+label.TrackingCode+`.pdf"`)
+		err = s.labelService.RenderPDF(&buf, label)
+	default:
+		SuccessResponse(w, http.StatusOK, label)
+		return
+	}
+
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		log.Error().Err(err).Msgf("[getParcelLabel] failed to render label '%s': %v", label.TrackingCode, err)
+		ErrInternalServerResponse(w, "Failed to render label "+label.TrackingCode, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// scanLabel records a carrier or a hub scanning the tracking code of a label and advances the parcel
+func (s *server) scanLabel(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleCarrier && claims.Role != model.RoleHub {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only carriers and hubs can scan labels :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.Scan
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.Role = claims.Role
+	data.ScannerID = claims.ID
+
+	if err := data.ValidateScanInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	event, err := s.labelService.Scan(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This code does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "parcel can not be advanced", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[scanLabel] failed to scan code '%s': %v", data.Code, err)
+		ErrInternalServerResponse(w, "failed to scan code", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, event)
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func hubToken(t *testing.T, signer interface {
+	Issue(model.Claims) (string, error)
+}, hubID int) string {
+	token, err := signer.Issue(model.Claims{Role: model.RoleHub, ID: hubID, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.Nil(t, err)
+	return token
+}
+
+func TestGetParcelLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	label := model.Label{
+		ParcelID:           1,
+		TrackingCode:       "PS7K3M9Q2X4B",
+		UserID:             3,
+		CarrierID:          7,
+		Status:             model.ParcelStatusAssigned,
+		SourceAddress:      "Dhaka Bangladesh",
+		DestinationAddress: "Pabna Shadar",
+		ParcelType:         "Document",
+	}
+	parcel := model.Parcel{ID: 1, UserID: 3, CarrierID: 7, Status: model.ParcelStatusAssigned}
+
+	testCases := []struct {
+		desc           string
+		url            string
+		token          string
+		mockParcelSvc  func() *mocks.MockParcelService
+		mockSvc        func() *mocks.MockLabelService
+		expStatusCode  int
+		expContentType string
+		expResponse    string
+	}{
+		{
+			desc:  "should return label json to the sender",
+			url:   "/api/v1/parcel/1/label",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().GetLabel(gomock.Any(), 1).Return(label, nil)
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":true,"errors":null,"data":{"parcel_id":1,"tracking_code":"PS7K3M9Q2X4B","user_id":3,"carrier_id":7,"status":2,"source_address":"Dhaka Bangladesh","destination_address":"Pabna Shadar","type":"Document","created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			desc:  "should return label png to the carrier",
+			url:   "/api/v1/parcel/1/label?format=png",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().GetLabel(gomock.Any(), 1).Return(label, nil)
+				s.EXPECT().RenderPNG(gomock.Any(), label).DoAndReturn(func(w io.Writer, _ model.Label) error {
+					_, err := w.Write([]byte("\x89PNG"))
+					return err
+				})
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "image/png",
+			expResponse:    "\x89PNG",
+		},
+		{
+			desc:  "should return label pdf to admins",
+			url:   "/api/v1/parcel/1/label?format=pdf",
+			token: "Bearer " + adminToken(t, signer),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().GetLabel(gomock.Any(), 1).Return(label, nil)
+				s.EXPECT().RenderPDF(gomock.Any(), label).DoAndReturn(func(w io.Writer, _ model.Label) error {
+					_, err := w.Write([]byte("%PDF-1.3"))
+					return err
+				})
+				return s
+			},
+			expStatusCode:  http.StatusOK,
+			expContentType: "application/pdf",
+			expResponse:    "%PDF-1.3",
+		},
+		{
+			desc:  "should return forbidden for another carrier",
+			url:   "/api/v1/parcel/1/label",
+			token: "Bearer " + carrierToken(t, signer, 8),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				return mocks.NewMockLabelService(ctrl)
+			},
+			expStatusCode:  http.StatusForbidden,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid format",
+			url:   "/api/v1/parcel/1/label?format=svg",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				return mocks.NewMockParcelService(ctrl)
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				return mocks.NewMockLabelService(ctrl)
+			},
+			expStatusCode:  http.StatusBadRequest,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"INVALID","message":"format must be json, png or pdf","message_title":"Invalid format value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not found",
+			url:   "/api/v1/parcel/1/label",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{}, model.ErrNotFound)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				return mocks.NewMockLabelService(ctrl)
+			},
+			expStatusCode:  http.StatusNotFound,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return render error",
+			url:   "/api/v1/parcel/1/label?format=png",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockParcelSvc: func() *mocks.MockParcelService {
+				s := mocks.NewMockParcelService(ctrl)
+				s.EXPECT().GetParcelByID(gomock.Any(), 1).Return(parcel, nil)
+				return s
+			},
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().GetLabel(gomock.Any(), 1).Return(label, nil)
+				s.EXPECT().RenderPNG(gomock.Any(), label).Return(errors.New("render-error"))
+				return s
+			},
+			expStatusCode:  http.StatusInternalServerError,
+			expContentType: "application/json; charset=UTF-8",
+			expResponse:    `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"render-error","message_title":"Failed to render label PS7K3M9Q2X4B","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", tc.mockParcelSvc(), nil, WithAuthenticator(signer), WithLabelService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestScanLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	scannedAt := time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockLabelService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should record carrier scan",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{"code":" ps7k3m9q2x4b "}`,
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().Scan(gomock.Any(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleCarrier, ScannerID: 7}).Return(model.ScanEvent{
+					ID:             5,
+					ParcelID:       1,
+					Role:           model.RoleCarrier,
+					ScannerID:      7,
+					PreviousStatus: model.ParcelStatusAssigned,
+					Status:         model.ParcelStatusPickedUp,
+					ParcelStatus:   model.ParcelStatusPickedUp,
+					ScannedAt:      scannedAt,
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":5,"parcel_id":1,"role":"carrier","scanner_id":7,"previous_status":2,"status":3,"parcel_status":3,"scanned_at":"2021-03-02T10:00:00Z"}}`,
+		},
+		{
+			desc:    "should record hub scan",
+			token:   "Bearer " + hubToken(t, signer, 2),
+			payload: `{"code":"PS7K3M9Q2X4B"}`,
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().Scan(gomock.Any(), model.Scan{Code: "PS7K3M9Q2X4B", Role: model.RoleHub, ScannerID: 2}).Return(model.ScanEvent{
+					ID:             6,
+					ParcelID:       1,
+					LegID:          10,
+					HubID:          2,
+					Role:           model.RoleHub,
+					ScannerID:      2,
+					PreviousStatus: model.ParcelStatusPickedUp,
+					Status:         model.ParcelStatusDelivered,
+					ParcelStatus:   model.ParcelStatusPickedUp,
+					ScannedAt:      scannedAt,
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":6,"parcel_id":1,"leg_id":10,"hub_id":2,"role":"hub","scanner_id":2,"previous_status":3,"status":4,"parcel_status":3,"scanned_at":"2021-03-02T10:00:00Z"}}`,
+		},
+		{
+			desc:    "should return forbidden for users",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"code":"PS7K3M9Q2X4B"}`,
+			mockSvc: func() *mocks.MockLabelService {
+				return mocks.NewMockLabelService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only carriers and hubs can scan labels :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid code",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{"code":"INV-000001"}`,
+			mockSvc: func() *mocks.MockLabelService {
+				return mocks.NewMockLabelService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"code \"INV-000001\" is not a tracking code :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return not found",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{"code":"PS7K3M9Q2X4B"}`,
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().Scan(gomock.Any(), gomock.Any()).Return(model.ScanEvent{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This code does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return parcel can not be advanced",
+			token:   "Bearer " + hubToken(t, signer, 2),
+			payload: `{"code":"PS7K3M9Q2X4B"}`,
+			mockSvc: func() *mocks.MockLabelService {
+				s := mocks.NewMockLabelService(ctrl)
+				s.EXPECT().Scan(gomock.Any(), gomock.Any()).Return(model.ScanEvent{}, model.ErrInvalid)
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid","message_title":"parcel can not be advanced","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithLabelService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/scan", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
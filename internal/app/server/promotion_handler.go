@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"parcel-service/internal/app/model"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *server) newPromotion(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	var data model.Promotion
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+
+	if err := data.ValidatePromotionInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	promotion, err := s.promotionService.CreatePromotion(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "invalid promotion", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newPromotion] failed to create promotion: %v", err)
+		ErrInternalServerResponse(w, "failed to create promotion", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, promotion)
+}
+
+func (s *server) getPromotion(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+
+	promotion, err := s.promotionService.GetPromotionByCode(r.Context(), code)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This promo code does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getPromotion] failed to fetch promotion '%s': %v", code, err)
+		ErrInternalServerResponse(w, "Failed to fetch promotion "+code, err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, promotion)
+}
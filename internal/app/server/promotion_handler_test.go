@@ -0,0 +1,194 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	promotion := model.Promotion{
+		ID:            1,
+		Code:          "WELCOME10",
+		DiscountType:  model.DiscountTypePercentage,
+		DiscountValue: 10,
+		MaxUses:       100,
+		PerUserLimit:  1,
+		CreatedAt:     time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC),
+	}
+	payload := `{"code":"WELCOME10","discount_type":"percentage","discount_value":10,"max_uses":100,"per_user_limit":1}`
+
+	testCases := []struct {
+		desc             string
+		payload          string
+		token            string
+		mockPromotionSvc func() *mocks.MockPromotionService
+		expStatusCode    int
+		expResponse      string
+	}{
+		{
+			desc:    "should success",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(promotion, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"code":"WELCOME10","discount_type":"percentage","discount_value":10,"min_order":0,"max_uses":100,"per_user_limit":1,"used_count":0,"expires_at":null,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:    "should return decode error",
+			payload: `------------`,
+			token:   "Bearer " + adminToken(t, signer),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				return mocks.NewMockPromotionService(ctrl)
+			},
+			expStatusCode: http.StatusUnprocessableEntity,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid character '-' in numeric literal","message_title":"Decode Error","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid input",
+			payload: `{"code":"WELCOME10","discount_type":"free"}`,
+			token:   "Bearer " + adminToken(t, signer),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				return mocks.NewMockPromotionService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"discount type must be percentage or fixed :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return duplicate code error",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(model.Promotion{}, model.ErrInvalid)
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid","message_title":"invalid promotion","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return internal server error",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().CreatePromotion(gomock.Any(), gomock.Any()).Return(model.Promotion{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to create promotion","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return unauthorized without a token",
+			payload: payload,
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				return mocks.NewMockPromotionService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return forbidden for users",
+			payload: payload,
+			token:   "Bearer " + userToken(t, signer, 3),
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				return mocks.NewMockPromotionService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithPromotionService(tc.mockPromotionSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/promotions", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodPost).Path("/api/v1/promotions").HandlerFunc(s.newPromotion)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetPromotion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc             string
+		mockPromotionSvc func() *mocks.MockPromotionService
+		expStatusCode    int
+		expResponse      string
+	}{
+		{
+			desc: "should success",
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().GetPromotionByCode(gomock.Any(), "WELCOME10").Return(model.Promotion{ID: 1, Code: "WELCOME10", DiscountType: model.DiscountTypeFixed, DiscountValue: 20}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"code":"WELCOME10","discount_type":"fixed","discount_value":20,"min_order":0,"max_uses":0,"per_user_limit":0,"used_count":0,"expires_at":null,"created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			desc: "should return not found",
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().GetPromotionByCode(gomock.Any(), "WELCOME10").Return(model.Promotion{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This promo code does not exist.","severity":"error"}],"data":null}`,
+		},
+		{
+			desc: "should return internal server error",
+			mockPromotionSvc: func() *mocks.MockPromotionService {
+				s := mocks.NewMockPromotionService(ctrl)
+				s.EXPECT().GetPromotionByCode(gomock.Any(), "WELCOME10").Return(model.Promotion{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"Failed to fetch promotion WELCOME10","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithPromotionService(tc.mockPromotionSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/promotions/WELCOME10", nil)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodGet).Path("/api/v1/promotions/{code}").HandlerFunc(s.getPromotion)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
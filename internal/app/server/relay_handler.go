@@ -0,0 +1,282 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// newHub lets an admin add a hub parcels can be relayed through
+func (s *server) newHub(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	var data model.Hub
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+
+	if err := data.ValidateHubInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	hub, err := s.relayService.CreateHub(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "invalid hub", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newHub] failed to create hub: %v", err)
+		ErrInternalServerResponse(w, "failed to create hub", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, hub)
+}
+
+func (s *server) getHubs(w http.ResponseWriter, r *http.Request) {
+	hubs, err := s.relayService.GetHubs(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msgf("[getHubs] failed to fetch hubs: %v", err)
+		ErrInternalServerResponse(w, "Failed to fetch hubs", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, hubs)
+}
+
+// splitParcel relays a parcel of the user of the access token through hubs
+func (s *server) splitParcel(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the sender can split a parcel into legs :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.RelaySplit
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ParcelID = parcelID
+	data.UserID = claims.ID
+
+	if err := data.ValidateRelayInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	relay, err := s.relayService.SplitParcel(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "parcel can not be split", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[splitParcel] failed to split parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to split parcel", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, relay)
+}
+
+// getParcelLegs shows the legs and custody transfers of a relayed parcel to its sender, to admins and to carriers
+func (s *server) getParcelLegs(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	relay, err := s.relayService.GetRelay(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getParcelLegs] failed to fetch legs of parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch legs", err)
+		return
+	}
+
+	if claims.Role != model.RoleCarrier && !claims.Allows(model.RoleUser, relay.UserID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for parcel %d :%w", relay.ParcelID, model.ErrForbidden))
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, relay)
+}
+
+// requestLeg lets the carrier of the access token request a leg of a relayed parcel
+func (s *server) requestLeg(w http.ResponseWriter, r *http.Request) {
+	legID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Leg ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleCarrier {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only carriers can request a leg :%w", model.ErrForbidden))
+		return
+	}
+
+	request, err := s.relayService.RequestLeg(r.Context(), model.LegRequest{LegID: legID, CarrierID: claims.ID})
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "leg can not be requested", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[requestLeg] failed to request leg '%d': %v", legID, err)
+		ErrInternalServerResponse(w, "failed to request leg", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, request)
+}
+
+// acceptLeg assigns the requesting carrier to a leg of a parcel of the user of the access token
+func (s *server) acceptLeg(w http.ResponseWriter, r *http.Request) {
+	legID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Leg ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the sender can accept a carrier for a leg :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.LegRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.LegID = legID
+
+	if err := data.ValidateCarrierId(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	change, err := s.relayService.AcceptLeg(r.Context(), claims.ID, data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "carrier request can not be accepted", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[acceptLeg] failed to assign carrier to leg '%d': %v", legID, err)
+		ErrInternalServerResponse(w, "failed to assign carrier to leg", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, change)
+}
+
+// updateLeg lets the carrier of a leg pick the parcel up or drop it off
+func (s *server) updateLeg(w http.ResponseWriter, r *http.Request) {
+	legID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Leg ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleCarrier {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the carrier of a leg can update it :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.LegUpdate
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.LegID = legID
+	data.CarrierID = claims.ID
+
+	if err := data.ValidateLegStatus(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	change, err := s.relayService.UpdateLeg(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "leg can not be updated", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[updateLeg] failed to update leg '%d': %v", legID, err)
+		ErrInternalServerResponse(w, "failed to update leg", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, change)
+}
@@ -0,0 +1,379 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHub(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockRelayService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + adminToken(t, signer),
+			payload: `{"name":"Bogura Hub","address":"Bogura Sadar","latitude":24.85,"longitude":89.37}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().CreateHub(gomock.Any(), model.Hub{Name: "Bogura Hub", Address: "Bogura Sadar", Latitude: 24.85, Longitude: 89.37}).
+					Return(model.Hub{ID: 2, Name: "Bogura Hub", Address: "Bogura Sadar", Latitude: 24.85, Longitude: 89.37}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":2,"name":"Bogura Hub","address":"Bogura Sadar","latitude":24.85,"longitude":89.37,"created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			desc:    "should return forbidden for users",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return missing address",
+			token:   "Bearer " + adminToken(t, signer),
+			payload: `{"name":"Bogura Hub"}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"address is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRelayService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/hubs", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestSplitParcel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockRelayService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"hub_ids":[2]}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().SplitParcel(gomock.Any(), model.RelaySplit{ParcelID: 1, UserID: 3, HubIDs: []int{2}}).Return(model.Relay{
+					ParcelID: 1,
+					UserID:   3,
+					Status:   model.ParcelStatusCreated,
+					Legs: []model.Leg{
+						{ID: 10, ParcelID: 1, Sequence: 1, ToHubID: 2, FromAddress: "Dhaka Bangladesh", ToAddress: "Bogura Sadar", Status: model.ParcelStatusCreated},
+						{ID: 11, ParcelID: 1, Sequence: 2, FromHubID: 2, FromAddress: "Bogura Sadar", ToAddress: "Rangpur", Status: model.ParcelStatusCreated},
+					},
+					Transfers: []model.CustodyTransfer{},
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"parcel_id":1,"user_id":3,"carrier_id":0,"status":1,"legs":[{"id":10,"parcel_id":1,"sequence":1,"to_hub_id":2,"from_address":"Dhaka Bangladesh","to_address":"Bogura Sadar","carrier_id":0,"status":1,"created_at":"0001-01-01T00:00:00Z"},{"id":11,"parcel_id":1,"sequence":2,"from_hub_id":2,"from_address":"Bogura Sadar","to_address":"Rangpur","carrier_id":0,"status":1,"created_at":"0001-01-01T00:00:00Z"}],"transfers":[]}}`,
+		},
+		{
+			desc:    "should return forbidden for carriers",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{"hub_ids":[2]}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the sender can split a parcel into legs :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return repeated hub",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"hub_ids":[2,3,2]}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"hub 2 can only be visited once :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return parcel that can not be split",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"hub_ids":[2]}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().SplitParcel(gomock.Any(), gomock.Any()).Return(model.Relay{}, fmt.Errorf("parcel 1 is assigned :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 1 is assigned :invalid","message_title":"parcel can not be split","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRelayService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/parcel/1/legs", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetParcelLegs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	relay := model.Relay{ParcelID: 1, UserID: 3, Status: model.ParcelStatusCreated, Legs: []model.Leg{}, Transfers: []model.CustodyTransfer{}}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		mockSvc       func() *mocks.MockRelayService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should show legs to carriers",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().GetRelay(gomock.Any(), 1).Return(relay, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"parcel_id":1,"user_id":3,"carrier_id":0,"status":1,"legs":[],"transfers":[]}}`,
+		},
+		{
+			desc:  "should return forbidden for another user",
+			token: "Bearer " + userToken(t, signer, 4),
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().GetRelay(gomock.Any(), 1).Return(relay, nil)
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not found for a parcel without legs",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().GetRelay(gomock.Any(), 1).Return(model.Relay{}, fmt.Errorf("parcel 1 is not delivered in legs :%w", model.ErrNotFound))
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"parcel 1 is not delivered in legs :not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRelayService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/parcel/1/legs", nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestAcceptLeg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockRelayService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().AcceptLeg(gomock.Any(), 3, model.LegRequest{LegID: 10, CarrierID: 7}).Return(model.LegChange{
+					Leg:          model.Leg{ID: 10, ParcelID: 1, Sequence: 1, ToHubID: 2, FromAddress: "Dhaka Bangladesh", ToAddress: "Bogura Sadar", CarrierID: 7, Status: model.ParcelStatusAssigned},
+					ParcelStatus: model.ParcelStatusAssigned,
+					CarrierID:    7,
+					Changed:      true,
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"leg":{"id":10,"parcel_id":1,"sequence":1,"to_hub_id":2,"from_address":"Dhaka Bangladesh","to_address":"Bogura Sadar","carrier_id":7,"status":2,"created_at":"0001-01-01T00:00:00Z"},"parcel_status":2,"carrier_id":7}}`,
+		},
+		{
+			desc:    "should return missing carrier",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"Carrier ID is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return forbidden for another user",
+			token:   "Bearer " + userToken(t, signer, 4),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().AcceptLeg(gomock.Any(), 4, gomock.Any()).Return(model.LegChange{}, fmt.Errorf("user 4 did not send parcel 1 :%w", model.ErrForbidden))
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"user 4 did not send parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRelayService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/legs/10/accept", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUpdateLeg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	transferredAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockRelayService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + carrierToken(t, signer, 8),
+			payload: `{"status":3}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().UpdateLeg(gomock.Any(), model.LegUpdate{LegID: 11, CarrierID: 8, Status: model.ParcelStatusPickedUp}).Return(model.LegChange{
+					Leg:          model.Leg{ID: 11, ParcelID: 1, Sequence: 2, FromHubID: 2, FromAddress: "Bogura Sadar", ToAddress: "Rangpur", CarrierID: 8, Status: model.ParcelStatusPickedUp, PickedUpAt: &transferredAt},
+					ParcelStatus: model.ParcelStatusPickedUp,
+					CarrierID:    8,
+					Transfer:     &model.CustodyTransfer{ID: 4, ParcelID: 1, HubID: 2, HubName: "Bogura Hub", FromLegID: 10, ToLegID: 11, FromCarrierID: 7, ToCarrierID: 8, ArrivedAt: transferredAt, TransferredAt: transferredAt},
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"leg":{"id":11,"parcel_id":1,"sequence":2,"from_hub_id":2,"from_address":"Bogura Sadar","to_address":"Rangpur","carrier_id":8,"status":3,"picked_up_at":"2020-04-11T21:34:01Z","created_at":"0001-01-01T00:00:00Z"},"parcel_status":3,"carrier_id":8,"transfer":{"id":4,"parcel_id":1,"hub_id":2,"hub_name":"Bogura Hub","from_leg_id":10,"to_leg_id":11,"from_carrier_id":7,"to_carrier_id":8,"arrived_at":"2020-04-11T21:34:01Z","transferred_at":"2020-04-11T21:34:01Z"}}}`,
+		},
+		{
+			desc:    "should return forbidden for users",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"status":3}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the carrier of a leg can update it :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid status",
+			token:   "Bearer " + carrierToken(t, signer, 8),
+			payload: `{"status":5}`,
+			mockSvc: func() *mocks.MockRelayService {
+				return mocks.NewMockRelayService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"status of a leg must be 3 (picked up) or 4 (delivered) :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return leg that has not reached the hub",
+			token:   "Bearer " + carrierToken(t, signer, 8),
+			payload: `{"status":3}`,
+			mockSvc: func() *mocks.MockRelayService {
+				s := mocks.NewMockRelayService(ctrl)
+				s.EXPECT().UpdateLeg(gomock.Any(), gomock.Any()).Return(model.LegChange{}, fmt.Errorf("parcel 1 has not arrived at the hub of leg 11 yet :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 1 has not arrived at the hub of leg 11 yet :invalid","message_title":"leg can not be updated","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRelayService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/legs/11", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// reportFilter reads the period, date range and limit of a report request, it answers the request itself when they are invalid
+func (s *server) reportFilter(w http.ResponseWriter, r *http.Request) (model.ReportFilter, bool) {
+	query := r.URL.Query()
+	filter := model.ReportFilter{Period: query.Get("period")}
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if value := query.Get(param.name); value != "" {
+			t, err := model.ParseExportTime(value)
+			if err != nil {
+				ErrInvalidEntityResponse(w, "Invalid "+param.name+" value", err)
+				return filter, false
+			}
+			*param.dst = t
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			ErrInvalidEntityResponse(w, "Invalid limit value", err)
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+
+	if err := filter.ValidateReportFilter(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return filter, false
+	}
+	return filter, true
+}
+
+func (s *server) getVolumeReport(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	filter, ok := s.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	volumes, err := s.reportService.GetVolumes(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getVolumeReport] failed to fetch parcel volumes by %s: %v", filter.Period, err)
+		ErrInternalServerResponse(w, "Failed to fetch parcel volumes", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, volumes)
+}
+
+func (s *server) getRevenueReport(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	filter, ok := s.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	revenue, err := s.reportService.GetRevenue(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getRevenueReport] failed to fetch revenue by %s: %v", filter.Period, err)
+		ErrInternalServerResponse(w, "Failed to fetch revenue", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, revenue)
+}
+
+func (s *server) getDeliveryTimeReport(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	filter, ok := s.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	times, err := s.reportService.GetDeliveryTimes(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getDeliveryTimeReport] failed to fetch delivery times by %s: %v", filter.Period, err)
+		ErrInternalServerResponse(w, "Failed to fetch delivery times", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, times)
+}
+
+func (s *server) getCarrierReport(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	filter, ok := s.reportFilter(w, r)
+	if !ok {
+		return
+	}
+
+	carriers, err := s.reportService.GetTopCarriers(r.Context(), filter)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getCarrierReport] failed to fetch top %d carriers: %v", filter.Limit, err)
+		ErrInternalServerResponse(w, "Failed to fetch top carriers", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, carriers)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	from := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		token         string
+		url           string
+		mockSvc       func() *mocks.MockReportService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should return weekly volumes",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/volumes?period=week&from=2021-03-01",
+			mockSvc: func() *mocks.MockReportService {
+				s := mocks.NewMockReportService(ctrl)
+				s.EXPECT().GetVolumes(gomock.Any(), model.ReportFilter{Period: model.ReportPeriodWeek, From: &from, Limit: model.DefaultTopCarriers}).
+					Return([]model.VolumeReport{{Period: from, Status: model.ParcelStatusDelivered, Type: "Document", Parcels: 3}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"period":"2021-03-01T00:00:00Z","status":4,"type":"Document","parcels":3}]}`,
+		},
+		{
+			desc:  "should return daily revenue",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/revenue",
+			mockSvc: func() *mocks.MockReportService {
+				s := mocks.NewMockReportService(ctrl)
+				s.EXPECT().GetRevenue(gomock.Any(), model.ReportFilter{Period: model.ReportPeriodDay, Limit: model.DefaultTopCarriers}).
+					Return([]model.RevenueReport{{Period: from, Parcels: 2, Price: 400, CarrierFee: 360, CompanyFee: 40}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"period":"2021-03-01T00:00:00Z","parcels":2,"price":400,"carrier_fee":360,"company_fee":40}]}`,
+		},
+		{
+			desc:  "should return delivery times",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/delivery-times",
+			mockSvc: func() *mocks.MockReportService {
+				s := mocks.NewMockReportService(ctrl)
+				s.EXPECT().GetDeliveryTimes(gomock.Any(), gomock.Any()).
+					Return([]model.DeliveryTimeReport{{Period: from, Parcels: 5, Assigned: 4, AvgAssignmentSeconds: 900.5, Delivered: 3, AvgDeliverySeconds: 86400}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"period":"2021-03-01T00:00:00Z","parcels":5,"assigned":4,"avg_assignment_seconds":900.5,"delivered":3,"avg_delivery_seconds":86400}]}`,
+		},
+		{
+			desc:  "should return top carriers",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/carriers?limit=5",
+			mockSvc: func() *mocks.MockReportService {
+				s := mocks.NewMockReportService(ctrl)
+				s.EXPECT().GetTopCarriers(gomock.Any(), model.ReportFilter{Period: model.ReportPeriodDay, Limit: 5}).
+					Return([]model.CarrierReport{{CarrierID: 7, Requests: 12, Accepted: 9, Delivered: 8, Earnings: 1440, AvgDeliverySeconds: 7200}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"carrier_id":7,"requests":12,"accepted":9,"delivered":8,"earnings":1440,"avg_delivery_seconds":7200}]}`,
+		},
+		{
+			desc:  "should return forbidden for carriers",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			url:   "/api/v1/admin/reports/carriers",
+			mockSvc: func() *mocks.MockReportService {
+				return mocks.NewMockReportService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid period",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/volumes?period=month",
+			mockSvc: func() *mocks.MockReportService {
+				return mocks.NewMockReportService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"period must be day or week :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid to value",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/revenue?to=yesterday",
+			mockSvc: func() *mocks.MockReportService {
+				return mocks.NewMockReportService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"\"yesterday\" is not a date or an RFC 3339 time :invalid","message_title":"Invalid to value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return limit out of range",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/carriers?limit=500",
+			mockSvc: func() *mocks.MockReportService {
+				return mocks.NewMockReportService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"limit must be between 1 and 100 :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return server error",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/admin/reports/volumes",
+			mockSvc: func() *mocks.MockReportService {
+				s := mocks.NewMockReportService(ctrl)
+				s.EXPECT().GetVolumes(gomock.Any(), gomock.Any()).Return(nil, errors.New("db-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"db-error","message_title":"Failed to fetch parcel volumes","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithReportService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
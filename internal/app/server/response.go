@@ -11,6 +11,10 @@ const (
 	codeInvalidErr        = "INVALID"
 	codeNotFoundErr       = "NOT FOUND"
 	codeInternalServerErr = "SERVER_ERROR"
+	codeUnauthorizedErr   = "UNAUTHORIZED"
+	codeForbiddenErr      = "FORBIDDEN"
+	codeLimitExceededErr  = "LIMIT_EXCEEDED"
+	codeConflictErr       = "CONFLICT"
 )
 
 var renderer = render.New(render.Options{})
@@ -39,6 +43,22 @@ func ErrInternalServerResponse(w http.ResponseWriter, title string, err error) {
 	errorResponse(w, http.StatusInternalServerError, codeInternalServerErr, title, err)
 }
 
+func ErrUnauthorizedResponse(w http.ResponseWriter, title string, err error) {
+	errorResponse(w, http.StatusUnauthorized, codeUnauthorizedErr, title, err)
+}
+
+func ErrForbiddenResponse(w http.ResponseWriter, title string, err error) {
+	errorResponse(w, http.StatusForbidden, codeForbiddenErr, title, err)
+}
+
+func ErrTooManyRequestsResponse(w http.ResponseWriter, title string, err error) {
+	errorResponse(w, http.StatusTooManyRequests, codeLimitExceededErr, title, err)
+}
+
+func ErrConflictResponse(w http.ResponseWriter, title string, err error) {
+	errorResponse(w, http.StatusConflict, codeConflictErr, title, err)
+}
+
 func errorResponse(w http.ResponseWriter, httpStatusCode int, code string, title string, err error) {
 	renderer.JSON(w, httpStatusCode, model.GenericResponse{
 		Success: false,
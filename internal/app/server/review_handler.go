@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// newReview rates the other side of a delivered parcel, the side and the author are taken from the access token
+func (s *server) newReview(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	var data model.Review
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ParcelID = parcelID
+	data.Role = claims.Role
+	data.AuthorID = claims.ID
+
+	if err := data.ValidateReviewInput(); err != nil {
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	review, err := s.reviewService.CreateReview(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "parcel can not be rated", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newReview] failed to create review of parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "failed to create review", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, review)
+}
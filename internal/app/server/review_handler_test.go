@@ -0,0 +1,136 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	createdAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		parcelId      string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockReviewService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:     "should success",
+			parcelId: "1",
+			token:    "Bearer " + userToken(t, signer, 3),
+			payload:  `{"rating":5,"comment":"On time"}`,
+			mockSvc: func() *mocks.MockReviewService {
+				s := mocks.NewMockReviewService(ctrl)
+				s.EXPECT().CreateReview(gomock.Any(), model.Review{ParcelID: 1, Role: model.RoleUser, AuthorID: 3, Rating: 5, Comment: "On time"}).
+					Return(model.Review{ID: 1, ParcelID: 1, Role: model.RoleUser, AuthorID: 3, SubjectID: 7, Rating: 5, Comment: "On time", CreatedAt: createdAt}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"parcel_id":1,"role":"user","author_id":3,"subject_id":7,"rating":5,"comment":"On time","created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:     "should return unauthorized without token",
+			parcelId: "1",
+			payload:  `{"rating":5}`,
+			mockSvc: func() *mocks.MockReviewService {
+				return mocks.NewMockReviewService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for admins",
+			parcelId: "1",
+			token:    "Bearer " + adminToken(t, signer),
+			payload:  `{"rating":5}`,
+			mockSvc: func() *mocks.MockReviewService {
+				return mocks.NewMockReviewService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the sender and the carrier can rate a parcel :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return invalid rating",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 7),
+			payload:  `{"rating":6}`,
+			mockSvc: func() *mocks.MockReviewService {
+				return mocks.NewMockReviewService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"rating must be between 1 and 5 :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return forbidden for another carrier",
+			parcelId: "1",
+			token:    "Bearer " + carrierToken(t, signer, 8),
+			payload:  `{"rating":4}`,
+			mockSvc: func() *mocks.MockReviewService {
+				s := mocks.NewMockReviewService(ctrl)
+				s.EXPECT().CreateReview(gomock.Any(), gomock.Any()).Return(model.Review{}, fmt.Errorf("carrier 8 did not deliver parcel 1 :%w", model.ErrForbidden))
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"carrier 8 did not deliver parcel 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return invalid when already rated",
+			parcelId: "1",
+			token:    "Bearer " + userToken(t, signer, 3),
+			payload:  `{"rating":4}`,
+			mockSvc: func() *mocks.MockReviewService {
+				s := mocks.NewMockReviewService(ctrl)
+				s.EXPECT().CreateReview(gomock.Any(), gomock.Any()).Return(model.Review{}, fmt.Errorf("parcel 1 has already been rated by the user :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 1 has already been rated by the user :invalid","message_title":"parcel can not be rated","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:     "should return not found",
+			parcelId: "2",
+			token:    "Bearer " + userToken(t, signer, 3),
+			payload:  `{"rating":4}`,
+			mockSvc: func() *mocks.MockReviewService {
+				s := mocks.NewMockReviewService(ctrl)
+				s.EXPECT().CreateReview(gomock.Any(), gomock.Any()).Return(model.Review{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithReviewService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/parcel/%s/reviews", tc.parcelId), strings.NewReader(tc.payload))
+			if tc.token != "" {
+				r.Header.Set("Authorization", tc.token)
+			}
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
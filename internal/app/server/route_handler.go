@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// carrierRoute returns the planned order of the pickups and drops of the carrier
+func (s *server) carrierRoute(w http.ResponseWriter, r *http.Request) {
+	carrierID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Carrier ID", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleCarrier, carrierID) {
+		return
+	}
+
+	route, err := s.routeService.PlanRoute(r.Context(), carrierID)
+	if err != nil {
+		log.Error().Err(err).Msgf("[carrierRoute] failed to plan route of carrier '%d': %v", carrierID, err)
+		ErrInternalServerResponse(w, "failed to plan route", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, route)
+}
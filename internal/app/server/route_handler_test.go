@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarrierRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	arrival := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		carrierId     string
+		token         string
+		mockSvc       func() *mocks.MockRouteService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:      "should success",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockRouteService {
+				s := mocks.NewMockRouteService(ctrl)
+				s.EXPECT().PlanRoute(gomock.Any(), 7).Return(model.Route{
+					CarrierID: 7,
+					Stops:     []model.RouteStop{{ParcelID: 1, Kind: model.RouteStopDrop, Address: "Pabna Shadar", Latitude: 24, Longitude: 89.2, Distance: 1.5, Arrival: arrival}},
+					Distance:  1.5,
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"carrier_id":7,"stops":[{"parcel_id":1,"kind":"drop","address":"Pabna Shadar","latitude":24,"longitude":89.2,"distance":1.5,"arrival":"2020-04-11T21:34:01Z"}],"distance":1.5}}`,
+		},
+		{
+			desc:      "should return forbidden for another carrier",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 8),
+			mockSvc: func() *mocks.MockRouteService {
+				return mocks.NewMockRouteService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for carrier 7 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return invalid carrier ID",
+			carrierId: "invalid",
+			token:     "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockRouteService {
+				return mocks.NewMockRouteService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Carrier ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:      "should return server error",
+			carrierId: "7",
+			token:     "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockRouteService {
+				s := mocks.NewMockRouteService(ctrl)
+				s.EXPECT().PlanRoute(gomock.Any(), 7).Return(model.Route{}, errors.New("db-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"db-error","message_title":"failed to plan route","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithRouteService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/carriers/"+tc.carrierId+"/route", nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -10,35 +10,252 @@ import (
 )
 
 type server struct {
-	listenAddress        string
-	http                 *http.Server
-	parcelService        service.ParcelService
-	carrierService       service.CarrierService
+	listenAddress    string
+	http             *http.Server
+	parcelService    service.ParcelService
+	carrierService   service.CarrierService
+	promotionService service.PromotionService
+	invoiceService   service.InvoiceService
+	taxService       service.TaxService
+	webhookService   service.WebhookService
+	broadcaster      service.Broadcaster
+	authenticator    service.Authenticator
+	jobFeed          service.JobFeed
+	dispatcher       service.Dispatcher
+	slaService       service.SLAService
+	reviewService    service.ReviewService
+	claimService     service.ClaimService
+	shipmentService  service.ShipmentService
+	routeService     service.RouteService
+	relayService     service.RelayService
+	labelService     service.LabelService
+	exportService    service.ExportService
+	reportService    service.ReportService
+	consoleService   service.ConsoleService
+	auditService     service.AuditService
 }
 
-func NewServer(port string, parcelSvc service.ParcelService, carrierSvc service.CarrierService) *server {
+// Option sets the optional services of the server
+type Option func(*server)
+
+// WithPromotionService enables the promotion endpoints
+func WithPromotionService(promotionSvc service.PromotionService) Option {
+	return func(s *server) {
+		s.promotionService = promotionSvc
+	}
+}
+
+// WithInvoiceService enables the parcel invoice endpoint
+func WithInvoiceService(invoiceSvc service.InvoiceService) Option {
+	return func(s *server) {
+		s.invoiceService = invoiceSvc
+	}
+}
+
+// WithTaxService enables the tax rule endpoints
+func WithTaxService(taxSvc service.TaxService) Option {
+	return func(s *server) {
+		s.taxService = taxSvc
+	}
+}
+
+// WithWebhookService enables the webhook subscription endpoints
+func WithWebhookService(webhookSvc service.WebhookService) Option {
+	return func(s *server) {
+		s.webhookService = webhookSvc
+	}
+}
+
+// WithBroadcaster enables the server-sent event streams, the streams are closed when the server shuts down
+func WithBroadcaster(broadcaster service.Broadcaster) Option {
+	return func(s *server) {
+		s.broadcaster = broadcaster
+	}
+}
+
+// WithAuthenticator verifies the access tokens of the endpoints that require one
+func WithAuthenticator(authenticator service.Authenticator) Option {
+	return func(s *server) {
+		s.authenticator = authenticator
+	}
+}
+
+// WithJobFeed enables the job connections of carriers, the connections are closed when the server shuts down
+func WithJobFeed(jobFeed service.JobFeed) Option {
+	return func(s *server) {
+		s.jobFeed = jobFeed
+	}
+}
+
+// WithDispatcher lets carriers answer the offers of auto-dispatched parcels on their job connections
+func WithDispatcher(dispatcher service.Dispatcher) Option {
+	return func(s *server) {
+		s.dispatcher = dispatcher
+	}
+}
+
+// WithSLAService enables the SLA breach endpoint of admins
+func WithSLAService(slaSvc service.SLAService) Option {
+	return func(s *server) {
+		s.slaService = slaSvc
+	}
+}
+
+// WithReviewService enables the reviews of delivered parcels
+func WithReviewService(reviewSvc service.ReviewService) Option {
+	return func(s *server) {
+		s.reviewService = reviewSvc
+	}
+}
+
+// WithClaimService enables the claims of senders and their decision by admins
+func WithClaimService(claimSvc service.ClaimService) Option {
+	return func(s *server) {
+		s.claimService = claimSvc
+	}
+}
+
+// WithShipmentService enables the shipments that group several parcels of a sender
+func WithShipmentService(shipmentSvc service.ShipmentService) Option {
+	return func(s *server) {
+		s.shipmentService = shipmentSvc
+	}
+}
+
+// WithRouteService enables the route planning of carriers
+func WithRouteService(routeSvc service.RouteService) Option {
+	return func(s *server) {
+		s.routeService = routeSvc
+	}
+}
+
+// WithRelayService enables the hubs and the legs of relayed parcels
+func WithRelayService(relaySvc service.RelayService) Option {
+	return func(s *server) {
+		s.relayService = relaySvc
+	}
+}
+
+// WithLabelService enables the shipping labels of parcels and the scans of their tracking codes
+func WithLabelService(labelSvc service.LabelService) Option {
+	return func(s *server) {
+		s.labelService = labelSvc
+	}
+}
+
+// WithExportService enables the streaming parcel export for admins
+func WithExportService(exportSvc service.ExportService) Option {
+	return func(s *server) {
+		s.exportService = exportSvc
+	}
+}
+
+// WithReportService enables the reporting endpoints of admins
+func WithReportService(reportSvc service.ReportService) Option {
+	return func(s *server) {
+		s.reportService = reportSvc
+	}
+}
+
+// WithConsoleService enables the admin web console under /admin
+func WithConsoleService(consoleSvc service.ConsoleService) Option {
+	return func(s *server) {
+		s.consoleService = consoleSvc
+	}
+}
+
+// WithAuditService enables the audit log endpoint of admins
+func WithAuditService(auditSvc service.AuditService) Option {
+	return func(s *server) {
+		s.auditService = auditSvc
+	}
+}
+
+func NewServer(port string, parcelSvc service.ParcelService, carrierSvc service.CarrierService, opts ...Option) *server {
 	s := &server{
-		listenAddress:        port,
-		parcelService:        parcelSvc,
-		carrierService:       carrierSvc,
+		listenAddress:  port,
+		parcelService:  parcelSvc,
+		carrierService: carrierSvc,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 	s.http = &http.Server{
 		Addr:    port,
 		Handler: s.route(),
 	}
+	if s.broadcaster != nil {
+		s.http.RegisterOnShutdown(s.broadcaster.Close)
+	}
+	if s.jobFeed != nil {
+		s.http.RegisterOnShutdown(s.jobFeed.Close)
+	}
 	return s
 }
 
 func (s *server) route() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(s.auditActor)
 	apiRoute := r.PathPrefix("/api/v1").Subrouter()
 	r.Methods(http.MethodGet).Path("/ping").HandlerFunc(s.pingHandler)
+	r.HandleFunc("/admin", s.consoleHome).Methods(http.MethodGet)
+	r.HandleFunc("/admin/login", s.consoleLoginPage).Methods(http.MethodGet)
+	r.HandleFunc("/admin/login", s.consoleLogin).Methods(http.MethodPost)
+	r.HandleFunc("/admin/logout", s.consoleLogout).Methods(http.MethodPost)
+	r.HandleFunc("/admin/parcels", s.consoleParcels).Methods(http.MethodGet)
+	r.HandleFunc("/admin/parcels/{id}", s.consoleParcel).Methods(http.MethodGet)
+	r.HandleFunc("/admin/parcels/{id}/status", s.consoleForceStatus).Methods(http.MethodPost)
+	r.HandleFunc("/admin/carriers", s.consoleCarriers).Methods(http.MethodGet)
+	r.HandleFunc("/admin/carriers/{id}/suspension", s.consoleSuspendCarrier).Methods(http.MethodPost)
 	apiRoute.HandleFunc("/parcel", s.getParcelList).Methods(http.MethodGet)
 	apiRoute.HandleFunc("/parcel/{id}/accept", s.parcelCarrierAccept).Methods(http.MethodPost)
 	apiRoute.HandleFunc("/parcel", s.newParcel).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/bulk", s.importParcels).Methods(http.MethodPost)
 	apiRoute.HandleFunc("/parcel/{id}/request", s.addCarrierRequest).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/{id}/requests", s.getCarrierRequests).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/parcel/{id}/reviews", s.newReview).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/{id}/claims", s.newClaim).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/claims/{id}", s.getClaim).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/parcel/{id}/cancel", s.cancelParcel).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/{id}/attempts", s.failDelivery).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/{id}/legs", s.splitParcel).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/parcel/{id}/legs", s.getParcelLegs).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/parcel/{id}/invoice", s.getParcelInvoice).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/parcel/{id}/label", s.getParcelLabel).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/parcel/{id}/events", s.parcelEvents).Methods(http.MethodGet)
 	apiRoute.HandleFunc("/parcel/{id}", s.getParcel).Methods(http.MethodGet)
 	apiRoute.HandleFunc("/parcel/{id}", s.editParcel).Methods(http.MethodPut)
+	apiRoute.HandleFunc("/shipments", s.newShipment).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/shipments/{id}", s.getShipment).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/shipments/{id}/request", s.requestShipment).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/shipments/{id}/accept", s.acceptShipment).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/legs/{id}", s.updateLeg).Methods(http.MethodPut)
+	apiRoute.HandleFunc("/legs/{id}/request", s.requestLeg).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/legs/{id}/accept", s.acceptLeg).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/scan", s.scanLabel).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/hubs", s.getHubs).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/carriers/{id}/location", s.updateCarrierLocation).Methods(http.MethodPut)
+	apiRoute.HandleFunc("/carriers/{id}/events", s.carrierEvents).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/carriers/{id}/jobs", s.carrierJobs).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/carriers/{id}/route", s.carrierRoute).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/promotions", s.newPromotion).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/promotions/{code}", s.getPromotion).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/tax-rules", s.newTaxRule).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/tax-rules", s.getTaxRules).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/webhooks", s.newWebhookSubscription).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/webhooks", s.getWebhookSubscriptions).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/webhooks/{id}/deliveries", s.getWebhookDeliveries).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/webhooks/deliveries/{id}/replay", s.replayWebhookDelivery).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/admin/sla-breaches", s.getSLABreaches).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/parcels/export", s.exportParcels).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/reports/volumes", s.getVolumeReport).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/reports/revenue", s.getRevenueReport).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/reports/delivery-times", s.getDeliveryTimeReport).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/reports/carriers", s.getCarrierReport).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/audit", s.getAuditEntries).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/hubs", s.newHub).Methods(http.MethodPost)
+	apiRoute.HandleFunc("/admin/claims", s.getClaims).Methods(http.MethodGet)
+	apiRoute.HandleFunc("/admin/claims/{id}", s.decideClaim).Methods(http.MethodPut)
 	return r
 }
 
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// newShipment books the parcels of a shipment for the user of the access token
+func (s *server) newShipment(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only senders can book a shipment :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.Shipment
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.UserID = claims.ID
+
+	if err := data.ValidateShipmentInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	shipment, err := s.shipmentService.CreateShipment(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "Invalid Input", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newShipment] failed to create shipment: %v", err)
+		ErrInternalServerResponse(w, "failed to create shipment", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, shipment)
+}
+
+// getShipment shows a shipment to its sender and admins, and to carriers while it waits for a carrier or once it is theirs
+func (s *server) getShipment(w http.ResponseWriter, r *http.Request) {
+	shipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Shipment ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	shipment, err := s.shipmentService.GetShipment(r.Context(), shipmentID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getShipment] failed to fetch shipment '%d': %v", shipmentID, err)
+		ErrInternalServerResponse(w, "Failed to fetch shipment", err)
+		return
+	}
+
+	open := claims.Role == model.RoleCarrier && (shipment.CarrierID == 0 || shipment.CarrierID == claims.ID)
+	if !open && !claims.Allows(model.RoleUser, shipment.UserID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for shipment %d :%w", shipment.ID, model.ErrForbidden))
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, shipment)
+}
+
+// requestShipment lets the carrier of the access token request every parcel of a shipment
+func (s *server) requestShipment(w http.ResponseWriter, r *http.Request) {
+	shipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Shipment ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleCarrier {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only carriers can request a shipment :%w", model.ErrForbidden))
+		return
+	}
+
+	request, err := s.shipmentService.RequestShipment(r.Context(), model.ShipmentRequest{ShipmentID: shipmentID, CarrierID: claims.ID})
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "shipment can not be requested", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[requestShipment] failed to request shipment '%d': %v", shipmentID, err)
+		ErrInternalServerResponse(w, "failed to request shipment", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, request)
+}
+
+// acceptShipment assigns the requesting carrier to every parcel of the shipment of the user of the access token
+func (s *server) acceptShipment(w http.ResponseWriter, r *http.Request) {
+	shipmentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Shipment ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+	if claims.Role != model.RoleUser {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only the sender can accept a carrier for a shipment :%w", model.ErrForbidden))
+		return
+	}
+
+	var data model.ShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.ShipmentID = shipmentID
+
+	if err := data.ValidateCarrierId(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	shipment, err := s.shipmentService.AcceptShipment(r.Context(), claims.ID, data)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "carrier request can not be accepted", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[acceptShipment] failed to assign carrier to shipment '%d': %v", shipmentID, err)
+		ErrInternalServerResponse(w, "failed to assign carrier to shipment", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, shipment)
+}
@@ -0,0 +1,304 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockShipmentService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"source_address":"Dhaka Bangladesh","parcels":[{"destination_address":"Pabna Shadar","type":"Document","stop":2},{"destination_address":"Rajshahi","type":"Food","stop":1}]}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().CreateShipment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, shipment model.Shipment) (model.Shipment, error) {
+					assert.True(t, shipment.Ordered)
+					assert.Equal(t, 3, shipment.Parcels[0].UserID)
+					assert.Equal(t, "Dhaka Bangladesh", shipment.Parcels[1].SourceAddress)
+					return model.Shipment{ID: 5, UserID: 3, SourceAddress: "Dhaka Bangladesh", Ordered: true, Parcels: []model.Parcel{}}, nil
+				})
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":5,"user_id":3,"carrier_id":0,"source_address":"Dhaka Bangladesh","source_time":"0001-01-01T00:00:00Z","ordered":true,"parcels":[],"created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			desc:    "should return forbidden for carriers",
+			token:   "Bearer " + carrierToken(t, signer, 7),
+			payload: `{}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only senders can book a shipment :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return missing parcels",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"source_address":"Dhaka Bangladesh","parcels":[]}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"a shipment needs at least one parcel :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid parcel",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"source_address":"Dhaka Bangladesh","parcels":[{"destination_address":"Pabna Shadar","type":"Document"},{"destination_address":"Rajshahi"}]}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"parcel 2: Parcel type is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid stops",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"source_address":"Dhaka Bangladesh","parcels":[{"destination_address":"Pabna Shadar","type":"Document","stop":1},{"destination_address":"Rajshahi","type":"Food","stop":3}]}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"stops must number the 2 parcels from 1 to 2 once each :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithShipmentService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/shipments", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	shipment := model.Shipment{ID: 5, UserID: 3, CarrierID: 7, SourceAddress: "Dhaka Bangladesh", Parcels: []model.Parcel{}}
+
+	testCases := []struct {
+		desc          string
+		token         string
+		expStatusCode int
+	}{
+		{desc: "should show the sender", token: userToken(t, signer, 3), expStatusCode: http.StatusOK},
+		{desc: "should show the assigned carrier", token: carrierToken(t, signer, 7), expStatusCode: http.StatusOK},
+		{desc: "should show admins", token: adminToken(t, signer), expStatusCode: http.StatusOK},
+		{desc: "should hide from another user", token: userToken(t, signer, 4), expStatusCode: http.StatusForbidden},
+		{desc: "should hide from another carrier once assigned", token: carrierToken(t, signer, 8), expStatusCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := mocks.NewMockShipmentService(ctrl)
+			svc.EXPECT().GetShipment(gomock.Any(), 5).Return(shipment, nil)
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithShipmentService(svc))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/shipments/5", nil)
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+		})
+	}
+}
+
+func TestRequestShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	expiresAt := time.Date(2020, time.April, 11, 23, 34, 01, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		token         string
+		mockSvc       func() *mocks.MockShipmentService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should success",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().RequestShipment(gomock.Any(), model.ShipmentRequest{ShipmentID: 5, CarrierID: 7}).
+					Return(model.ShipmentRequest{ShipmentID: 5, CarrierID: 7, ExpiresAt: expiresAt}, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"shipment_id":5,"carrier_id":7,"expires_at":"2020-04-11T23:34:01Z"}}`,
+		},
+		{
+			desc:  "should return forbidden for users",
+			token: "Bearer " + userToken(t, signer, 3),
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only carriers can request a shipment :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid when already requested",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().RequestShipment(gomock.Any(), gomock.Any()).
+					Return(model.ShipmentRequest{}, fmt.Errorf("carrier 7 has already requested shipment 5 or it has no parcels left to deliver :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"carrier 7 has already requested shipment 5 or it has no parcels left to deliver :invalid","message_title":"shipment can not be requested","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not found",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().RequestShipment(gomock.Any(), gomock.Any()).Return(model.ShipmentRequest{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithShipmentService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/5/request", nil)
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestAcceptShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc          string
+		token         string
+		payload       string
+		mockSvc       func() *mocks.MockShipmentService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().AcceptShipment(gomock.Any(), 3, model.ShipmentRequest{ShipmentID: 5, CarrierID: 7}).
+					Return(model.Shipment{ID: 5, UserID: 3, CarrierID: 7, SourceAddress: "Dhaka Bangladesh", Parcels: []model.Parcel{}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":5,"user_id":3,"carrier_id":7,"source_address":"Dhaka Bangladesh","source_time":"0001-01-01T00:00:00Z","ordered":false,"parcels":[],"created_at":"0001-01-01T00:00:00Z"}}`,
+		},
+		{
+			desc:    "should return forbidden for carriers",
+			token:   "Bearer " + carrierToken(t, signer, 3),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"only the sender can accept a carrier for a shipment :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return missing carrier",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				return mocks.NewMockShipmentService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"Carrier ID is required :empty","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid when a parcel can not be assigned",
+			token:   "Bearer " + userToken(t, signer, 3),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().AcceptShipment(gomock.Any(), 3, gomock.Any()).
+					Return(model.Shipment{}, fmt.Errorf("carrier 7 has no open request for every parcel of shipment 5 :%w", model.ErrInvalid))
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"carrier 7 has no open request for every parcel of shipment 5 :invalid","message_title":"carrier request can not be accepted","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return forbidden for another user",
+			token:   "Bearer " + userToken(t, signer, 4),
+			payload: `{"carrier_id":7}`,
+			mockSvc: func() *mocks.MockShipmentService {
+				s := mocks.NewMockShipmentService(ctrl)
+				s.EXPECT().AcceptShipment(gomock.Any(), 4, gomock.Any()).
+					Return(model.Shipment{}, fmt.Errorf("user 4 did not book shipment 5 :%w", model.ErrForbidden))
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"user 4 did not book shipment 5 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithShipmentService(tc.mockSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/shipments/5/accept", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
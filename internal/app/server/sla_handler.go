@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultBreachLimit = 20
+
+func (s *server) getSLABreaches(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	if err := model.ValidateSLABreachKind(kind); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid kind value", err)
+		return
+	}
+
+	var err error
+	limit, offset := defaultBreachLimit, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid limit value", err)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid offset value", err)
+			return
+		}
+	}
+
+	breaches, err := s.slaService.GetBreaches(r.Context(), kind, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getSLABreaches] failed to fetch SLA breaches of kind '%s': %v", kind, err)
+		ErrInternalServerResponse(w, "Failed to fetch SLA breaches", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, breaches)
+}
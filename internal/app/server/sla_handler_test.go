@@ -0,0 +1,133 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func adminToken(t *testing.T, signer interface {
+	Issue(model.Claims) (string, error)
+}) string {
+	token, err := signer.Issue(model.Claims{Role: model.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	assert.Nil(t, err)
+	return token
+}
+
+func TestGetSLABreaches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	deadline := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	breach := model.SLABreach{ID: 1, ParcelID: 3, UserID: 1, CarrierID: 7, Status: model.ParcelStatusPickedUp, Kind: model.SLABreachDelivery, Deadline: deadline, DetectedAt: deadline.Add(time.Minute)}
+
+	testCases := []struct {
+		desc          string
+		url           string
+		token         string
+		mockSLASvc    func() *mocks.MockSLAService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:  "should success",
+			url:   "/api/v1/admin/sla-breaches?kind=delivery&limit=10&offset=10",
+			token: "Bearer " + adminToken(t, signer),
+			mockSLASvc: func() *mocks.MockSLAService {
+				s := mocks.NewMockSLAService(ctrl)
+				s.EXPECT().GetBreaches(gomock.Any(), model.SLABreachDelivery, 10, 10).Return([]model.SLABreach{breach}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"id":1,"parcel_id":3,"user_id":1,"carrier_id":7,"status":3,"kind":"delivery","deadline":"2020-04-11T21:34:01Z","detected_at":"2020-04-11T21:35:01Z"}]}`,
+		},
+		{
+			desc:  "should use default limit",
+			url:   "/api/v1/admin/sla-breaches",
+			token: "Bearer " + adminToken(t, signer),
+			mockSLASvc: func() *mocks.MockSLAService {
+				s := mocks.NewMockSLAService(ctrl)
+				s.EXPECT().GetBreaches(gomock.Any(), "", defaultBreachLimit, 0).Return([]model.SLABreach{}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[]}`,
+		},
+		{
+			desc: "should return unauthorized without token",
+			url:  "/api/v1/admin/sla-breaches",
+			mockSLASvc: func() *mocks.MockSLAService {
+				return mocks.NewMockSLAService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return forbidden for carriers",
+			url:   "/api/v1/admin/sla-breaches",
+			token: "Bearer " + carrierToken(t, signer, 7),
+			mockSLASvc: func() *mocks.MockSLAService {
+				return mocks.NewMockSLAService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid kind",
+			url:   "/api/v1/admin/sla-breaches?kind=lost",
+			token: "Bearer " + adminToken(t, signer),
+			mockSLASvc: func() *mocks.MockSLAService {
+				return mocks.NewMockSLAService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"kind must be pickup or delivery :invalid","message_title":"Invalid kind value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return invalid limit",
+			url:   "/api/v1/admin/sla-breaches?limit=all",
+			token: "Bearer " + adminToken(t, signer),
+			mockSLASvc: func() *mocks.MockSLAService {
+				return mocks.NewMockSLAService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"all\": invalid syntax","message_title":"Invalid limit value","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return internal server error",
+			url:   "/api/v1/admin/sla-breaches",
+			token: "Bearer " + adminToken(t, signer),
+			mockSLASvc: func() *mocks.MockSLAService {
+				s := mocks.NewMockSLAService(ctrl)
+				s.EXPECT().GetBreaches(gomock.Any(), "", defaultBreachLimit, 0).Return(nil, errors.New("db-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"db-error","message_title":"Failed to fetch SLA breaches","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithSLAService(tc.mockSLASvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", tc.token)
+			}
+
+			s.route().ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// sseHeartbeat is the interval of the comment lines that keep idle streams open through proxies
+var sseHeartbeat = 15 * time.Second
+
+// parcelEvents streams the events of a parcel to its sender, its carrier and admins
+func (s *server) parcelEvents(w http.ResponseWriter, r *http.Request) {
+	parcelID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Parcel ID", err)
+		return
+	}
+
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return
+	}
+
+	parcel, err := s.parcelService.GetParcelByID(r.Context(), parcelID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[parcelEvents] failed to fetch parcel '%d': %v", parcelID, err)
+		ErrInternalServerResponse(w, "Failed to fetch parcel "+strconv.Itoa(parcelID), err)
+		return
+	}
+
+	if !claims.Allows(model.RoleUser, parcel.UserID) && !claims.Allows(model.RoleCarrier, parcel.CarrierID) {
+		ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("token is not valid for parcel %d :%w", parcelID, model.ErrForbidden))
+		return
+	}
+
+	s.streamEvents(w, r, model.ParcelTopic(parcelID))
+}
+
+// carrierEvents streams the events of a carrier to the carrier and admins
+func (s *server) carrierEvents(w http.ResponseWriter, r *http.Request) {
+	carrierID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Carrier ID", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleCarrier, carrierID) {
+		return
+	}
+
+	s.streamEvents(w, r, model.CarrierTopic(carrierID))
+}
+
+// streamEvents writes the events of the topic as server-sent events until the client goes away or the
+// server shuts down. A Last-Event-ID header resumes the stream after the last event the client received.
+func (s *server) streamEvents(w http.ResponseWriter, r *http.Request, topic string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		ErrInternalServerResponse(w, "Streaming is not supported", fmt.Errorf("response writer can not flush :%w", model.IntServerErr))
+		return
+	}
+
+	var lastEventID int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			ErrInvalidEntityResponse(w, "Invalid Last-Event-ID", err)
+			return
+		}
+		lastEventID = id
+	}
+
+	events, unsubscribe := s.broadcaster.Subscribe(topic, lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Event)
+			if err != nil {
+				log.Error().Err(err).Msgf("[streamEvents] failed to encode event %d: %v", event.ID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/broadcast"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// readEvent reads the lines of one server-sent event
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		assert.Nil(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestParcelEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+	occurredAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	parcelSvc := mocks.NewMockParcelService(ctrl)
+	parcelSvc.EXPECT().GetParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3, CarrierID: 7}, nil).Times(3)
+	parcelSvc.EXPECT().GetParcelByID(gomock.Any(), 2).Return(model.Parcel{}, model.ErrNotFound)
+
+	b := broadcast.NewBroadcaster()
+	s := NewServer(":8080", parcelSvc, nil, WithAuthenticator(signer), WithBroadcaster(b))
+	ts := httptest.NewServer(s.route())
+	defer ts.Close()
+
+	t.Run("should stream parcel events", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/parcel/1/events", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken(t, signer, 3))
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 2, Status: 3, OccurredAt: occurredAt})
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: 3, OccurredAt: occurredAt})
+
+		assert.Equal(t, []string{
+			"id: 2",
+			"event: parcel.status_changed",
+			`data: {"type":"parcel.status_changed","parcel_id":1,"status":3,"occurred_at":"2020-04-11T21:34:01Z"}`,
+		}, readEvent(t, bufio.NewReader(resp.Body)))
+	})
+
+	t.Run("should resume after Last-Event-ID", func(t *testing.T) {
+		b.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: 4, OccurredAt: occurredAt})
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/parcel/1/events?access_token="+carrierToken(t, signer, 7), nil)
+		req.Header.Set("Last-Event-ID", "2")
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, []string{
+			"id: 3",
+			"event: parcel.status_changed",
+			`data: {"type":"parcel.status_changed","parcel_id":1,"status":4,"occurred_at":"2020-04-11T21:34:01Z"}`,
+		}, readEvent(t, bufio.NewReader(resp.Body)))
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/parcel/2/events?access_token=" + adminToken(t, signer))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should return unauthorized without a token", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/parcel/1/events")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should return forbidden for another user", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/parcel/1/events?access_token=" + userToken(t, signer, 4))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
+func TestCarrierEventsHeartbeatAndShutdown(t *testing.T) {
+	heartbeat := sseHeartbeat
+	sseHeartbeat = 10 * time.Millisecond
+	defer func() { sseHeartbeat = heartbeat }()
+
+	signer := auth.NewSigner([]byte("secret"))
+	s := NewServer("127.0.0.1:0", nil, nil, WithAuthenticator(signer), WithBroadcaster(broadcast.NewBroadcaster()))
+	ts := httptest.NewUnstartedServer(s.http.Handler)
+	ts.Config = s.http
+	ts.Start()
+	defer ts.Close()
+
+	forbidden, err := http.Get(ts.URL + "/api/v1/carriers/7/events?access_token=" + carrierToken(t, signer, 8))
+	assert.Nil(t, err)
+	forbidden.Body.Close()
+	assert.Equal(t, http.StatusForbidden, forbidden.StatusCode)
+
+	resp, err := http.Get(ts.URL + "/api/v1/carriers/7/events?access_token=" + carrierToken(t, signer, 7))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, r))
+
+	done := make(chan error)
+	go func() {
+		done <- s.Shutdown()
+	}()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown is blocked by the open stream")
+	}
+}
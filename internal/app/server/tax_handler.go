@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"parcel-service/internal/app/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *server) newTaxRule(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	var data model.TaxRule
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+
+	if err := data.ValidateTaxRuleInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	rule, err := s.taxService.CreateTaxRule(r.Context(), data)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalid) {
+			ErrInvalidEntityResponse(w, "invalid tax rule", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[newTaxRule] failed to create tax rule: %v", err)
+		ErrInternalServerResponse(w, "failed to create tax rule", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusCreated, rule)
+}
+
+func (s *server) getTaxRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.taxService.GetTaxRules(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msgf("[getTaxRules] failed to fetch tax rules: %v", err)
+		ErrInternalServerResponse(w, "Failed to fetch tax rules", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, rules)
+}
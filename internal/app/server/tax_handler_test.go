@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTaxRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := auth.NewSigner([]byte("secret"))
+
+	rule := model.TaxRule{ID: 1, Name: "VAT", Region: "Dhaka", Rate: 0.15, CreatedAt: time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)}
+	payload := `{"name":"VAT","region":"Dhaka","rate":0.15}`
+
+	testCases := []struct {
+		desc          string
+		payload       string
+		token         string
+		mockTaxSvc    func() *mocks.MockTaxService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc:    "should success",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CreateTaxRule(gomock.Any(), model.TaxRule{Name: "VAT", Region: "Dhaka", Rate: 0.15}).Return(rule, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"name":"VAT","region":"Dhaka","type":"","rate":0.15,"inclusive":false,"created_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:    "should return decode error",
+			payload: `------------`,
+			token:   "Bearer " + adminToken(t, signer),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				return mocks.NewMockTaxService(ctrl)
+			},
+			expStatusCode: http.StatusUnprocessableEntity,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid character '-' in numeric literal","message_title":"Decode Error","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid input",
+			payload: `{"name":"VAT","rate":15}`,
+			token:   "Bearer " + adminToken(t, signer),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				return mocks.NewMockTaxService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"tax rate must be a fraction between 0 and 1 :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return duplicate rule error",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CreateTaxRule(gomock.Any(), gomock.Any()).Return(model.TaxRule{}, model.ErrInvalid)
+				return s
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"invalid","message_title":"invalid tax rule","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return internal server error",
+			payload: payload,
+			token:   "Bearer " + adminToken(t, signer),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().CreateTaxRule(gomock.Any(), gomock.Any()).Return(model.TaxRule{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to create tax rule","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return unauthorized without a token",
+			payload: payload,
+			mockTaxSvc: func() *mocks.MockTaxService {
+				return mocks.NewMockTaxService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return forbidden for users",
+			payload: payload,
+			token:   "Bearer " + userToken(t, signer, 3),
+			mockTaxSvc: func() *mocks.MockTaxService {
+				return mocks.NewMockTaxService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"admin role is required :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithTaxService(tc.mockTaxSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/tax-rules", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodPost).Path("/api/v1/tax-rules").HandlerFunc(s.newTaxRule)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestGetTaxRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc          string
+		mockTaxSvc    func() *mocks.MockTaxService
+		expStatusCode int
+		expResponse   string
+	}{
+		{
+			desc: "should success",
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().GetTaxRules(gomock.Any()).Return([]model.TaxRule{{ID: 1, Name: "VAT", Rate: 0.15, Inclusive: true}}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":[{"id":1,"name":"VAT","region":"","type":"","rate":0.15,"inclusive":true,"created_at":"0001-01-01T00:00:00Z"}]}`,
+		},
+		{
+			desc: "should return internal server error",
+			mockTaxSvc: func() *mocks.MockTaxService {
+				s := mocks.NewMockTaxService(ctrl)
+				s.EXPECT().GetTaxRules(gomock.Any()).Return(nil, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"Failed to fetch tax rules","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithTaxService(tc.mockTaxSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/tax-rules", nil)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodGet).Path("/api/v1/tax-rules").HandlerFunc(s.getTaxRules)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
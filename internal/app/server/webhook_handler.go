@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultDeliveryLimit = 20
+
+func (s *server) newWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	var data model.WebhookSubscriptionSecret
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		ErrUnprocessableEntityResponse(w, "Decode Error", err)
+		return
+	}
+	data.WebhookSubscription.Secret = data.Secret
+
+	if err := data.ValidateWebhookSubscriptionInput(); err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Input", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleUser, data.UserID) {
+		return
+	}
+
+	subscription, err := s.webhookService.CreateSubscription(r.Context(), data.WebhookSubscription)
+	if err != nil {
+		log.Error().Err(err).Msgf("[newWebhookSubscription] failed to create webhook subscription: %v", err)
+		ErrInternalServerResponse(w, "failed to create webhook subscription", err)
+		return
+	}
+
+	// the secret is sent back only once, later reads of the subscription leave it out
+	SuccessResponse(w, http.StatusCreated, model.WebhookSubscriptionSecret{WebhookSubscription: subscription, Secret: subscription.Secret})
+}
+
+func (s *server) getWebhookSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid user_id value", err)
+		return
+	}
+
+	if !s.authorize(w, r, model.RoleUser, userID) {
+		return
+	}
+
+	subscriptions, err := s.webhookService.GetSubscriptions(r.Context(), userID)
+	if err != nil {
+		log.Error().Err(err).Msgf("[getWebhookSubscriptions] failed to fetch webhook subscriptions of user '%d': %v", userID, err)
+		ErrInternalServerResponse(w, "Failed to fetch webhook subscriptions", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, subscriptions)
+}
+
+func (s *server) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Subscription ID", err)
+		return
+	}
+
+	limit, offset := defaultDeliveryLimit, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid limit value", err)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			ErrInvalidEntityResponse(w, "Invalid offset value", err)
+			return
+		}
+	}
+
+	userID, ok := s.webhookOwner(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := s.webhookService.GetDeliveries(r.Context(), userID, subscriptionID, limit, offset)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[getWebhookDeliveries] failed to fetch deliveries of subscription '%d': %v", subscriptionID, err)
+		ErrInternalServerResponse(w, "Failed to fetch webhook deliveries", err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, deliveries)
+}
+
+func (s *server) replayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		ErrInvalidEntityResponse(w, "Invalid Delivery ID", err)
+		return
+	}
+
+	userID, ok := s.webhookOwner(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := s.webhookService.ReplayDelivery(r.Context(), userID, deliveryID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			ErrNotFoundResponse(w, "This ID does not exist.", err)
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			ErrForbiddenResponse(w, "Forbidden", err)
+			return
+		}
+		log.Error().Err(err).Msgf("[replayWebhookDelivery] failed to replay delivery '%d': %v", deliveryID, err)
+		ErrInternalServerResponse(w, "Failed to replay webhook delivery "+strconv.Itoa(deliveryID), err)
+		return
+	}
+
+	SuccessResponse(w, http.StatusOK, delivery)
+}
+
+// webhookOwner writes the error response and returns false unless the request is made by a user or an admin,
+// the returned user ID is 0 for an admin who may read the subscriptions of every user
+func (s *server) webhookOwner(w http.ResponseWriter, r *http.Request) (int, bool) {
+	claims, err := s.authenticate(r)
+	if err != nil {
+		ErrUnauthorizedResponse(w, "Unauthorized", err)
+		return 0, false
+	}
+
+	switch claims.Role {
+	case model.RoleAdmin:
+		return 0, true
+	case model.RoleUser:
+		return claims.ID, true
+	}
+	ErrForbiddenResponse(w, "Forbidden", fmt.Errorf("only users can read webhook subscriptions :%w", model.ErrForbidden))
+	return 0, false
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/auth"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhookSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payload := `{"user_id":1,"url":"https://shop.example/hook","secret":"s3cret","event_types":["parcel.status_changed"]}`
+	input := model.WebhookSubscription{UserID: 1, URL: "https://shop.example/hook", Secret: "s3cret", EventTypes: []string{model.EventParcelStatusChanged}}
+	subscription := input
+	subscription.ID = 1
+	subscription.Active = true
+	subscription.CreatedAt = time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc           string
+		token          string
+		payload        string
+		mockWebhookSvc func() *mocks.MockWebhookService
+		expStatusCode  int
+		expResponse    string
+	}{
+		{
+			desc:    "should success",
+			token:   "Bearer " + userToken(t, signer, 1),
+			payload: payload,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().CreateSubscription(gomock.Any(), input).Return(subscription, nil)
+				return s
+			},
+			expStatusCode: http.StatusCreated,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":1,"user_id":1,"url":"https://shop.example/hook","event_types":["parcel.status_changed"],"active":true,"created_at":"2020-04-11T21:34:01Z","secret":"s3cret"}}`,
+		},
+		{
+			desc:    "should return unauthorized",
+			payload: payload,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return forbidden for another user",
+			token:   "Bearer " + userToken(t, signer, 2),
+			payload: payload,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"token is not valid for user 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid event type",
+			payload: `{"user_id":1,"url":"https://shop.example/hook","event_types":["parcel.lost"]}`,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"unknown event type parcel.lost :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return invalid url",
+			payload: `{"user_id":1,"url":"shop.example/hook","event_types":["parcel.created"]}`,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"url must be an absolute http or https URL :invalid","message_title":"Invalid Input","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:    "should return internal server error",
+			token:   "Bearer " + userToken(t, signer, 1),
+			payload: payload,
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Return(model.WebhookSubscription{}, errors.New("server-error"))
+				return s
+			},
+			expStatusCode: http.StatusInternalServerError,
+			expResponse:   `{"success":false,"errors":[{"code":"SERVER_ERROR","message":"server-error","message_title":"failed to create webhook subscription","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithWebhookService(tc.mockWebhookSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(tc.payload))
+			r.Header.Set("Authorization", tc.token)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodPost).Path("/api/v1/webhooks").HandlerFunc(s.newWebhookSubscription)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deliveredAt := time.Date(2020, time.April, 11, 21, 34, 01, 0, time.UTC)
+	signer := auth.NewSigner([]byte("secret"))
+
+	testCases := []struct {
+		desc           string
+		token          string
+		url            string
+		mockWebhookSvc func() *mocks.MockWebhookService
+		expStatusCode  int
+		expResponse    string
+	}{
+		{
+			desc:  "should success",
+			token: "Bearer " + userToken(t, signer, 3),
+			url:   "/api/v1/webhooks/deliveries/10/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().ReplayDelivery(gomock.Any(), 3, 10).Return(model.WebhookDelivery{
+					ID: 11, SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: "{}", Status: model.WebhookDeliveryDelivered,
+					Attempts: 1, ResponseCode: 200, CreatedAt: deliveredAt, DeliveredAt: &deliveredAt,
+				}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":11,"subscription_id":1,"event_type":"parcel.created","payload":"{}","status":"delivered","attempts":1,"response_code":200,"error":"","created_at":"2020-04-11T21:34:01Z","delivered_at":"2020-04-11T21:34:01Z"}}`,
+		},
+		{
+			desc:  "should return invalid delivery ID",
+			token: "Bearer " + userToken(t, signer, 3),
+			url:   "/api/v1/webhooks/deliveries/invalid/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponse:   `{"success":false,"errors":[{"code":"INVALID","message":"strconv.Atoi: parsing \"invalid\": invalid syntax","message_title":"Invalid Delivery ID","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should replay any delivery for an admin",
+			token: "Bearer " + adminToken(t, signer),
+			url:   "/api/v1/webhooks/deliveries/10/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().ReplayDelivery(gomock.Any(), 0, 10).Return(model.WebhookDelivery{ID: 11, SubscriptionID: 1, CreatedAt: deliveredAt}, nil)
+				return s
+			},
+			expStatusCode: http.StatusOK,
+			expResponse:   `{"success":true,"errors":null,"data":{"id":11,"subscription_id":1,"event_type":"","payload":"","status":"","attempts":0,"response_code":0,"error":"","created_at":"2020-04-11T21:34:01Z","delivered_at":null}}`,
+		},
+		{
+			desc: "should return unauthorized",
+			url:  "/api/v1/webhooks/deliveries/10/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				return mocks.NewMockWebhookService(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+			expResponse:   `{"success":false,"errors":[{"code":"UNAUTHORIZED","message":"access token is required :unauthorized","message_title":"Unauthorized","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return forbidden for the delivery of another user",
+			token: "Bearer " + userToken(t, signer, 3),
+			url:   "/api/v1/webhooks/deliveries/10/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().ReplayDelivery(gomock.Any(), 3, 10).Return(model.WebhookDelivery{}, fmt.Errorf("user 3 does not own webhook subscription 1 :%w", model.ErrForbidden))
+				return s
+			},
+			expStatusCode: http.StatusForbidden,
+			expResponse:   `{"success":false,"errors":[{"code":"FORBIDDEN","message":"user 3 does not own webhook subscription 1 :forbidden","message_title":"Forbidden","severity":"error"}],"data":null}`,
+		},
+		{
+			desc:  "should return not found",
+			token: "Bearer " + userToken(t, signer, 3),
+			url:   "/api/v1/webhooks/deliveries/10/replay",
+			mockWebhookSvc: func() *mocks.MockWebhookService {
+				s := mocks.NewMockWebhookService(ctrl)
+				s.EXPECT().ReplayDelivery(gomock.Any(), 3, 10).Return(model.WebhookDelivery{}, model.ErrNotFound)
+				return s
+			},
+			expStatusCode: http.StatusNotFound,
+			expResponse:   `{"success":false,"errors":[{"code":"NOT FOUND","message":"not found","message_title":"This ID does not exist.","severity":"error"}],"data":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewServer(":8080", nil, nil, WithAuthenticator(signer), WithWebhookService(tc.mockWebhookSvc()))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tc.url, nil)
+			r.Header.Set("Authorization", tc.token)
+
+			router := mux.NewRouter()
+			router.Methods(http.MethodPost).Path("/api/v1/webhooks/deliveries/{id}/replay").HandlerFunc(s.replayWebhookDelivery)
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponse, w.Body.String())
+		})
+	}
+}
@@ -6,7 +6,9 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	model "parcel-service/internal/app/model"
+	service "parcel-service/internal/app/service"
 	reflect "reflect"
 	time "time"
 
@@ -36,6 +38,21 @@ func (m *MockParcelRepository) EXPECT() *MockParcelRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CancelParcel mocks base method.
+func (m *MockParcelRepository) CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelParcel", ctx, cancellation)
+	ret0, _ := ret[0].(model.Cancellation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelParcel indicates an expected call of CancelParcel.
+func (mr *MockParcelRepositoryMockRecorder) CancelParcel(ctx, cancellation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelParcel", reflect.TypeOf((*MockParcelRepository)(nil).CancelParcel), ctx, cancellation)
+}
+
 // FetchParcelByID mocks base method.
 func (m *MockParcelRepository) FetchParcelByID(ctx context.Context, parcelID int) (model.Parcel, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +98,36 @@ func (mr *MockParcelRepositoryMockRecorder) InsertParcel(ctx, parcel interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertParcel", reflect.TypeOf((*MockParcelRepository)(nil).InsertParcel), ctx, parcel)
 }
 
+// InsertParcels mocks base method.
+func (m *MockParcelRepository) InsertParcels(ctx context.Context, parcels []model.Parcel) ([]model.Parcel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertParcels", ctx, parcels)
+	ret0, _ := ret[0].([]model.Parcel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertParcels indicates an expected call of InsertParcels.
+func (mr *MockParcelRepositoryMockRecorder) InsertParcels(ctx, parcels interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertParcels", reflect.TypeOf((*MockParcelRepository)(nil).InsertParcels), ctx, parcels)
+}
+
+// RecordFailedAttempt mocks base method.
+func (m *MockParcelRepository) RecordFailedAttempt(ctx context.Context, attempt model.DeliveryAttempt, maxAttempts int, returnParcel *model.Parcel) (model.DeliveryAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedAttempt", ctx, attempt, maxAttempts, returnParcel)
+	ret0, _ := ret[0].(model.DeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailedAttempt indicates an expected call of RecordFailedAttempt.
+func (mr *MockParcelRepositoryMockRecorder) RecordFailedAttempt(ctx, attempt, maxAttempts, returnParcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedAttempt", reflect.TypeOf((*MockParcelRepository)(nil).RecordFailedAttempt), ctx, attempt, maxAttempts, returnParcel)
+}
+
 // UpdateParcel mocks base method.
 func (m *MockParcelRepository) UpdateParcel(ctx context.Context, parcel model.Parcel) error {
 	m.ctrl.T.Helper()
@@ -118,6 +165,21 @@ func (m *MockParcelService) EXPECT() *MockParcelServiceMockRecorder {
 	return m.recorder
 }
 
+// CancelParcel mocks base method.
+func (m *MockParcelService) CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelParcel", ctx, cancellation)
+	ret0, _ := ret[0].(model.Cancellation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelParcel indicates an expected call of CancelParcel.
+func (mr *MockParcelServiceMockRecorder) CancelParcel(ctx, cancellation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelParcel", reflect.TypeOf((*MockParcelService)(nil).CancelParcel), ctx, cancellation)
+}
+
 // CreateParcel mocks base method.
 func (m *MockParcelService) CreateParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error) {
 	m.ctrl.T.Helper()
@@ -147,6 +209,21 @@ func (mr *MockParcelServiceMockRecorder) EditParcel(ctx, parcel interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditParcel", reflect.TypeOf((*MockParcelService)(nil).EditParcel), ctx, parcel)
 }
 
+// FailDelivery mocks base method.
+func (m *MockParcelService) FailDelivery(ctx context.Context, attempt model.DeliveryAttempt) (model.DeliveryAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDelivery", ctx, attempt)
+	ret0, _ := ret[0].(model.DeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailDelivery indicates an expected call of FailDelivery.
+func (mr *MockParcelServiceMockRecorder) FailDelivery(ctx, attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDelivery", reflect.TypeOf((*MockParcelService)(nil).FailDelivery), ctx, attempt)
+}
+
 // GetParcelByID mocks base method.
 func (m *MockParcelService) GetParcelByID(ctx context.Context, parcelID int) (model.Parcel, error) {
 	m.ctrl.T.Helper()
@@ -177,9 +254,34 @@ func (mr *MockParcelServiceMockRecorder) GetParcels(ctx, status, limit, offset i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParcels", reflect.TypeOf((*MockParcelService)(nil).GetParcels), ctx, status, limit, offset)
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockCarrierRepository) EXPECT() *MockCarrierRepositoryMockRecorder {
-	return m.recorder
+// ImportParcels mocks base method.
+func (m *MockParcelService) ImportParcels(ctx context.Context, userID int, rows []model.BulkRow) (model.BulkReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportParcels", ctx, userID, rows)
+	ret0, _ := ret[0].(model.BulkReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportParcels indicates an expected call of ImportParcels.
+func (mr *MockParcelServiceMockRecorder) ImportParcels(ctx, userID, rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportParcels", reflect.TypeOf((*MockParcelService)(nil).ImportParcels), ctx, userID, rows)
+}
+
+// PriceParcel mocks base method.
+func (m *MockParcelService) PriceParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceParcel", ctx, parcel)
+	ret0, _ := ret[0].(model.Parcel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceParcel indicates an expected call of PriceParcel.
+func (mr *MockParcelServiceMockRecorder) PriceParcel(ctx, parcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceParcel", reflect.TypeOf((*MockParcelService)(nil).PriceParcel), ctx, parcel)
 }
 
 // MockCarrierRepository is a mock of CarrierRepository interface.
@@ -200,6 +302,56 @@ func NewMockCarrierRepository(ctrl *gomock.Controller) *MockCarrierRepository {
 	return mock
 }
 
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCarrierRepository) EXPECT() *MockCarrierRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ExpireCarrierRequests mocks base method.
+func (m *MockCarrierRepository) ExpireCarrierRequests(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireCarrierRequests", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireCarrierRequests indicates an expected call of ExpireCarrierRequests.
+func (mr *MockCarrierRepositoryMockRecorder) ExpireCarrierRequests(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireCarrierRequests", reflect.TypeOf((*MockCarrierRepository)(nil).ExpireCarrierRequests), ctx, now)
+}
+
+// FetchCarrierRatings mocks base method.
+func (m *MockCarrierRepository) FetchCarrierRatings(ctx context.Context, carrierIDs []int) ([]model.CarrierRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCarrierRatings", ctx, carrierIDs)
+	ret0, _ := ret[0].([]model.CarrierRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCarrierRatings indicates an expected call of FetchCarrierRatings.
+func (mr *MockCarrierRepositoryMockRecorder) FetchCarrierRatings(ctx, carrierIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCarrierRatings", reflect.TypeOf((*MockCarrierRepository)(nil).FetchCarrierRatings), ctx, carrierIDs)
+}
+
+// FetchCarrierRequests mocks base method.
+func (m *MockCarrierRepository) FetchCarrierRequests(ctx context.Context, parcelID int, now time.Time) ([]model.CarrierRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCarrierRequests", ctx, parcelID, now)
+	ret0, _ := ret[0].([]model.CarrierRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCarrierRequests indicates an expected call of FetchCarrierRequests.
+func (mr *MockCarrierRepositoryMockRecorder) FetchCarrierRequests(ctx, parcelID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCarrierRequests", reflect.TypeOf((*MockCarrierRepository)(nil).FetchCarrierRequests), ctx, parcelID, now)
+}
+
 // InsertCarrierRequest mocks base method.
 func (m *MockCarrierRepository) InsertCarrierRequest(ctx context.Context, carrierReq model.CarrierRequest) error {
 	m.ctrl.T.Helper()
@@ -214,18 +366,33 @@ func (mr *MockCarrierRepositoryMockRecorder) InsertCarrierRequest(ctx, carrierRe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCarrierRequest", reflect.TypeOf((*MockCarrierRepository)(nil).InsertCarrierRequest), ctx, carrierReq)
 }
 
+// UpdateCarrierLocation mocks base method.
+func (m *MockCarrierRepository) UpdateCarrierLocation(ctx context.Context, location model.CarrierLocation) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCarrierLocation", ctx, location)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCarrierLocation indicates an expected call of UpdateCarrierLocation.
+func (mr *MockCarrierRepositoryMockRecorder) UpdateCarrierLocation(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCarrierLocation", reflect.TypeOf((*MockCarrierRepository)(nil).UpdateCarrierLocation), ctx, location)
+}
+
 // UpdateCarrierRequest mocks base method.
-func (m *MockCarrierRepository) UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus, rejectStatus, parcelStatus int, sourceTime time.Time) error {
+func (m *MockCarrierRepository) UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus, rejectStatus, parcelStatus int, assignedAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCarrierRequest", ctx, parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+	ret := m.ctrl.Call(m, "UpdateCarrierRequest", ctx, parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateCarrierRequest indicates an expected call of UpdateCarrierRequest.
-func (mr *MockCarrierRepositoryMockRecorder) UpdateCarrierRequest(ctx, parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime interface{}) *gomock.Call {
+func (mr *MockCarrierRepositoryMockRecorder) UpdateCarrierRequest(ctx, parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCarrierRequest", reflect.TypeOf((*MockCarrierRepository)(nil).UpdateCarrierRequest), ctx, parcel, acceptStatus, rejectStatus, parcelStatus, sourceTime)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCarrierRequest", reflect.TypeOf((*MockCarrierRepository)(nil).UpdateCarrierRequest), ctx, parcel, acceptStatus, rejectStatus, parcelStatus, assignedAt)
 }
 
 // MockCarrierService is a mock of CarrierService interface.
@@ -265,6 +432,21 @@ func (mr *MockCarrierServiceMockRecorder) AssignCarrierToParcel(ctx, parcel inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignCarrierToParcel", reflect.TypeOf((*MockCarrierService)(nil).AssignCarrierToParcel), ctx, parcel)
 }
 
+// GetCarrierRequests mocks base method.
+func (m *MockCarrierService) GetCarrierRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarrierRequests", ctx, parcelID)
+	ret0, _ := ret[0].([]model.CarrierRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarrierRequests indicates an expected call of GetCarrierRequests.
+func (mr *MockCarrierServiceMockRecorder) GetCarrierRequests(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarrierRequests", reflect.TypeOf((*MockCarrierService)(nil).GetCarrierRequests), ctx, parcelID)
+}
+
 // NewCarrierRequest mocks base method.
 func (m *MockCarrierService) NewCarrierRequest(ctx context.Context, carrierReq model.CarrierRequest) error {
 	m.ctrl.T.Helper()
@@ -278,3 +460,2679 @@ func (mr *MockCarrierServiceMockRecorder) NewCarrierRequest(ctx, carrierReq inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewCarrierRequest", reflect.TypeOf((*MockCarrierService)(nil).NewCarrierRequest), ctx, carrierReq)
 }
+
+// UpdateLocation mocks base method.
+func (m *MockCarrierService) UpdateLocation(ctx context.Context, location model.CarrierLocation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLocation", ctx, location)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLocation indicates an expected call of UpdateLocation.
+func (mr *MockCarrierServiceMockRecorder) UpdateLocation(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLocation", reflect.TypeOf((*MockCarrierService)(nil).UpdateLocation), ctx, location)
+}
+
+// MockPromotionRepository is a mock of PromotionRepository interface.
+type MockPromotionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionRepositoryMockRecorder
+}
+
+// MockPromotionRepositoryMockRecorder is the mock recorder for MockPromotionRepository.
+type MockPromotionRepositoryMockRecorder struct {
+	mock *MockPromotionRepository
+}
+
+// NewMockPromotionRepository creates a new mock instance.
+func NewMockPromotionRepository(ctrl *gomock.Controller) *MockPromotionRepository {
+	mock := &MockPromotionRepository{ctrl: ctrl}
+	mock.recorder = &MockPromotionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionRepository) EXPECT() *MockPromotionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountUserRedemptions mocks base method.
+func (m *MockPromotionRepository) CountUserRedemptions(ctx context.Context, promotionID, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserRedemptions", ctx, promotionID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserRedemptions indicates an expected call of CountUserRedemptions.
+func (mr *MockPromotionRepositoryMockRecorder) CountUserRedemptions(ctx, promotionID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserRedemptions", reflect.TypeOf((*MockPromotionRepository)(nil).CountUserRedemptions), ctx, promotionID, userID)
+}
+
+// FetchPromotionByCode mocks base method.
+func (m *MockPromotionRepository) FetchPromotionByCode(ctx context.Context, code string) (model.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPromotionByCode", ctx, code)
+	ret0, _ := ret[0].(model.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPromotionByCode indicates an expected call of FetchPromotionByCode.
+func (mr *MockPromotionRepositoryMockRecorder) FetchPromotionByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPromotionByCode", reflect.TypeOf((*MockPromotionRepository)(nil).FetchPromotionByCode), ctx, code)
+}
+
+// InsertPromotion mocks base method.
+func (m *MockPromotionRepository) InsertPromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPromotion", ctx, promotion)
+	ret0, _ := ret[0].(model.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPromotion indicates an expected call of InsertPromotion.
+func (mr *MockPromotionRepositoryMockRecorder) InsertPromotion(ctx, promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPromotion", reflect.TypeOf((*MockPromotionRepository)(nil).InsertPromotion), ctx, promotion)
+}
+
+// MockPromotionService is a mock of PromotionService interface.
+type MockPromotionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionServiceMockRecorder
+}
+
+// MockPromotionServiceMockRecorder is the mock recorder for MockPromotionService.
+type MockPromotionServiceMockRecorder struct {
+	mock *MockPromotionService
+}
+
+// NewMockPromotionService creates a new mock instance.
+func NewMockPromotionService(ctrl *gomock.Controller) *MockPromotionService {
+	mock := &MockPromotionService{ctrl: ctrl}
+	mock.recorder = &MockPromotionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionService) EXPECT() *MockPromotionServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyPromotion mocks base method.
+func (m *MockPromotionService) ApplyPromotion(ctx context.Context, code string, userID int, price float32) (model.Promotion, float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyPromotion", ctx, code, userID, price)
+	ret0, _ := ret[0].(model.Promotion)
+	ret1, _ := ret[1].(float32)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyPromotion indicates an expected call of ApplyPromotion.
+func (mr *MockPromotionServiceMockRecorder) ApplyPromotion(ctx, code, userID, price interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyPromotion", reflect.TypeOf((*MockPromotionService)(nil).ApplyPromotion), ctx, code, userID, price)
+}
+
+// CreatePromotion mocks base method.
+func (m *MockPromotionService) CreatePromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", ctx, promotion)
+	ret0, _ := ret[0].(model.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockPromotionServiceMockRecorder) CreatePromotion(ctx, promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockPromotionService)(nil).CreatePromotion), ctx, promotion)
+}
+
+// GetPromotionByCode mocks base method.
+func (m *MockPromotionService) GetPromotionByCode(ctx context.Context, code string) (model.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotionByCode", ctx, code)
+	ret0, _ := ret[0].(model.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotionByCode indicates an expected call of GetPromotionByCode.
+func (mr *MockPromotionServiceMockRecorder) GetPromotionByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionByCode", reflect.TypeOf((*MockPromotionService)(nil).GetPromotionByCode), ctx, code)
+}
+
+// MockInvoiceRepository is a mock of InvoiceRepository interface.
+type MockInvoiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceRepositoryMockRecorder
+}
+
+// MockInvoiceRepositoryMockRecorder is the mock recorder for MockInvoiceRepository.
+type MockInvoiceRepositoryMockRecorder struct {
+	mock *MockInvoiceRepository
+}
+
+// NewMockInvoiceRepository creates a new mock instance.
+func NewMockInvoiceRepository(ctrl *gomock.Controller) *MockInvoiceRepository {
+	mock := &MockInvoiceRepository{ctrl: ctrl}
+	mock.recorder = &MockInvoiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvoiceRepository) EXPECT() *MockInvoiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchInvoiceByParcelID mocks base method.
+func (m *MockInvoiceRepository) FetchInvoiceByParcelID(ctx context.Context, parcelID int) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchInvoiceByParcelID", ctx, parcelID)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchInvoiceByParcelID indicates an expected call of FetchInvoiceByParcelID.
+func (mr *MockInvoiceRepositoryMockRecorder) FetchInvoiceByParcelID(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchInvoiceByParcelID", reflect.TypeOf((*MockInvoiceRepository)(nil).FetchInvoiceByParcelID), ctx, parcelID)
+}
+
+// IssueInvoice mocks base method.
+func (m *MockInvoiceRepository) IssueInvoice(ctx context.Context, parcelID int) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueInvoice", ctx, parcelID)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueInvoice indicates an expected call of IssueInvoice.
+func (mr *MockInvoiceRepositoryMockRecorder) IssueInvoice(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueInvoice", reflect.TypeOf((*MockInvoiceRepository)(nil).IssueInvoice), ctx, parcelID)
+}
+
+// MockInvoiceService is a mock of InvoiceService interface.
+type MockInvoiceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvoiceServiceMockRecorder
+}
+
+// MockInvoiceServiceMockRecorder is the mock recorder for MockInvoiceService.
+type MockInvoiceServiceMockRecorder struct {
+	mock *MockInvoiceService
+}
+
+// NewMockInvoiceService creates a new mock instance.
+func NewMockInvoiceService(ctrl *gomock.Controller) *MockInvoiceService {
+	mock := &MockInvoiceService{ctrl: ctrl}
+	mock.recorder = &MockInvoiceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvoiceService) EXPECT() *MockInvoiceServiceMockRecorder {
+	return m.recorder
+}
+
+// GetInvoice mocks base method.
+func (m *MockInvoiceService) GetInvoice(ctx context.Context, parcelID int) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvoice", ctx, parcelID)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvoice indicates an expected call of GetInvoice.
+func (mr *MockInvoiceServiceMockRecorder) GetInvoice(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockInvoiceService)(nil).GetInvoice), ctx, parcelID)
+}
+
+// RenderHTML mocks base method.
+func (m *MockInvoiceService) RenderHTML(w io.Writer, invoice model.Invoice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderHTML", w, invoice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenderHTML indicates an expected call of RenderHTML.
+func (mr *MockInvoiceServiceMockRecorder) RenderHTML(w, invoice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderHTML", reflect.TypeOf((*MockInvoiceService)(nil).RenderHTML), w, invoice)
+}
+
+// RenderPDF mocks base method.
+func (m *MockInvoiceService) RenderPDF(w io.Writer, invoice model.Invoice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderPDF", w, invoice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenderPDF indicates an expected call of RenderPDF.
+func (mr *MockInvoiceServiceMockRecorder) RenderPDF(w, invoice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderPDF", reflect.TypeOf((*MockInvoiceService)(nil).RenderPDF), w, invoice)
+}
+
+// MockTaxRepository is a mock of TaxRepository interface.
+type MockTaxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxRepositoryMockRecorder
+}
+
+// MockTaxRepositoryMockRecorder is the mock recorder for MockTaxRepository.
+type MockTaxRepositoryMockRecorder struct {
+	mock *MockTaxRepository
+}
+
+// NewMockTaxRepository creates a new mock instance.
+func NewMockTaxRepository(ctrl *gomock.Controller) *MockTaxRepository {
+	mock := &MockTaxRepository{ctrl: ctrl}
+	mock.recorder = &MockTaxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaxRepository) EXPECT() *MockTaxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchTaxRules mocks base method.
+func (m *MockTaxRepository) FetchTaxRules(ctx context.Context) ([]model.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchTaxRules", ctx)
+	ret0, _ := ret[0].([]model.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchTaxRules indicates an expected call of FetchTaxRules.
+func (mr *MockTaxRepositoryMockRecorder) FetchTaxRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchTaxRules", reflect.TypeOf((*MockTaxRepository)(nil).FetchTaxRules), ctx)
+}
+
+// InsertTaxRule mocks base method.
+func (m *MockTaxRepository) InsertTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTaxRule", ctx, rule)
+	ret0, _ := ret[0].(model.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTaxRule indicates an expected call of InsertTaxRule.
+func (mr *MockTaxRepositoryMockRecorder) InsertTaxRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTaxRule", reflect.TypeOf((*MockTaxRepository)(nil).InsertTaxRule), ctx, rule)
+}
+
+// MockTaxService is a mock of TaxService interface.
+type MockTaxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxServiceMockRecorder
+}
+
+// MockTaxServiceMockRecorder is the mock recorder for MockTaxService.
+type MockTaxServiceMockRecorder struct {
+	mock *MockTaxService
+}
+
+// NewMockTaxService creates a new mock instance.
+func NewMockTaxService(ctrl *gomock.Controller) *MockTaxService {
+	mock := &MockTaxService{ctrl: ctrl}
+	mock.recorder = &MockTaxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaxService) EXPECT() *MockTaxServiceMockRecorder {
+	return m.recorder
+}
+
+// CalculateTax mocks base method.
+func (m *MockTaxService) CalculateTax(ctx context.Context, parcel model.Parcel) (model.Tax, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateTax", ctx, parcel)
+	ret0, _ := ret[0].(model.Tax)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateTax indicates an expected call of CalculateTax.
+func (mr *MockTaxServiceMockRecorder) CalculateTax(ctx, parcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateTax", reflect.TypeOf((*MockTaxService)(nil).CalculateTax), ctx, parcel)
+}
+
+// CreateTaxRule mocks base method.
+func (m *MockTaxService) CreateTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxRule", ctx, rule)
+	ret0, _ := ret[0].(model.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxRule indicates an expected call of CreateTaxRule.
+func (mr *MockTaxServiceMockRecorder) CreateTaxRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxRule", reflect.TypeOf((*MockTaxService)(nil).CreateTaxRule), ctx, rule)
+}
+
+// GetTaxRules mocks base method.
+func (m *MockTaxService) GetTaxRules(ctx context.Context) ([]model.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxRules", ctx)
+	ret0, _ := ret[0].([]model.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxRules indicates an expected call of GetTaxRules.
+func (mr *MockTaxServiceMockRecorder) GetTaxRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxRules", reflect.TypeOf((*MockTaxService)(nil).GetTaxRules), ctx)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Channel mocks base method.
+func (m *MockNotifier) Channel() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Channel")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Channel indicates an expected call of Channel.
+func (mr *MockNotifierMockRecorder) Channel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Channel", reflect.TypeOf((*MockNotifier)(nil).Channel))
+}
+
+// Send mocks base method.
+func (m *MockNotifier) Send(ctx context.Context, notification model.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockNotifierMockRecorder) Send(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockNotifier)(nil).Send), ctx, notification)
+}
+
+// MockEventNotifier is a mock of EventNotifier interface.
+type MockEventNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventNotifierMockRecorder
+}
+
+// MockEventNotifierMockRecorder is the mock recorder for MockEventNotifier.
+type MockEventNotifierMockRecorder struct {
+	mock *MockEventNotifier
+}
+
+// NewMockEventNotifier creates a new mock instance.
+func NewMockEventNotifier(ctrl *gomock.Controller) *MockEventNotifier {
+	mock := &MockEventNotifier{ctrl: ctrl}
+	mock.recorder = &MockEventNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventNotifier) EXPECT() *MockEventNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockEventNotifier) Notify(ctx context.Context, event model.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Notify", ctx, event)
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockEventNotifierMockRecorder) Notify(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockEventNotifier)(nil).Notify), ctx, event)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchDeliveriesBySubscriptionID mocks base method.
+func (m *MockWebhookRepository) FetchDeliveriesBySubscriptionID(ctx context.Context, subscriptionID, limit, offset int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeliveriesBySubscriptionID", ctx, subscriptionID, limit, offset)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeliveriesBySubscriptionID indicates an expected call of FetchDeliveriesBySubscriptionID.
+func (mr *MockWebhookRepositoryMockRecorder) FetchDeliveriesBySubscriptionID(ctx, subscriptionID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeliveriesBySubscriptionID", reflect.TypeOf((*MockWebhookRepository)(nil).FetchDeliveriesBySubscriptionID), ctx, subscriptionID, limit, offset)
+}
+
+// FetchDeliveryByID mocks base method.
+func (m *MockWebhookRepository) FetchDeliveryByID(ctx context.Context, deliveryID int) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeliveryByID", ctx, deliveryID)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeliveryByID indicates an expected call of FetchDeliveryByID.
+func (mr *MockWebhookRepositoryMockRecorder) FetchDeliveryByID(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeliveryByID", reflect.TypeOf((*MockWebhookRepository)(nil).FetchDeliveryByID), ctx, deliveryID)
+}
+
+// FetchSubscriptionByID mocks base method.
+func (m *MockWebhookRepository) FetchSubscriptionByID(ctx context.Context, subscriptionID int) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchSubscriptionByID", ctx, subscriptionID)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchSubscriptionByID indicates an expected call of FetchSubscriptionByID.
+func (mr *MockWebhookRepositoryMockRecorder) FetchSubscriptionByID(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSubscriptionByID", reflect.TypeOf((*MockWebhookRepository)(nil).FetchSubscriptionByID), ctx, subscriptionID)
+}
+
+// FetchSubscriptionsByUserID mocks base method.
+func (m *MockWebhookRepository) FetchSubscriptionsByUserID(ctx context.Context, userID int) ([]model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchSubscriptionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchSubscriptionsByUserID indicates an expected call of FetchSubscriptionsByUserID.
+func (mr *MockWebhookRepositoryMockRecorder) FetchSubscriptionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSubscriptionsByUserID", reflect.TypeOf((*MockWebhookRepository)(nil).FetchSubscriptionsByUserID), ctx, userID)
+}
+
+// InsertDelivery mocks base method.
+func (m *MockWebhookRepository) InsertDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDelivery", ctx, delivery)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDelivery indicates an expected call of InsertDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) InsertDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).InsertDelivery), ctx, delivery)
+}
+
+// InsertReplay mocks base method.
+func (m *MockWebhookRepository) InsertReplay(ctx context.Context, delivery model.WebhookDelivery, originalID int) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReplay", ctx, delivery, originalID)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReplay indicates an expected call of InsertReplay.
+func (mr *MockWebhookRepositoryMockRecorder) InsertReplay(ctx, delivery, originalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReplay", reflect.TypeOf((*MockWebhookRepository)(nil).InsertReplay), ctx, delivery, originalID)
+}
+
+// InsertSubscription mocks base method.
+func (m *MockWebhookRepository) InsertSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSubscription", ctx, subscription)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertSubscription indicates an expected call of InsertSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) InsertSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).InsertSubscription), ctx, subscription)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateDelivery), ctx, delivery)
+}
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSubscription mocks base method.
+func (m *MockWebhookService) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockWebhookServiceMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookService)(nil).CreateSubscription), ctx, subscription)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookService) GetDeliveries(ctx context.Context, userID, subscriptionID, limit, offset int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, userID, subscriptionID, limit, offset)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookServiceMockRecorder) GetDeliveries(ctx, userID, subscriptionID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookService)(nil).GetDeliveries), ctx, userID, subscriptionID, limit, offset)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockWebhookService) GetSubscriptions(ctx context.Context, userID int) ([]model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx, userID)
+	ret0, _ := ret[0].([]model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockWebhookServiceMockRecorder) GetSubscriptions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockWebhookService)(nil).GetSubscriptions), ctx, userID)
+}
+
+// ReplayDelivery mocks base method.
+func (m *MockWebhookService) ReplayDelivery(ctx context.Context, userID, deliveryID int) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDelivery", ctx, userID, deliveryID)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayDelivery indicates an expected call of ReplayDelivery.
+func (mr *MockWebhookServiceMockRecorder) ReplayDelivery(ctx, userID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDelivery", reflect.TypeOf((*MockWebhookService)(nil).ReplayDelivery), ctx, userID, deliveryID)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchPendingMessages mocks base method.
+func (m *MockOutboxRepository) FetchPendingMessages(ctx context.Context, limit int) ([]model.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPendingMessages", ctx, limit)
+	ret0, _ := ret[0].([]model.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPendingMessages indicates an expected call of FetchPendingMessages.
+func (mr *MockOutboxRepositoryMockRecorder) FetchPendingMessages(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPendingMessages", reflect.TypeOf((*MockOutboxRepository)(nil).FetchPendingMessages), ctx, limit)
+}
+
+// MarkMessageFailed mocks base method.
+func (m *MockOutboxRepository) MarkMessageFailed(ctx context.Context, messageID int64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMessageFailed", ctx, messageID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMessageFailed indicates an expected call of MarkMessageFailed.
+func (mr *MockOutboxRepositoryMockRecorder) MarkMessageFailed(ctx, messageID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMessageFailed", reflect.TypeOf((*MockOutboxRepository)(nil).MarkMessageFailed), ctx, messageID, reason)
+}
+
+// MarkMessageSent mocks base method.
+func (m *MockOutboxRepository) MarkMessageSent(ctx context.Context, messageID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMessageSent", ctx, messageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMessageSent indicates an expected call of MarkMessageSent.
+func (mr *MockOutboxRepositoryMockRecorder) MarkMessageSent(ctx, messageID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMessageSent", reflect.TypeOf((*MockOutboxRepository)(nil).MarkMessageSent), ctx, messageID)
+}
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, topic, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(ctx, topic, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), ctx, topic, payload)
+}
+
+// MockBroadcaster is a mock of Broadcaster interface.
+type MockBroadcaster struct {
+	ctrl     *gomock.Controller
+	recorder *MockBroadcasterMockRecorder
+}
+
+// MockBroadcasterMockRecorder is the mock recorder for MockBroadcaster.
+type MockBroadcasterMockRecorder struct {
+	mock *MockBroadcaster
+}
+
+// NewMockBroadcaster creates a new mock instance.
+func NewMockBroadcaster(ctrl *gomock.Controller) *MockBroadcaster {
+	mock := &MockBroadcaster{ctrl: ctrl}
+	mock.recorder = &MockBroadcasterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBroadcaster) EXPECT() *MockBroadcasterMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockBroadcaster) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBroadcasterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBroadcaster)(nil).Close))
+}
+
+// Subscribe mocks base method.
+func (m *MockBroadcaster) Subscribe(topic string, lastEventID int64) (<-chan model.StreamEvent, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", topic, lastEventID)
+	ret0, _ := ret[0].(<-chan model.StreamEvent)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockBroadcasterMockRecorder) Subscribe(topic, lastEventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockBroadcaster)(nil).Subscribe), topic, lastEventID)
+}
+
+// MockAuthenticator is a mock of Authenticator interface.
+type MockAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthenticatorMockRecorder
+}
+
+// MockAuthenticatorMockRecorder is the mock recorder for MockAuthenticator.
+type MockAuthenticatorMockRecorder struct {
+	mock *MockAuthenticator
+}
+
+// NewMockAuthenticator creates a new mock instance.
+func NewMockAuthenticator(ctrl *gomock.Controller) *MockAuthenticator {
+	mock := &MockAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthenticator) EXPECT() *MockAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockAuthenticator) Verify(token string) (model.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", token)
+	ret0, _ := ret[0].(model.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockAuthenticatorMockRecorder) Verify(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockAuthenticator)(nil).Verify), token)
+}
+
+// MockJobFeed is a mock of JobFeed interface.
+type MockJobFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobFeedMockRecorder
+}
+
+// MockJobFeedMockRecorder is the mock recorder for MockJobFeed.
+type MockJobFeedMockRecorder struct {
+	mock *MockJobFeed
+}
+
+// NewMockJobFeed creates a new mock instance.
+func NewMockJobFeed(ctrl *gomock.Controller) *MockJobFeed {
+	mock := &MockJobFeed{ctrl: ctrl}
+	mock.recorder = &MockJobFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobFeed) EXPECT() *MockJobFeedMockRecorder {
+	return m.recorder
+}
+
+// Available mocks base method.
+func (m *MockJobFeed) Available() map[int]model.JobFilter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Available")
+	ret0, _ := ret[0].(map[int]model.JobFilter)
+	return ret0
+}
+
+// Available indicates an expected call of Available.
+func (mr *MockJobFeedMockRecorder) Available() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Available", reflect.TypeOf((*MockJobFeed)(nil).Available))
+}
+
+// Close mocks base method.
+func (m *MockJobFeed) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockJobFeedMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockJobFeed)(nil).Close))
+}
+
+// Offer mocks base method.
+func (m *MockJobFeed) Offer(offer model.Offer) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offer", offer)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Offer indicates an expected call of Offer.
+func (mr *MockJobFeedMockRecorder) Offer(offer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offer", reflect.TypeOf((*MockJobFeed)(nil).Offer), offer)
+}
+
+// Publish mocks base method.
+func (m *MockJobFeed) Publish(parcel model.Parcel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", parcel)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockJobFeedMockRecorder) Publish(parcel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockJobFeed)(nil).Publish), parcel)
+}
+
+// Subscribe mocks base method.
+func (m *MockJobFeed) Subscribe(carrierID int) (service.JobSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", carrierID)
+	ret0, _ := ret[0].(service.JobSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockJobFeedMockRecorder) Subscribe(carrierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockJobFeed)(nil).Subscribe), carrierID)
+}
+
+// MockJobSubscription is a mock of JobSubscription interface.
+type MockJobSubscription struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobSubscriptionMockRecorder
+}
+
+// MockJobSubscriptionMockRecorder is the mock recorder for MockJobSubscription.
+type MockJobSubscriptionMockRecorder struct {
+	mock *MockJobSubscription
+}
+
+// NewMockJobSubscription creates a new mock instance.
+func NewMockJobSubscription(ctrl *gomock.Controller) *MockJobSubscription {
+	mock := &MockJobSubscription{ctrl: ctrl}
+	mock.recorder = &MockJobSubscriptionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobSubscription) EXPECT() *MockJobSubscriptionMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockJobSubscription) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockJobSubscriptionMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockJobSubscription)(nil).Close))
+}
+
+// Jobs mocks base method.
+func (m *MockJobSubscription) Jobs() <-chan model.Job {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Jobs")
+	ret0, _ := ret[0].(<-chan model.Job)
+	return ret0
+}
+
+// Jobs indicates an expected call of Jobs.
+func (mr *MockJobSubscriptionMockRecorder) Jobs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Jobs", reflect.TypeOf((*MockJobSubscription)(nil).Jobs))
+}
+
+// Offers mocks base method.
+func (m *MockJobSubscription) Offers() <-chan model.Offer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offers")
+	ret0, _ := ret[0].(<-chan model.Offer)
+	return ret0
+}
+
+// Offers indicates an expected call of Offers.
+func (mr *MockJobSubscriptionMockRecorder) Offers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offers", reflect.TypeOf((*MockJobSubscription)(nil).Offers))
+}
+
+// Update mocks base method.
+func (m *MockJobSubscription) Update(filter model.JobFilter) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Update", filter)
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockJobSubscriptionMockRecorder) Update(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockJobSubscription)(nil).Update), filter)
+}
+
+// MockDispatcher is a mock of Dispatcher interface.
+type MockDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockDispatcherMockRecorder
+}
+
+// MockDispatcherMockRecorder is the mock recorder for MockDispatcher.
+type MockDispatcherMockRecorder struct {
+	mock *MockDispatcher
+}
+
+// NewMockDispatcher creates a new mock instance.
+func NewMockDispatcher(ctrl *gomock.Controller) *MockDispatcher {
+	mock := &MockDispatcher{ctrl: ctrl}
+	mock.recorder = &MockDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDispatcher) EXPECT() *MockDispatcherMockRecorder {
+	return m.recorder
+}
+
+// Respond mocks base method.
+func (m *MockDispatcher) Respond(ctx context.Context, carrierID int, offerID int64, accept bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Respond", ctx, carrierID, offerID, accept)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Respond indicates an expected call of Respond.
+func (mr *MockDispatcherMockRecorder) Respond(ctx, carrierID, offerID, accept interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Respond", reflect.TypeOf((*MockDispatcher)(nil).Respond), ctx, carrierID, offerID, accept)
+}
+
+// MockSLARepository is a mock of SLARepository interface.
+type MockSLARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSLARepositoryMockRecorder
+}
+
+// MockSLARepositoryMockRecorder is the mock recorder for MockSLARepository.
+type MockSLARepositoryMockRecorder struct {
+	mock *MockSLARepository
+}
+
+// NewMockSLARepository creates a new mock instance.
+func NewMockSLARepository(ctrl *gomock.Controller) *MockSLARepository {
+	mock := &MockSLARepository{ctrl: ctrl}
+	mock.recorder = &MockSLARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSLARepository) EXPECT() *MockSLARepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchBreaches mocks base method.
+func (m *MockSLARepository) FetchBreaches(ctx context.Context, kind string, limit, offset int) ([]model.SLABreach, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchBreaches", ctx, kind, limit, offset)
+	ret0, _ := ret[0].([]model.SLABreach)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchBreaches indicates an expected call of FetchBreaches.
+func (mr *MockSLARepositoryMockRecorder) FetchBreaches(ctx, kind, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchBreaches", reflect.TypeOf((*MockSLARepository)(nil).FetchBreaches), ctx, kind, limit, offset)
+}
+
+// InsertBreaches mocks base method.
+func (m *MockSLARepository) InsertBreaches(ctx context.Context, now time.Time) ([]model.SLABreach, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBreaches", ctx, now)
+	ret0, _ := ret[0].([]model.SLABreach)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBreaches indicates an expected call of InsertBreaches.
+func (mr *MockSLARepositoryMockRecorder) InsertBreaches(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBreaches", reflect.TypeOf((*MockSLARepository)(nil).InsertBreaches), ctx, now)
+}
+
+// MockSLAService is a mock of SLAService interface.
+type MockSLAService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSLAServiceMockRecorder
+}
+
+// MockSLAServiceMockRecorder is the mock recorder for MockSLAService.
+type MockSLAServiceMockRecorder struct {
+	mock *MockSLAService
+}
+
+// NewMockSLAService creates a new mock instance.
+func NewMockSLAService(ctrl *gomock.Controller) *MockSLAService {
+	mock := &MockSLAService{ctrl: ctrl}
+	mock.recorder = &MockSLAServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSLAService) EXPECT() *MockSLAServiceMockRecorder {
+	return m.recorder
+}
+
+// GetBreaches mocks base method.
+func (m *MockSLAService) GetBreaches(ctx context.Context, kind string, limit, offset int) ([]model.SLABreach, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBreaches", ctx, kind, limit, offset)
+	ret0, _ := ret[0].([]model.SLABreach)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBreaches indicates an expected call of GetBreaches.
+func (mr *MockSLAServiceMockRecorder) GetBreaches(ctx, kind, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBreaches", reflect.TypeOf((*MockSLAService)(nil).GetBreaches), ctx, kind, limit, offset)
+}
+
+// MockReviewRepository is a mock of ReviewRepository interface.
+type MockReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewRepositoryMockRecorder
+}
+
+// MockReviewRepositoryMockRecorder is the mock recorder for MockReviewRepository.
+type MockReviewRepositoryMockRecorder struct {
+	mock *MockReviewRepository
+}
+
+// NewMockReviewRepository creates a new mock instance.
+func NewMockReviewRepository(ctrl *gomock.Controller) *MockReviewRepository {
+	mock := &MockReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewRepository) EXPECT() *MockReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// InsertReview mocks base method.
+func (m *MockReviewRepository) InsertReview(ctx context.Context, review model.Review) (model.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReview", ctx, review)
+	ret0, _ := ret[0].(model.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReview indicates an expected call of InsertReview.
+func (mr *MockReviewRepositoryMockRecorder) InsertReview(ctx, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReview", reflect.TypeOf((*MockReviewRepository)(nil).InsertReview), ctx, review)
+}
+
+// MockReviewService is a mock of ReviewService interface.
+type MockReviewService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewServiceMockRecorder
+}
+
+// MockReviewServiceMockRecorder is the mock recorder for MockReviewService.
+type MockReviewServiceMockRecorder struct {
+	mock *MockReviewService
+}
+
+// NewMockReviewService creates a new mock instance.
+func NewMockReviewService(ctrl *gomock.Controller) *MockReviewService {
+	mock := &MockReviewService{ctrl: ctrl}
+	mock.recorder = &MockReviewServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewService) EXPECT() *MockReviewServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateReview mocks base method.
+func (m *MockReviewService) CreateReview(ctx context.Context, review model.Review) (model.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReview", ctx, review)
+	ret0, _ := ret[0].(model.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReview indicates an expected call of CreateReview.
+func (mr *MockReviewServiceMockRecorder) CreateReview(ctx, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReview", reflect.TypeOf((*MockReviewService)(nil).CreateReview), ctx, review)
+}
+
+// MockClaimRepository is a mock of ClaimRepository interface.
+type MockClaimRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClaimRepositoryMockRecorder
+}
+
+// MockClaimRepositoryMockRecorder is the mock recorder for MockClaimRepository.
+type MockClaimRepositoryMockRecorder struct {
+	mock *MockClaimRepository
+}
+
+// NewMockClaimRepository creates a new mock instance.
+func NewMockClaimRepository(ctrl *gomock.Controller) *MockClaimRepository {
+	mock := &MockClaimRepository{ctrl: ctrl}
+	mock.recorder = &MockClaimRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClaimRepository) EXPECT() *MockClaimRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DecideClaim mocks base method.
+func (m *MockClaimRepository) DecideClaim(ctx context.Context, claim model.Claim, previousStatus string) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideClaim", ctx, claim, previousStatus)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideClaim indicates an expected call of DecideClaim.
+func (mr *MockClaimRepositoryMockRecorder) DecideClaim(ctx, claim, previousStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideClaim", reflect.TypeOf((*MockClaimRepository)(nil).DecideClaim), ctx, claim, previousStatus)
+}
+
+// FetchClaimByID mocks base method.
+func (m *MockClaimRepository) FetchClaimByID(ctx context.Context, claimID int) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchClaimByID", ctx, claimID)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchClaimByID indicates an expected call of FetchClaimByID.
+func (mr *MockClaimRepositoryMockRecorder) FetchClaimByID(ctx, claimID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClaimByID", reflect.TypeOf((*MockClaimRepository)(nil).FetchClaimByID), ctx, claimID)
+}
+
+// FetchClaims mocks base method.
+func (m *MockClaimRepository) FetchClaims(ctx context.Context, status string, limit, offset int) ([]model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchClaims", ctx, status, limit, offset)
+	ret0, _ := ret[0].([]model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchClaims indicates an expected call of FetchClaims.
+func (mr *MockClaimRepositoryMockRecorder) FetchClaims(ctx, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClaims", reflect.TypeOf((*MockClaimRepository)(nil).FetchClaims), ctx, status, limit, offset)
+}
+
+// InsertClaim mocks base method.
+func (m *MockClaimRepository) InsertClaim(ctx context.Context, claim model.Claim) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertClaim", ctx, claim)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertClaim indicates an expected call of InsertClaim.
+func (mr *MockClaimRepositoryMockRecorder) InsertClaim(ctx, claim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertClaim", reflect.TypeOf((*MockClaimRepository)(nil).InsertClaim), ctx, claim)
+}
+
+// MockClaimService is a mock of ClaimService interface.
+type MockClaimService struct {
+	ctrl     *gomock.Controller
+	recorder *MockClaimServiceMockRecorder
+}
+
+// MockClaimServiceMockRecorder is the mock recorder for MockClaimService.
+type MockClaimServiceMockRecorder struct {
+	mock *MockClaimService
+}
+
+// NewMockClaimService creates a new mock instance.
+func NewMockClaimService(ctrl *gomock.Controller) *MockClaimService {
+	mock := &MockClaimService{ctrl: ctrl}
+	mock.recorder = &MockClaimServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClaimService) EXPECT() *MockClaimServiceMockRecorder {
+	return m.recorder
+}
+
+// DecideClaim mocks base method.
+func (m *MockClaimService) DecideClaim(ctx context.Context, decision model.ClaimDecision) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideClaim", ctx, decision)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideClaim indicates an expected call of DecideClaim.
+func (mr *MockClaimServiceMockRecorder) DecideClaim(ctx, decision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideClaim", reflect.TypeOf((*MockClaimService)(nil).DecideClaim), ctx, decision)
+}
+
+// GetClaim mocks base method.
+func (m *MockClaimService) GetClaim(ctx context.Context, claimID int) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClaim", ctx, claimID)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClaim indicates an expected call of GetClaim.
+func (mr *MockClaimServiceMockRecorder) GetClaim(ctx, claimID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClaim", reflect.TypeOf((*MockClaimService)(nil).GetClaim), ctx, claimID)
+}
+
+// GetClaims mocks base method.
+func (m *MockClaimService) GetClaims(ctx context.Context, status string, limit, offset int) ([]model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClaims", ctx, status, limit, offset)
+	ret0, _ := ret[0].([]model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClaims indicates an expected call of GetClaims.
+func (mr *MockClaimServiceMockRecorder) GetClaims(ctx, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClaims", reflect.TypeOf((*MockClaimService)(nil).GetClaims), ctx, status, limit, offset)
+}
+
+// OpenClaim mocks base method.
+func (m *MockClaimService) OpenClaim(ctx context.Context, claim model.Claim) (model.Claim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenClaim", ctx, claim)
+	ret0, _ := ret[0].(model.Claim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenClaim indicates an expected call of OpenClaim.
+func (mr *MockClaimServiceMockRecorder) OpenClaim(ctx, claim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenClaim", reflect.TypeOf((*MockClaimService)(nil).OpenClaim), ctx, claim)
+}
+
+// MockShipmentRepository is a mock of ShipmentRepository interface.
+type MockShipmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockShipmentRepositoryMockRecorder
+}
+
+// MockShipmentRepositoryMockRecorder is the mock recorder for MockShipmentRepository.
+type MockShipmentRepositoryMockRecorder struct {
+	mock *MockShipmentRepository
+}
+
+// NewMockShipmentRepository creates a new mock instance.
+func NewMockShipmentRepository(ctrl *gomock.Controller) *MockShipmentRepository {
+	mock := &MockShipmentRepository{ctrl: ctrl}
+	mock.recorder = &MockShipmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShipmentRepository) EXPECT() *MockShipmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptShipmentRequest mocks base method.
+func (m *MockShipmentRepository) AcceptShipmentRequest(ctx context.Context, request model.ShipmentRequest, assignedAt time.Time) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptShipmentRequest", ctx, request, assignedAt)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptShipmentRequest indicates an expected call of AcceptShipmentRequest.
+func (mr *MockShipmentRepositoryMockRecorder) AcceptShipmentRequest(ctx, request, assignedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptShipmentRequest", reflect.TypeOf((*MockShipmentRepository)(nil).AcceptShipmentRequest), ctx, request, assignedAt)
+}
+
+// FetchShipmentByID mocks base method.
+func (m *MockShipmentRepository) FetchShipmentByID(ctx context.Context, shipmentID int) (model.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchShipmentByID", ctx, shipmentID)
+	ret0, _ := ret[0].(model.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchShipmentByID indicates an expected call of FetchShipmentByID.
+func (mr *MockShipmentRepositoryMockRecorder) FetchShipmentByID(ctx, shipmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchShipmentByID", reflect.TypeOf((*MockShipmentRepository)(nil).FetchShipmentByID), ctx, shipmentID)
+}
+
+// InsertShipment mocks base method.
+func (m *MockShipmentRepository) InsertShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertShipment", ctx, shipment)
+	ret0, _ := ret[0].(model.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertShipment indicates an expected call of InsertShipment.
+func (mr *MockShipmentRepositoryMockRecorder) InsertShipment(ctx, shipment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertShipment", reflect.TypeOf((*MockShipmentRepository)(nil).InsertShipment), ctx, shipment)
+}
+
+// InsertShipmentRequest mocks base method.
+func (m *MockShipmentRepository) InsertShipmentRequest(ctx context.Context, request model.ShipmentRequest) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertShipmentRequest", ctx, request)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertShipmentRequest indicates an expected call of InsertShipmentRequest.
+func (mr *MockShipmentRepositoryMockRecorder) InsertShipmentRequest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertShipmentRequest", reflect.TypeOf((*MockShipmentRepository)(nil).InsertShipmentRequest), ctx, request)
+}
+
+// MockShipmentService is a mock of ShipmentService interface.
+type MockShipmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockShipmentServiceMockRecorder
+}
+
+// MockShipmentServiceMockRecorder is the mock recorder for MockShipmentService.
+type MockShipmentServiceMockRecorder struct {
+	mock *MockShipmentService
+}
+
+// NewMockShipmentService creates a new mock instance.
+func NewMockShipmentService(ctrl *gomock.Controller) *MockShipmentService {
+	mock := &MockShipmentService{ctrl: ctrl}
+	mock.recorder = &MockShipmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShipmentService) EXPECT() *MockShipmentServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptShipment mocks base method.
+func (m *MockShipmentService) AcceptShipment(ctx context.Context, userID int, request model.ShipmentRequest) (model.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptShipment", ctx, userID, request)
+	ret0, _ := ret[0].(model.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptShipment indicates an expected call of AcceptShipment.
+func (mr *MockShipmentServiceMockRecorder) AcceptShipment(ctx, userID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptShipment", reflect.TypeOf((*MockShipmentService)(nil).AcceptShipment), ctx, userID, request)
+}
+
+// CreateShipment mocks base method.
+func (m *MockShipmentService) CreateShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShipment", ctx, shipment)
+	ret0, _ := ret[0].(model.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShipment indicates an expected call of CreateShipment.
+func (mr *MockShipmentServiceMockRecorder) CreateShipment(ctx, shipment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShipment", reflect.TypeOf((*MockShipmentService)(nil).CreateShipment), ctx, shipment)
+}
+
+// GetShipment mocks base method.
+func (m *MockShipmentService) GetShipment(ctx context.Context, shipmentID int) (model.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShipment", ctx, shipmentID)
+	ret0, _ := ret[0].(model.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShipment indicates an expected call of GetShipment.
+func (mr *MockShipmentServiceMockRecorder) GetShipment(ctx, shipmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShipment", reflect.TypeOf((*MockShipmentService)(nil).GetShipment), ctx, shipmentID)
+}
+
+// RequestShipment mocks base method.
+func (m *MockShipmentService) RequestShipment(ctx context.Context, request model.ShipmentRequest) (model.ShipmentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestShipment", ctx, request)
+	ret0, _ := ret[0].(model.ShipmentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestShipment indicates an expected call of RequestShipment.
+func (mr *MockShipmentServiceMockRecorder) RequestShipment(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestShipment", reflect.TypeOf((*MockShipmentService)(nil).RequestShipment), ctx, request)
+}
+
+// MockRouteRepository is a mock of RouteRepository interface.
+type MockRouteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRouteRepositoryMockRecorder
+}
+
+// MockRouteRepositoryMockRecorder is the mock recorder for MockRouteRepository.
+type MockRouteRepositoryMockRecorder struct {
+	mock *MockRouteRepository
+}
+
+// NewMockRouteRepository creates a new mock instance.
+func NewMockRouteRepository(ctrl *gomock.Controller) *MockRouteRepository {
+	mock := &MockRouteRepository{ctrl: ctrl}
+	mock.recorder = &MockRouteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRouteRepository) EXPECT() *MockRouteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchActiveParcels mocks base method.
+func (m *MockRouteRepository) FetchActiveParcels(ctx context.Context, carrierID int) ([]model.Parcel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchActiveParcels", ctx, carrierID)
+	ret0, _ := ret[0].([]model.Parcel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchActiveParcels indicates an expected call of FetchActiveParcels.
+func (mr *MockRouteRepositoryMockRecorder) FetchActiveParcels(ctx, carrierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchActiveParcels", reflect.TypeOf((*MockRouteRepository)(nil).FetchActiveParcels), ctx, carrierID)
+}
+
+// FetchCarrierLocation mocks base method.
+func (m *MockRouteRepository) FetchCarrierLocation(ctx context.Context, carrierID int) (model.CarrierLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCarrierLocation", ctx, carrierID)
+	ret0, _ := ret[0].(model.CarrierLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCarrierLocation indicates an expected call of FetchCarrierLocation.
+func (mr *MockRouteRepositoryMockRecorder) FetchCarrierLocation(ctx, carrierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCarrierLocation", reflect.TypeOf((*MockRouteRepository)(nil).FetchCarrierLocation), ctx, carrierID)
+}
+
+// MockRouteService is a mock of RouteService interface.
+type MockRouteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRouteServiceMockRecorder
+}
+
+// MockRouteServiceMockRecorder is the mock recorder for MockRouteService.
+type MockRouteServiceMockRecorder struct {
+	mock *MockRouteService
+}
+
+// NewMockRouteService creates a new mock instance.
+func NewMockRouteService(ctrl *gomock.Controller) *MockRouteService {
+	mock := &MockRouteService{ctrl: ctrl}
+	mock.recorder = &MockRouteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRouteService) EXPECT() *MockRouteServiceMockRecorder {
+	return m.recorder
+}
+
+// PlanRoute mocks base method.
+func (m *MockRouteService) PlanRoute(ctx context.Context, carrierID int) (model.Route, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlanRoute", ctx, carrierID)
+	ret0, _ := ret[0].(model.Route)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlanRoute indicates an expected call of PlanRoute.
+func (mr *MockRouteServiceMockRecorder) PlanRoute(ctx, carrierID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlanRoute", reflect.TypeOf((*MockRouteService)(nil).PlanRoute), ctx, carrierID)
+}
+
+// MockRelayRepository is a mock of RelayRepository interface.
+type MockRelayRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelayRepositoryMockRecorder
+}
+
+// MockRelayRepositoryMockRecorder is the mock recorder for MockRelayRepository.
+type MockRelayRepositoryMockRecorder struct {
+	mock *MockRelayRepository
+}
+
+// NewMockRelayRepository creates a new mock instance.
+func NewMockRelayRepository(ctrl *gomock.Controller) *MockRelayRepository {
+	mock := &MockRelayRepository{ctrl: ctrl}
+	mock.recorder = &MockRelayRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelayRepository) EXPECT() *MockRelayRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptLegRequest mocks base method.
+func (m *MockRelayRepository) AcceptLegRequest(ctx context.Context, request model.LegRequest, assignedAt time.Time) (model.LegChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptLegRequest", ctx, request, assignedAt)
+	ret0, _ := ret[0].(model.LegChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptLegRequest indicates an expected call of AcceptLegRequest.
+func (mr *MockRelayRepositoryMockRecorder) AcceptLegRequest(ctx, request, assignedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptLegRequest", reflect.TypeOf((*MockRelayRepository)(nil).AcceptLegRequest), ctx, request, assignedAt)
+}
+
+// FetchHubs mocks base method.
+func (m *MockRelayRepository) FetchHubs(ctx context.Context) ([]model.Hub, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchHubs", ctx)
+	ret0, _ := ret[0].([]model.Hub)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchHubs indicates an expected call of FetchHubs.
+func (mr *MockRelayRepositoryMockRecorder) FetchHubs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchHubs", reflect.TypeOf((*MockRelayRepository)(nil).FetchHubs), ctx)
+}
+
+// FetchLeg mocks base method.
+func (m *MockRelayRepository) FetchLeg(ctx context.Context, legID int) (model.Leg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLeg", ctx, legID)
+	ret0, _ := ret[0].(model.Leg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchLeg indicates an expected call of FetchLeg.
+func (mr *MockRelayRepositoryMockRecorder) FetchLeg(ctx, legID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLeg", reflect.TypeOf((*MockRelayRepository)(nil).FetchLeg), ctx, legID)
+}
+
+// FetchRelay mocks base method.
+func (m *MockRelayRepository) FetchRelay(ctx context.Context, parcelID int) (model.Relay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchRelay", ctx, parcelID)
+	ret0, _ := ret[0].(model.Relay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchRelay indicates an expected call of FetchRelay.
+func (mr *MockRelayRepositoryMockRecorder) FetchRelay(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchRelay", reflect.TypeOf((*MockRelayRepository)(nil).FetchRelay), ctx, parcelID)
+}
+
+// InsertHub mocks base method.
+func (m *MockRelayRepository) InsertHub(ctx context.Context, hub model.Hub) (model.Hub, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertHub", ctx, hub)
+	ret0, _ := ret[0].(model.Hub)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertHub indicates an expected call of InsertHub.
+func (mr *MockRelayRepositoryMockRecorder) InsertHub(ctx, hub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertHub", reflect.TypeOf((*MockRelayRepository)(nil).InsertHub), ctx, hub)
+}
+
+// InsertLegRequest mocks base method.
+func (m *MockRelayRepository) InsertLegRequest(ctx context.Context, request model.LegRequest, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLegRequest", ctx, request, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertLegRequest indicates an expected call of InsertLegRequest.
+func (mr *MockRelayRepositoryMockRecorder) InsertLegRequest(ctx, request, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLegRequest", reflect.TypeOf((*MockRelayRepository)(nil).InsertLegRequest), ctx, request, now)
+}
+
+// InsertLegs mocks base method.
+func (m *MockRelayRepository) InsertLegs(ctx context.Context, split model.RelaySplit) ([]model.Leg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLegs", ctx, split)
+	ret0, _ := ret[0].([]model.Leg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertLegs indicates an expected call of InsertLegs.
+func (mr *MockRelayRepositoryMockRecorder) InsertLegs(ctx, split interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLegs", reflect.TypeOf((*MockRelayRepository)(nil).InsertLegs), ctx, split)
+}
+
+// UpdateLegStatus mocks base method.
+func (m *MockRelayRepository) UpdateLegStatus(ctx context.Context, update model.LegUpdate, now time.Time) (model.LegChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLegStatus", ctx, update, now)
+	ret0, _ := ret[0].(model.LegChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLegStatus indicates an expected call of UpdateLegStatus.
+func (mr *MockRelayRepositoryMockRecorder) UpdateLegStatus(ctx, update, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLegStatus", reflect.TypeOf((*MockRelayRepository)(nil).UpdateLegStatus), ctx, update, now)
+}
+
+// MockRelayService is a mock of RelayService interface.
+type MockRelayService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelayServiceMockRecorder
+}
+
+// MockRelayServiceMockRecorder is the mock recorder for MockRelayService.
+type MockRelayServiceMockRecorder struct {
+	mock *MockRelayService
+}
+
+// NewMockRelayService creates a new mock instance.
+func NewMockRelayService(ctrl *gomock.Controller) *MockRelayService {
+	mock := &MockRelayService{ctrl: ctrl}
+	mock.recorder = &MockRelayServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelayService) EXPECT() *MockRelayServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptLeg mocks base method.
+func (m *MockRelayService) AcceptLeg(ctx context.Context, userID int, request model.LegRequest) (model.LegChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptLeg", ctx, userID, request)
+	ret0, _ := ret[0].(model.LegChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptLeg indicates an expected call of AcceptLeg.
+func (mr *MockRelayServiceMockRecorder) AcceptLeg(ctx, userID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptLeg", reflect.TypeOf((*MockRelayService)(nil).AcceptLeg), ctx, userID, request)
+}
+
+// CreateHub mocks base method.
+func (m *MockRelayService) CreateHub(ctx context.Context, hub model.Hub) (model.Hub, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHub", ctx, hub)
+	ret0, _ := ret[0].(model.Hub)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHub indicates an expected call of CreateHub.
+func (mr *MockRelayServiceMockRecorder) CreateHub(ctx, hub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHub", reflect.TypeOf((*MockRelayService)(nil).CreateHub), ctx, hub)
+}
+
+// GetHubs mocks base method.
+func (m *MockRelayService) GetHubs(ctx context.Context) ([]model.Hub, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHubs", ctx)
+	ret0, _ := ret[0].([]model.Hub)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHubs indicates an expected call of GetHubs.
+func (mr *MockRelayServiceMockRecorder) GetHubs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHubs", reflect.TypeOf((*MockRelayService)(nil).GetHubs), ctx)
+}
+
+// GetRelay mocks base method.
+func (m *MockRelayService) GetRelay(ctx context.Context, parcelID int) (model.Relay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelay", ctx, parcelID)
+	ret0, _ := ret[0].(model.Relay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelay indicates an expected call of GetRelay.
+func (mr *MockRelayServiceMockRecorder) GetRelay(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelay", reflect.TypeOf((*MockRelayService)(nil).GetRelay), ctx, parcelID)
+}
+
+// RequestLeg mocks base method.
+func (m *MockRelayService) RequestLeg(ctx context.Context, request model.LegRequest) (model.LegRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestLeg", ctx, request)
+	ret0, _ := ret[0].(model.LegRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestLeg indicates an expected call of RequestLeg.
+func (mr *MockRelayServiceMockRecorder) RequestLeg(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestLeg", reflect.TypeOf((*MockRelayService)(nil).RequestLeg), ctx, request)
+}
+
+// SplitParcel mocks base method.
+func (m *MockRelayService) SplitParcel(ctx context.Context, split model.RelaySplit) (model.Relay, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SplitParcel", ctx, split)
+	ret0, _ := ret[0].(model.Relay)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SplitParcel indicates an expected call of SplitParcel.
+func (mr *MockRelayServiceMockRecorder) SplitParcel(ctx, split interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SplitParcel", reflect.TypeOf((*MockRelayService)(nil).SplitParcel), ctx, split)
+}
+
+// UpdateLeg mocks base method.
+func (m *MockRelayService) UpdateLeg(ctx context.Context, update model.LegUpdate) (model.LegChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLeg", ctx, update)
+	ret0, _ := ret[0].(model.LegChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLeg indicates an expected call of UpdateLeg.
+func (mr *MockRelayServiceMockRecorder) UpdateLeg(ctx, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLeg", reflect.TypeOf((*MockRelayService)(nil).UpdateLeg), ctx, update)
+}
+
+// MockLabelRepository is a mock of LabelRepository interface.
+type MockLabelRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLabelRepositoryMockRecorder
+}
+
+// MockLabelRepositoryMockRecorder is the mock recorder for MockLabelRepository.
+type MockLabelRepositoryMockRecorder struct {
+	mock *MockLabelRepository
+}
+
+// NewMockLabelRepository creates a new mock instance.
+func NewMockLabelRepository(ctrl *gomock.Controller) *MockLabelRepository {
+	mock := &MockLabelRepository{ctrl: ctrl}
+	mock.recorder = &MockLabelRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLabelRepository) EXPECT() *MockLabelRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchLabel mocks base method.
+func (m *MockLabelRepository) FetchLabel(ctx context.Context, parcelID int) (model.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLabel", ctx, parcelID)
+	ret0, _ := ret[0].(model.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchLabel indicates an expected call of FetchLabel.
+func (mr *MockLabelRepositoryMockRecorder) FetchLabel(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLabel", reflect.TypeOf((*MockLabelRepository)(nil).FetchLabel), ctx, parcelID)
+}
+
+// FetchLabelByCode mocks base method.
+func (m *MockLabelRepository) FetchLabelByCode(ctx context.Context, code string) (model.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchLabelByCode", ctx, code)
+	ret0, _ := ret[0].(model.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchLabelByCode indicates an expected call of FetchLabelByCode.
+func (mr *MockLabelRepositoryMockRecorder) FetchLabelByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchLabelByCode", reflect.TypeOf((*MockLabelRepository)(nil).FetchLabelByCode), ctx, code)
+}
+
+// InsertLegScan mocks base method.
+func (m *MockLabelRepository) InsertLegScan(ctx context.Context, event model.ScanEvent, update model.LegUpdate) (model.ScanEvent, model.LegChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLegScan", ctx, event, update)
+	ret0, _ := ret[0].(model.ScanEvent)
+	ret1, _ := ret[1].(model.LegChange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InsertLegScan indicates an expected call of InsertLegScan.
+func (mr *MockLabelRepositoryMockRecorder) InsertLegScan(ctx, event, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLegScan", reflect.TypeOf((*MockLabelRepository)(nil).InsertLegScan), ctx, event, update)
+}
+
+// InsertParcelScan mocks base method.
+func (m *MockLabelRepository) InsertParcelScan(ctx context.Context, event model.ScanEvent) (model.ScanEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertParcelScan", ctx, event)
+	ret0, _ := ret[0].(model.ScanEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertParcelScan indicates an expected call of InsertParcelScan.
+func (mr *MockLabelRepositoryMockRecorder) InsertParcelScan(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertParcelScan", reflect.TypeOf((*MockLabelRepository)(nil).InsertParcelScan), ctx, event)
+}
+
+// IssueLabel mocks base method.
+func (m *MockLabelRepository) IssueLabel(ctx context.Context, parcelID int, code string) (model.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueLabel", ctx, parcelID, code)
+	ret0, _ := ret[0].(model.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueLabel indicates an expected call of IssueLabel.
+func (mr *MockLabelRepositoryMockRecorder) IssueLabel(ctx, parcelID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueLabel", reflect.TypeOf((*MockLabelRepository)(nil).IssueLabel), ctx, parcelID, code)
+}
+
+// MockLabelService is a mock of LabelService interface.
+type MockLabelService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLabelServiceMockRecorder
+}
+
+// MockLabelServiceMockRecorder is the mock recorder for MockLabelService.
+type MockLabelServiceMockRecorder struct {
+	mock *MockLabelService
+}
+
+// NewMockLabelService creates a new mock instance.
+func NewMockLabelService(ctrl *gomock.Controller) *MockLabelService {
+	mock := &MockLabelService{ctrl: ctrl}
+	mock.recorder = &MockLabelServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLabelService) EXPECT() *MockLabelServiceMockRecorder {
+	return m.recorder
+}
+
+// GetLabel mocks base method.
+func (m *MockLabelService) GetLabel(ctx context.Context, parcelID int) (model.Label, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLabel", ctx, parcelID)
+	ret0, _ := ret[0].(model.Label)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLabel indicates an expected call of GetLabel.
+func (mr *MockLabelServiceMockRecorder) GetLabel(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLabel", reflect.TypeOf((*MockLabelService)(nil).GetLabel), ctx, parcelID)
+}
+
+// RenderPDF mocks base method.
+func (m *MockLabelService) RenderPDF(w io.Writer, label model.Label) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderPDF", w, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenderPDF indicates an expected call of RenderPDF.
+func (mr *MockLabelServiceMockRecorder) RenderPDF(w, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderPDF", reflect.TypeOf((*MockLabelService)(nil).RenderPDF), w, label)
+}
+
+// RenderPNG mocks base method.
+func (m *MockLabelService) RenderPNG(w io.Writer, label model.Label) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderPNG", w, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenderPNG indicates an expected call of RenderPNG.
+func (mr *MockLabelServiceMockRecorder) RenderPNG(w, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderPNG", reflect.TypeOf((*MockLabelService)(nil).RenderPNG), w, label)
+}
+
+// Scan mocks base method.
+func (m *MockLabelService) Scan(ctx context.Context, scan model.Scan) (model.ScanEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", ctx, scan)
+	ret0, _ := ret[0].(model.ScanEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockLabelServiceMockRecorder) Scan(ctx, scan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockLabelService)(nil).Scan), ctx, scan)
+}
+
+// MockExportRepository is a mock of ExportRepository interface.
+type MockExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportRepositoryMockRecorder
+}
+
+// MockExportRepositoryMockRecorder is the mock recorder for MockExportRepository.
+type MockExportRepositoryMockRecorder struct {
+	mock *MockExportRepository
+}
+
+// NewMockExportRepository creates a new mock instance.
+func NewMockExportRepository(ctrl *gomock.Controller) *MockExportRepository {
+	mock := &MockExportRepository{ctrl: ctrl}
+	mock.recorder = &MockExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportRepository) EXPECT() *MockExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// StreamParcels mocks base method.
+func (m *MockExportRepository) StreamParcels(ctx context.Context, filter model.ExportFilter, fn func(model.Parcel) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamParcels", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamParcels indicates an expected call of StreamParcels.
+func (mr *MockExportRepositoryMockRecorder) StreamParcels(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamParcels", reflect.TypeOf((*MockExportRepository)(nil).StreamParcels), ctx, filter, fn)
+}
+
+// MockExportService is a mock of ExportService interface.
+type MockExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportServiceMockRecorder
+}
+
+// MockExportServiceMockRecorder is the mock recorder for MockExportService.
+type MockExportServiceMockRecorder struct {
+	mock *MockExportService
+}
+
+// NewMockExportService creates a new mock instance.
+func NewMockExportService(ctrl *gomock.Controller) *MockExportService {
+	mock := &MockExportService{ctrl: ctrl}
+	mock.recorder = &MockExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportService) EXPECT() *MockExportServiceMockRecorder {
+	return m.recorder
+}
+
+// ExportParcels mocks base method.
+func (m *MockExportService) ExportParcels(ctx context.Context, w io.Writer, format string, filter model.ExportFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportParcels", ctx, w, format, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportParcels indicates an expected call of ExportParcels.
+func (mr *MockExportServiceMockRecorder) ExportParcels(ctx, w, format, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportParcels", reflect.TypeOf((*MockExportService)(nil).ExportParcels), ctx, w, format, filter)
+}
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchDeliveryTimes mocks base method.
+func (m *MockReportRepository) FetchDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchDeliveryTimes", ctx, filter)
+	ret0, _ := ret[0].([]model.DeliveryTimeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchDeliveryTimes indicates an expected call of FetchDeliveryTimes.
+func (mr *MockReportRepositoryMockRecorder) FetchDeliveryTimes(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchDeliveryTimes", reflect.TypeOf((*MockReportRepository)(nil).FetchDeliveryTimes), ctx, filter)
+}
+
+// FetchRevenue mocks base method.
+func (m *MockReportRepository) FetchRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchRevenue", ctx, filter)
+	ret0, _ := ret[0].([]model.RevenueReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchRevenue indicates an expected call of FetchRevenue.
+func (mr *MockReportRepositoryMockRecorder) FetchRevenue(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchRevenue", reflect.TypeOf((*MockReportRepository)(nil).FetchRevenue), ctx, filter)
+}
+
+// FetchTopCarriers mocks base method.
+func (m *MockReportRepository) FetchTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchTopCarriers", ctx, filter)
+	ret0, _ := ret[0].([]model.CarrierReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchTopCarriers indicates an expected call of FetchTopCarriers.
+func (mr *MockReportRepositoryMockRecorder) FetchTopCarriers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchTopCarriers", reflect.TypeOf((*MockReportRepository)(nil).FetchTopCarriers), ctx, filter)
+}
+
+// FetchVolumes mocks base method.
+func (m *MockReportRepository) FetchVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchVolumes", ctx, filter)
+	ret0, _ := ret[0].([]model.VolumeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchVolumes indicates an expected call of FetchVolumes.
+func (mr *MockReportRepositoryMockRecorder) FetchVolumes(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchVolumes", reflect.TypeOf((*MockReportRepository)(nil).FetchVolumes), ctx, filter)
+}
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetDeliveryTimes mocks base method.
+func (m *MockReportService) GetDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryTimes", ctx, filter)
+	ret0, _ := ret[0].([]model.DeliveryTimeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryTimes indicates an expected call of GetDeliveryTimes.
+func (mr *MockReportServiceMockRecorder) GetDeliveryTimes(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryTimes", reflect.TypeOf((*MockReportService)(nil).GetDeliveryTimes), ctx, filter)
+}
+
+// GetRevenue mocks base method.
+func (m *MockReportService) GetRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevenue", ctx, filter)
+	ret0, _ := ret[0].([]model.RevenueReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevenue indicates an expected call of GetRevenue.
+func (mr *MockReportServiceMockRecorder) GetRevenue(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevenue", reflect.TypeOf((*MockReportService)(nil).GetRevenue), ctx, filter)
+}
+
+// GetTopCarriers mocks base method.
+func (m *MockReportService) GetTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopCarriers", ctx, filter)
+	ret0, _ := ret[0].([]model.CarrierReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopCarriers indicates an expected call of GetTopCarriers.
+func (mr *MockReportServiceMockRecorder) GetTopCarriers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopCarriers", reflect.TypeOf((*MockReportService)(nil).GetTopCarriers), ctx, filter)
+}
+
+// GetVolumes mocks base method.
+func (m *MockReportService) GetVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVolumes", ctx, filter)
+	ret0, _ := ret[0].([]model.VolumeReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVolumes indicates an expected call of GetVolumes.
+func (mr *MockReportServiceMockRecorder) GetVolumes(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumes", reflect.TypeOf((*MockReportService)(nil).GetVolumes), ctx, filter)
+}
+
+// MockConsoleRepository is a mock of ConsoleRepository interface.
+type MockConsoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsoleRepositoryMockRecorder
+}
+
+// MockConsoleRepositoryMockRecorder is the mock recorder for MockConsoleRepository.
+type MockConsoleRepositoryMockRecorder struct {
+	mock *MockConsoleRepository
+}
+
+// NewMockConsoleRepository creates a new mock instance.
+func NewMockConsoleRepository(ctrl *gomock.Controller) *MockConsoleRepository {
+	mock := &MockConsoleRepository{ctrl: ctrl}
+	mock.recorder = &MockConsoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsoleRepository) EXPECT() *MockConsoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchCarriers mocks base method.
+func (m *MockConsoleRepository) FetchCarriers(ctx context.Context, carrierID, limit, offset int) ([]model.CarrierProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCarriers", ctx, carrierID, limit, offset)
+	ret0, _ := ret[0].([]model.CarrierProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCarriers indicates an expected call of FetchCarriers.
+func (mr *MockConsoleRepositoryMockRecorder) FetchCarriers(ctx, carrierID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCarriers", reflect.TypeOf((*MockConsoleRepository)(nil).FetchCarriers), ctx, carrierID, limit, offset)
+}
+
+// FetchParcelEvents mocks base method.
+func (m *MockConsoleRepository) FetchParcelEvents(ctx context.Context, parcelID int) ([]model.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchParcelEvents", ctx, parcelID)
+	ret0, _ := ret[0].([]model.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchParcelEvents indicates an expected call of FetchParcelEvents.
+func (mr *MockConsoleRepositoryMockRecorder) FetchParcelEvents(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchParcelEvents", reflect.TypeOf((*MockConsoleRepository)(nil).FetchParcelEvents), ctx, parcelID)
+}
+
+// FetchParcelRequests mocks base method.
+func (m *MockConsoleRepository) FetchParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchParcelRequests", ctx, parcelID)
+	ret0, _ := ret[0].([]model.CarrierRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchParcelRequests indicates an expected call of FetchParcelRequests.
+func (mr *MockConsoleRepositoryMockRecorder) FetchParcelRequests(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchParcelRequests", reflect.TypeOf((*MockConsoleRepository)(nil).FetchParcelRequests), ctx, parcelID)
+}
+
+// ForceParcelStatus mocks base method.
+func (m *MockConsoleRepository) ForceParcelStatus(ctx context.Context, override model.StatusOverride, now time.Time) (model.StatusOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceParcelStatus", ctx, override, now)
+	ret0, _ := ret[0].(model.StatusOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceParcelStatus indicates an expected call of ForceParcelStatus.
+func (mr *MockConsoleRepositoryMockRecorder) ForceParcelStatus(ctx, override, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceParcelStatus", reflect.TypeOf((*MockConsoleRepository)(nil).ForceParcelStatus), ctx, override, now)
+}
+
+// SearchParcels mocks base method.
+func (m *MockConsoleRepository) SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchParcels", ctx, search)
+	ret0, _ := ret[0].([]model.Parcel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchParcels indicates an expected call of SearchParcels.
+func (mr *MockConsoleRepositoryMockRecorder) SearchParcels(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchParcels", reflect.TypeOf((*MockConsoleRepository)(nil).SearchParcels), ctx, search)
+}
+
+// UpdateCarrierSuspension mocks base method.
+func (m *MockConsoleRepository) UpdateCarrierSuspension(ctx context.Context, suspension model.CarrierSuspension) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCarrierSuspension", ctx, suspension)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCarrierSuspension indicates an expected call of UpdateCarrierSuspension.
+func (mr *MockConsoleRepositoryMockRecorder) UpdateCarrierSuspension(ctx, suspension interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCarrierSuspension", reflect.TypeOf((*MockConsoleRepository)(nil).UpdateCarrierSuspension), ctx, suspension)
+}
+
+// MockConsoleService is a mock of ConsoleService interface.
+type MockConsoleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsoleServiceMockRecorder
+}
+
+// MockConsoleServiceMockRecorder is the mock recorder for MockConsoleService.
+type MockConsoleServiceMockRecorder struct {
+	mock *MockConsoleService
+}
+
+// NewMockConsoleService creates a new mock instance.
+func NewMockConsoleService(ctrl *gomock.Controller) *MockConsoleService {
+	mock := &MockConsoleService{ctrl: ctrl}
+	mock.recorder = &MockConsoleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsoleService) EXPECT() *MockConsoleServiceMockRecorder {
+	return m.recorder
+}
+
+// ForceStatus mocks base method.
+func (m *MockConsoleService) ForceStatus(ctx context.Context, override model.StatusOverride) (model.StatusOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceStatus", ctx, override)
+	ret0, _ := ret[0].(model.StatusOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceStatus indicates an expected call of ForceStatus.
+func (mr *MockConsoleServiceMockRecorder) ForceStatus(ctx, override interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceStatus", reflect.TypeOf((*MockConsoleService)(nil).ForceStatus), ctx, override)
+}
+
+// GetCarriers mocks base method.
+func (m *MockConsoleService) GetCarriers(ctx context.Context, carrierID, limit, offset int) ([]model.CarrierProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCarriers", ctx, carrierID, limit, offset)
+	ret0, _ := ret[0].([]model.CarrierProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCarriers indicates an expected call of GetCarriers.
+func (mr *MockConsoleServiceMockRecorder) GetCarriers(ctx, carrierID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCarriers", reflect.TypeOf((*MockConsoleService)(nil).GetCarriers), ctx, carrierID, limit, offset)
+}
+
+// GetParcelHistory mocks base method.
+func (m *MockConsoleService) GetParcelHistory(ctx context.Context, parcelID int) ([]model.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParcelHistory", ctx, parcelID)
+	ret0, _ := ret[0].([]model.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParcelHistory indicates an expected call of GetParcelHistory.
+func (mr *MockConsoleServiceMockRecorder) GetParcelHistory(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParcelHistory", reflect.TypeOf((*MockConsoleService)(nil).GetParcelHistory), ctx, parcelID)
+}
+
+// GetParcelRequests mocks base method.
+func (m *MockConsoleService) GetParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParcelRequests", ctx, parcelID)
+	ret0, _ := ret[0].([]model.CarrierRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParcelRequests indicates an expected call of GetParcelRequests.
+func (mr *MockConsoleServiceMockRecorder) GetParcelRequests(ctx, parcelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParcelRequests", reflect.TypeOf((*MockConsoleService)(nil).GetParcelRequests), ctx, parcelID)
+}
+
+// SearchParcels mocks base method.
+func (m *MockConsoleService) SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchParcels", ctx, search)
+	ret0, _ := ret[0].([]model.Parcel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchParcels indicates an expected call of SearchParcels.
+func (mr *MockConsoleServiceMockRecorder) SearchParcels(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchParcels", reflect.TypeOf((*MockConsoleService)(nil).SearchParcels), ctx, search)
+}
+
+// SuspendCarrier mocks base method.
+func (m *MockConsoleService) SuspendCarrier(ctx context.Context, suspension model.CarrierSuspension) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendCarrier", ctx, suspension)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendCarrier indicates an expected call of SuspendCarrier.
+func (mr *MockConsoleServiceMockRecorder) SuspendCarrier(ctx, suspension interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendCarrier", reflect.TypeOf((*MockConsoleService)(nil).SuspendCarrier), ctx, suspension)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FetchEntries mocks base method.
+func (m *MockAuditRepository) FetchEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchEntries", ctx, filter)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchEntries indicates an expected call of FetchEntries.
+func (mr *MockAuditRepositoryMockRecorder) FetchEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchEntries", reflect.TypeOf((*MockAuditRepository)(nil).FetchEntries), ctx, filter)
+}
+
+// MockAuditService is a mock of AuditService interface.
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService.
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance.
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// GetEntries mocks base method.
+func (m *MockAuditService) GetEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", ctx, filter)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockAuditServiceMockRecorder) GetEntries(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockAuditService)(nil).GetEntries), ctx, filter)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"parcel-service/internal/app/model"
 	"time"
 )
@@ -9,9 +10,12 @@ import (
 // ParcelRepository to Insert New Parcel and get parcel list
 type ParcelRepository interface {
 	InsertParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error)
+	InsertParcels(ctx context.Context, parcels []model.Parcel) ([]model.Parcel, error)
 	FetchParcelByID(ctx context.Context, parcelID int) (model.Parcel, error)
 	GetParcelsList(ctx context.Context, status int, limit int, offset int) ([]model.Parcel, error)
 	UpdateParcel(ctx context.Context, parcel model.Parcel) error
+	CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error)
+	RecordFailedAttempt(ctx context.Context, attempt model.DeliveryAttempt, maxAttempts int, returnParcel *model.Parcel) (model.DeliveryAttempt, error)
 }
 
 // ParcelService to Create new parcel & get parcel list
@@ -20,14 +24,301 @@ type ParcelService interface {
 	GetParcelByID(ctx context.Context, parcelID int) (model.Parcel, error)
 	GetParcels(ctx context.Context, status int, limit int, offset int) ([]model.Parcel, error)
 	EditParcel(ctx context.Context, parcel model.Parcel) error
+	CancelParcel(ctx context.Context, cancellation model.Cancellation) (model.Cancellation, error)
+	FailDelivery(ctx context.Context, attempt model.DeliveryAttempt) (model.DeliveryAttempt, error)
+	PriceParcel(ctx context.Context, parcel model.Parcel) (model.Parcel, error)
+	ImportParcels(ctx context.Context, userID int, rows []model.BulkRow) (model.BulkReport, error)
 }
 
 type CarrierRepository interface {
 	InsertCarrierRequest(ctx context.Context, carrierReq model.CarrierRequest) error
-	UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus int, rejectStatus int, parcelStatus int, sourceTime time.Time) error
+	UpdateCarrierRequest(ctx context.Context, parcel model.CarrierRequest, acceptStatus int, rejectStatus int, parcelStatus int, assignedAt time.Time) error
+	UpdateCarrierLocation(ctx context.Context, location model.CarrierLocation) ([]int, error)
+	FetchCarrierRatings(ctx context.Context, carrierIDs []int) ([]model.CarrierRating, error)
+	ExpireCarrierRequests(ctx context.Context, now time.Time) (int64, error)
+	FetchCarrierRequests(ctx context.Context, parcelID int, now time.Time) ([]model.CarrierRequest, error)
 }
 
 type CarrierService interface {
 	NewCarrierRequest(ctx context.Context, carrierReq model.CarrierRequest) error
 	AssignCarrierToParcel(ctx context.Context, parcel model.CarrierRequest) error
+	UpdateLocation(ctx context.Context, location model.CarrierLocation) error
+	GetCarrierRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error)
+}
+
+// PromotionRepository to store promo codes and count their redemptions
+type PromotionRepository interface {
+	InsertPromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error)
+	FetchPromotionByCode(ctx context.Context, code string) (model.Promotion, error)
+	CountUserRedemptions(ctx context.Context, promotionID int, userID int) (int, error)
+}
+
+// PromotionService to manage promo codes and calculate discounts
+type PromotionService interface {
+	CreatePromotion(ctx context.Context, promotion model.Promotion) (model.Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (model.Promotion, error)
+	ApplyPromotion(ctx context.Context, code string, userID int, price float32) (model.Promotion, float32, error)
+}
+
+// InvoiceRepository to issue invoices with sequential numbers
+type InvoiceRepository interface {
+	FetchInvoiceByParcelID(ctx context.Context, parcelID int) (model.Invoice, error)
+	IssueInvoice(ctx context.Context, parcelID int) (model.Invoice, error)
+}
+
+// InvoiceService to get invoices of delivered parcels and render them as documents
+type InvoiceService interface {
+	GetInvoice(ctx context.Context, parcelID int) (model.Invoice, error)
+	RenderHTML(w io.Writer, invoice model.Invoice) error
+	RenderPDF(w io.Writer, invoice model.Invoice) error
+}
+
+// TaxRepository to store tax rules
+type TaxRepository interface {
+	InsertTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error)
+	FetchTaxRules(ctx context.Context) ([]model.TaxRule, error)
+}
+
+// TaxService to manage tax rules and calculate the tax of parcel pricing
+type TaxService interface {
+	CreateTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error)
+	GetTaxRules(ctx context.Context) ([]model.TaxRule, error)
+	CalculateTax(ctx context.Context, parcel model.Parcel) (model.Tax, error)
+}
+
+// Notifier delivers a rendered notification over a single channel such as sms, email or push
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, notification model.Notification) error
+}
+
+// EventNotifier accepts parcel events for asynchronous delivery, it never blocks the caller
+type EventNotifier interface {
+	Notify(ctx context.Context, event model.Event)
+}
+
+// WebhookRepository to store webhook subscriptions and their delivery log
+type WebhookRepository interface {
+	InsertSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	FetchSubscriptionByID(ctx context.Context, subscriptionID int) (model.WebhookSubscription, error)
+	FetchSubscriptionsByUserID(ctx context.Context, userID int) ([]model.WebhookSubscription, error)
+	InsertDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error)
+	InsertReplay(ctx context.Context, delivery model.WebhookDelivery, originalID int) (model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	FetchDeliveryByID(ctx context.Context, deliveryID int) (model.WebhookDelivery, error)
+	FetchDeliveriesBySubscriptionID(ctx context.Context, subscriptionID int, limit int, offset int) ([]model.WebhookDelivery, error)
+}
+
+// WebhookService to manage webhook subscriptions and inspect or replay their deliveries
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context, userID int) ([]model.WebhookSubscription, error)
+	GetDeliveries(ctx context.Context, userID int, subscriptionID int, limit int, offset int) ([]model.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, userID int, deliveryID int) (model.WebhookDelivery, error)
+}
+
+// OutboxRepository to read pending outbox messages and record their publishing
+type OutboxRepository interface {
+	FetchPendingMessages(ctx context.Context, limit int) ([]model.OutboxMessage, error)
+	MarkMessageSent(ctx context.Context, messageID int64) error
+	MarkMessageFailed(ctx context.Context, messageID int64, reason string) error
+}
+
+// Publisher sends an outbox message payload to a message broker topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+}
+
+// Broadcaster streams events to in-process subscribers of a parcel or carrier topic
+type Broadcaster interface {
+	Subscribe(topic string, lastEventID int64) (<-chan model.StreamEvent, func())
+	Close()
+}
+
+// Authenticator verifies access tokens and returns the claims of their holder
+type Authenticator interface {
+	Verify(token string) (model.Claims, error)
+}
+
+// JobFeed pushes newly created parcels to the carriers looking for work near their pickup address
+// and the offers of auto-dispatched parcels to the carrier they are offered to
+type JobFeed interface {
+	Subscribe(carrierID int) (JobSubscription, error)
+	Available() map[int]model.JobFilter
+	Publish(parcel model.Parcel)
+	Offer(offer model.Offer) bool
+	Close()
+}
+
+// JobSubscription receives the jobs matching the latest filter of one carrier connection and the offers made to the carrier
+type JobSubscription interface {
+	Jobs() <-chan model.Job
+	Offers() <-chan model.Offer
+	Update(filter model.JobFilter)
+	Close()
+}
+
+// Dispatcher offers auto-dispatched parcels to the best ranked available carriers one at a time
+type Dispatcher interface {
+	Respond(ctx context.Context, carrierID int, offerID int64, accept bool) error
+}
+
+// SLARepository to record the parcels that missed their SLA deadlines
+type SLARepository interface {
+	InsertBreaches(ctx context.Context, now time.Time) ([]model.SLABreach, error)
+	FetchBreaches(ctx context.Context, kind string, limit int, offset int) ([]model.SLABreach, error)
+}
+
+// SLAService to list the SLA breaches of parcels
+type SLAService interface {
+	GetBreaches(ctx context.Context, kind string, limit int, offset int) ([]model.SLABreach, error)
+}
+
+// ReviewRepository to store reviews and keep the aggregate rating of the rated carrier or sender
+type ReviewRepository interface {
+	InsertReview(ctx context.Context, review model.Review) (model.Review, error)
+}
+
+// ReviewService to rate the carrier or the sender of a delivered parcel
+type ReviewService interface {
+	CreateReview(ctx context.Context, review model.Review) (model.Review, error)
+}
+
+// ClaimRepository to store claims on parcels and post the compensation of approved claims
+type ClaimRepository interface {
+	InsertClaim(ctx context.Context, claim model.Claim) (model.Claim, error)
+	FetchClaimByID(ctx context.Context, claimID int) (model.Claim, error)
+	FetchClaims(ctx context.Context, status string, limit int, offset int) ([]model.Claim, error)
+	DecideClaim(ctx context.Context, claim model.Claim, previousStatus string) (model.Claim, error)
+}
+
+// ClaimService to open claims on damaged or lost parcels and let admins decide them
+type ClaimService interface {
+	OpenClaim(ctx context.Context, claim model.Claim) (model.Claim, error)
+	GetClaim(ctx context.Context, claimID int) (model.Claim, error)
+	GetClaims(ctx context.Context, status string, limit int, offset int) ([]model.Claim, error)
+	DecideClaim(ctx context.Context, decision model.ClaimDecision) (model.Claim, error)
+}
+
+// ShipmentRepository to store shipments with their parcels and assign a carrier to all of them at once
+type ShipmentRepository interface {
+	InsertShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error)
+	FetchShipmentByID(ctx context.Context, shipmentID int) (model.Shipment, error)
+	InsertShipmentRequest(ctx context.Context, request model.ShipmentRequest) ([]int, error)
+	AcceptShipmentRequest(ctx context.Context, request model.ShipmentRequest, assignedAt time.Time) ([]int, error)
+}
+
+// ShipmentService to book several parcels from one pickup point and let one carrier deliver them all
+type ShipmentService interface {
+	CreateShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error)
+	GetShipment(ctx context.Context, shipmentID int) (model.Shipment, error)
+	RequestShipment(ctx context.Context, request model.ShipmentRequest) (model.ShipmentRequest, error)
+	AcceptShipment(ctx context.Context, userID int, request model.ShipmentRequest) (model.Shipment, error)
+}
+
+// RouteRepository to read the parcels a carrier is delivering and its last location
+type RouteRepository interface {
+	FetchActiveParcels(ctx context.Context, carrierID int) ([]model.Parcel, error)
+	FetchCarrierLocation(ctx context.Context, carrierID int) (model.CarrierLocation, error)
+}
+
+// RouteService to plan the order in which a carrier picks up and drops its parcels
+type RouteService interface {
+	PlanRoute(ctx context.Context, carrierID int) (model.Route, error)
+}
+
+// RelayRepository to store hubs and the legs of relayed parcels with their requests and custody transfers
+type RelayRepository interface {
+	InsertHub(ctx context.Context, hub model.Hub) (model.Hub, error)
+	FetchHubs(ctx context.Context) ([]model.Hub, error)
+	InsertLegs(ctx context.Context, split model.RelaySplit) ([]model.Leg, error)
+	FetchRelay(ctx context.Context, parcelID int) (model.Relay, error)
+	FetchLeg(ctx context.Context, legID int) (model.Leg, error)
+	InsertLegRequest(ctx context.Context, request model.LegRequest, now time.Time) error
+	AcceptLegRequest(ctx context.Context, request model.LegRequest, assignedAt time.Time) (model.LegChange, error)
+	UpdateLegStatus(ctx context.Context, update model.LegUpdate, now time.Time) (model.LegChange, error)
+}
+
+// RelayService to relay long-distance parcels through hubs, each leg delivered by its own carrier
+type RelayService interface {
+	CreateHub(ctx context.Context, hub model.Hub) (model.Hub, error)
+	GetHubs(ctx context.Context) ([]model.Hub, error)
+	SplitParcel(ctx context.Context, split model.RelaySplit) (model.Relay, error)
+	GetRelay(ctx context.Context, parcelID int) (model.Relay, error)
+	RequestLeg(ctx context.Context, request model.LegRequest) (model.LegRequest, error)
+	AcceptLeg(ctx context.Context, userID int, request model.LegRequest) (model.LegChange, error)
+	UpdateLeg(ctx context.Context, update model.LegUpdate) (model.LegChange, error)
+}
+
+// LabelRepository to store the tracking codes of parcel labels and the scans of those labels
+type LabelRepository interface {
+	FetchLabel(ctx context.Context, parcelID int) (model.Label, error)
+	FetchLabelByCode(ctx context.Context, code string) (model.Label, error)
+	IssueLabel(ctx context.Context, parcelID int, code string) (model.Label, error)
+	InsertParcelScan(ctx context.Context, event model.ScanEvent) (model.ScanEvent, error)
+	InsertLegScan(ctx context.Context, event model.ScanEvent, update model.LegUpdate) (model.ScanEvent, model.LegChange, error)
+}
+
+// LabelService to print shipping labels and advance parcels when carriers and hubs scan them
+type LabelService interface {
+	GetLabel(ctx context.Context, parcelID int) (model.Label, error)
+	RenderPNG(w io.Writer, label model.Label) error
+	RenderPDF(w io.Writer, label model.Label) error
+	Scan(ctx context.Context, scan model.Scan) (model.ScanEvent, error)
+}
+
+// ExportRepository to read the parcels of an export from a cursor
+type ExportRepository interface {
+	StreamParcels(ctx context.Context, filter model.ExportFilter, fn func(model.Parcel) error) error
+}
+
+// ExportService to stream parcels as CSV or NDJSON for reporting
+type ExportService interface {
+	ExportParcels(ctx context.Context, w io.Writer, format string, filter model.ExportFilter) (int, error)
+}
+
+// ReportRepository to aggregate parcels and carrier requests for reporting
+type ReportRepository interface {
+	FetchVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error)
+	FetchRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error)
+	FetchDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error)
+	FetchTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error)
+}
+
+// ReportService to report parcel volumes, revenue and carrier performance to admins
+type ReportService interface {
+	GetVolumes(ctx context.Context, filter model.ReportFilter) ([]model.VolumeReport, error)
+	GetRevenue(ctx context.Context, filter model.ReportFilter) ([]model.RevenueReport, error)
+	GetDeliveryTimes(ctx context.Context, filter model.ReportFilter) ([]model.DeliveryTimeReport, error)
+	GetTopCarriers(ctx context.Context, filter model.ReportFilter) ([]model.CarrierReport, error)
+}
+
+// ConsoleRepository to search parcels, read their history and manage carriers from the admin console
+type ConsoleRepository interface {
+	SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error)
+	FetchParcelEvents(ctx context.Context, parcelID int) ([]model.OutboxMessage, error)
+	FetchParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error)
+	ForceParcelStatus(ctx context.Context, override model.StatusOverride, now time.Time) (model.StatusOverride, error)
+	FetchCarriers(ctx context.Context, carrierID int, limit int, offset int) ([]model.CarrierProfile, error)
+	UpdateCarrierSuspension(ctx context.Context, suspension model.CarrierSuspension) error
+}
+
+// ConsoleService to support parcels and carriers from the admin console
+type ConsoleService interface {
+	SearchParcels(ctx context.Context, search model.ParcelSearch) ([]model.Parcel, error)
+	GetParcelHistory(ctx context.Context, parcelID int) ([]model.Event, error)
+	GetParcelRequests(ctx context.Context, parcelID int) ([]model.CarrierRequest, error)
+	ForceStatus(ctx context.Context, override model.StatusOverride) (model.StatusOverride, error)
+	GetCarriers(ctx context.Context, carrierID int, limit int, offset int) ([]model.CarrierProfile, error)
+	SuspendCarrier(ctx context.Context, suspension model.CarrierSuspension) error
+}
+
+// AuditRepository to read the audit log, entries are written by the repositories making the changes
+type AuditRepository interface {
+	FetchEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
+}
+
+// AuditService to let admins search who changed what
+type AuditService interface {
+	GetEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error)
 }
@@ -0,0 +1,230 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/outbox"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	insertShipmentQuery = `INSERT INTO shipment (user_id, source_address, source_time, source_latitude, source_longitude, pickup_window_start, pickup_window_end, ordered) VALUES (:user_id, :source_address, :source_time, :source_latitude, :source_longitude, :pickup_window_start, :pickup_window_end, :ordered) RETURNING id, created_at`
+	insertParcelQuery   = `INSERT INTO parcel (user_id, shipment_id, stop, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, region, tax, tax_name, tax_rate, tax_inclusive, pickup_deadline, delivery_deadline) VALUES (:user_id, :shipment_id, :stop, :source_address, :destination_address, :source_time, :source_latitude, :source_longitude, :destination_latitude, :destination_longitude, :weight, :declared_value, :pickup_window_start, :pickup_window_end, :delivery_window_start, :delivery_window_end, :type, :price, :carrier_fee, :company_fee, :region, :tax, :tax_name, :tax_rate, :tax_inclusive, :pickup_deadline, :delivery_deadline) RETURNING id, status, created_at, updated_at`
+	insertPaymentQuery  = `INSERT INTO payment (parcel_id, user_id, amount) VALUES ($1, $2, $3)`
+	fetchShipmentQuery  = `SELECT id, user_id, carrier_id, source_address, source_time, source_latitude, source_longitude, pickup_window_start, pickup_window_end, ordered, created_at FROM shipment WHERE id = $1`
+	fetchParcelsQuery   = `SELECT id, user_id, carrier_id, status, source_address, destination_address, source_time, source_latitude, source_longitude, destination_latitude, destination_longitude, weight, declared_value, auto_dispatch, pickup_window_start, pickup_window_end, delivery_window_start, delivery_window_end, type, price, carrier_fee, company_fee, discount, region, tax, tax_name, tax_rate, tax_inclusive, assigned_at, picked_up_at, delivered_at, pickup_deadline, delivery_deadline, COALESCE(return_of, 0) AS return_of, COALESCE(shipment_id, 0) AS shipment_id, stop, created_at, updated_at FROM parcel WHERE shipment_id = $1 ORDER BY stop, id`
+	insertRequestQuery  = `INSERT INTO carrier_request (carrier_id, parcel_id, expires_at) SELECT $1, id, $3 FROM parcel WHERE shipment_id = $2 AND status = $4 ON CONFLICT (parcel_id, carrier_id) DO UPDATE SET status = $5, expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP WHERE carrier_request.status = $6 RETURNING parcel_id`
+	lockParcelsQuery    = `SELECT id, status FROM parcel WHERE shipment_id = $1 ORDER BY id FOR UPDATE`
+	acceptRequestsQuery = `UPDATE carrier_request SET status = $1 WHERE parcel_id = ANY($2) AND carrier_id = $3 AND status = $4 AND expires_at > $5`
+	rejectRequestsQuery = `UPDATE carrier_request SET status = $1 WHERE parcel_id = ANY($2) AND carrier_id != $3`
+	assignParcelsQuery  = `UPDATE parcel SET carrier_id = $1, status = $2, assigned_at = $3 WHERE shipment_id = $4`
+	assignShipmentQuery = `UPDATE shipment SET carrier_id = $1 WHERE id = $2`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates shipment repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// InsertShipment stores the shipment with its priced parcels and their payments in one transaction
+func (r *repository) InsertShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertShipment] failed to begin transaction")
+		return model.Shipment{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertShipmentQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertShipment] PrepareNamedContext Error: %v", err)
+		return model.Shipment{}, err
+	}
+	if err := stmt.GetContext(ctx, &shipment, &shipment); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertShipment] failed to insert shipment Error: %v", err)
+		return model.Shipment{}, err
+	}
+	// the parcels are audited one by one as they are stored
+	header := shipment
+	header.Parcels = nil
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditShipment, shipment.ID, nil, header); err != nil {
+		tx.Rollback()
+		return model.Shipment{}, err
+	}
+
+	stmt, err = tx.PrepareNamedContext(ctx, insertParcelQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertShipment] PrepareNamedContext Error: %v", err)
+		return model.Shipment{}, err
+	}
+	for i := range shipment.Parcels {
+		parcel := &shipment.Parcels[i]
+		parcel.ShipmentID = shipment.ID
+		if err := stmt.GetContext(ctx, parcel, parcel); err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[InsertShipment] failed to insert parcel Error: %v", err)
+			return model.Shipment{}, err
+		}
+		if _, err := tx.ExecContext(ctx, insertPaymentQuery, parcel.ID, parcel.UserID, parcel.Price); err != nil {
+			tx.Rollback()
+			log.Error().Err(err).Msgf("[InsertShipment] failed to insert payment Error: %v", err)
+			return model.Shipment{}, err
+		}
+		if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditParcel, parcel.ID, nil, *parcel); err != nil {
+			tx.Rollback()
+			return model.Shipment{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertShipment] failed to commit")
+		return model.Shipment{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return shipment, nil
+}
+
+// FetchShipmentByID returns the shipment with its parcels in drop order
+func (r *repository) FetchShipmentByID(ctx context.Context, shipmentID int) (model.Shipment, error) {
+	var shipment model.Shipment
+	if err := r.db.GetContext(ctx, &shipment, fetchShipmentQuery, shipmentID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Shipment{}, fmt.Errorf("shipment with the ID %d is not found. :%w", shipmentID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchShipmentByID] failed to fetch shipment %d Error: %v", shipmentID, err)
+		return model.Shipment{}, err
+	}
+
+	shipment.Parcels = []model.Parcel{}
+	if err := r.db.SelectContext(ctx, &shipment.Parcels, fetchParcelsQuery, shipmentID); err != nil {
+		log.Error().Err(err).Msgf("[FetchShipmentByID] failed to fetch parcels of shipment %d Error: %v", shipmentID, err)
+		return model.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+// InsertShipmentRequest stores a pending request of the carrier for every parcel of the shipment that still needs
+// a carrier and returns those parcels. Expired requests of the carrier are renewed, other existing requests are kept.
+func (r *repository) InsertShipmentRequest(ctx context.Context, request model.ShipmentRequest) ([]int, error) {
+	parcelIDs := []int{}
+	err := r.db.SelectContext(ctx, &parcelIDs, insertRequestQuery, request.CarrierID, request.ShipmentID, request.ExpiresAt,
+		model.ParcelStatusCreated, model.CarrierRequestPending, model.CarrierRequestExpired)
+	if err != nil {
+		log.Error().Err(err).Msgf("[InsertShipmentRequest] failed to insert requests of carrier %d Error: %v", request.CarrierID, err)
+		return nil, err
+	}
+	if len(parcelIDs) == 0 {
+		return nil, fmt.Errorf("carrier %d has already requested shipment %d or it has no parcels left to deliver :%w", request.CarrierID, request.ShipmentID, model.ErrInvalid)
+	}
+	return parcelIDs, nil
+}
+
+// AcceptShipmentRequest assigns the carrier to every parcel of the shipment in one transaction, like the
+// acceptance of a single carrier request. The carrier needs an open request for each parcel and every parcel
+// must still be waiting for a carrier, otherwise nothing is assigned. The assigned parcels are returned.
+func (r *repository) AcceptShipmentRequest(ctx context.Context, request model.ShipmentRequest, assignedAt time.Time) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[AcceptShipmentRequest] failed to begin transaction")
+		return nil, err
+	}
+
+	// the lock keeps the parcels from being assigned or cancelled one by one while the shipment is accepted
+	var parcels []model.Parcel
+	if err := tx.SelectContext(ctx, &parcels, lockParcelsQuery, request.ShipmentID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptShipmentRequest] failed to lock parcels Error: %v", err)
+		return nil, err
+	}
+	if len(parcels) == 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("shipment with the ID %d is not found. :%w", request.ShipmentID, model.ErrNotFound)
+	}
+
+	parcelIDs := make([]int, 0, len(parcels))
+	for _, parcel := range parcels {
+		if parcel.Status != model.ParcelStatusCreated {
+			tx.Rollback()
+			return nil, fmt.Errorf("parcel %d of shipment %d is %s :%w", parcel.ID, request.ShipmentID, model.ParcelStatusName(parcel.Status), model.ErrInvalid)
+		}
+		parcelIDs = append(parcelIDs, parcel.ID)
+	}
+
+	result, err := tx.ExecContext(ctx, acceptRequestsQuery, model.CarrierRequestAccepted, pq.Array(parcelIDs), request.CarrierID, model.CarrierRequestPending, assignedAt)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptShipmentRequest] failed to accept requests Error: %v", err)
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if rows != int64(len(parcelIDs)) {
+		tx.Rollback()
+		return nil, fmt.Errorf("carrier %d has no open request for every parcel of shipment %d :%w", request.CarrierID, request.ShipmentID, model.ErrInvalid)
+	}
+
+	if _, err := tx.ExecContext(ctx, rejectRequestsQuery, model.CarrierRequestRejected, pq.Array(parcelIDs), request.CarrierID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptShipmentRequest] failed to reject requests Error: %v", err)
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, assignParcelsQuery, request.CarrierID, model.ParcelStatusAssigned, assignedAt, request.ShipmentID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptShipmentRequest] failed to assign parcels Error: %v", err)
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, assignShipmentQuery, request.CarrierID, request.ShipmentID); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[AcceptShipmentRequest] failed to assign shipment Error: %v", err)
+		return nil, err
+	}
+	for _, parcelID := range parcelIDs {
+		if err := outbox.Write(ctx, tx, model.Event{
+			Type:      model.EventCarrierAssigned,
+			ParcelID:  parcelID,
+			CarrierID: request.CarrierID,
+			Status:    model.ParcelStatusAssigned,
+		}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := audit.Write(ctx, tx, model.AuditAccept, model.AuditParcel, parcelID,
+			map[string]interface{}{"status": model.ParcelStatusCreated, "carrier_id": 0},
+			map[string]interface{}{"status": model.ParcelStatusAssigned, "carrier_id": request.CarrierID, "assigned_at": assignedAt},
+		); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := audit.Write(ctx, tx, model.AuditAccept, model.AuditShipment, request.ShipmentID,
+		map[string]interface{}{"carrier_id": 0},
+		map[string]interface{}{"carrier_id": request.CarrierID},
+	); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[AcceptShipmentRequest] failed to commit")
+		return nil, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return parcelIDs, nil
+}
@@ -0,0 +1,301 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_InsertShipment(t *testing.T) {
+	createdAt := time.Now()
+	shipment := model.Shipment{
+		UserID:        3,
+		SourceAddress: "Dhaka Bangladesh",
+		Ordered:       true,
+		Parcels: []model.Parcel{
+			{UserID: 3, SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Pabna Shadar", ParcelType: "Document", Price: 200, Stop: 1},
+			{UserID: 3, SourceAddress: "Dhaka Bangladesh", DestinationAddress: "Rajshahi", ParcelType: "Food", Price: 220, Stop: 2},
+		},
+	}
+
+	t.Run("should store shipment with parcels and payments", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO shipment (.+) RETURNING id, created_at").
+			ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		prepare := m.ExpectPrepare("INSERT INTO parcel (.+) RETURNING id, status, created_at, updated_at")
+		prepare.ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "updated_at"}).AddRow(10, model.ParcelStatusCreated, createdAt, createdAt))
+		m.ExpectExec("INSERT INTO payment (.+)").
+			WithArgs(10, 3, float32(200)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		prepare.ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "updated_at"}).AddRow(11, model.ParcelStatusCreated, createdAt, createdAt))
+		m.ExpectExec("INSERT INTO payment (.+)").
+			WithArgs(11, 3, float32(220)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertShipment(context.Background(), shipment)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, result.ID)
+		assert.Equal(t, 10, result.Parcels[0].ID)
+		assert.Equal(t, 5, result.Parcels[0].ShipmentID)
+		assert.Equal(t, 11, result.Parcels[1].ID)
+		assert.Equal(t, model.ParcelStatusCreated, result.Parcels[1].Status)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when a parcel can not be stored", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO shipment (.+)").
+			ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectPrepare("INSERT INTO parcel (.+)").
+			ExpectQuery().
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertShipment(context.Background(), shipment)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FetchShipmentByID(t *testing.T) {
+	createdAt := time.Now()
+
+	t.Run("should return shipment with parcels in drop order", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchShipmentQuery)).
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "carrier_id", "source_address", "ordered", "created_at"}).
+				AddRow(5, 3, 0, "Dhaka Bangladesh", true, createdAt))
+		m.ExpectQuery(regexp.QuoteMeta(fetchParcelsQuery)).
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "shipment_id", "stop"}).
+				AddRow(11, 3, 5, 1).
+				AddRow(10, 3, 5, 2))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchShipmentByID(context.Background(), 5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.Shipment{
+			ID:            5,
+			UserID:        3,
+			SourceAddress: "Dhaka Bangladesh",
+			Ordered:       true,
+			CreatedAt:     createdAt,
+			Parcels: []model.Parcel{
+				{ID: 11, UserID: 3, ShipmentID: 5, Stop: 1},
+				{ID: 10, UserID: 3, ShipmentID: 5, Stop: 2},
+			},
+		}, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchShipmentQuery)).
+			WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchShipmentByID(context.Background(), 5)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
+
+func TestRepository_InsertShipmentRequest(t *testing.T) {
+	request := model.ShipmentRequest{ShipmentID: 5, CarrierID: 7, ExpiresAt: time.Now()}
+
+	t.Run("should request every open parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("INSERT INTO carrier_request (.+) SELECT (.+) FROM parcel WHERE (.+) RETURNING parcel_id").
+			WithArgs(7, 5, request.ExpiresAt, model.ParcelStatusCreated, model.CarrierRequestPending, model.CarrierRequestExpired).
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id"}).AddRow(10).AddRow(11))
+
+		repo := NewRepository(sqlxDB)
+		parcelIDs, err := repo.InsertShipmentRequest(context.Background(), request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{10, 11}, parcelIDs)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return invalid when nothing is requested", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("INSERT INTO carrier_request (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"parcel_id"}))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertShipmentRequest(context.Background(), request)
+		assert.EqualError(t, err, "carrier 7 has already requested shipment 5 or it has no parcels left to deliver :invalid")
+	})
+}
+
+func TestRepository_AcceptShipmentRequest(t *testing.T) {
+	assignedAt := time.Now()
+	request := model.ShipmentRequest{ShipmentID: 5, CarrierID: 7}
+
+	t.Run("should assign carrier to every parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT id, status FROM parcel WHERE (.+) FOR UPDATE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(10, model.ParcelStatusCreated).AddRow(11, model.ParcelStatusCreated))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE (.+) carrier_id = (.+) AND status = (.+)").
+			WithArgs(model.CarrierRequestAccepted, sqlmock.AnyArg(), 7, model.CarrierRequestPending, assignedAt).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE (.+) carrier_id != (.+)").
+			WithArgs(model.CarrierRequestRejected, sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		m.ExpectExec("UPDATE parcel SET carrier_id = (.+) WHERE shipment_id = (.+)").
+			WithArgs(7, model.ParcelStatusAssigned, assignedAt, 5).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		m.ExpectExec("UPDATE shipment SET carrier_id = (.+) WHERE (.+)").
+			WithArgs(7, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditParcel, 10, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO outbox (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditParcel, 11, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditAccept, model.AuditShipment, 5, `{"carrier_id":{"before":0,"after":7}}`, "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		parcelIDs, err := repo.AcceptShipmentRequest(context.Background(), request, assignedAt)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{10, 11}, parcelIDs)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not assign when a parcel has moved on", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT id, status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(10, model.ParcelStatusCreated).AddRow(11, model.ParcelStatusCancelled))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptShipmentRequest(context.Background(), request, assignedAt)
+		assert.EqualError(t, err, "parcel 11 of shipment 5 is cancelled :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not assign without a request for every parcel", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT id, status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(10, model.ParcelStatusCreated).AddRow(11, model.ParcelStatusCreated))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptShipmentRequest(context.Background(), request, assignedAt)
+		assert.EqualError(t, err, "carrier 7 has no open request for every parcel of shipment 5 :invalid")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return not found for shipment without parcels", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT id, status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptShipmentRequest(context.Background(), request, assignedAt)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+
+	t.Run("should return commit error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectQuery("SELECT id, status FROM parcel WHERE (.+) FOR UPDATE").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(10, model.ParcelStatusCreated))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE (.+) carrier_id = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("UPDATE carrier_request SET status = (.+) WHERE (.+) carrier_id != (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		m.ExpectExec("UPDATE parcel SET (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("UPDATE shipment SET (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		m.ExpectExec("INSERT INTO outbox (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit().WillReturnError(errors.New("commit-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.AcceptShipmentRequest(context.Background(), request, assignedAt)
+		assert.True(t, errors.Is(err, model.IntServerErr))
+	})
+}
@@ -0,0 +1,113 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/carrier"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+)
+
+type service struct {
+	repo       svc.ShipmentRepository
+	parcelSvc  svc.ParcelService
+	notifier   svc.EventNotifier
+	requestTTL time.Duration
+}
+
+func NewService(repo svc.ShipmentRepository, parcelSvc svc.ParcelService, notifier svc.EventNotifier) *service {
+	return &service{
+		repo:       repo,
+		parcelSvc:  parcelSvc,
+		notifier:   notifier,
+		requestTTL: carrier.REQUEST_TTL,
+	}
+}
+
+// CreateShipment prices every parcel of the shipment like a single parcel and stores them together
+func (s *service) CreateShipment(ctx context.Context, shipment model.Shipment) (model.Shipment, error) {
+	shipment.SetPickup()
+	for i := range shipment.Parcels {
+		parcel, err := s.parcelSvc.PriceParcel(ctx, shipment.Parcels[i])
+		if err != nil {
+			return model.Shipment{}, err
+		}
+		shipment.Parcels[i] = parcel
+	}
+
+	shipment, err := s.repo.InsertShipment(ctx, shipment)
+	if err != nil {
+		return model.Shipment{}, err
+	}
+
+	for _, parcel := range shipment.Parcels {
+		s.notifier.Notify(ctx, model.Event{
+			Type:     model.EventParcelCreated,
+			ParcelID: parcel.ID,
+			UserID:   parcel.UserID,
+			Status:   parcel.Status,
+		})
+	}
+	return shipment, nil
+}
+
+func (s *service) GetShipment(ctx context.Context, shipmentID int) (model.Shipment, error) {
+	return s.repo.FetchShipmentByID(ctx, shipmentID)
+}
+
+// RequestShipment lets the carrier request every parcel of the shipment that still needs a carrier
+func (s *service) RequestShipment(ctx context.Context, request model.ShipmentRequest) (model.ShipmentRequest, error) {
+	if _, err := s.repo.FetchShipmentByID(ctx, request.ShipmentID); err != nil {
+		return model.ShipmentRequest{}, err
+	}
+
+	request.ExpiresAt = time.Now().Add(s.requestTTL)
+	parcelIDs, err := s.repo.InsertShipmentRequest(ctx, request)
+	if err != nil {
+		return model.ShipmentRequest{}, err
+	}
+
+	for _, parcelID := range parcelIDs {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventCarrierRequested,
+			ParcelID:  parcelID,
+			CarrierID: request.CarrierID,
+		})
+	}
+	return request, nil
+}
+
+// AcceptShipment lets the sender assign the carrier to the whole shipment, it fails when any parcel can not be assigned
+func (s *service) AcceptShipment(ctx context.Context, userID int, request model.ShipmentRequest) (model.Shipment, error) {
+	shipment, err := s.repo.FetchShipmentByID(ctx, request.ShipmentID)
+	if err != nil {
+		return model.Shipment{}, err
+	}
+
+	if shipment.UserID != userID {
+		return model.Shipment{}, fmt.Errorf("user %d did not book shipment %d :%w", userID, shipment.ID, model.ErrForbidden)
+	}
+
+	assignedAt := time.Now()
+	parcelIDs, err := s.repo.AcceptShipmentRequest(ctx, request, assignedAt)
+	if err != nil {
+		return model.Shipment{}, err
+	}
+
+	shipment.CarrierID = request.CarrierID
+	for i := range shipment.Parcels {
+		shipment.Parcels[i].CarrierID = request.CarrierID
+		shipment.Parcels[i].Status = model.ParcelStatusAssigned
+		shipment.Parcels[i].AssignedAt = &assignedAt
+	}
+	for _, parcelID := range parcelIDs {
+		s.notifier.Notify(ctx, model.Event{
+			Type:      model.EventCarrierAssigned,
+			ParcelID:  parcelID,
+			CarrierID: request.CarrierID,
+			Status:    model.ParcelStatusAssigned,
+		})
+	}
+	return shipment, nil
+}
@@ -0,0 +1,181 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CreateShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	input := model.Shipment{
+		UserID:        3,
+		SourceAddress: "Dhaka Bangladesh",
+		Parcels: []model.Parcel{
+			{DestinationAddress: "Pabna Shadar", ParcelType: "Document"},
+			{DestinationAddress: "Rajshahi", ParcelType: "Food"},
+		},
+	}
+
+	t.Run("should price every parcel and notify their creation", func(t *testing.T) {
+		parcelSvc := mocks.NewMockParcelService(ctrl)
+		parcelSvc.EXPECT().PriceParcel(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, p model.Parcel) (model.Parcel, error) {
+			assert.Equal(t, 3, p.UserID)
+			assert.Equal(t, "Dhaka Bangladesh", p.SourceAddress)
+			p.Price = 200
+			return p, nil
+		})
+
+		repo := mocks.NewMockShipmentRepository(ctrl)
+		repo.EXPECT().InsertShipment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s model.Shipment) (model.Shipment, error) {
+			s.ID = 5
+			for i := range s.Parcels {
+				assert.Equal(t, float32(200), s.Parcels[i].Price)
+				s.Parcels[i].ID = 10 + i
+				s.Parcels[i].Status = model.ParcelStatusCreated
+			}
+			return s, nil
+		})
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelCreated, ParcelID: 10, UserID: 3, Status: model.ParcelStatusCreated})
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventParcelCreated, ParcelID: 11, UserID: 3, Status: model.ParcelStatusCreated})
+
+		s := NewService(repo, parcelSvc, notifier)
+		shipment, err := s.CreateShipment(context.Background(), input)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, shipment.ID)
+		assert.Len(t, shipment.Parcels, 2)
+	})
+
+	t.Run("should not store the shipment when pricing fails", func(t *testing.T) {
+		parcelSvc := mocks.NewMockParcelService(ctrl)
+		parcelSvc.EXPECT().PriceParcel(gomock.Any(), gomock.Any()).Return(model.Parcel{}, model.ErrInvalid)
+
+		s := NewService(mocks.NewMockShipmentRepository(ctrl), parcelSvc, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.CreateShipment(context.Background(), input)
+		assert.Equal(t, model.ErrInvalid, err)
+	})
+}
+
+func TestService_RequestShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should request every parcel and notify the sender", func(t *testing.T) {
+		repo := mocks.NewMockShipmentRepository(ctrl)
+		repo.EXPECT().FetchShipmentByID(gomock.Any(), 5).Return(model.Shipment{ID: 5, UserID: 3}, nil)
+		repo.EXPECT().InsertShipmentRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r model.ShipmentRequest) ([]int, error) {
+			assert.WithinDuration(t, time.Now().Add(2*time.Hour), r.ExpiresAt, time.Minute)
+			return []int{10, 11}, nil
+		})
+
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCarrierRequested, ParcelID: 10, CarrierID: 7})
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{Type: model.EventCarrierRequested, ParcelID: 11, CarrierID: 7})
+
+		s := NewService(repo, nil, notifier)
+		request, err := s.RequestShipment(context.Background(), model.ShipmentRequest{ShipmentID: 5, CarrierID: 7})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 7, request.CarrierID)
+		assert.False(t, request.ExpiresAt.IsZero())
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		repo := mocks.NewMockShipmentRepository(ctrl)
+		repo.EXPECT().FetchShipmentByID(gomock.Any(), 5).Return(model.Shipment{}, model.ErrNotFound)
+
+		s := NewService(repo, nil, mocks.NewMockEventNotifier(ctrl))
+		_, err := s.RequestShipment(context.Background(), model.ShipmentRequest{ShipmentID: 5, CarrierID: 7})
+		assert.Equal(t, model.ErrNotFound, err)
+	})
+}
+
+func TestService_AcceptShipment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shipment := model.Shipment{
+		ID:     5,
+		UserID: 3,
+		Parcels: []model.Parcel{
+			{ID: 10, UserID: 3, Status: model.ParcelStatusCreated},
+			{ID: 11, UserID: 3, Status: model.ParcelStatusCreated},
+		},
+	}
+	request := model.ShipmentRequest{ShipmentID: 5, CarrierID: 7}
+
+	testCases := []struct {
+		desc      string
+		userID    int
+		mockRepo  func() *mocks.MockShipmentRepository
+		expEvents []model.Event
+		expErr    error
+	}{
+		{
+			desc:   "should assign carrier to the whole shipment",
+			userID: 3,
+			mockRepo: func() *mocks.MockShipmentRepository {
+				r := mocks.NewMockShipmentRepository(ctrl)
+				r.EXPECT().FetchShipmentByID(gomock.Any(), 5).Return(shipment, nil)
+				r.EXPECT().AcceptShipmentRequest(gomock.Any(), request, gomock.Any()).Return([]int{10, 11}, nil)
+				return r
+			},
+			expEvents: []model.Event{
+				{Type: model.EventCarrierAssigned, ParcelID: 10, CarrierID: 7, Status: model.ParcelStatusAssigned},
+				{Type: model.EventCarrierAssigned, ParcelID: 11, CarrierID: 7, Status: model.ParcelStatusAssigned},
+			},
+		},
+		{
+			desc:   "should return forbidden for another user",
+			userID: 4,
+			mockRepo: func() *mocks.MockShipmentRepository {
+				r := mocks.NewMockShipmentRepository(ctrl)
+				r.EXPECT().FetchShipmentByID(gomock.Any(), 5).Return(shipment, nil)
+				return r
+			},
+			expErr: fmt.Errorf("user 4 did not book shipment 5 :%w", model.ErrForbidden),
+		},
+		{
+			desc:   "should return repository error",
+			userID: 3,
+			mockRepo: func() *mocks.MockShipmentRepository {
+				r := mocks.NewMockShipmentRepository(ctrl)
+				r.EXPECT().FetchShipmentByID(gomock.Any(), 5).Return(shipment, nil)
+				r.EXPECT().AcceptShipmentRequest(gomock.Any(), request, gomock.Any()).Return(nil, model.ErrInvalid)
+				return r
+			},
+			expErr: model.ErrInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			notifier := mocks.NewMockEventNotifier(ctrl)
+			for _, event := range tc.expEvents {
+				notifier.EXPECT().Notify(gomock.Any(), event)
+			}
+			s := NewService(tc.mockRepo(), nil, notifier)
+			result, err := s.AcceptShipment(context.Background(), tc.userID, request)
+			assert.Equal(t, tc.expErr, err)
+			if tc.expErr == nil {
+				assert.Equal(t, 7, result.CarrierID)
+				for _, parcel := range result.Parcels {
+					assert.Equal(t, 7, parcel.CarrierID)
+					assert.Equal(t, model.ParcelStatusAssigned, parcel.Status)
+					assert.NotNil(t, parcel.AssignedAt)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package sla
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	insertBreachesQuery = `WITH breach AS (INSERT INTO sla_breach (parcel_id, kind, deadline) SELECT id, 'pickup', pickup_deadline FROM parcel WHERE status IN ($1, $2) AND pickup_deadline < $4 UNION ALL SELECT id, 'delivery', delivery_deadline FROM parcel WHERE status IN ($1, $2, $3) AND delivery_deadline < $4 ON CONFLICT (parcel_id, kind) DO NOTHING RETURNING id, parcel_id, kind, deadline, detected_at) SELECT breach.id, breach.parcel_id, parcel.user_id, COALESCE(parcel.carrier_id, 0) AS carrier_id, parcel.status, breach.kind, breach.deadline, breach.detected_at FROM breach JOIN parcel ON parcel.id = breach.parcel_id ORDER BY breach.id`
+	fetchBreachesQuery  = `SELECT sla_breach.id, sla_breach.parcel_id, parcel.user_id, COALESCE(parcel.carrier_id, 0) AS carrier_id, parcel.status, sla_breach.kind, sla_breach.deadline, sla_breach.detected_at FROM sla_breach JOIN parcel ON parcel.id = sla_breach.parcel_id WHERE ($1 = '' OR sla_breach.kind = $1) ORDER BY sla_breach.detected_at DESC, sla_breach.id DESC LIMIT $2 OFFSET $3`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates SLA breach repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+// InsertBreaches records the parcels that are past a deadline they have not met and returns only the newly recorded breaches,
+// a parcel breaches each deadline once
+func (r *repository) InsertBreaches(ctx context.Context, now time.Time) ([]model.SLABreach, error) {
+	breaches := []model.SLABreach{}
+	err := r.db.SelectContext(ctx, &breaches, insertBreachesQuery, model.ParcelStatusCreated, model.ParcelStatusAssigned, model.ParcelStatusPickedUp, now)
+	if err != nil {
+		log.Error().Err(err).Msgf("[InsertBreaches] failed to insert SLA breaches: %v", err)
+		return nil, err
+	}
+	return breaches, nil
+}
+
+// FetchBreaches returns the latest breaches first, an empty kind returns every kind
+func (r *repository) FetchBreaches(ctx context.Context, kind string, limit int, offset int) ([]model.SLABreach, error) {
+	breaches := []model.SLABreach{}
+	err := r.db.SelectContext(ctx, &breaches, fetchBreachesQuery, kind, limit, offset)
+	if err != nil {
+		log.Error().Err(err).Msgf("[FetchBreaches] failed to fetch SLA breaches: %v", err)
+		return nil, err
+	}
+	return breaches, nil
+}
@@ -0,0 +1,83 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	deadline   = time.Date(2020, time.April, 11, 12, 0, 0, 0, time.UTC)
+	detectedAt = time.Date(2020, time.April, 11, 12, 1, 0, 0, time.UTC)
+	columns    = []string{"id", "parcel_id", "user_id", "carrier_id", "status", "kind", "deadline", "detected_at"}
+)
+
+func TestRepository_InsertBreaches(t *testing.T) {
+	t.Run("should return new breaches", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(insertBreachesQuery)).
+			WithArgs(model.ParcelStatusCreated, model.ParcelStatusAssigned, model.ParcelStatusPickedUp, detectedAt).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 3, 1, 0, model.ParcelStatusCreated, model.SLABreachPickup, deadline, detectedAt).
+				AddRow(2, 4, 1, 7, model.ParcelStatusPickedUp, model.SLABreachDelivery, deadline, detectedAt))
+
+		breaches, err := NewRepository(sqlxDB).InsertBreaches(context.Background(), detectedAt)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.SLABreach{
+			{ID: 1, ParcelID: 3, UserID: 1, Status: model.ParcelStatusCreated, Kind: model.SLABreachPickup, Deadline: deadline, DetectedAt: detectedAt},
+			{ID: 2, ParcelID: 4, UserID: 1, CarrierID: 7, Status: model.ParcelStatusPickedUp, Kind: model.SLABreachDelivery, Deadline: deadline, DetectedAt: detectedAt},
+		}, breaches)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(insertBreachesQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).InsertBreaches(context.Background(), detectedAt)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchBreaches(t *testing.T) {
+	t.Run("should return breaches of a kind", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchBreachesQuery)).
+			WithArgs(model.SLABreachPickup, 20, 0).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 3, 1, 0, model.ParcelStatusCreated, model.SLABreachPickup, deadline, detectedAt))
+
+		breaches, err := NewRepository(sqlxDB).FetchBreaches(context.Background(), model.SLABreachPickup, 20, 0)
+		assert.Nil(t, err)
+		assert.Len(t, breaches, 1)
+		assert.Equal(t, 3, breaches[0].ParcelID)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchBreachesQuery)).WillReturnError(errors.New("sql-error"))
+
+		_, err := NewRepository(sqlxDB).FetchBreaches(context.Background(), "", 20, 0)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
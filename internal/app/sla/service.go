@@ -0,0 +1,70 @@
+package sla
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CHECK_INTERVAL is the wait between two checks of the SLA deadlines
+const CHECK_INTERVAL = time.Minute
+
+type service struct {
+	repo     svc.SLARepository
+	notifier svc.EventNotifier
+	interval time.Duration
+}
+
+// NewService initiates the SLA checker, every new breach is published to the notifier
+func NewService(repo svc.SLARepository, notifier svc.EventNotifier) *service {
+	return &service{
+		repo:     repo,
+		notifier: notifier,
+		interval: CHECK_INTERVAL,
+	}
+}
+
+// Run checks the SLA deadlines every interval until the context is cancelled
+func (s *service) Run(ctx context.Context) {
+	for {
+		s.Check(ctx)
+
+		select {
+		case <-time.After(s.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check flags the parcels that were not picked up or not delivered in time and notifies about each new breach
+func (s *service) Check(ctx context.Context) ([]model.SLABreach, error) {
+	breaches, err := s.repo.InsertBreaches(ctx, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msgf("[Check] failed to check SLA deadlines. Error: %v", err)
+		return nil, err
+	}
+
+	for _, breach := range breaches {
+		s.notifier.Notify(ctx, model.Event{
+			Type:       model.EventParcelSLABreached,
+			ParcelID:   breach.ParcelID,
+			UserID:     breach.UserID,
+			CarrierID:  breach.CarrierID,
+			Status:     breach.Status,
+			Breach:     breach.Kind,
+			OccurredAt: breach.DetectedAt,
+		})
+	}
+	if len(breaches) > 0 {
+		log.Info().Msgf("[Check] %d parcels breached their SLA", len(breaches))
+	}
+	return breaches, nil
+}
+
+func (s *service) GetBreaches(ctx context.Context, kind string, limit int, offset int) ([]model.SLABreach, error) {
+	return s.repo.FetchBreaches(ctx, kind, limit, offset)
+}
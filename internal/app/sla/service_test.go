@@ -0,0 +1,69 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Check(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	breach := model.SLABreach{ID: 1, ParcelID: 3, UserID: 1, CarrierID: 7, Status: model.ParcelStatusAssigned, Kind: model.SLABreachPickup, Deadline: deadline, DetectedAt: detectedAt}
+
+	t.Run("should notify every new breach", func(t *testing.T) {
+		repo := mocks.NewMockSLARepository(ctrl)
+		repo.EXPECT().InsertBreaches(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, now time.Time) ([]model.SLABreach, error) {
+			assert.WithinDuration(t, time.Now(), now, time.Second)
+			return []model.SLABreach{breach}, nil
+		})
+		notifier := mocks.NewMockEventNotifier(ctrl)
+		notifier.EXPECT().Notify(gomock.Any(), model.Event{
+			Type:       model.EventParcelSLABreached,
+			ParcelID:   3,
+			UserID:     1,
+			CarrierID:  7,
+			Status:     model.ParcelStatusAssigned,
+			Breach:     model.SLABreachPickup,
+			OccurredAt: detectedAt,
+		})
+
+		breaches, err := NewService(repo, notifier).Check(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, []model.SLABreach{breach}, breaches)
+	})
+
+	t.Run("should return db error", func(t *testing.T) {
+		repo := mocks.NewMockSLARepository(ctrl)
+		repo.EXPECT().InsertBreaches(gomock.Any(), gomock.Any()).Return(nil, errors.New("db-error"))
+
+		_, err := NewService(repo, mocks.NewMockEventNotifier(ctrl)).Check(context.Background())
+		assert.EqualError(t, err, "db-error")
+	})
+}
+
+func TestService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := mocks.NewMockSLARepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().InsertBreaches(gomock.Any(), gomock.Any()).Return(nil, errors.New("db-error")),
+		repo.EXPECT().InsertBreaches(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, time.Time) ([]model.SLABreach, error) {
+			cancel()
+			return nil, nil
+		}),
+	)
+
+	s := NewService(repo, mocks.NewMockEventNotifier(ctrl))
+	s.interval = time.Millisecond
+	s.Run(ctx)
+}
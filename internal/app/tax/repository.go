@@ -0,0 +1,74 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query and error
+const (
+	errUniqueViolation = pq.ErrorCode("23505")
+	insertTaxRuleQuery = `INSERT INTO tax_rule (name, region, type, rate, inclusive) VALUES (:name, :region, :type, :rate, :inclusive) RETURNING id, created_at`
+	fetchTaxRulesQuery = `SELECT id, name, region, type, rate, inclusive, created_at FROM tax_rule ORDER BY id`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates tax repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) InsertTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertTaxRule] failed to begin transaction")
+		return model.TaxRule{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertTaxRuleQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertTaxRule] PrepareNamedContext Error: %v", err)
+		return model.TaxRule{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &rule, &rule); err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == errUniqueViolation {
+			return model.TaxRule{}, fmt.Errorf("tax rule for region '%s' and type '%s' already exists :%w", rule.Region, rule.ParcelType, model.ErrInvalid)
+		}
+		log.Error().Err(err).Msgf("[InsertTaxRule] GetContext Error: %v", err)
+		return model.TaxRule{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditTaxRule, rule.ID, nil, rule); err != nil {
+		tx.Rollback()
+		return model.TaxRule{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertTaxRule] failed to commit")
+		return model.TaxRule{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return rule, nil
+}
+
+func (r *repository) FetchTaxRules(ctx context.Context) ([]model.TaxRule, error) {
+	var rules []model.TaxRule
+	if err := r.db.SelectContext(ctx, &rules, fetchTaxRulesQuery); err != nil {
+		log.Error().Err(err).Msgf("[FetchTaxRules] failed to fetch tax rules Error: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,106 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_InsertTaxRule(t *testing.T) {
+	rule := model.TaxRule{Name: "VAT", Region: "Dhaka", Rate: 0.15}
+
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		createdAt := time.Now()
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO tax_rule (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditTaxRule, 1, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertTaxRule(context.Background(), rule)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.TaxRule{ID: 1, Name: "VAT", Region: "Dhaka", Rate: 0.15, CreatedAt: createdAt}, result)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return unique key violation error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO tax_rule (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnError(&pq.Error{Code: "23505"})
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertTaxRule(context.Background(), rule)
+		assert.True(t, errors.Is(err, model.ErrInvalid))
+	})
+
+	t.Run("should return prepare statement error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO tax_rule (.+) VALUES (.+) RETURNING .+").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertTaxRule(context.Background(), rule)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchTaxRules(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta("SELECT id, name, region, type, rate, inclusive, created_at FROM tax_rule ORDER BY id")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "region", "type", "rate", "inclusive"}).
+				AddRow(1, "VAT", "", "", 0.15, false).
+				AddRow(2, "Dhaka VAT", "Dhaka", "Document", 0.1, true))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchTaxRules(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, []model.TaxRule{
+			{ID: 1, Name: "VAT", Rate: 0.15},
+			{ID: 2, Name: "Dhaka VAT", Region: "Dhaka", ParcelType: "Document", Rate: 0.1, Inclusive: true},
+		}, result)
+	})
+
+	t.Run("should return sql error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery("^SELECT (.+) FROM tax_rule").
+			WillReturnError(errors.New("sql-error"))
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchTaxRules(context.Background())
+		assert.EqualError(t, err, "sql-error")
+	})
+}
@@ -0,0 +1,50 @@
+package tax
+
+import (
+	"context"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+)
+
+type service struct {
+	repo svc.TaxRepository
+}
+
+func NewService(repo svc.TaxRepository) *service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) CreateTaxRule(ctx context.Context, rule model.TaxRule) (model.TaxRule, error) {
+	return s.repo.InsertTaxRule(ctx, rule)
+}
+
+func (s *service) GetTaxRules(ctx context.Context) ([]model.TaxRule, error) {
+	return s.repo.FetchTaxRules(ctx)
+}
+
+// CalculateTax applies the most specific rule for the parcel region and type to the company fee,
+// which is the only VAT-able part of the price. Parcels without a matching rule are not taxed.
+func (s *service) CalculateTax(ctx context.Context, parcel model.Parcel) (model.Tax, error) {
+	rules, err := s.repo.FetchTaxRules(ctx)
+	if err != nil {
+		return model.Tax{}, err
+	}
+
+	var rule *model.TaxRule
+	for i := range rules {
+		if !rules[i].Matches(parcel.Region, parcel.ParcelType) {
+			continue
+		}
+		if rule == nil || rules[i].Specificity() > rule.Specificity() {
+			rule = &rules[i]
+		}
+	}
+
+	if rule == nil {
+		return model.Tax{}, nil
+	}
+
+	return rule.Calculate(parcel.CompanyFee - parcel.Discount), nil
+}
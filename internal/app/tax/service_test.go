@@ -0,0 +1,146 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_CreateTaxRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rule := model.TaxRule{Name: "VAT", Rate: 0.15}
+
+	testCases := []struct {
+		desc     string
+		mockRepo func() *mocks.MockTaxRepository
+		expRule  model.TaxRule
+		expErr   error
+	}{
+		{
+			desc: "should return success",
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().InsertTaxRule(gomock.Any(), rule).Return(model.TaxRule{ID: 1, Name: "VAT", Rate: 0.15}, nil)
+				return r
+			},
+			expRule: model.TaxRule{ID: 1, Name: "VAT", Rate: 0.15},
+		},
+		{
+			desc: "should return db error",
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().InsertTaxRule(gomock.Any(), rule).Return(model.TaxRule{}, errors.New("db-error"))
+				return r
+			},
+			expRule: model.TaxRule{},
+			expErr:  errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewService(tc.mockRepo())
+			result, err := s.CreateTaxRule(context.Background(), rule)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expRule, result)
+		})
+	}
+}
+
+func TestService_CalculateTax(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rules := []model.TaxRule{
+		{ID: 1, Name: "VAT", Rate: 0.15},
+		{ID: 2, Name: "Document VAT", ParcelType: "Document", Rate: 0.05},
+		{ID: 3, Name: "Dhaka VAT", Region: "Dhaka", Rate: 0.10, Inclusive: true},
+		{ID: 4, Name: "Dhaka Document VAT", Region: "Dhaka", ParcelType: "Document", Rate: 0.25},
+	}
+
+	testCases := []struct {
+		desc     string
+		parcel   model.Parcel
+		mockRepo func() *mocks.MockTaxRepository
+		expTax   model.Tax
+		expErr   error
+	}{
+		{
+			desc:   "should use default rule",
+			parcel: model.Parcel{Region: "Khulna", ParcelType: "Box", CompanyFee: 20},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(rules, nil)
+				return r
+			},
+			expTax: model.Tax{Name: "VAT", Rate: 0.15, Amount: 3},
+		},
+		{
+			desc:   "should use parcel type rule",
+			parcel: model.Parcel{Region: "Khulna", ParcelType: "Document", CompanyFee: 20},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(rules, nil)
+				return r
+			},
+			expTax: model.Tax{Name: "Document VAT", Rate: 0.05, Amount: 1},
+		},
+		{
+			desc:   "should prefer region rule and calculate inclusive tax",
+			parcel: model.Parcel{Region: "Dhaka", ParcelType: "Box", CompanyFee: 22},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(rules, nil)
+				return r
+			},
+			expTax: model.Tax{Name: "Dhaka VAT", Rate: 0.10, Inclusive: true, Amount: 2},
+		},
+		{
+			desc:   "should use region and type rule on discounted fee",
+			parcel: model.Parcel{Region: "Dhaka", ParcelType: "Document", CompanyFee: 20, Discount: 4},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(rules, nil)
+				return r
+			},
+			expTax: model.Tax{Name: "Dhaka Document VAT", Rate: 0.25, Amount: 4},
+		},
+		{
+			desc:   "should not tax without matching rule",
+			parcel: model.Parcel{Region: "Dhaka", ParcelType: "Box", CompanyFee: 20},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(nil, nil)
+				return r
+			},
+			expTax: model.Tax{},
+		},
+		{
+			desc:   "should return db error",
+			parcel: model.Parcel{CompanyFee: 20},
+			mockRepo: func() *mocks.MockTaxRepository {
+				r := mocks.NewMockTaxRepository(ctrl)
+				r.EXPECT().FetchTaxRules(gomock.Any()).Return(nil, errors.New("db-error"))
+				return r
+			},
+			expTax: model.Tax{},
+			expErr: errors.New("db-error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := NewService(tc.mockRepo())
+			result, err := s.CalculateTax(context.Background(), tc.parcel)
+			assert.Equal(t, tc.expErr, err)
+			assert.Equal(t, tc.expTax, result)
+		})
+	}
+}
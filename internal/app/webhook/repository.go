@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"parcel-service/internal/app/audit"
+	"parcel-service/internal/app/model"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// SQL Query
+const (
+	insertSubscriptionQuery            = `INSERT INTO webhook_subscription (user_id, url, secret, event_types, active) VALUES (:user_id, :url, :secret, :event_types, :active) RETURNING id, created_at`
+	fetchSubscriptionByIDQuery         = `SELECT id, user_id, url, secret, event_types, active, created_at FROM webhook_subscription WHERE id = $1`
+	fetchSubscriptionsByUserQuery      = `SELECT id, user_id, url, secret, event_types, active, created_at FROM webhook_subscription WHERE user_id = $1 ORDER BY id`
+	insertDeliveryQuery                = `INSERT INTO webhook_delivery (subscription_id, event_type, payload, status) VALUES (:subscription_id, :event_type, :payload, :status) RETURNING id, created_at`
+	updateDeliveryQuery                = `UPDATE webhook_delivery SET status = $1, attempts = $2, response_code = $3, error = $4, delivered_at = $5 WHERE id = $6`
+	fetchDeliveryByIDQuery             = `SELECT id, subscription_id, event_type, payload, status, attempts, response_code, error, created_at, delivered_at FROM webhook_delivery WHERE id = $1`
+	fetchDeliveriesBySubscriptionQuery = `SELECT id, subscription_id, event_type, payload, status, attempts, response_code, error, created_at, delivered_at FROM webhook_delivery WHERE subscription_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3`
+)
+
+type repository struct {
+	db *sqlx.DB
+}
+
+// NewRepository initiates webhook repository and returns DB
+func NewRepository(db *sqlx.DB) *repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) InsertSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertSubscription] failed to begin transaction")
+		return model.WebhookSubscription{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertSubscriptionQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertSubscription] PrepareNamedContext Error: %v", err)
+		return model.WebhookSubscription{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &subscription, &subscription); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertSubscription] GetContext Error: %v", err)
+		return model.WebhookSubscription{}, err
+	}
+
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditWebhookSubscription, subscription.ID, nil, subscription); err != nil {
+		tx.Rollback()
+		return model.WebhookSubscription{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertSubscription] failed to commit")
+		return model.WebhookSubscription{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return subscription, nil
+}
+
+func (r *repository) FetchSubscriptionByID(ctx context.Context, subscriptionID int) (model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+
+	if err := r.db.GetContext(ctx, &subscription, fetchSubscriptionByIDQuery, subscriptionID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.WebhookSubscription{}, fmt.Errorf("webhook subscription %d is not found. :%w", subscriptionID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchSubscriptionByID] failed to fetch webhook subscription Error: %v", err)
+		return model.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (r *repository) FetchSubscriptionsByUserID(ctx context.Context, userID int) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+
+	if err := r.db.SelectContext(ctx, &subscriptions, fetchSubscriptionsByUserQuery, userID); err != nil {
+		log.Error().Err(err).Msgf("[FetchSubscriptionsByUserID] failed to fetch webhook subscriptions Error: %v", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *repository) InsertDelivery(ctx context.Context, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	stmt, err := r.db.PrepareNamedContext(ctx, insertDeliveryQuery)
+	if err != nil {
+		log.Error().Err(err).Msgf("[InsertDelivery] PrepareNamedContext Error: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &delivery, &delivery); err != nil {
+		log.Error().Err(err).Msgf("[InsertDelivery] GetContext Error: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// InsertReplay stores the replay of an earlier delivery as a new delivery and audits which delivery it replays
+func (r *repository) InsertReplay(ctx context.Context, delivery model.WebhookDelivery, originalID int) (model.WebhookDelivery, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("[InsertReplay] failed to begin transaction")
+		return model.WebhookDelivery{}, err
+	}
+
+	stmt, err := tx.PrepareNamedContext(ctx, insertDeliveryQuery)
+	if err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertReplay] PrepareNamedContext Error: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+
+	if err := stmt.GetContext(ctx, &delivery, &delivery); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msgf("[InsertReplay] GetContext Error: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+
+	replay := struct {
+		model.WebhookDelivery
+		ReplayOf int `json:"replay_of"`
+	}{delivery, originalID}
+	if err := audit.Write(ctx, tx, model.AuditCreate, model.AuditWebhookDelivery, delivery.ID, nil, replay); err != nil {
+		tx.Rollback()
+		return model.WebhookDelivery{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("[InsertReplay] failed to commit")
+		return model.WebhookDelivery{}, fmt.Errorf("%v :%w", err, model.IntServerErr)
+	}
+	return delivery, nil
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	if _, err := r.db.ExecContext(ctx, updateDeliveryQuery, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.Error, delivery.DeliveredAt, delivery.ID); err != nil {
+		log.Error().Err(err).Msgf("[UpdateDelivery] failed to update webhook delivery %d Error: %v", delivery.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *repository) FetchDeliveryByID(ctx context.Context, deliveryID int) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	if err := r.db.GetContext(ctx, &delivery, fetchDeliveryByIDQuery, deliveryID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.WebhookDelivery{}, fmt.Errorf("webhook delivery %d is not found. :%w", deliveryID, model.ErrNotFound)
+		}
+		log.Error().Err(err).Msgf("[FetchDeliveryByID] failed to fetch webhook delivery Error: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func (r *repository) FetchDeliveriesBySubscriptionID(ctx context.Context, subscriptionID int, limit int, offset int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+
+	if err := r.db.SelectContext(ctx, &deliveries, fetchDeliveriesBySubscriptionQuery, subscriptionID, limit, offset); err != nil {
+		log.Error().Err(err).Msgf("[FetchDeliveriesBySubscriptionID] failed to fetch webhook deliveries Error: %v", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"parcel-service/internal/app/model"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_InsertSubscription(t *testing.T) {
+	subscription := model.WebhookSubscription{UserID: 1, URL: "https://shop.example/hook", Secret: "s3cret", EventTypes: []string{model.EventParcelCreated}, Active: true}
+
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		createdAt := time.Now()
+		stored := subscription
+		stored.ID = 1
+		stored.CreatedAt = createdAt
+		changes, err := model.AuditChanges(nil, stored)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(changes), "s3cret")
+
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO webhook_subscription (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WithArgs(1, "https://shop.example/hook", "s3cret", "{\"parcel.created\"}", true).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditWebhookSubscription, 1, string(changes), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertSubscription(context.Background(), subscription)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, createdAt, result.CreatedAt)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should return prepare statement error", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO webhook_subscription (.+) VALUES (.+) RETURNING .+").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertSubscription(context.Background(), subscription)
+		assert.EqualError(t, err, "sql-error")
+	})
+}
+
+func TestRepository_FetchSubscriptionsByUserID(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	m.ExpectQuery(regexp.QuoteMeta(fetchSubscriptionsByUserQuery)).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "url", "secret", "event_types", "active"}).
+			AddRow(1, 1, "https://shop.example/hook", "s3cret", "{parcel.created,parcel.cancelled}", true))
+
+	repo := NewRepository(sqlxDB)
+	result, err := repo.FetchSubscriptionsByUserID(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.WebhookSubscription{
+		{ID: 1, UserID: 1, URL: "https://shop.example/hook", Secret: "s3cret", EventTypes: []string{model.EventParcelCreated, model.EventParcelCancelled}, Active: true},
+	}, result)
+}
+
+func TestRepository_FetchDeliveryByID(t *testing.T) {
+	t.Run("should return success", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchDeliveryByIDQuery)).WithArgs(10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_type", "payload", "status", "attempts", "response_code", "error", "delivered_at"}).
+				AddRow(10, 1, model.EventParcelCreated, "{}", model.WebhookDeliveryFailed, 5, 503, "unexpected response status 503", nil))
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.FetchDeliveryByID(context.Background(), 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, model.WebhookDelivery{ID: 10, SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: "{}", Status: model.WebhookDeliveryFailed, Attempts: 5, ResponseCode: 503, Error: "unexpected response status 503"}, result)
+	})
+
+	t.Run("should return not found", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectQuery(regexp.QuoteMeta(fetchDeliveryByIDQuery)).WithArgs(10).WillReturnError(sql.ErrNoRows)
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.FetchDeliveryByID(context.Background(), 10)
+		assert.True(t, errors.Is(err, model.ErrNotFound))
+	})
+}
+
+func TestRepository_InsertReplay(t *testing.T) {
+	delivery := model.WebhookDelivery{SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: `{"type":"parcel.created"}`, Status: model.WebhookDeliveryPending}
+
+	t.Run("should audit the delivery it replays", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		createdAt := time.Now()
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO webhook_delivery (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WithArgs(1, model.EventParcelCreated, `{"type":"parcel.created"}`, model.WebhookDeliveryPending).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, createdAt))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WithArgs(0, model.RoleSystem, model.AuditCreate, model.AuditWebhookDelivery, 11, sqlmock.AnyArg(), "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		m.ExpectCommit()
+
+		repo := NewRepository(sqlxDB)
+		result, err := repo.InsertReplay(context.Background(), delivery, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 11, result.ID)
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("should not store the replay when the audit fails", func(t *testing.T) {
+		db, m, _ := sqlmock.New()
+		defer db.Close()
+
+		sqlxDB := sqlx.NewDb(db, "sqlmock")
+		m.ExpectBegin()
+		m.ExpectPrepare("INSERT INTO webhook_delivery (.+) VALUES (.+) RETURNING .+").ExpectQuery().
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, time.Now()))
+		m.ExpectExec("INSERT INTO audit_log (.+) VALUES (.+)").
+			WillReturnError(errors.New("sql-error"))
+		m.ExpectRollback()
+
+		repo := NewRepository(sqlxDB)
+		_, err := repo.InsertReplay(context.Background(), delivery, 10)
+		assert.EqualError(t, err, "sql-error")
+		assert.Nil(t, m.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_UpdateDelivery(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	deliveredAt := time.Now()
+	m.ExpectExec(regexp.QuoteMeta(updateDeliveryQuery)).
+		WithArgs(model.WebhookDeliveryDelivered, 2, 200, "", &deliveredAt, 10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(sqlxDB)
+	err := repo.UpdateDelivery(context.Background(), model.WebhookDelivery{ID: 10, Status: model.WebhookDeliveryDelivered, Attempts: 2, ResponseCode: 200, DeliveredAt: &deliveredAt})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"parcel-service/internal/app/model"
+	svc "parcel-service/internal/app/service"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MAX_ATTEMPTS is the number of times a delivery is sent before it is marked failed
+	MAX_ATTEMPTS = 5
+	// RETRY_BACKOFF is the wait before the first retry, it doubles after every failed attempt
+	RETRY_BACKOFF = 2 * time.Second
+	// QUEUE_SIZE is the number of events waiting for dispatch, events are dropped when the queue is full
+	QUEUE_SIZE = 1000
+
+	SignatureHeader = "X-Parcel-Signature"
+	EventHeader     = "X-Parcel-Event"
+	DeliveryHeader  = "X-Parcel-Delivery"
+)
+
+type service struct {
+	repo       svc.WebhookRepository
+	parcelRepo svc.ParcelRepository
+	client     *http.Client
+	backoff    time.Duration
+	queue      chan model.Event
+	wg         sync.WaitGroup
+}
+
+func NewService(repo svc.WebhookRepository, parcelRepo svc.ParcelRepository, client *http.Client) *service {
+	return &service{
+		repo:       repo,
+		parcelRepo: parcelRepo,
+		client:     client,
+		backoff:    RETRY_BACKOFF,
+		queue:      make(chan model.Event, QUEUE_SIZE),
+	}
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload sent in the signature header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateSubscription stores an active subscription, a secret is generated when the user does not give one
+func (s *service) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return model.WebhookSubscription{}, fmt.Errorf("failed to generate webhook secret: %v :%w", err, model.IntServerErr)
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.Active = true
+
+	return s.repo.InsertSubscription(ctx, subscription)
+}
+
+func (s *service) GetSubscriptions(ctx context.Context, userID int) ([]model.WebhookSubscription, error) {
+	return s.repo.FetchSubscriptionsByUserID(ctx, userID)
+}
+
+// GetDeliveries returns the delivery log of a subscription of the user, a user ID of 0 is an admin who can read every log
+func (s *service) GetDeliveries(ctx context.Context, userID int, subscriptionID int, limit int, offset int) ([]model.WebhookDelivery, error) {
+	subscription, err := s.repo.FetchSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ownsSubscription(userID, subscription); err != nil {
+		return nil, err
+	}
+	return s.repo.FetchDeliveriesBySubscriptionID(ctx, subscriptionID, limit, offset)
+}
+
+// ReplayDelivery sends the payload of an earlier delivery of a subscription of the user again as a new delivery
+// with a single attempt, a user ID of 0 is an admin who can replay any delivery
+func (s *service) ReplayDelivery(ctx context.Context, userID int, deliveryID int) (model.WebhookDelivery, error) {
+	original, err := s.repo.FetchDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	subscription, err := s.repo.FetchSubscriptionByID(ctx, original.SubscriptionID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if err := ownsSubscription(userID, subscription); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	delivery, err := s.repo.InsertReplay(ctx, model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         model.WebhookDeliveryPending,
+	}, original.ID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	delivery = s.attempt(ctx, subscription, delivery)
+	if delivery.Status != model.WebhookDeliveryDelivered {
+		delivery.Status = model.WebhookDeliveryFailed
+	}
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func ownsSubscription(userID int, subscription model.WebhookSubscription) error {
+	if userID != 0 && subscription.UserID != userID {
+		return fmt.Errorf("user %d does not own webhook subscription %d :%w", userID, subscription.ID, model.ErrForbidden)
+	}
+	return nil
+}
+
+// Notify queues the event for dispatch by Run, a full queue drops the event
+func (s *service) Notify(ctx context.Context, event model.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		log.Error().Msgf("[Notify] webhook queue is full, dropping event %s of parcel %d", event.Type, event.ParcelID)
+	}
+}
+
+// Run dispatches queued events until the context is cancelled. Queued events are still dispatched on shutdown,
+// but pending retries are given up and left in the delivery log as failed so they can be replayed.
+func (s *service) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-s.queue:
+			s.dispatch(ctx, event)
+		case <-ctx.Done():
+			s.drain(ctx)
+			s.wg.Wait()
+			return
+		}
+	}
+}
+
+func (s *service) drain(ctx context.Context) {
+	for {
+		select {
+		case event := <-s.queue:
+			s.dispatch(ctx, event)
+		default:
+			return
+		}
+	}
+}
+
+// dispatch logs a delivery for every subscription of the parcel owner to the event and sends them in the background
+func (s *service) dispatch(ctx context.Context, event model.Event) {
+	if !model.IsEventType(event.Type) {
+		return
+	}
+
+	// the delivery log is written even while shutting down
+	dbCtx := context.Background()
+
+	if event.UserID == 0 {
+		parcel, err := s.parcelRepo.FetchParcelByID(dbCtx, event.ParcelID)
+		if err != nil {
+			log.Error().Err(err).Msgf("[dispatch] failed to fetch parcel %d for event %s. Error: %v", event.ParcelID, event.Type, err)
+			return
+		}
+		event.UserID = parcel.UserID
+		if event.CarrierID == 0 {
+			event.CarrierID = parcel.CarrierID
+		}
+	}
+
+	subscriptions, err := s.repo.FetchSubscriptionsByUserID(dbCtx, event.UserID)
+	if err != nil {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Msgf("[dispatch] failed to encode event %s. Error: %v", event.Type, err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+
+		delivery, err := s.repo.InsertDelivery(dbCtx, model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         model.WebhookDeliveryPending,
+		})
+		if err != nil {
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(subscription model.WebhookSubscription, delivery model.WebhookDelivery) {
+			defer s.wg.Done()
+			s.deliver(ctx, subscription, delivery)
+		}(subscription, delivery)
+	}
+}
+
+// deliver sends the delivery until the receiver accepts it, waiting with exponential backoff between attempts
+func (s *service) deliver(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery) {
+	backoff := s.backoff
+	for {
+		delivery = s.attempt(ctx, subscription, delivery)
+		if delivery.Status == model.WebhookDeliveryDelivered || delivery.Attempts >= MAX_ATTEMPTS {
+			break
+		}
+
+		_ = s.repo.UpdateDelivery(context.Background(), delivery)
+
+		if !wait(ctx, backoff) {
+			delivery.Error = "shutdown before retry: " + delivery.Error
+			break
+		}
+		backoff *= 2
+	}
+
+	if delivery.Status != model.WebhookDeliveryDelivered {
+		delivery.Status = model.WebhookDeliveryFailed
+	}
+	_ = s.repo.UpdateDelivery(context.Background(), delivery)
+}
+
+// attempt sends the signed payload once and records the result on the delivery
+func (s *service) attempt(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery) model.WebhookDelivery {
+	delivery.Attempts++
+	delivery.ResponseCode = 0
+	delivery.Error = ""
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	delivery.ResponseCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("unexpected response status %d", resp.StatusCode)
+		return delivery
+	}
+
+	now := time.Now()
+	delivery.Status = model.WebhookDeliveryDelivered
+	delivery.DeliveredAt = &now
+	return delivery
+}
+
+// wait sleeps for the duration and reports false when the context is cancelled first
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
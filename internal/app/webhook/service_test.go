@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"parcel-service/internal/app/model"
+	"parcel-service/internal/app/service/mocks"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver is a local webhook endpoint that fails the first requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, string(body))
+	if len(rc.requests) <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestService_CreateSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("should generate secret", func(t *testing.T) {
+		r := mocks.NewMockWebhookRepository(ctrl)
+		r.EXPECT().InsertSubscription(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+			assert.Len(t, sub.Secret, 64)
+			assert.True(t, sub.Active)
+			sub.ID = 1
+			return sub, nil
+		})
+
+		result, err := NewService(r, nil, nil).CreateSubscription(context.Background(), model.WebhookSubscription{UserID: 1, URL: "http://localhost"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.ID)
+	})
+
+	t.Run("should keep given secret", func(t *testing.T) {
+		r := mocks.NewMockWebhookRepository(ctrl)
+		r.EXPECT().InsertSubscription(gomock.Any(), model.WebhookSubscription{UserID: 1, URL: "http://localhost", Secret: "s3cret", Active: true}).
+			Return(model.WebhookSubscription{}, errors.New("db-error"))
+
+		_, err := NewService(r, nil, nil).CreateSubscription(context.Background(), model.WebhookSubscription{UserID: 1, URL: "http://localhost", Secret: "s3cret"})
+		assert.EqualError(t, err, "db-error")
+	})
+}
+
+func TestService_Dispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testCases := []struct {
+		desc         string
+		failures     int
+		expStatus    string
+		expAttempts  int
+		expResponse  int
+		expDelivered bool
+	}{
+		{
+			desc:         "should deliver on first attempt",
+			expStatus:    model.WebhookDeliveryDelivered,
+			expAttempts:  1,
+			expResponse:  http.StatusOK,
+			expDelivered: true,
+		},
+		{
+			desc:         "should retry until delivered",
+			failures:     2,
+			expStatus:    model.WebhookDeliveryDelivered,
+			expAttempts:  3,
+			expResponse:  http.StatusOK,
+			expDelivered: true,
+		},
+		{
+			desc:        "should fail after max attempts",
+			failures:    MAX_ATTEMPTS,
+			expStatus:   model.WebhookDeliveryFailed,
+			expAttempts: MAX_ATTEMPTS,
+			expResponse: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rc := &receiver{failures: tc.failures}
+			ts := httptest.NewServer(rc)
+			defer ts.Close()
+
+			subscriptions := []model.WebhookSubscription{
+				{ID: 1, UserID: 3, URL: ts.URL, Secret: "s3cret", EventTypes: []string{model.EventParcelStatusChanged}, Active: true},
+				{ID: 2, UserID: 3, URL: ts.URL, Secret: "other", EventTypes: []string{model.EventParcelCreated}, Active: true},
+			}
+
+			parcelRepo := mocks.NewMockParcelRepository(ctrl)
+			parcelRepo.EXPECT().FetchParcelByID(gomock.Any(), 1).Return(model.Parcel{ID: 1, UserID: 3}, nil)
+
+			var last model.WebhookDelivery
+			r := mocks.NewMockWebhookRepository(ctrl)
+			r.EXPECT().FetchSubscriptionsByUserID(gomock.Any(), 3).Return(subscriptions, nil)
+			r.EXPECT().InsertDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d model.WebhookDelivery) (model.WebhookDelivery, error) {
+				assert.Equal(t, 1, d.SubscriptionID)
+				d.ID = 10
+				return d, nil
+			})
+			r.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d model.WebhookDelivery) error {
+				last = d
+				return nil
+			}).MinTimes(1)
+
+			s := NewService(r, parcelRepo, ts.Client())
+			s.backoff = time.Millisecond
+			s.Notify(context.Background(), model.Event{Type: model.EventParcelStatusChanged, ParcelID: 1, Status: model.ParcelStatusDelivered})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				for len(s.queue) > 0 {
+					time.Sleep(time.Millisecond)
+				}
+				// give the delivery time to run its retries before shutting down
+				time.Sleep(200 * time.Millisecond)
+				cancel()
+			}()
+			s.Run(ctx)
+
+			assert.Equal(t, tc.expStatus, last.Status)
+			assert.Equal(t, tc.expAttempts, last.Attempts)
+			assert.Equal(t, tc.expResponse, last.ResponseCode)
+			assert.Equal(t, tc.expDelivered, last.DeliveredAt != nil)
+
+			assert.Len(t, rc.requests, tc.expAttempts)
+			req := rc.requests[0]
+			assert.Equal(t, model.EventParcelStatusChanged, req.Header.Get(EventHeader))
+			assert.Equal(t, "10", req.Header.Get(DeliveryHeader))
+			assert.Equal(t, Sign("s3cret", []byte(rc.bodies[0])), req.Header.Get(SignatureHeader))
+			assert.Contains(t, rc.bodies[0], `"type":"parcel.status_changed","parcel_id":1,"user_id":3,"status":4`)
+		})
+	}
+}
+
+func TestService_DeliverStopsRetryingOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ts := httptest.NewServer(&receiver{failures: MAX_ATTEMPTS})
+	defer ts.Close()
+
+	var last model.WebhookDelivery
+	r := mocks.NewMockWebhookRepository(ctrl)
+	r.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d model.WebhookDelivery) error {
+		last = d
+		return nil
+	}).Times(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := NewService(r, nil, ts.Client())
+	s.deliver(ctx, model.WebhookSubscription{ID: 1, URL: ts.URL}, model.WebhookDelivery{ID: 10, Payload: "{}"})
+
+	assert.Equal(t, model.WebhookDeliveryFailed, last.Status)
+	assert.Equal(t, 1, last.Attempts)
+}
+
+func TestService_ReplayDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	original := model.WebhookDelivery{ID: 10, SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: `{"type":"parcel.created"}`, Status: model.WebhookDeliveryFailed, Attempts: MAX_ATTEMPTS}
+
+	testCases := []struct {
+		desc      string
+		failures  int
+		mockRepo  func(url string) *mocks.MockWebhookRepository
+		expStatus string
+		expErr    error
+	}{
+		{
+			desc: "should deliver replay",
+			mockRepo: func(url string) *mocks.MockWebhookRepository {
+				r := mocks.NewMockWebhookRepository(ctrl)
+				r.EXPECT().FetchDeliveryByID(gomock.Any(), 10).Return(original, nil)
+				r.EXPECT().FetchSubscriptionByID(gomock.Any(), 1).Return(model.WebhookSubscription{ID: 1, UserID: 3, URL: url, Secret: "s3cret"}, nil)
+				r.EXPECT().InsertReplay(gomock.Any(), model.WebhookDelivery{SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: original.Payload, Status: model.WebhookDeliveryPending}, 10).
+					Return(model.WebhookDelivery{ID: 11, SubscriptionID: 1, EventType: model.EventParcelCreated, Payload: original.Payload, Status: model.WebhookDeliveryPending}, nil)
+				r.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil)
+				return r
+			},
+			expStatus: model.WebhookDeliveryDelivered,
+		},
+		{
+			desc:     "should record failed replay",
+			failures: 1,
+			mockRepo: func(url string) *mocks.MockWebhookRepository {
+				r := mocks.NewMockWebhookRepository(ctrl)
+				r.EXPECT().FetchDeliveryByID(gomock.Any(), 10).Return(original, nil)
+				r.EXPECT().FetchSubscriptionByID(gomock.Any(), 1).Return(model.WebhookSubscription{ID: 1, UserID: 3, URL: url, Secret: "s3cret"}, nil)
+				r.EXPECT().InsertReplay(gomock.Any(), gomock.Any(), 10).Return(model.WebhookDelivery{ID: 11, Payload: original.Payload}, nil)
+				r.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil)
+				return r
+			},
+			expStatus: model.WebhookDeliveryFailed,
+		},
+		{
+			desc: "should return not found",
+			mockRepo: func(url string) *mocks.MockWebhookRepository {
+				r := mocks.NewMockWebhookRepository(ctrl)
+				r.EXPECT().FetchDeliveryByID(gomock.Any(), 10).Return(model.WebhookDelivery{}, model.ErrNotFound)
+				return r
+			},
+			expErr: model.ErrNotFound,
+		},
+		{
+			desc: "should refuse the delivery of another user",
+			mockRepo: func(url string) *mocks.MockWebhookRepository {
+				r := mocks.NewMockWebhookRepository(ctrl)
+				r.EXPECT().FetchDeliveryByID(gomock.Any(), 10).Return(original, nil)
+				r.EXPECT().FetchSubscriptionByID(gomock.Any(), 1).Return(model.WebhookSubscription{ID: 1, UserID: 4, URL: url}, nil)
+				return r
+			},
+			expErr: model.ErrForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(&receiver{failures: tc.failures})
+			defer ts.Close()
+
+			s := NewService(tc.mockRepo(ts.URL), nil, ts.Client())
+			delivery, err := s.ReplayDelivery(context.Background(), 3, 10)
+			assert.True(t, errors.Is(err, tc.expErr))
+			assert.Equal(t, tc.expStatus, delivery.Status)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS promotion (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE CHECK(code != ''),
+    discount_type TEXT NOT NULL CHECK(discount_type IN ('percentage', 'fixed')),
+    discount_value FLOAT NOT NULL CHECK(discount_value > 0),
+    min_order FLOAT NOT NULL DEFAULT 0,
+    max_uses INT NOT NULL DEFAULT 0,
+    per_user_limit INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE parcel ADD COLUMN IF NOT EXISTS discount FLOAT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS promotion_redemption (
+    id SERIAL PRIMARY KEY,
+    promotion_id INT NOT NULL,
+    parcel_id INT NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    discount FLOAT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT promotion_id
+        FOREIGN KEY(promotion_id)
+            REFERENCES promotion(id),
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+                ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS promotion_redemption_user_idx ON promotion_redemption (promotion_id, user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS promotion_redemption;
+ALTER TABLE parcel DROP COLUMN IF EXISTS discount;
+DROP TABLE IF EXISTS promotion;
@@ -0,0 +1,64 @@
+INSERT INTO parcel_status (id, status_value) VALUES
+    (1, 'created'),
+    (2, 'carrier assigned'),
+    (3, 'picked up'),
+    (4, 'delivered'),
+    (5, 'cancelled')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO carrier_request_status (id, status_value) VALUES
+    (1, 'pending'),
+    (2, 'accepted'),
+    (3, 'rejected')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS payment (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    amount FLOAT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+                ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS parcel_cancellation (
+    parcel_id INT PRIMARY KEY,
+    previous_status INT NOT NULL,
+    reason TEXT NOT NULL CHECK(reason != ''),
+    fee FLOAT NOT NULL DEFAULT 0,
+    refund FLOAT NOT NULL DEFAULT 0,
+    carrier_compensation FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+                ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS refund (
+    id SERIAL PRIMARY KEY,
+    payment_id INT NOT NULL,
+    parcel_id INT NOT NULL,
+    amount FLOAT NOT NULL CHECK(amount > 0),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payment_id
+        FOREIGN KEY(payment_id)
+            REFERENCES payment(id)
+                ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS carrier_compensation (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    carrier_id INT NOT NULL,
+    amount FLOAT NOT NULL CHECK(amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+                ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS carrier_compensation;
+DROP TABLE IF EXISTS refund;
+DROP TABLE IF EXISTS parcel_cancellation;
+DROP TABLE IF EXISTS payment;
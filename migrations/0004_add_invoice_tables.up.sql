@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS invoice_counter (
+    id INT PRIMARY KEY CHECK(id = 1),
+    last_number INT NOT NULL DEFAULT 0
+);
+
+INSERT INTO invoice_counter (id, last_number) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS invoice (
+    id SERIAL PRIMARY KEY,
+    number TEXT NOT NULL UNIQUE,
+    parcel_id INT NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    source_address TEXT NOT NULL,
+    destination_address TEXT NOT NULL,
+    type TEXT NOT NULL,
+    carrier_fee FLOAT NOT NULL,
+    company_fee FLOAT NOT NULL,
+    discount FLOAT NOT NULL DEFAULT 0,
+    tax FLOAT NOT NULL DEFAULT 0,
+    total FLOAT NOT NULL,
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+);
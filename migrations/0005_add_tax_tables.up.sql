@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS tax_rule (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL CHECK(name != ''),
+    region TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL DEFAULT '',
+    rate FLOAT NOT NULL CHECK(rate >= 0 AND rate < 1),
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(region, type)
+);
+
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tax FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tax_rate FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE invoice
+    ADD COLUMN IF NOT EXISTS tax_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tax_rate FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
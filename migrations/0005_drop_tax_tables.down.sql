@@ -0,0 +1,13 @@
+ALTER TABLE invoice
+    DROP COLUMN IF EXISTS tax_name,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS tax_inclusive;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS region,
+    DROP COLUMN IF EXISTS tax,
+    DROP COLUMN IF EXISTS tax_name,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS tax_inclusive;
+
+DROP TABLE IF EXISTS tax_rule;
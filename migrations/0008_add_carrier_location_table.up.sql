@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS carrier_location (
+    carrier_id INT PRIMARY KEY,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
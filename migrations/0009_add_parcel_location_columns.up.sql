@@ -0,0 +1,4 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS source_latitude FLOAT NOT NULL DEFAULT 0 CHECK(source_latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS source_longitude FLOAT NOT NULL DEFAULT 0 CHECK(source_longitude BETWEEN -180 AND 180),
+    ADD COLUMN IF NOT EXISTS weight FLOAT NOT NULL DEFAULT 0 CHECK(weight >= 0);
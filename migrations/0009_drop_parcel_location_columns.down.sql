@@ -0,0 +1,4 @@
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS source_latitude,
+    DROP COLUMN IF EXISTS source_longitude,
+    DROP COLUMN IF EXISTS weight;
@@ -0,0 +1,13 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS auto_dispatch BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS carrier_profile (
+    carrier_id INT PRIMARY KEY CHECK(carrier_id > 0),
+    rating FLOAT NOT NULL DEFAULT 0 CHECK(rating >= 0 AND rating <= 5),
+    rating_count INT NOT NULL DEFAULT 0 CHECK(rating_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER carrier_profile_timestamp BEFORE INSERT OR UPDATE ON carrier_profile
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
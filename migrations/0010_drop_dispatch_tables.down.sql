@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS carrier_profile;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS auto_dispatch;
@@ -0,0 +1,9 @@
+INSERT INTO carrier_request_status (id, status_value) VALUES
+    (4, 'expired')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE carrier_request
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP + INTERVAL '2 hours';
+
+CREATE INDEX IF NOT EXISTS carrier_request_expiry ON carrier_request (expires_at) WHERE status = 1;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS carrier_request_expiry;
+
+UPDATE carrier_request SET status = 3 WHERE status = 4;
+
+ALTER TABLE carrier_request
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS expires_at;
+
+DELETE FROM carrier_request_status WHERE id = 4;
@@ -0,0 +1,9 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS pickup_window_start TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS pickup_window_end TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivery_window_start TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivery_window_end TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMP,
+    ADD CONSTRAINT pickup_window CHECK(pickup_window_end > pickup_window_start),
+    ADD CONSTRAINT delivery_window CHECK(delivery_window_end > delivery_window_start);
@@ -0,0 +1,9 @@
+ALTER TABLE parcel
+    DROP CONSTRAINT IF EXISTS pickup_window,
+    DROP CONSTRAINT IF EXISTS delivery_window,
+    DROP COLUMN IF EXISTS pickup_window_start,
+    DROP COLUMN IF EXISTS pickup_window_end,
+    DROP COLUMN IF EXISTS delivery_window_start,
+    DROP COLUMN IF EXISTS delivery_window_end,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS picked_up_at;
@@ -0,0 +1,21 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS pickup_deadline TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivery_deadline TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS parcel_pickup_deadline ON parcel (pickup_deadline) WHERE status IN (1, 2);
+CREATE INDEX IF NOT EXISTS parcel_delivery_deadline ON parcel (delivery_deadline) WHERE status IN (1, 2, 3);
+
+CREATE TABLE IF NOT EXISTS sla_breach (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    kind TEXT NOT NULL CHECK(kind IN ('pickup', 'delivery')),
+    deadline TIMESTAMP NOT NULL,
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id),
+    UNIQUE(parcel_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS sla_breach_detected_at ON sla_breach (detected_at);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS sla_breach;
+
+DROP INDEX IF EXISTS parcel_pickup_deadline;
+DROP INDEX IF EXISTS parcel_delivery_deadline;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS pickup_deadline,
+    DROP COLUMN IF EXISTS delivery_deadline;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS review (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    role TEXT NOT NULL CHECK(role IN ('user', 'carrier')),
+    author_id INT NOT NULL CHECK(author_id > 0),
+    subject_id INT NOT NULL CHECK(subject_id > 0),
+    rating INT NOT NULL CHECK(rating >= 1 AND rating <= 5),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id),
+    UNIQUE(parcel_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS review_subject ON review (role, subject_id);
+
+CREATE TABLE IF NOT EXISTS user_profile (
+    user_id INT PRIMARY KEY CHECK(user_id > 0),
+    rating FLOAT NOT NULL DEFAULT 0 CHECK(rating >= 0 AND rating <= 5),
+    rating_count INT NOT NULL DEFAULT 0 CHECK(rating_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER user_profile_timestamp BEFORE INSERT OR UPDATE ON user_profile
+FOR EACH ROW EXECUTE PROCEDURE update_timestamp();
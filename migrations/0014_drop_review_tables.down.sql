@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_profile;
+DROP TABLE IF EXISTS review;
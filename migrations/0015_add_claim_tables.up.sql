@@ -0,0 +1,33 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS declared_value FLOAT NOT NULL DEFAULT 0 CHECK(declared_value >= 0);
+
+CREATE TABLE IF NOT EXISTS claim (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    user_id INT NOT NULL CHECK(user_id > 0),
+    status TEXT NOT NULL DEFAULT 'open' CHECK(status IN ('open', 'investigating', 'approved', 'rejected')),
+    description TEXT NOT NULL CHECK(description != ''),
+    evidence TEXT[] NOT NULL DEFAULT '{}',
+    compensation FLOAT NOT NULL DEFAULT 0 CHECK(compensation >= 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS claim_parcel_id ON claim (parcel_id) WHERE status != 'rejected';
+CREATE INDEX IF NOT EXISTS claim_status ON claim (status, created_at);
+
+CREATE TABLE IF NOT EXISTS claim_compensation (
+    id SERIAL PRIMARY KEY,
+    claim_id INT NOT NULL UNIQUE,
+    parcel_id INT NOT NULL,
+    user_id INT NOT NULL,
+    amount FLOAT NOT NULL CHECK(amount >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT claim_id
+        FOREIGN KEY(claim_id)
+            REFERENCES claim(id)
+);
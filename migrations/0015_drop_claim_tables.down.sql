@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS claim_compensation;
+DROP TABLE IF EXISTS claim;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS declared_value;
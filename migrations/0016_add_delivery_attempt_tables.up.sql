@@ -0,0 +1,22 @@
+INSERT INTO parcel_status (id, status_value) VALUES
+    (6, 'returned')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS return_of INT REFERENCES parcel(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS parcel_return_of ON parcel (return_of);
+
+CREATE TABLE IF NOT EXISTS delivery_attempt (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    carrier_id INT NOT NULL,
+    attempt INT NOT NULL CHECK(attempt > 0),
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id),
+    UNIQUE(parcel_id, attempt)
+);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS delivery_attempt;
+
+DROP INDEX IF EXISTS parcel_return_of;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS return_of;
+
+UPDATE parcel SET status = 5 WHERE status = 6;
+
+DELETE FROM parcel_status WHERE id = 6;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS shipment (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL CHECK(user_id > 0),
+    carrier_id INT NOT NULL DEFAULT 0,
+    source_address TEXT NOT NULL CHECK(source_address != ''),
+    source_time TIMESTAMP,
+    source_latitude FLOAT NOT NULL DEFAULT 0,
+    source_longitude FLOAT NOT NULL DEFAULT 0,
+    pickup_window_start TIMESTAMP,
+    pickup_window_end TIMESTAMP,
+    ordered BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS shipment_id INT REFERENCES shipment(id),
+    ADD COLUMN IF NOT EXISTS stop INT NOT NULL DEFAULT 0 CHECK(stop >= 0);
+
+CREATE INDEX IF NOT EXISTS parcel_shipment_id ON parcel (shipment_id, stop);
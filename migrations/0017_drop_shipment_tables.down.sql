@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS parcel_shipment_id;
+
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS stop,
+    DROP COLUMN IF EXISTS shipment_id;
+
+DROP TABLE IF EXISTS shipment;
@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    ADD COLUMN IF NOT EXISTS destination_latitude FLOAT NOT NULL DEFAULT 0 CHECK(destination_latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS destination_longitude FLOAT NOT NULL DEFAULT 0 CHECK(destination_longitude BETWEEN -180 AND 180);
@@ -0,0 +1,3 @@
+ALTER TABLE parcel
+    DROP COLUMN IF EXISTS destination_latitude,
+    DROP COLUMN IF EXISTS destination_longitude;
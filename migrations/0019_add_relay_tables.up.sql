@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS hub (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE CHECK(name != ''),
+    address TEXT NOT NULL CHECK(address != ''),
+    latitude FLOAT NOT NULL DEFAULT 0,
+    longitude FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS parcel_leg (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL,
+    sequence INT NOT NULL CHECK(sequence > 0),
+    from_hub_id INT REFERENCES hub(id),
+    to_hub_id INT REFERENCES hub(id),
+    from_address TEXT NOT NULL,
+    to_address TEXT NOT NULL,
+    carrier_id INT NOT NULL DEFAULT 0,
+    status INT NOT NULL DEFAULT 1,
+    assigned_at TIMESTAMP,
+    picked_up_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT parcel_id
+        FOREIGN KEY(parcel_id)
+            REFERENCES parcel(id),
+    CONSTRAINT status
+        FOREIGN KEY(status)
+            REFERENCES parcel_status(id),
+    UNIQUE(parcel_id, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS parcel_leg_carrier_id ON parcel_leg (carrier_id);
+
+CREATE TABLE IF NOT EXISTS leg_request (
+    PRIMARY KEY(leg_id, carrier_id),
+    leg_id INT NOT NULL,
+    carrier_id INT NOT NULL,
+    status INT NOT NULL DEFAULT 1,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT leg_id
+        FOREIGN KEY(leg_id)
+            REFERENCES parcel_leg(id)
+                ON DELETE CASCADE,
+    CONSTRAINT status
+        FOREIGN KEY(status)
+            REFERENCES carrier_request_status(id)
+);
+
+CREATE TABLE IF NOT EXISTS custody_transfer (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL REFERENCES parcel(id),
+    hub_id INT NOT NULL REFERENCES hub(id),
+    from_leg_id INT NOT NULL REFERENCES parcel_leg(id),
+    to_leg_id INT NOT NULL UNIQUE REFERENCES parcel_leg(id),
+    from_carrier_id INT NOT NULL,
+    to_carrier_id INT NOT NULL,
+    arrived_at TIMESTAMP NOT NULL,
+    transferred_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS custody_transfer;
+
+DROP TABLE IF EXISTS leg_request;
+
+DROP INDEX IF EXISTS parcel_leg_carrier_id;
+
+DROP TABLE IF EXISTS parcel_leg;
+
+DROP TABLE IF EXISTS hub;
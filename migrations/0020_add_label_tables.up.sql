@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS parcel_label (
+    parcel_id INT PRIMARY KEY REFERENCES parcel(id),
+    tracking_code TEXT NOT NULL UNIQUE CHECK(tracking_code != ''),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scan_event (
+    id SERIAL PRIMARY KEY,
+    parcel_id INT NOT NULL REFERENCES parcel(id),
+    leg_id INT REFERENCES parcel_leg(id),
+    hub_id INT REFERENCES hub(id),
+    role TEXT NOT NULL,
+    scanner_id INT NOT NULL,
+    previous_status INT NOT NULL REFERENCES parcel_status(id),
+    status INT NOT NULL REFERENCES parcel_status(id),
+    parcel_status INT NOT NULL REFERENCES parcel_status(id),
+    scanned_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS scan_event_parcel_id ON scan_event (parcel_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS scan_event_parcel_id;
+
+DROP TABLE IF EXISTS scan_event;
+
+DROP TABLE IF EXISTS parcel_label;
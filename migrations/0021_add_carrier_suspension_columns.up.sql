@@ -0,0 +1,3 @@
+ALTER TABLE carrier_profile
+    ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS suspended_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE carrier_profile
+    DROP COLUMN IF EXISTS suspended_reason,
+    DROP COLUMN IF EXISTS suspended;
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS audit_log_immutable ON audit_log;
+
+DROP FUNCTION IF EXISTS reject_audit_log_change();
+
+DROP INDEX IF EXISTS audit_log_actor;
+
+DROP INDEX IF EXISTS audit_log_entity;
+
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS outbox_parcel_id ON outbox (parcel_id);